@@ -0,0 +1,139 @@
+package num
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// chunksPerWorker is the number of pieces that each worker's share of a
+// parallel evaluation is divided into. Using more than one chunk per worker
+// balances the load when f is more expensive in some regions than others.
+const chunksPerWorker = 4
+
+// BatchFunc1D is implemented by functions which can be evaluated at many
+// points at once. EvalBatch evaluates the function at every point in xs and
+// writes the results to ys, which must have the same length as xs.
+//
+// Routines in package num which evaluate a function at many points at once
+// accept a BatchFunc1D. Func1D implements BatchFunc1D by evaluating each
+// point in turn, so any Func1D can be used with these routines.
+type BatchFunc1D interface {
+	EvalBatch(xs, ys []float64)
+}
+
+// EvalBatch evaluates f at every point in xs in order and writes the results
+// to ys.
+//
+// EvalBatch panics if xs and ys have different lengths.
+func (f Func1D) EvalBatch(xs, ys []float64) {
+	if len(xs) != len(ys) {
+		panic(fmt.Sprintf("len(xs) = %d, but len(ys) = %d", len(xs), len(ys)))
+	}
+	for i := range xs { ys[i] = f(xs[i]) }
+}
+
+// ParallelFunc1D is a BatchFunc1D which evaluates an expensive Func1D over
+// many points concurrently.
+type ParallelFunc1D struct {
+	F       Func1D
+	Workers int
+}
+
+// Parallel returns a ParallelFunc1D which evaluates f using the given number
+// of goroutines. If workers is non-positive, runtime.GOMAXPROCS(0) goroutines
+// are used.
+//
+// f must be safe to call from multiple goroutines.
+func Parallel(f Func1D, workers int) *ParallelFunc1D {
+	return &ParallelFunc1D{f, workers}
+}
+
+// Eval evaluates p.F at a single point.
+func (p *ParallelFunc1D) Eval(x float64) float64 { return p.F(x) }
+
+// EvalBatch evaluates p.F at every point in xs concurrently and writes the
+// results to ys. ys[i] is always f(xs[i]), regardless of the order in which
+// the points were evaluated.
+//
+// EvalBatch panics if xs and ys have different lengths.
+func (p *ParallelFunc1D) EvalBatch(xs, ys []float64) {
+	if len(xs) != len(ys) {
+		panic(fmt.Sprintf("len(xs) = %d, but len(ys) = %d", len(xs), len(ys)))
+	}
+
+	workers := p.Workers
+	if workers <= 0 { workers = runtime.GOMAXPROCS(0) }
+	if workers > len(xs) { workers = len(xs) }
+	if workers <= 1 {
+		p.F.EvalBatch(xs, ys)
+		return
+	}
+
+	chunk := len(xs) / (workers * chunksPerWorker)
+	if chunk == 0 { chunk = 1 }
+
+	starts := make(chan int, workers)
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for start := range starts {
+				end := start + chunk
+				if end > len(xs) { end = len(xs) }
+				for i := start; i < end; i++ { ys[i] = p.F(xs[i]) }
+			}
+		}()
+	}
+
+	for start := 0; start < len(xs); start += chunk { starts <- start }
+	close(starts)
+	wg.Wait()
+}
+
+// ParallelEval evaluates f at every point in xs using the given number of
+// goroutines and returns the results in the same order as xs. If workers is
+// non-positive, runtime.GOMAXPROCS(0) goroutines are used.
+//
+// f must be safe to call from multiple goroutines.
+func ParallelEval(f Func1D, xs []float64, workers int) []float64 {
+	ys := make([]float64, len(xs))
+	Parallel(f, workers).EvalBatch(xs, ys)
+	return ys
+}
+
+// Tabulate evaluates f at n points uniformly spaced in either linear or
+// logarithmic space between low and high, inclusive, and returns the points
+// and the values of f at those points. All points are evaluated with a single
+// call to f.EvalBatch.
+//
+// Tabulate panics if n < 2 or if st is Log and low or high is non-positive.
+func Tabulate(f BatchFunc1D, low, high float64, n int, st ScaleType) (
+	xs, ys []float64,
+) {
+	if n < 2 {
+		panic(fmt.Sprintf("Tabulate given %d points, but at least 2 are "+
+			"required.", n))
+	} else if st == Log && (low <= 0 || high <= 0) {
+		panic(fmt.Sprintf("Tabulate given non-positive log-space bounds "+
+			"[%g, %g].", low, high))
+	}
+
+	xs, ys = make([]float64, n), make([]float64, n)
+	for i := range xs {
+		t := float64(i) / float64(n-1)
+		switch st {
+		case Log:
+			lLow, lHigh := math.Log10(low), math.Log10(high)
+			xs[i] = math.Pow(10, lLow+t*(lHigh-lLow))
+		case Linear:
+			xs[i] = low + t*(high-low)
+		}
+	}
+	xs[0], xs[n-1] = low, high
+
+	f.EvalBatch(xs, ys)
+	return xs, ys
+}
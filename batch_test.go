@@ -0,0 +1,106 @@
+package num
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+// countingFunc is a BatchFunc1D which records how many times EvalBatch was
+// called.
+type countingFunc struct {
+	f     Func1D
+	calls int
+}
+
+func (c *countingFunc) EvalBatch(xs, ys []float64) {
+	c.calls++
+	c.f.EvalBatch(xs, ys)
+}
+
+func TestParallelEval(t *testing.T) {
+	xs := make([]float64, 1001)
+	for i := range xs { xs[i] = float64(i) / 100 }
+
+	var evals int64
+	f := func(x float64) float64 {
+		atomic.AddInt64(&evals, 1)
+		return math.Sin(x) * math.Exp(-x)
+	}
+
+	for _, workers := range []int{-1, 0, 1, 3, 8, 5000} {
+		evals = 0
+		ys := ParallelEval(f, xs, workers)
+		if evals != int64(len(xs)) {
+			t.Errorf("ParallelEval(workers = %d) made %d evaluations, "+
+				"wanted %d", workers, evals, len(xs))
+		}
+		for i := range xs {
+			if exp := math.Sin(xs[i]) * math.Exp(-xs[i]); ys[i] != exp {
+				t.Errorf("ParallelEval(workers = %d)[%d] = %g, wanted %g",
+					workers, i, ys[i], exp)
+				break
+			}
+		}
+	}
+}
+
+func TestIntegralBatch(t *testing.T) {
+	f := func(x float64) float64 { return x * x }
+
+	for _, dt := range []DomainType{Flat, Spherical} {
+		cf := &countingFunc{f: f}
+		serial := Integral(f, 1, 1, Linear, dt)(3)
+		batch := IntegralBatch(cf, 1, 1, Linear, dt)(3)
+		parallel := IntegralBatch(Parallel(f, 4), 1, 1, Linear, dt)(3)
+
+		if serial != batch || serial != parallel {
+			t.Errorf("Integral(dt = %d) -> %g, but IntegralBatch gave %g "+
+				"and %g", dt, serial, batch, parallel)
+		} else if cf.calls != 1 {
+			t.Errorf("IntegralBatch called EvalBatch %d times, wanted 1.",
+				cf.calls)
+		}
+
+		xs1, ys1 := IntegralArray(f, 1, 1, Linear, dt)(3)
+		xs2, ys2 := IntegralArrayBatch(Parallel(f, 4), 1, 1, Linear, dt)(3)
+		if !floatsEq(xs1, xs2) || !floatsEq(ys1, ys2) {
+			t.Errorf("IntegralArrayBatch(dt = %d) does not match "+
+				"IntegralArray.", dt)
+		}
+	}
+}
+
+func TestTabulate(t *testing.T) {
+	sqr := Func1D(func(x float64) float64 { return x * x })
+
+	tests := []struct {
+		low, high float64
+		n         int
+		st        ScaleType
+		xs        []float64
+	}{
+		{0, 1, 5, Linear, []float64{0, 0.25, 0.5, 0.75, 1}},
+		{1, 1000, 4, Log, []float64{1, 10, 100, 1000}},
+	}
+
+	for _, test := range tests {
+		xs, ys := Tabulate(sqr, test.low, test.high, test.n, test.st)
+		for i := range test.xs {
+			if !AlmostEqual(xs[i], test.xs[i]) ||
+				!AlmostEqual(ys[i], test.xs[i]*test.xs[i]) {
+				t.Errorf("Tabulate(%g, %g, %d) -> %v, %v", test.low,
+					test.high, test.n, xs, ys)
+				break
+			}
+		}
+	}
+}
+
+func floatsEq(xs, ys []float64) bool {
+	if len(xs) != len(ys) { return false }
+	for i := range xs {
+		if xs[i] != ys[i] { return false }
+	}
+	return true
+}
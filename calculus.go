@@ -31,7 +31,9 @@ func Derivative(f Func1D, scale float64) Func1D {
 	}
 }
 
-func integrateBlock(f Func1D, center, width float64, st ScaleType) float64 {
+// blockBounds returns the midpoint and the length of an integration step
+// with the given center and width.
+func blockBounds(center, width float64, st ScaleType) (mid, length float64) {
 	var stepStart, stepEnd, stepMiddle float64
 
 	switch st {
@@ -45,7 +47,26 @@ func integrateBlock(f Func1D, center, width float64, st ScaleType) float64 {
 		stepMiddle = center
 	}
 
-	return f(stepMiddle) * (stepEnd - stepStart)
+	return stepMiddle, stepEnd - stepStart
+}
+
+func integrateBlock(f Func1D, center, width float64, st ScaleType) float64 {
+	mid, length := blockBounds(center, width, st)
+	return f(mid) * length
+}
+
+// integrateBlocks evaluates f at the midpoints of a sequence of integration
+// steps and writes the integral over each step to blocks.
+func integrateBlocks(f BatchFunc1D, mids, lengths, blocks []float64,
+	dt DomainType) {
+
+	f.EvalBatch(mids, blocks)
+	for i, x := range mids {
+		if dt == Spherical {
+			blocks[i] = blocks[i] * x * x * 4.0 * math.Pi
+		}
+		blocks[i] *= lengths[i]
+	}
 }
 
 // Integral returns a function that computest the integral of f starting
@@ -101,6 +122,57 @@ func Integral(f Func1D, xStart, scale float64, st ScaleType, dt DomainType) Func
 	}
 }
 
+// IntegralBatch is equivalent to Integral, but evaluates f at every step of
+// the integration with a single call to f.EvalBatch.
+func IntegralBatch(f BatchFunc1D, xStart, scale float64, st ScaleType, dt DomainType) Func1D {
+	if st == Log {
+		xStart = math.Log10(xStart)
+	}
+	dx := scale / 100.0
+
+	return func(xEnd float64) float64 {
+		var width, signedDx float64
+		if st == Log {
+			xEnd = math.Log10(xEnd)
+		}
+
+		if xStart == xEnd {
+			return 0
+		} else if xStart < xEnd {
+			width = xEnd - xStart
+			signedDx = dx
+		} else {
+			width = xStart - xEnd
+			signedDx = -dx
+		}
+
+		fullSteps := int(math.Floor(width / dx))
+
+		mids := make([]float64, fullSteps+1)
+		lengths := make([]float64, fullSteps+1)
+		blocks := make([]float64, fullSteps+1)
+
+		x := xStart + (signedDx / 2.0)
+		for i := 0; i < fullSteps; i++ {
+			mids[i], lengths[i] = blockBounds(x, signedDx, st)
+			x += signedDx
+		}
+
+		x -= signedDx / 2.0
+		mids[fullSteps], lengths[fullSteps] =
+			blockBounds(x+(xEnd-x)/2.0, xEnd-x, st)
+
+		integrateBlocks(f, mids, lengths, blocks, dt)
+
+		sum := 0.0
+		for _, block := range blocks {
+			sum += block
+		}
+
+		return sum
+	}
+}
+
 // IntegralArray is equivelent to Integral, but returns an array of the
 // the x values of the intermediate steps and the value of the integral
 // at those points.
@@ -158,3 +230,62 @@ func IntegralArray(f Func1D, xStart, scale float64, st ScaleType, dt DomainType)
 		return xs, ys
 	}
 }
+
+// IntegralArrayBatch is equivalent to IntegralArray, but evaluates f at every
+// step of the integration with a single call to f.EvalBatch.
+func IntegralArrayBatch(f BatchFunc1D, xStart, scale float64, st ScaleType, dt DomainType) Func1DArray {
+	if st == Log {
+		xStart = math.Log10(xStart)
+	}
+	dx := scale / 100.0
+
+	return func(xEnd float64) (xs, ys []float64) {
+		var width, signedDx float64
+		if st == Log {
+			xEnd = math.Log10(xEnd)
+		}
+
+		if xStart == xEnd {
+			return []float64{0.0}, []float64{0.0}
+		} else if xStart < xEnd {
+			width = xEnd - xStart
+			signedDx = dx
+		} else {
+			width = xStart - xEnd
+			signedDx = -dx
+		}
+
+		fullSteps := int(math.Floor(width / dx))
+
+		xs = make([]float64, fullSteps+2)
+		ys = make([]float64, fullSteps+2)
+
+		mids := make([]float64, fullSteps+1)
+		lengths := make([]float64, fullSteps+1)
+		blocks := make([]float64, fullSteps+1)
+
+		xs[0] = xStart
+		for i := 1; i <= fullSteps; i++ {
+			if i == 1 {
+				xs[i] = xStart + (signedDx / 2.0)
+			} else {
+				xs[i] = xs[i-1] + signedDx
+			}
+			mids[i-1], lengths[i-1] = blockBounds(xs[i], signedDx, st)
+		}
+
+		x := xs[fullSteps]
+		xs[fullSteps+1] = xEnd
+		mids[fullSteps], lengths[fullSteps] =
+			blockBounds(x+(xEnd-x)/2.0, xEnd-x, st)
+
+		integrateBlocks(f, mids, lengths, blocks, dt)
+
+		ys[0] = 0.0
+		for i, block := range blocks {
+			ys[i+1] = ys[i] + block
+		}
+
+		return xs, ys
+	}
+}
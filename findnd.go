@@ -0,0 +1,406 @@
+package num
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	lineSearchAlpha   = 1e-4
+	lineSearchMinStep = 1.0 / 1024
+)
+
+// FuncND represents a function which maps an N-dimensional vector onto
+// another N-dimensional vector. f(x, fx) evaluates the function at x and
+// writes the result to fx, which will always have the same length as x.
+// FuncND functions must not modify x.
+type FuncND func(x, fx []float64)
+
+// ConvergenceError is the error returned by iterative solvers which fail to
+// converge to a solution.
+type ConvergenceError struct {
+	OperationName string    // name of solver which gave error
+	Description   string    // description of the specifics of the error
+	Iters         int       // number of iterations performed
+	X             []float64 // best estimate of the solution
+	Residual      float64   // norm of the residual at X
+}
+
+// Error returns a string representation of err.
+func (err *ConvergenceError) Error() string {
+	return fmt.Sprintf("%s failed to converge after %d iterations: %s "+
+		"Residual norm is %g.", err.OperationName, err.Iters,
+		err.Description, err.Residual)
+}
+
+// FindZeroNewton finds an x at which the N-dimensional function f is zero
+// using Newton-Raphson iteration with a backtracking line search. The
+// Jacobian is computed by central differences with step size scale / 1e4,
+// where scale is the distance at which "interesting" features of f can be
+// seen. guess is not modified.
+//
+// The solution and the norm of the residual, |f(x)|, are returned. If the
+// iteration does not converge within ConvergenceIters steps, if the Jacobian
+// becomes singular, or if the line search cannot reduce the residual, a
+// non-nil *ConvergenceError is returned along with the best estimate found.
+func FindZeroNewton(f FuncND, guess []float64, scale float64) (
+	x []float64, resid float64, err error,
+) {
+	s := newNDSolver(f, guess, scale)
+	for i := 0; i < ConvergenceIters; i++ {
+		s.jacobian()
+		if !s.solveStep() {
+			return s.fail("FindZeroNewton", i, "Jacobian is singular.")
+		}
+		if !s.lineSearch() {
+			if s.converged() { return s.x, s.resid(), nil }
+			return s.fail("FindZeroNewton", i,
+				"Line search failed to reduce the residual.")
+		}
+		if s.converged() { return s.x, s.resid(), nil }
+	}
+	return s.fail("FindZeroNewton", ConvergenceIters,
+		"Iteration limit reached.")
+}
+
+// FindZeroBroyden finds an x at which the N-dimensional function f is zero
+// using Broyden's quasi-Newton method with a backtracking line search. The
+// Jacobian is computed once by central differences with step size
+// scale / 1e4 and is then updated with rank-one corrections, so each step
+// requires only a single evaluation of f. The Jacobian is recomputed if
+// a Broyden step fails to reduce the residual. guess is not modified.
+//
+// The solution and the norm of the residual, |f(x)|, are returned. If the
+// iteration does not converge within ConvergenceIters steps, if the Jacobian
+// becomes singular, or if the line search cannot reduce the residual, a
+// non-nil *ConvergenceError is returned along with the best estimate found.
+func FindZeroBroyden(f FuncND, guess []float64, scale float64) (
+	x []float64, resid float64, err error,
+) {
+	s := newNDSolver(f, guess, scale)
+	n := len(s.x)
+	prevX, prevFx := make([]float64, n), make([]float64, n)
+	df, jdx := make([]float64, n), make([]float64, n)
+
+	fresh := false
+	for i := 0; i < ConvergenceIters; i++ {
+		if i == 0 {
+			s.jacobian()
+			fresh = true
+		}
+		if !s.solveStep() {
+			return s.fail("FindZeroBroyden", i, "Jacobian is singular.")
+		}
+
+		copy(prevX, s.x)
+		copy(prevFx, s.fx)
+		if !s.lineSearch() {
+			if s.converged() {
+				return s.x, s.resid(), nil
+			} else if fresh {
+				return s.fail("FindZeroBroyden", i,
+					"Line search failed to reduce the residual.")
+			}
+			// The Broyden Jacobian has drifted too far to be useful.
+			s.jacobian()
+			fresh = true
+			continue
+		}
+		if s.converged() { return s.x, s.resid(), nil }
+
+		// J += ((df - J dx) dx^T) / (dx^T dx)
+		dxSqr := 0.0
+		for k := 0; k < n; k++ {
+			s.dx[k] = s.x[k] - prevX[k]
+			df[k] = s.fx[k] - prevFx[k]
+			dxSqr += s.dx[k] * s.dx[k]
+		}
+		if dxSqr == 0 { continue }
+		matVec(s.jac, s.dx, jdx)
+		for r := 0; r < n; r++ {
+			c := (df[r] - jdx[r]) / dxSqr
+			for k := 0; k < n; k++ { s.jac[r*n+k] += c * s.dx[k] }
+		}
+		fresh = false
+	}
+	return s.fail("FindZeroBroyden", ConvergenceIters,
+		"Iteration limit reached.")
+}
+
+// FindZeroPowell finds an x at which the N-dimensional function f is zero
+// using Powell's hybrid (dogleg) trust region method. Each step is a
+// combination of a Newton step and a steepest descent step on |f|^2, which
+// makes the method considerably more robust than Newton iteration when the
+// initial guess is poor. The Jacobian is computed by central differences with
+// step size scale / 1e4. guess is not modified.
+//
+// The solution and the norm of the residual, |f(x)|, are returned. If the
+// iteration does not converge within ConvergenceIters steps or if the trust
+// region collapses, a non-nil *ConvergenceError is returned along with the
+// best estimate found.
+func FindZeroPowell(f FuncND, guess []float64, scale float64) (
+	x []float64, resid float64, err error,
+) {
+	s := newNDSolver(f, guess, scale)
+	n := len(s.x)
+	g, jg := make([]float64, n), make([]float64, n)
+	sd, step := make([]float64, n), make([]float64, n)
+	xTrial, fxTrial := make([]float64, n), make([]float64, n)
+	jp := make([]float64, n)
+
+	delta := norm2(s.x)
+	if delta == 0 { delta = scale }
+
+	for i := 0; i < ConvergenceIters; i++ {
+		if s.phi == 0 { return s.x, 0, nil }
+		s.jacobian()
+
+		// Steepest descent direction g = J^T f and the Cauchy point.
+		for k := 0; k < n; k++ {
+			g[k] = 0
+			for r := 0; r < n; r++ { g[k] += s.jac[r*n+k] * s.fx[r] }
+		}
+		matVec(s.jac, g, jg)
+		gNorm, jgNorm := norm2(g), norm2(jg)
+		if gNorm == 0 {
+			return s.fail("FindZeroPowell", i,
+				"Converged to a stationary point of |f| which is not a zero.")
+		}
+		for k := range sd { sd[k] = -g[k] * (gNorm * gNorm) / (jgNorm * jgNorm) }
+
+		newtonOK := s.solveStep()
+
+		for {
+			dogleg(s.dx, sd, g, newtonOK, delta, step)
+			for k := range xTrial { xTrial[k] = s.x[k] + step[k] }
+			f(xTrial, fxTrial)
+			phiTrial := 0.5 * dot(fxTrial, fxTrial)
+
+			// Predicted reduction from the linear model.
+			matVec(s.jac, step, jp)
+			for k := range jp { jp[k] += s.fx[k] }
+			pred := s.phi - 0.5*dot(jp, jp)
+			// Trial points where f is not finite are rejected like any other
+			// poor step, which shrinks the trust region.
+			rho := 0.0
+			if pred > 0 && !math.IsNaN(phiTrial) && !math.IsInf(phiTrial, 0) {
+				rho = (s.phi - phiTrial) / pred
+			}
+
+			stepNorm := norm2(step)
+			if rho < 0.25 {
+				delta = 0.5 * stepNorm
+			} else if rho > 0.75 && stepNorm >= 0.99*delta {
+				delta = 2 * delta
+			}
+
+			if rho > lineSearchAlpha && phiTrial < s.phi {
+				copy(s.dx, step)
+				copy(s.x, xTrial)
+				copy(s.fx, fxTrial)
+				s.phi = phiTrial
+				break
+			}
+
+			if math.IsNaN(delta) || math.IsInf(delta, 0) {
+				return s.fail("FindZeroPowell", i, "Trust region is not finite.")
+			} else if delta <= ConvergenceEpsilon*(norm2(s.x)+scale*ConvergenceEpsilon) {
+				return s.fail("FindZeroPowell", i, "Trust region collapsed.")
+			}
+		}
+
+		if s.converged() { return s.x, s.resid(), nil }
+	}
+	return s.fail("FindZeroPowell", ConvergenceIters,
+		"Iteration limit reached.")
+}
+
+// dogleg computes the dogleg step within a trust region of radius delta and
+// writes it to step. newton is the Newton step, sd is the Cauchy step, and g
+// is the gradient of |f|^2 / 2. If newtonOK is false, only the steepest
+// descent direction is used.
+func dogleg(newton, sd, g []float64, newtonOK bool, delta float64,
+	step []float64) {
+
+	if newtonOK && norm2(newton) <= delta {
+		copy(step, newton)
+		return
+	}
+
+	sdNorm := norm2(sd)
+	if sdNorm >= delta {
+		gNorm := norm2(g)
+		for k := range step { step[k] = -delta * g[k] / gNorm }
+		return
+	} else if !newtonOK {
+		copy(step, sd)
+		return
+	}
+
+	// Find tau such that |sd + tau (newton - sd)| = delta.
+	a, b := 0.0, 0.0
+	for k := range sd {
+		d := newton[k] - sd[k]
+		a += d * d
+		b += 2 * sd[k] * d
+	}
+	c := sdNorm*sdNorm - delta*delta
+	tau := (-b + math.Sqrt(b*b-4*a*c)) / (2 * a)
+	for k := range step { step[k] = sd[k] + tau*(newton[k]-sd[k]) }
+}
+
+// ndSolver contains the state shared by the N-dimensional root finders.
+type ndSolver struct {
+	f     FuncND
+	scale float64
+	n     int
+
+	x, fx, dx []float64
+	phi       float64 // |fx|^2 / 2
+
+	jac, lu           []float64
+	piv               []int
+	xTmp, fTmp, fTmp2 []float64
+}
+
+func newNDSolver(f FuncND, guess []float64, scale float64) *ndSolver {
+	n := len(guess)
+	s := &ndSolver{
+		f: f, scale: scale, n: n,
+		x: make([]float64, n), fx: make([]float64, n),
+		dx: make([]float64, n),
+		jac: make([]float64, n*n), lu: make([]float64, n*n),
+		piv: make([]int, n),
+		xTmp: make([]float64, n), fTmp: make([]float64, n),
+		fTmp2: make([]float64, n),
+	}
+	copy(s.x, guess)
+	f(s.x, s.fx)
+	s.phi = 0.5 * dot(s.fx, s.fx)
+	return s
+}
+
+func (s *ndSolver) resid() float64 { return math.Sqrt(2 * s.phi) }
+
+func (s *ndSolver) fail(name string, iters int, desc string) (
+	[]float64, float64, error,
+) {
+	err := &ConvergenceError{name, desc, iters, s.x, s.resid()}
+	if PanicOnError { panic(err.Error()) }
+	return s.x, s.resid(), err
+}
+
+// jacobian computes the Jacobian of f at s.x via central differences.
+func (s *ndSolver) jacobian() {
+	h := s.scale / 1e4
+	n := s.n
+	copy(s.xTmp, s.x)
+	for c := 0; c < n; c++ {
+		s.xTmp[c] = s.x[c] + h
+		s.f(s.xTmp, s.fTmp)
+		s.xTmp[c] = s.x[c] - h
+		s.f(s.xTmp, s.fTmp2)
+		s.xTmp[c] = s.x[c]
+		for r := 0; r < n; r++ {
+			s.jac[r*n+c] = (s.fTmp[r] - s.fTmp2[r]) / (2 * h)
+		}
+	}
+}
+
+// solveStep solves J dx = -f for the Newton step. False is returned if the
+// Jacobian is singular.
+func (s *ndSolver) solveStep() bool {
+	copy(s.lu, s.jac)
+	if !luDecompose(s.lu, s.piv, s.n) { return false }
+	for i := range s.dx { s.dx[i] = -s.fx[i] }
+	luSolve(s.lu, s.piv, s.n, s.dx)
+	return true
+}
+
+// lineSearch moves s.x along s.dx, backtracking until |f|^2 decreases
+// sufficiently, and returns true. On return, s.dx contains the step which was
+// actually taken. If no step of at least lineSearchMinStep times s.dx
+// decreases |f|^2 sufficiently, s.x is not changed and false is returned.
+func (s *ndSolver) lineSearch() bool {
+	phi0 := s.phi
+	for lambda := 1.0; lambda >= lineSearchMinStep; lambda /= 2 {
+		for i := range s.xTmp { s.xTmp[i] = s.x[i] + lambda*s.dx[i] }
+		s.f(s.xTmp, s.fTmp)
+		phi := 0.5 * dot(s.fTmp, s.fTmp)
+
+		// The directional derivative of phi along dx is -2 phi0. NaNs fail
+		// this comparison and are rejected.
+		if phi <= phi0*(1-2*lineSearchAlpha*lambda) {
+			for i := range s.dx { s.dx[i] *= lambda }
+			copy(s.x, s.xTmp)
+			copy(s.fx, s.fTmp)
+			s.phi = phi
+			return true
+		}
+	}
+	return false
+}
+
+// converged returns true if the last step was negligible relative to x or if
+// f(x) is exactly zero.
+func (s *ndSolver) converged() bool {
+	if s.phi == 0 { return true }
+	tol := ConvergenceEpsilon * (norm2(s.x) + s.scale*ConvergenceEpsilon)
+	return norm2(s.dx) <= tol
+}
+
+// luDecompose performs an in-place LU decomposition with partial pivoting on
+// the row-major n x n matrix a. False is returned if a is singular.
+func luDecompose(a []float64, piv []int, n int) bool {
+	for k := 0; k < n; k++ {
+		p, max := k, math.Abs(a[k*n+k])
+		for r := k + 1; r < n; r++ {
+			if v := math.Abs(a[r*n+k]); v > max { p, max = r, v }
+		}
+		piv[k] = p
+		if max == 0 { return false }
+		if p != k {
+			for c := 0; c < n; c++ {
+				a[k*n+c], a[p*n+c] = a[p*n+c], a[k*n+c]
+			}
+		}
+
+		for r := k + 1; r < n; r++ {
+			a[r*n+k] /= a[k*n+k]
+			l := a[r*n+k]
+			for c := k + 1; c < n; c++ { a[r*n+c] -= l * a[k*n+c] }
+		}
+	}
+	return true
+}
+
+// luSolve solves a x = b in place using the output of luDecompose.
+func luSolve(lu []float64, piv []int, n int, b []float64) {
+	for k := 0; k < n; k++ {
+		b[k], b[piv[k]] = b[piv[k]], b[k]
+	}
+	for r := 1; r < n; r++ {
+		for c := 0; c < r; c++ { b[r] -= lu[r*n+c] * b[c] }
+	}
+	for r := n - 1; r >= 0; r-- {
+		for c := r + 1; c < n; c++ { b[r] -= lu[r*n+c] * b[c] }
+		b[r] /= lu[r*n+r]
+	}
+}
+
+func matVec(a, x, out []float64) {
+	n := len(x)
+	for r := range out {
+		sum := 0.0
+		for c := 0; c < n; c++ { sum += a[r*n+c] * x[c] }
+		out[r] = sum
+	}
+}
+
+func dot(x, y []float64) float64 {
+	sum := 0.0
+	for i := range x { sum += x[i] * y[i] }
+	return sum
+}
+
+func norm2(x []float64) float64 { return math.Sqrt(dot(x, x)) }
@@ -0,0 +1,128 @@
+package num
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFindZeroND(t *testing.T) {
+	// Intersection of the unit circle with the line y = x.
+	circle := func(x, fx []float64) {
+		fx[0] = x[0]*x[0] + x[1]*x[1] - 1
+		fx[1] = x[0] - x[1]
+	}
+	// Gradient of the Rosenbrock function, which is zero at (1, 1).
+	rosen := func(x, fx []float64) {
+		fx[0] = 10 * (x[1] - x[0]*x[0])
+		fx[1] = 1 - x[0]
+	}
+	// A coupled system with a solution at (1, 2, 3).
+	cubic := func(x, fx []float64) {
+		fx[0] = x[0]*x[1] - 2
+		fx[1] = x[1]*x[2] - 6
+		fx[2] = x[0] + x[1]*x[1] + x[2]*x[2]*x[2] - 32
+	}
+
+	solvers := []struct {
+		name string
+		f    func(FuncND, []float64, float64) ([]float64, float64, error)
+	}{
+		{"FindZeroNewton", FindZeroNewton},
+		{"FindZeroBroyden", FindZeroBroyden},
+		{"FindZeroPowell", FindZeroPowell},
+	}
+
+	r := math.Sqrt(0.5)
+	tests := []struct {
+		name       string
+		f          FuncND
+		guess, exp []float64
+	}{
+		{"circle", circle, []float64{2, 0.5}, []float64{r, r}},
+		{"rosen", rosen, []float64{-1.2, 1}, []float64{1, 1}},
+		{"cubic", cubic, []float64{1.5, 1.5, 2.5}, []float64{1, 2, 3}},
+	}
+
+	for _, solver := range solvers {
+		for _, test := range tests {
+			guess := append([]float64{}, test.guess...)
+			x, resid, err := solver.f(test.f, guess, 1.0)
+			if err != nil {
+				t.Errorf("%s(%s) returned error: %s", solver.name,
+					test.name, err.Error())
+				continue
+			}
+
+			for i := range x {
+				if math.Abs(x[i]-test.exp[i]) > 1e-6 {
+					t.Errorf("%s(%s) -> %v (resid = %g), wanted %v",
+						solver.name, test.name, x, resid, test.exp)
+					break
+				}
+			}
+
+			for i := range guess {
+				if guess[i] != test.guess[i] {
+					t.Errorf("%s(%s) modified its guess.",
+						solver.name, test.name)
+				}
+			}
+		}
+	}
+}
+
+func TestFindZeroNDDomain(t *testing.T) {
+	// log(x) - 1 is NaN for x < 0, where the first full Newton step from a
+	// large guess lands.
+	logFunc := func(x, fx []float64) { fx[0] = math.Log(x[0]) - 1 }
+
+	solvers := []struct {
+		name string
+		f    func(FuncND, []float64, float64) ([]float64, float64, error)
+	}{
+		{"FindZeroNewton", FindZeroNewton},
+		{"FindZeroBroyden", FindZeroBroyden},
+		{"FindZeroPowell", FindZeroPowell},
+	}
+	for _, solver := range solvers {
+		for _, guess := range []float64{10, 20, 50} {
+			x, _, err := solver.f(logFunc, []float64{guess}, 1.0)
+			if err != nil {
+				t.Errorf("%s(log(x) - 1) from %g gave error %v",
+					solver.name, guess, err)
+			} else if math.Abs(x[0]-math.E) > 1e-8 {
+				t.Errorf("%s(log(x) - 1) from %g gave %.10g, wanted e",
+					solver.name, guess, x[0])
+			}
+		}
+	}
+}
+
+func TestFindZeroNDError(t *testing.T) {
+	// x^2 + 1 has no real zeros.
+	noZero := func(x, fx []float64) {
+		fx[0] = x[0]*x[0] + 1
+		fx[1] = x[1]
+	}
+
+	_, _, err := FindZeroNewton(noZero, []float64{0.5, 0.5}, 1.0)
+	if _, ok := err.(*ConvergenceError); !ok {
+		t.Errorf("FindZeroNewton on function with no zeros gave error %v, "+
+			"wanted *ConvergenceError", err)
+	}
+
+	_, _, err = FindZeroPowell(noZero, []float64{0.5, 0.5}, 1.0)
+	if _, ok := err.(*ConvergenceError); !ok {
+		t.Errorf("FindZeroPowell on function with no zeros gave error %v, "+
+			"wanted *ConvergenceError", err)
+	}
+
+	// sqrt(x) + 1 has no zeros and is NaN for x < 0, where the trust region
+	// steps push the iteration.
+	nanFunc := func(x, fx []float64) { fx[0] = math.Sqrt(x[0]) + 1 }
+	_, _, err = FindZeroPowell(nanFunc, []float64{1}, 1.0)
+	if _, ok := err.(*ConvergenceError); !ok {
+		t.Errorf("FindZeroPowell on NaN-producing function gave error %v, "+
+			"wanted *ConvergenceError", err)
+	}
+}
@@ -0,0 +1,315 @@
+package num
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	defaultAndersonDepth = 5
+	andersonDropTol      = 1e-10
+)
+
+// FixedPointMethod specifies the acceleration scheme used by FixedPoint and
+// FixedPointND.
+type FixedPointMethod int
+
+const (
+	// Picard performs unaccelerated iteration, x_{k+1} = g(x_k).
+	Picard FixedPointMethod = iota
+	// Anderson performs Anderson acceleration (also known as Pulay mixing or
+	// DIIS), which extrapolates from the last few iterates.
+	Anderson
+	// Steffensen applies Aitken's delta-squared process to every pair of
+	// iterations. It is only supported by FixedPoint.
+	Steffensen
+)
+
+// FixedPointHistory records the progress of a fixed-point iteration.
+type FixedPointHistory struct {
+	// Iterates[k] is the kth iterate, x_k.
+	Iterates [][]float64
+	// Residuals[k] is |g(x_k) - x_k|.
+	Residuals []float64
+	// Evals is the total number of times that g was called.
+	Evals int
+}
+
+func (h *FixedPointHistory) record(x []float64, resid float64) {
+	h.Iterates = append(h.Iterates, append([]float64{}, x...))
+	h.Residuals = append(h.Residuals, resid)
+}
+
+type fixedPointParams struct {
+	method  FixedPointMethod
+	damping float64
+	depth   int
+	iters   int
+}
+
+type fixedPointOption func(*fixedPointParams)
+
+// FixedPointOptions are passed to FixedPoint and FixedPointND as variadic
+// arguments to customize their behavior.
+type FixedPointOption fixedPointOption
+
+// Accelerate sets the acceleration scheme used by the iteration. By default,
+// Picard iteration is used.
+func Accelerate(method FixedPointMethod) FixedPointOption {
+	return func(p *fixedPointParams) { p.method = method }
+}
+
+// Damping sets the damping (or mixing) parameter, alpha. Each iteration uses
+// the map (1 - alpha) x + alpha g(x) in place of g(x), which can stabilize
+// oscillating iterations. alpha must be in (0, 1] and is 1 by default.
+func Damping(alpha float64) FixedPointOption {
+	return func(p *fixedPointParams) { p.damping = alpha }
+}
+
+// AndersonDepth sets the number of previous iterates used by Anderson
+// acceleration. By default, five iterates are used.
+func AndersonDepth(depth int) FixedPointOption {
+	return func(p *fixedPointParams) { p.depth = depth }
+}
+
+// FixedPointIters sets the maximum number of iterations. By default,
+// ConvergenceIters iterations are allowed.
+func FixedPointIters(iters int) FixedPointOption {
+	return func(p *fixedPointParams) { p.iters = iters }
+}
+
+func (p *fixedPointParams) load(opts []FixedPointOption) {
+	p.method, p.damping = Picard, 1
+	p.depth, p.iters = defaultAndersonDepth, ConvergenceIters
+	for _, opt := range opts { opt(p) }
+
+	if p.damping <= 0 || p.damping > 1 {
+		panic(fmt.Sprintf("Damping parameter %g is not in (0, 1].", p.damping))
+	} else if p.depth <= 0 {
+		panic(fmt.Sprintf("Anderson depth %d is non-positive.", p.depth))
+	} else if p.iters <= 0 {
+		panic(fmt.Sprintf("Iteration limit %d is non-positive.", p.iters))
+	}
+}
+
+// FixedPoint finds an x such that g(x) = x by iterating g from the given
+// initial guess. Iteration stops once CloseEnough(x, g(x) - x) is satisfied.
+//
+// The fixed point and a record of every iterate are returned. If the
+// iteration does not converge, a non-nil *ConvergenceError is returned along
+// with the last iterate.
+//
+// Supported options are:
+//
+//     Accelerate(method)
+//     Damping(alpha)
+//     AndersonDepth(depth)
+//     FixedPointIters(iters)
+func FixedPoint(g Func1D, guess float64, opts ...FixedPointOption) (
+	x float64, hist *FixedPointHistory, err error,
+) {
+	p := &fixedPointParams{}
+	p.load(opts)
+
+	if p.method != Steffensen {
+		gND := func(x, gx []float64) { gx[0] = g(x[0]) }
+		xs, hist, err := fixedPointND(gND, []float64{guess}, p, "FixedPoint")
+		return xs[0], hist, err
+	}
+
+	hist = &FixedPointHistory{}
+	alpha := p.damping
+	damped := func(x float64) float64 {
+		hist.Evals++
+		return (1-alpha)*x + alpha*g(x)
+	}
+
+	x = guess
+	for i := 0; i < p.iters; i++ {
+		x1 := damped(x)
+		resid := (x1 - x) / alpha
+		hist.record([]float64{x}, math.Abs(resid))
+		if resid == 0 || CloseEnough(x, resid) { return x, hist, nil }
+
+		x2 := damped(x1)
+		denom := x2 - 2*x1 + x
+		if denom == 0 {
+			x = x2
+		} else {
+			x = x - (x1-x)*(x1-x)/denom
+		}
+
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return x, hist, fixedPointError("FixedPoint", i+1,
+				"Iteration diverged.", []float64{x}, math.Abs(resid))
+		}
+	}
+
+	return x, hist, fixedPointError("FixedPoint", p.iters,
+		"Iteration limit reached.", []float64{x},
+		hist.Residuals[len(hist.Residuals)-1])
+}
+
+// FixedPointND finds an x such that g(x) = x for a vector-valued map by
+// iterating g from the given initial guess. Iteration stops once
+// CloseEnough(|x|, |g(x) - x|) is satisfied. guess is not modified.
+//
+// The fixed point and a record of every iterate are returned. If the
+// iteration does not converge, a non-nil *ConvergenceError is returned along
+// with the last iterate.
+//
+// Supported options are:
+//
+//     Accelerate(method)
+//     Damping(alpha)
+//     AndersonDepth(depth)
+//     FixedPointIters(iters)
+//
+// Steffensen acceleration is not supported and will cause a panic.
+func FixedPointND(g FuncND, guess []float64, opts ...FixedPointOption) (
+	x []float64, hist *FixedPointHistory, err error,
+) {
+	p := &fixedPointParams{}
+	p.load(opts)
+	if p.method == Steffensen {
+		panic("Steffensen acceleration is not supported by FixedPointND.")
+	}
+	return fixedPointND(g, guess, p, "FixedPointND")
+}
+
+func fixedPointND(g FuncND, guess []float64, p *fixedPointParams,
+	name string) ([]float64, *FixedPointHistory, error) {
+
+	n := len(guess)
+	hist := &FixedPointHistory{}
+	x, gx, f := make([]float64, n), make([]float64, n), make([]float64, n)
+	copy(x, guess)
+
+	depth := 0
+	if p.method == Anderson { depth = p.depth }
+	acc := newAnderson(n, depth)
+
+	alpha := p.damping
+	for i := 0; i < p.iters; i++ {
+		g(x, gx)
+		hist.Evals++
+		for k := range f { f[k] = gx[k] - x[k] }
+
+		resid := norm2(f)
+		hist.record(x, resid)
+		if resid == 0 || CloseEnough(norm2(x), resid) {
+			return x, hist, nil
+		} else if math.IsNaN(resid) || math.IsInf(resid, 0) {
+			return x, hist, fixedPointError(name, i, "Iteration diverged.",
+				x, resid)
+		}
+
+		// Damped map: G(x) = x + alpha f.
+		for k := range gx { gx[k] = x[k] + alpha*f[k] }
+		for k := range f { f[k] *= alpha }
+		acc.next(gx, f, x)
+	}
+
+	return x, hist, fixedPointError(name, p.iters, "Iteration limit reached.",
+		x, hist.Residuals[len(hist.Residuals)-1])
+}
+
+func fixedPointError(name string, iters int, desc string, x []float64,
+	resid float64) error {
+
+	err := &ConvergenceError{name, desc, iters, x, resid}
+	if PanicOnError { panic(err.Error()) }
+	return err
+}
+
+// anderson stores the state of an Anderson-accelerated iteration. It keeps
+// up to depth differences of the map values, dG, and residuals, dF.
+type anderson struct {
+	n, depth int
+	dG, dF   [][]float64
+	prevG    []float64
+	prevF    []float64
+	started  bool
+
+	q     [][]float64
+	r     []float64
+	gamma []float64
+}
+
+func newAnderson(n, depth int) *anderson {
+	a := &anderson{n: n, depth: depth}
+	a.prevG, a.prevF = make([]float64, n), make([]float64, n)
+	a.q = make([][]float64, depth)
+	for i := range a.q { a.q[i] = make([]float64, n) }
+	a.r = make([]float64, depth*depth)
+	a.gamma = make([]float64, depth)
+	return a
+}
+
+// next computes the next iterate from the value of the map, gx, and the
+// residual, f = gx - x, at the current iterate and writes it to x.
+func (a *anderson) next(gx, f, x []float64) {
+	if a.depth == 0 {
+		copy(x, gx)
+		return
+	}
+
+	if a.started {
+		dg, df := make([]float64, a.n), make([]float64, a.n)
+		if len(a.dG) == a.depth {
+			dg, df = a.dG[0], a.dF[0]
+			a.dG, a.dF = a.dG[1:], a.dF[1:]
+		}
+		for k := range dg {
+			dg[k] = gx[k] - a.prevG[k]
+			df[k] = f[k] - a.prevF[k]
+		}
+		a.dG, a.dF = append(a.dG, dg), append(a.dF, df)
+	}
+	copy(a.prevG, gx)
+	copy(a.prevF, f)
+	a.started = true
+
+	copy(x, gx)
+	cols := a.solve(f)
+	for j, c := range cols {
+		for k := range x { x[k] -= a.gamma[j] * a.dG[c][k] }
+	}
+}
+
+// solve finds gamma minimizing |f - dF gamma| using modified Gram-Schmidt,
+// dropping columns of dF which are nearly linearly dependent on the more
+// recent ones. The indices of the columns which were used are returned and
+// the corresponding coefficients are written to a.gamma.
+func (a *anderson) solve(f []float64) []int {
+	m := len(a.dF)
+	cols := make([]int, 0, m)
+	for c := m - 1; c >= 0; c-- {
+		j := len(cols)
+		q := a.q[j]
+		copy(q, a.dF[c])
+		orig := norm2(q)
+		for i := 0; i < j; i++ {
+			rij := dot(a.q[i], q)
+			a.r[i*a.depth+j] = rij
+			for k := range q { q[k] -= rij * a.q[i][k] }
+		}
+		rjj := norm2(q)
+		if rjj <= andersonDropTol*orig || rjj == 0 { continue }
+		a.r[j*a.depth+j] = rjj
+		for k := range q { q[k] /= rjj }
+		cols = append(cols, c)
+		if len(cols) == a.n { break }
+	}
+
+	// Solve R gamma = Q^T f by back substitution.
+	nc := len(cols)
+	for i := 0; i < nc; i++ { a.gamma[i] = dot(a.q[i], f) }
+	for i := nc - 1; i >= 0; i-- {
+		for j := i + 1; j < nc; j++ {
+			a.gamma[i] -= a.r[i*a.depth+j] * a.gamma[j]
+		}
+		a.gamma[i] /= a.r[i*a.depth+i]
+	}
+	return cols
+}
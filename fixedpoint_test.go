@@ -0,0 +1,90 @@
+package num
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFixedPoint(t *testing.T) {
+	// The Dottie number, cos(x) = x.
+	dottie := 0.7390851332151607
+	// x = 3.2 x (1 - x) oscillates about its fixed point at 1 - 1/3.2
+	// without damping.
+	logistic := func(x float64) float64 { return 3.2 * x * (1 - x) }
+
+	tests := []struct {
+		name       string
+		g          Func1D
+		guess, exp float64
+		opts       []FixedPointOption
+	}{
+		{"cos", math.Cos, 1, dottie, nil},
+		{"cos, Anderson", math.Cos, 1, dottie,
+			[]FixedPointOption{Accelerate(Anderson)}},
+		{"cos, Steffensen", math.Cos, 1, dottie,
+			[]FixedPointOption{Accelerate(Steffensen)}},
+		{"logistic, damped", logistic, 0.5, 1 - 1/3.2,
+			[]FixedPointOption{Damping(0.3)}},
+		{"logistic, Anderson", logistic, 0.5, 1 - 1/3.2,
+			[]FixedPointOption{Accelerate(Anderson)}},
+		{"logistic, Steffensen", logistic, 0.5, 1 - 1/3.2,
+			[]FixedPointOption{Accelerate(Steffensen)}},
+	}
+
+	for _, test := range tests {
+		x, hist, err := FixedPoint(test.g, test.guess, test.opts...)
+		if err != nil {
+			t.Errorf("FixedPoint(%s) returned error: %s", test.name, err)
+		} else if math.Abs(x-test.exp) > 1e-6 {
+			t.Errorf("FixedPoint(%s) -> %.10g, wanted %.10g",
+				test.name, x, test.exp)
+		} else if len(hist.Iterates) != len(hist.Residuals) ||
+			hist.Iterates[0][0] != test.guess {
+			t.Errorf("FixedPoint(%s) returned inconsistent history.",
+				test.name)
+		}
+	}
+
+	_, _, err := FixedPoint(logistic, 0.5)
+	if _, ok := err.(*ConvergenceError); !ok {
+		t.Errorf("FixedPoint(logistic) gave error %v, wanted "+
+			"*ConvergenceError", err)
+	}
+}
+
+func TestFixedPointND(t *testing.T) {
+	// Jacobi iteration for a diagonally dominant linear system with
+	// solution (1, 2, 3).
+	jacobi := func(x, gx []float64) {
+		gx[0] = (9 - x[1] - x[2]) / 4
+		gx[1] = (10 - x[0] - x[2]) / 3
+		gx[2] = (15 - x[0] - x[1]) / 4
+	}
+	exp := []float64{1, 2, 3}
+
+	for _, method := range []FixedPointMethod{Picard, Anderson} {
+		guess := []float64{0, 0, 0}
+		x, hist, err := FixedPointND(
+			jacobi, guess, Accelerate(method), FixedPointIters(1000),
+		)
+		if err != nil {
+			t.Errorf("FixedPointND(method = %d) returned error: %s",
+				method, err)
+			continue
+		}
+		for i := range x {
+			if math.Abs(x[i]-exp[i]) > 1e-5 {
+				t.Errorf("FixedPointND(method = %d) -> %v, wanted %v",
+					method, x, exp)
+				break
+			}
+		}
+		if guess[0] != 0 || guess[1] != 0 || guess[2] != 0 {
+			t.Errorf("FixedPointND(method = %d) modified guess.", method)
+		}
+		if method == Anderson && len(hist.Iterates) > 20 {
+			t.Errorf("FixedPointND(method = Anderson) took %d iterations.",
+				len(hist.Iterates))
+		}
+	}
+}
@@ -233,6 +233,13 @@ func (s *Spline) EvalAll(xs []float64, out ...[]float64) []float64 {
 	return evalOut
 }
 
+// EvalBatch writes the value of the spline at each point in xs to ys. This
+// allows a Spline to be used as a num.BatchFunc1D, for example with
+// num.Tabulate or num.IntegralBatch.
+//
+// EvalBatch panics if xs and ys have different lengths.
+func (s *Spline) EvalBatch(xs, ys []float64) { s.EvalAll(xs, ys) }
+
 // Deriv calculates the derivative of the spline at the given point to the 
 // specified order.
 func (s *Spline) Deriv(x float64, order int) float64 {
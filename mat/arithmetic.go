@@ -1,11 +1,31 @@
 package mat
 
+import (
+	"fmt"
+
+	"github.com/phil-mansfield/num/mat/optmat"
+)
+
+const (
+	// Block sizes used by Mult. A panel of multBlockK x multBlockJ elements of
+	// the right-hand Matrix is stored transposed so that it fits in L2 cache
+	// while rows of the left-hand Matrix are streamed past it.
+	multBlockK = 256
+	multBlockJ = 64
+	// optmatWork is the number of multiply-adds above which Mult and MulVec
+	// delegate to the parallel kernels in package optmat.
+	optmatWork = 1 << 18
+)
+
 // Add computes m1 + m2 and returns the result.
 //
 // If m1 and m2 are not the same shape or if either are nil, an error Matrix is
 // returned.
 func Add(m1, m2 *Matrix) *Matrix {
-	return nil
+	if err := inputError("Add", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m1.width, m1.height).Add(m1, m2)
 }
 
 // Sub computes m1 - m2 and returns the result.
@@ -13,7 +33,10 @@ func Add(m1, m2 *Matrix) *Matrix {
 // If m1 and m2 are not the same size or if either are nil, an error Matrix is
 // returned.
 func Sub(m1, m2 *Matrix) *Matrix {
-	return nil
+	if err := inputError("Sub", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m1.width, m1.height).Sub(m1, m2)
 }
 
 // Mult computes m1 * m2 and returns the result.
@@ -21,14 +44,20 @@ func Sub(m1, m2 *Matrix) *Matrix {
 // If the width of m1 is not the same as the height of m2 or if either are
 // nil, an error Matrix is returned.
 func Mult(m1, m2 *Matrix) *Matrix {
-	return nil
+	if err := inputError("Mult", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m2.width, m1.height).Mult(m1, m2)
 }
 
 // Scale multiplies every element in m by c and returns the result.
 //
 // If m is nil, an error Matrix is returned.
 func Scale(m *Matrix, c float64) *Matrix {
-	return nil
+	if err := inputError("Scale", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).Scale(m, c)
 }
 
 // Add computes m1 + m2 and stores the result in the target Matrix. The target
@@ -37,7 +66,22 @@ func Scale(m *Matrix, c float64) *Matrix {
 // If m1, m2, and target are not all the same size or if either input Matrix is
 // nil, target is set to an error Matrix.
 func (target *Matrix) Add(m1, m2 *Matrix) *Matrix {
-	return nil
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Add(m1, m2) })
+	}
+	m1, m2 = target.operand(m1), target.operand(m2)
+
+	if err := target.checkElementwise("Add", m1, m2); err != nil {
+		return target.setError(err)
+	}
+
+	v1, v2, out := m1.values, m2.values, target.values
+	for i := range out {
+		out[i] = v1[i] + v2[i]
+	}
+
+	target.err = nil
+	return target
 }
 
 // Sub computes m1 - m2 and stores the result in the target Matrix. The target
@@ -46,7 +90,22 @@ func (target *Matrix) Add(m1, m2 *Matrix) *Matrix {
 // If m1, m2, and target are not all the same size or if either input Matrix is
 // nil, target is set to an error Matrix.
 func (target *Matrix) Sub(m1, m2 *Matrix) *Matrix {
-	return nil
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Sub(m1, m2) })
+	}
+	m1, m2 = target.operand(m1), target.operand(m2)
+
+	if err := target.checkElementwise("Sub", m1, m2); err != nil {
+		return target.setError(err)
+	}
+
+	v1, v2, out := m1.values, m2.values, target.values
+	for i := range out {
+		out[i] = v1[i] - v2[i]
+	}
+
+	target.err = nil
+	return target
 }
 
 // Mult computes m1 * m2 and stores the result in the target matrix. The target
@@ -56,6 +115,110 @@ func (target *Matrix) Sub(m1, m2 *Matrix) *Matrix {
 // not have the same width as m2 and the same height as m1, or if either input
 // Matrix is nil, target is set to an error Matrix.
 func (target *Matrix) Mult(m1, m2 *Matrix) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Mult(m1, m2) })
+	}
+	m1, m2 = target.operand(m1), target.operand(m2)
+
+	if err := inputError("Mult", m1, m2); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "Mult", "Target Matrix is nil.")
+	} else if !MultCompatible(m1, m2) {
+		desc := fmt.Sprintf("Input shapes (%d, %d) and (%d, %d) cannot be multiplied.",
+			m1.width, m1.height, m2.width, m2.height)
+		return target.setError(newError(ShapeError, "Mult", desc))
+	} else if target.width != m2.width || target.height != m1.height {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match product shape (%d, %d).",
+			target.width, target.height, m2.width, m1.height)
+		return target.setError(newError(ShapeError, "Mult", desc))
+	}
+
+	out := target.values
+	if overlaps(target, m1) || overlaps(target, m2) {
+		out = make([]float64, len(target.values))
+	}
+
+	n, k, w := m1.height, m1.width, m2.width
+	if float64(n) * float64(k) * float64(w) >= optmatWork {
+		optmat.Gemm(optmat.NoTrans, optmat.NoTrans, n, w, k,
+			1, m1.values, k, m2.values, w, 0, out, w)
+	} else {
+		multKernel(m1.values, m2.values, out, n, k, w)
+	}
+
+	if &out[0] != &target.values[0] {
+		copy(target.values, out)
+	}
+	target.err = nil
+	return target
+}
+
+// MulVec computes the matrix-vector product m * x and returns the result.
+//
+// If m is nil or an error Matrix, or if len(x) is not equal to the width of
+// m, a non-nil error is returned.
+func (m *Matrix) MulVec(x []float64) ([]float64, error) {
+	if err := m.checkMulVec("MulVec", x, nil); err != nil {
+		return nil, err
+	}
+
+	target := make([]float64, m.height)
+	m.mulVec(x, target)
+	return target, nil
+}
+
+// MulVecAt computes the matrix-vector product m * x and stores the result in
+// target. target may be the same slice as x.
+//
+// If m is nil or an error Matrix, if len(x) is not equal to the width of m,
+// or if len(target) is not equal to the height of m, a non-nil error is
+// returned.
+func (m *Matrix) MulVecAt(x, target []float64) error {
+	if err := m.checkMulVec("MulVecAt", x, target); err != nil {
+		return err
+	}
+
+	if len(x) > 0 && &x[0] == &target[0] {
+		out := make([]float64, len(target))
+		m.mulVec(x, out)
+		copy(target, out)
+	} else {
+		m.mulVec(x, target)
+	}
+	return nil
+}
+
+func (m *Matrix) mulVec(x, target []float64) {
+	if float64(m.width) * float64(m.height) >= optmatWork {
+		optmat.Gemv(optmat.NoTrans, m.height, m.width,
+			1, m.values, m.stride, x, 1, 0, target, 1)
+		return
+	}
+
+	for y := 0; y < m.height; y++ {
+		sum := 0.0
+		for i, val := range m.row(y) {
+			sum += val * x[i]
+		}
+		target[y] = sum
+	}
+}
+
+// checkMulVec returns an error if m is invalid or if x and target have the
+// wrong lengths. target is not checked if it is nil.
+func (m *Matrix) checkMulVec(operationName string, x, target []float64) *MatrixError {
+	if err := inputError(operationName, m); err != nil {
+		return err
+	} else if len(x) != m.width {
+		desc := fmt.Sprintf("Length of input vector, %d, does not match Matrix width %d.",
+			len(x), m.width)
+		return newError(ShapeError, operationName, desc)
+	} else if target != nil && len(target) != m.height {
+		desc := fmt.Sprintf("Length of target vector, %d, does not match Matrix height %d.",
+			len(target), m.height)
+		return newError(ShapeError, operationName, desc)
+	}
 	return nil
 }
 
@@ -65,5 +228,127 @@ func (target *Matrix) Mult(m1, m2 *Matrix) *Matrix {
 // If m and target are not the same size or if m is nil, target is set to an
 // error Matrix.
 func (target *Matrix) Scale(m *Matrix, c float64) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Scale(m, c) })
+	}
+	m = target.operand(m)
+
+	if err := target.checkElementwise("Scale", m); err != nil {
+		return target.setError(err)
+	}
+
+	v, out := m.values, target.values
+	for i := range out {
+		out[i] = c * v[i]
+	}
+
+	target.err = nil
+	return target
+}
+
+// checkElementwise returns an error if any of the inputs are nil or error
+// matrices, or if target and the inputs are not all the same shape.
+func (target *Matrix) checkElementwise(operationName string, ms ...*Matrix) *MatrixError {
+	if err := inputError(operationName, ms...); err != nil {
+		return err
+	} else if target == nil {
+		return newError(NilError, operationName, "Target Matrix is nil.")
+	}
+
+	for _, m := range ms {
+		if target.width != m.width || target.height != m.height {
+			desc := fmt.Sprintf("Input shape (%d, %d) does not match target shape (%d, %d).",
+				m.width, m.height, target.width, target.height)
+			return newError(ShapeError, operationName, desc)
+		}
+	}
+
 	return nil
 }
+
+// overlaps returns true if m1 and m2 share the same underlying array.
+func overlaps(m1, m2 *Matrix) bool {
+	c1, c2 := cap(m1.values), cap(m2.values)
+	if c1 == 0 || c2 == 0 {
+		return false
+	}
+	// Slices into the same array always end at the same element.
+	return &m1.values[:c1][c1 - 1] == &m2.values[:c2][c2 - 1]
+}
+
+// multKernel computes the product of the row-major (n x k) matrix a and the
+// (k x m) matrix b and writes it to the (n x m) matrix c, which must not
+// overlap with a or b.
+//
+// b is processed in panels of multBlockK rows and multBlockJ columns. Each
+// panel is copied into a transposed buffer so that the innermost loop is a
+// set of contiguous dot products.
+func multKernel(a, b, c []float64, n, k, m int) {
+	for i := range c {
+		c[i] = 0
+	}
+
+	panel := make([]float64, multBlockK * multBlockJ)
+	for k0 := 0; k0 < k; k0 += multBlockK {
+		kb := minInt(multBlockK, k - k0)
+		for j0 := 0; j0 < m; j0 += multBlockJ {
+			jb := minInt(multBlockJ, m - j0)
+
+			// panel[j * kb + kk] = b[k0 + kk][j0 + j]
+			for kk := 0; kk < kb; kk++ {
+				row := b[(k0 + kk) * m + j0: (k0 + kk) * m + j0 + jb]
+				for j, v := range row {
+					panel[j * kb + kk] = v
+				}
+			}
+
+			for i := 0; i < n; i++ {
+				aRow := a[i * k + k0: i * k + k0 + kb]
+				cRow := c[i * m + j0: i * m + j0 + jb]
+				multPanelRow(aRow, panel[:jb * kb], cRow, kb)
+			}
+		}
+	}
+}
+
+// multPanelRow adds the dot products of aRow with each row of the transposed
+// panel to the corresponding elements of cRow. Four columns are processed at
+// once so that each element of aRow is loaded only once per four products.
+func multPanelRow(aRow, panel, cRow []float64, kb int) {
+	j := 0
+	for ; j + 4 <= len(cRow); j += 4 {
+		p0 := panel[j * kb: (j + 1) * kb]
+		p1 := panel[(j + 1) * kb: (j + 2) * kb]
+		p2 := panel[(j + 2) * kb: (j + 3) * kb]
+		p3 := panel[(j + 3) * kb: (j + 4) * kb]
+
+		var s0, s1, s2, s3 float64
+		for kk, av := range aRow {
+			s0 += av * p0[kk]
+			s1 += av * p1[kk]
+			s2 += av * p2[kk]
+			s3 += av * p3[kk]
+		}
+
+		cRow[j] += s0
+		cRow[j + 1] += s1
+		cRow[j + 2] += s2
+		cRow[j + 3] += s3
+	}
+
+	for ; j < len(cRow); j++ {
+		p := panel[j * kb: (j + 1) * kb]
+		s := 0.0
+		for kk, av := range aRow {
+			s += av * p[kk]
+		}
+		cRow[j] += s
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
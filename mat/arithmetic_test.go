@@ -0,0 +1,234 @@
+package mat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomMatrix(width, height int) *Matrix {
+	m := New(width, height)
+	for i := range m.values {
+		m.values[i] = rand.Float64() - 0.5
+	}
+	return m
+}
+
+// naiveMult computes m1 * m2 with the textbook triple loop.
+func naiveMult(m1, m2 *Matrix) *Matrix {
+	out := New(m2.width, m1.height)
+	for y := 0; y < m1.height; y++ {
+		for x := 0; x < m2.width; x++ {
+			sum := 0.0
+			for k := 0; k < m1.width; k++ {
+				sum += m1.Get(k, y) * m2.Get(x, k)
+			}
+			out.Set(x, y, sum)
+		}
+	}
+	return out
+}
+
+func TestElementwise(t *testing.T) {
+	a := FromSlice(2, 2, []float64{1, 2, 3, 4})
+	b := FromSlice(2, 2, []float64{10, 20, 30, 40})
+
+	tests := []struct {
+		name string
+		m    *Matrix
+		exp  []float64
+	}{
+		{"Add(a, b)", Add(a, b), []float64{11, 22, 33, 44}},
+		{"Sub(a, b)", Sub(a, b), []float64{-9, -18, -27, -36}},
+		{"Scale(a, 2)", Scale(a, 2), []float64{2, 4, 6, 8}},
+		{"Mult(a, b)", Mult(a, b), []float64{70, 100, 150, 220}},
+		{"Mult(row, col)", Mult(FromSlice(3, 1, []float64{1, 2, 3}),
+			FromSlice(1, 3, []float64{4, 5, 6})), []float64{32}},
+		{"Mult(col, row)", Mult(FromSlice(1, 2, []float64{1, 2}),
+			FromSlice(2, 1, []float64{3, 4})), []float64{3, 4, 6, 8}},
+	}
+
+	for _, test := range tests {
+		if test.m.IsError() {
+			t.Errorf("%s returned error: %s", test.name, test.m.Error())
+		} else if !sliceEq(test.m.Slice(), test.exp) {
+			t.Errorf("%s = %v, wanted %v", test.name, test.m.Slice(), test.exp)
+		}
+	}
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	a, b := New(2, 2), New(3, 2)
+	errMatrix := New(0, 0)
+
+	tests := []struct {
+		name string
+		m    *Matrix
+		code int
+		op   string
+	}{
+		{"Add(shape)", Add(a, b), ShapeError, "Add"},
+		{"Sub(shape)", Sub(a, b), ShapeError, "Sub"},
+		{"Mult(shape)", Mult(b, b), ShapeError, "Mult"},
+		{"Add(nil)", Add(a, nil), NilError, "Add"},
+		{"Scale(nil)", Scale(nil, 2), NilError, "Scale"},
+		{"Mult(error)", Mult(errMatrix, a), ParameterError, "New"},
+		{"Scale(Add(shape))", Scale(Add(a, b), 2), ShapeError, "Add"},
+		{"target.Add(shape)", New(3, 3).Add(a, a), ShapeError, "Add"},
+		{"target.Mult(shape)", New(2, 2).Mult(a, b), ShapeError, "Mult"},
+		{"target.Scale(error)", New(2, 2).Scale(errMatrix, 1),
+			ParameterError, "New"},
+	}
+
+	for _, test := range tests {
+		if errorCode(test.m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(test.m), test.code)
+		} else if name := test.m.MatrixError().OperationName; name != test.op {
+			t.Errorf("%s has operation name %s, wanted %s",
+				test.name, name, test.op)
+		}
+	}
+}
+
+func TestMult(t *testing.T) {
+	shapes := []struct{ n, k, m int }{
+		{1, 1, 1}, {3, 5, 7}, {17, 300, 65}, {70, 3, 130}, {257, 258, 67},
+	}
+
+	for _, s := range shapes {
+		a, b := randomMatrix(s.k, s.n), randomMatrix(s.m, s.k)
+		res, exp := Mult(a, b), naiveMult(a, b)
+
+		maxErr := 0.0
+		for i := range exp.values {
+			maxErr = math.Max(maxErr, math.Abs(res.values[i]-exp.values[i]))
+		}
+		if maxErr > 1e-12 {
+			t.Errorf("Mult(%dx%d, %dx%d) differs from naive product by %g",
+				s.n, s.k, s.k, s.m, maxErr)
+		}
+	}
+}
+
+func TestMulVec(t *testing.T) {
+	for _, shape := range [][2]int{{1, 1}, {3, 5}, {7, 2}, {40, 40}} {
+		m := randomMatrix(shape[0], shape[1])
+		x := randomMatrix(1, shape[0]).Slice()
+		want := naiveMult(m, FromSlice(1, shape[0], x)).Slice()
+
+		y, err := m.MulVec(x)
+		if err != nil {
+			t.Errorf("MulVec on %v returned error: %s", shape, err)
+		} else if diff := maxDiff(FromSlice(1, shape[1], y),
+			FromSlice(1, shape[1], want)); diff > 1e-13 {
+			t.Errorf("MulVec on %v differs from Mult by %g", shape, diff)
+		}
+
+		if shape[0] == shape[1] {
+			if err := m.MulVecAt(x, x); err != nil {
+				t.Errorf("MulVecAt on %v returned error: %s", shape, err)
+			} else if diff := maxDiff(FromSlice(1, shape[1], x),
+				FromSlice(1, shape[1], want)); diff > 1e-13 {
+				t.Errorf("Aliased MulVecAt on %v differs from Mult by %g",
+					shape, diff)
+			}
+		}
+	}
+
+	m := New(3, 2)
+	if _, err := m.MulVec(make([]float64, 2)); err == nil {
+		t.Errorf("MulVec with wrong input length gave no error.")
+	}
+	if err := m.MulVecAt(make([]float64, 3), make([]float64, 3)); err == nil {
+		t.Errorf("MulVecAt with wrong target length gave no error.")
+	}
+	var nilMat *Matrix
+	if _, err := nilMat.MulVec(nil); err == nil {
+		t.Errorf("MulVec on nil Matrix gave no error.")
+	}
+}
+
+func TestAliasing(t *testing.T) {
+	a := FromSlice(2, 2, []float64{1, 2, 3, 4})
+	b := FromSlice(2, 2, []float64{5, 6, 7, 8})
+
+	tests := []struct {
+		name string
+		f    func(a, b *Matrix) *Matrix
+		exp  []float64
+	}{
+		{"a.Add(a, b)", func(a, b *Matrix) *Matrix { return a.Add(a, b) },
+			[]float64{6, 8, 10, 12}},
+		{"a.Sub(b, a)", func(a, b *Matrix) *Matrix { return a.Sub(b, a) },
+			[]float64{4, 4, 4, 4}},
+		{"a.Scale(a, 3)", func(a, b *Matrix) *Matrix { return a.Scale(a, 3) },
+			[]float64{3, 6, 9, 12}},
+		{"a.Mult(a, b)", func(a, b *Matrix) *Matrix { return a.Mult(a, b) },
+			[]float64{19, 22, 43, 50}},
+		{"b.Mult(a, b)", func(a, b *Matrix) *Matrix { return b.Mult(a, b) },
+			[]float64{19, 22, 43, 50}},
+		{"a.Mult(a, a)", func(a, b *Matrix) *Matrix { return a.Mult(a, a) },
+			[]float64{7, 10, 15, 22}},
+	}
+
+	for _, test := range tests {
+		res := test.f(Copy(a), Copy(b))
+		if !sliceEq(res.Slice(), test.exp) {
+			t.Errorf("%s = %v, wanted %v", test.name, res.Slice(), test.exp)
+		}
+	}
+}
+
+func TestNoAllocation(t *testing.T) {
+	a, b, target := randomMatrix(20, 20), randomMatrix(20, 20), New(20, 20)
+
+	tests := []struct {
+		name string
+		f    func()
+	}{
+		{"Add", func() { target.Add(a, b) }},
+		{"Sub", func() { target.Sub(a, b) }},
+		{"Scale", func() { target.Scale(a, 2) }},
+		{"Add (aliased)", func() { a.Add(a, b) }},
+	}
+
+	for _, test := range tests {
+		if allocs := testing.AllocsPerRun(10, test.f); allocs != 0 {
+			t.Errorf("target.%s made %g allocations.", test.name, allocs)
+		}
+	}
+}
+
+func benchmarkMult(n int, b *testing.B) {
+	m1, m2, target := randomMatrix(n, n), randomMatrix(n, n), New(n, n)
+	b.SetBytes(int64(2 * n * n * n)) // Reported MB/s is MFLOPS.
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target.Mult(m1, m2)
+	}
+}
+
+func benchmarkNaiveMult(n int, b *testing.B) {
+	m1, m2 := randomMatrix(n, n), randomMatrix(n, n)
+	b.SetBytes(int64(2 * n * n * n))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		naiveMult(m1, m2)
+	}
+}
+
+func BenchmarkMult10(b *testing.B)        { benchmarkMult(10, b) }
+func BenchmarkMult100(b *testing.B)       { benchmarkMult(100, b) }
+func BenchmarkMult1000(b *testing.B)      { benchmarkMult(1000, b) }
+func BenchmarkNaiveMult100(b *testing.B)  { benchmarkNaiveMult(100, b) }
+func BenchmarkNaiveMult1000(b *testing.B) { benchmarkNaiveMult(1000, b) }
+
+func BenchmarkAdd1000(b *testing.B) {
+	m1, m2, target := randomMatrix(1000, 1000), randomMatrix(1000, 1000),
+		New(1000, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		target.Add(m1, m2)
+	}
+}
@@ -0,0 +1,444 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+// Banded represents a square Matrix whose non-zero elements all lie within
+// kl diagonals below the main diagonal and ku diagonals above it. Only the
+// band is stored, so a Banded uses O(n (kl + ku)) memory.
+//
+// Linear systems with Banded matrices can be solved in O(n kl (kl + ku))
+// time with BandedLU or, for symmetric positive definite matrices, in
+// O(n kl^2) time with BandedCholesky.
+type Banded struct {
+	n, kl, ku int
+	// Row y stores the elements in columns y - kl through y + ku, so element
+	// (x, y) is at data[y * (kl + ku + 1) + x - y + kl].
+	data []float64
+}
+
+// NewBanded returns an n by n Banded with kl subdiagonals and ku
+// superdiagonals where all elements are initialized to zero.
+//
+// If n is non-positive or kl or ku is negative, a non-nil error is returned.
+func NewBanded(n, kl, ku int) (*Banded, error) {
+	b, err := newBanded("NewBanded", n, kl, ku)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func newBanded(operationName string, n, kl, ku int) (*Banded, *MatrixError) {
+	if n <= 0 {
+		desc := fmt.Sprintf("Input size %d is non-positive.", n)
+		return nil, newError(ParameterError, operationName, desc)
+	} else if kl < 0 || ku < 0 {
+		desc := fmt.Sprintf("Input bandwidths (%d, %d) are not both non-negative.",
+			kl, ku)
+		return nil, newError(ParameterError, operationName, desc)
+	}
+	// Diagonals which lie entirely outside the Matrix are not stored.
+	kl, ku = minInt(kl, n - 1), minInt(ku, n - 1)
+	return &Banded{n, kl, ku, make([]float64, n * (kl + ku + 1))}, nil
+}
+
+// BandedFromDense converts a square Matrix to a Banded with kl subdiagonals
+// and ku superdiagonals.
+//
+// If m is nil, an error Matrix, or not square, if kl or ku is negative, or if
+// m has non-zero elements outside the band, a non-nil error is returned.
+func BandedFromDense(m *Matrix, kl, ku int) (*Banded, error) {
+	m = dense(m)
+	if err := squareError("BandedFromDense", m); err != nil {
+		return nil, err
+	}
+	b, err := newBanded("BandedFromDense", m.width, kl, ku)
+	if err != nil {
+		return nil, err
+	}
+
+	for y := 0; y < b.n; y++ {
+		for x := 0; x < b.n; x++ {
+			val := m.values[y * b.n + x]
+			if b.inBand(x, y) {
+				b.data[b.index(x, y)] = val
+			} else if val != 0 {
+				desc := fmt.Sprintf("Element (%d, %d) is non-zero, but is outside the band.",
+					x, y)
+				return nil, newError(ParameterError, "BandedFromDense", desc)
+			}
+		}
+	}
+	return b, nil
+}
+
+// inBand returns true if (x, y) is within the band of b.
+func (b *Banded) inBand(x, y int) bool {
+	return x - y >= -b.kl && x - y <= b.ku
+}
+
+// index returns the index of element (x, y) in b.data. (x, y) must be within
+// the band.
+func (b *Banded) index(x, y int) int {
+	return y * (b.kl + b.ku + 1) + x - y + b.kl
+}
+
+// Width returns the width of b.
+func (b *Banded) Width() int {
+	return b.n
+}
+
+// Height returns the height of b.
+func (b *Banded) Height() int {
+	return b.n
+}
+
+// Bandwidths returns the number of subdiagonals, kl, and superdiagonals, ku,
+// stored in b.
+func (b *Banded) Bandwidths() (kl, ku int) {
+	return b.kl, b.ku
+}
+
+// At returns the element of b with coordinates (x, y). Elements outside the
+// band are zero.
+//
+// Like m.Get, At panics if (x, y) is out of bounds.
+func (b *Banded) At(x, y int) float64 {
+	checkSquareBounds("At", x, y, b.n)
+	if !b.inBand(x, y) {
+		return 0
+	}
+	return b.data[b.index(x, y)]
+}
+
+// Set changes the element of b with coordinates (x, y) so that it has the
+// given value.
+//
+// Like m.Set, Set panics if (x, y) is out of bounds. Set also panics if
+// (x, y) is outside the band.
+func (b *Banded) Set(x, y int, val float64) {
+	checkSquareBounds("Set", x, y, b.n)
+	if !b.inBand(x, y) {
+		panic(fmt.Sprintf("mat.Set given coordinates (%d, %d), which are "+
+			"outside the band of a Banded Matrix with bandwidths (%d, %d).",
+			x, y, b.kl, b.ku))
+	}
+	b.data[b.index(x, y)] = val
+}
+
+// Dense converts b to a Matrix.
+func (b *Banded) Dense() *Matrix {
+	m := New(b.n, b.n)
+	for y := 0; y < b.n; y++ {
+		for x := maxInt(0, y - b.kl); x <= minInt(b.n - 1, y + b.ku); x++ {
+			m.values[y * b.n + x] = b.data[b.index(x, y)]
+		}
+	}
+	return m
+}
+
+// MulVec computes the product b * x and returns the result.
+//
+// If len(x) is not equal to the width of b, a non-nil error is returned.
+func (b *Banded) MulVec(x []float64) ([]float64, error) {
+	target := make([]float64, b.n)
+	if err := b.mulVecAt("MulVec", x, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// MulVecAt computes the product b * x and stores the result in target.
+// target may be the same slice as x.
+//
+// If x or target does not have a length equal to the width of b, a non-nil
+// error is returned.
+func (b *Banded) MulVecAt(x, target []float64) error {
+	if err := b.mulVecAt("MulVecAt", x, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *Banded) mulVecAt(operationName string, x, target []float64) *MatrixError {
+	if err := checkVecs(operationName, b.n, x, target); err != nil {
+		return err
+	}
+
+	out := target
+	if sameVec(x, target) {
+		out = make([]float64, b.n)
+	}
+	for y := 0; y < b.n; y++ {
+		sum := 0.0
+		for xi := maxInt(0, y - b.kl); xi <= minInt(b.n - 1, y + b.ku); xi++ {
+			sum += b.data[b.index(xi, y)] * x[xi]
+		}
+		out[y] = sum
+	}
+	if !sameVec(out, target) {
+		copy(target, out)
+	}
+	return nil
+}
+
+// BandedLU represents the LU decomposition with partial pivoting of a Banded
+// Matrix, A. Row exchanges widen the upper bandwidth of U to kl + ku, but
+// otherwise the band structure is preserved.
+type BandedLU struct {
+	n, kl, ku int
+	// Row y stores columns y - kl through y + kl + ku. U is stored on and
+	// above the diagonal and the multipliers of L below it.
+	lu       []float64
+	perm     []int // Rows k and perm[k] were exchanged at step k.
+	singular bool
+}
+
+// NewBandedLU computes the LU decomposition of b. b is not modified.
+//
+// If b is nil, a non-nil error is returned. Singular matrices can be
+// decomposed, but cannot be used to solve linear systems.
+func NewBandedLU(b *Banded) (*BandedLU, error) {
+	if b == nil {
+		return nil, newError(NilError, "NewBandedLU", "Input Matrix is nil.")
+	}
+
+	n, kl, ku := b.n, b.kl, b.ku
+	w := 2 * kl + ku + 1
+	f := &BandedLU{n: n, kl: kl, ku: ku, lu: make([]float64, n * w),
+		perm: make([]int, n)}
+	at := func(x, y int) int { return y * w + x - y + kl }
+	for y := 0; y < n; y++ {
+		for x := maxInt(0, y - kl); x <= minInt(n - 1, y + ku); x++ {
+			f.lu[at(x, y)] = b.data[b.index(x, y)]
+		}
+	}
+
+	lu := f.lu
+	for k := 0; k < n; k++ {
+		last := minInt(n - 1, k + kl)
+		right := minInt(n - 1, k + kl + ku)
+
+		p := k
+		for y := k + 1; y <= last; y++ {
+			if math.Abs(lu[at(k, y)]) > math.Abs(lu[at(k, p)]) {
+				p = y
+			}
+		}
+		f.perm[k] = p
+		if lu[at(k, p)] == 0 {
+			f.singular = true
+			continue
+		}
+		if p != k {
+			for x := k; x <= right; x++ {
+				lu[at(x, k)], lu[at(x, p)] = lu[at(x, p)], lu[at(x, k)]
+			}
+		}
+
+		pivot := lu[at(k, k)]
+		for y := k + 1; y <= last; y++ {
+			fact := lu[at(k, y)] / pivot
+			lu[at(k, y)] = fact
+			if fact == 0 {
+				continue
+			}
+			for x := k + 1; x <= right; x++ {
+				lu[at(x, y)] -= fact * lu[at(x, k)]
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// IsSingular returns true if A is singular.
+func (f *BandedLU) IsSingular() bool {
+	return f.singular
+}
+
+// Determinant returns the determinant of A.
+func (f *BandedLU) Determinant() float64 {
+	w := 2 * f.kl + f.ku + 1
+	det := 1.0
+	for k := 0; k < f.n; k++ {
+		det *= f.lu[k * w + f.kl]
+		if f.perm[k] != k {
+			det = -det
+		}
+	}
+	return det
+}
+
+// SolveVec solves the linear system A x = b and returns x.
+//
+// If b does not have a length equal to the height of A, or if A is singular,
+// a non-nil error is returned.
+func (f *BandedLU) SolveVec(b []float64) ([]float64, error) {
+	x := make([]float64, f.n)
+	if err := f.solveVecAt("SolveVec", b, x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// SolveVecAt solves the linear system A x = b and stores x in target. target
+// may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of A, or if A is
+// singular, a non-nil error is returned.
+func (f *BandedLU) SolveVecAt(b, target []float64) error {
+	if err := f.solveVecAt("SolveVecAt", b, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *BandedLU) solveVecAt(operationName string, b, x []float64) *MatrixError {
+	if err := checkVecs(operationName, f.n, b, x); err != nil {
+		return err
+	} else if f.singular {
+		return newError(SingularError, operationName, "Matrix is singular.")
+	}
+
+	n, kl, ku, lu := f.n, f.kl, f.ku, f.lu
+	w := 2 * kl + ku + 1
+	at := func(x, y int) int { return y * w + x - y + kl }
+	copy(x, b)
+
+	// L y = P b.
+	for k := 0; k < n; k++ {
+		if p := f.perm[k]; p != k {
+			x[k], x[p] = x[p], x[k]
+		}
+		for y := k + 1; y <= minInt(n - 1, k + kl); y++ {
+			x[y] -= lu[at(k, y)] * x[k]
+		}
+	}
+
+	// U x = y.
+	for y := n - 1; y >= 0; y-- {
+		sum := x[y]
+		for xi := y + 1; xi <= minInt(n - 1, y + kl + ku); xi++ {
+			sum -= lu[at(xi, y)] * x[xi]
+		}
+		x[y] = sum / lu[at(y, y)]
+	}
+	return nil
+}
+
+// BandedCholesky represents the Cholesky decomposition, A = L L^T, of a
+// symmetric positive definite Banded Matrix, A. L has the same lower
+// bandwidth as A.
+type BandedCholesky struct {
+	n, k int
+	// Row y stores columns y - k through y, so the diagonal element of each
+	// row is last.
+	l []float64
+}
+
+// NewBandedCholesky computes the Cholesky decomposition of b. Only the
+// diagonal and subdiagonals of b are read. b is not modified.
+//
+// If b is nil, if b does not have the same number of subdiagonals and
+// superdiagonals, or if b is not positive definite, a non-nil error is
+// returned.
+func NewBandedCholesky(b *Banded) (*BandedCholesky, error) {
+	if b == nil {
+		return nil, newError(NilError, "NewBandedCholesky", "Input Matrix is nil.")
+	} else if b.kl != b.ku {
+		desc := fmt.Sprintf("Bandwidths (%d, %d) are not equal, so the Matrix is not symmetric.",
+			b.kl, b.ku)
+		return nil, newError(ShapeError, "NewBandedCholesky", desc)
+	}
+
+	n, k := b.n, b.kl
+	f := &BandedCholesky{n: n, k: k, l: make([]float64, n * (k + 1))}
+	l := f.l
+	at := func(x, y int) int { return y * (k + 1) + x - y + k }
+
+	for y := 0; y < n; y++ {
+		for x := maxInt(0, y - k); x <= y; x++ {
+			sum := b.data[b.index(x, y)]
+			for j := maxInt(0, y - k); j < x; j++ {
+				sum -= l[at(j, y)] * l[at(j, x)]
+			}
+
+			if x < y {
+				l[at(x, y)] = sum / l[at(x, x)]
+			} else if sum > 0 {
+				l[at(y, y)] = math.Sqrt(sum)
+			} else {
+				desc := fmt.Sprintf("Non-positive pivot %g found in row %d.", sum, y)
+				return nil, newError(DefiniteError, "NewBandedCholesky", desc)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// Determinant returns the determinant of A.
+func (f *BandedCholesky) Determinant() float64 {
+	det := 1.0
+	for y := 0; y < f.n; y++ {
+		d := f.l[y * (f.k + 1) + f.k]
+		det *= d * d
+	}
+	return det
+}
+
+// SolveVec solves the linear system A x = b and returns x.
+//
+// If b does not have a length equal to the height of A, a non-nil error is
+// returned.
+func (f *BandedCholesky) SolveVec(b []float64) ([]float64, error) {
+	x := make([]float64, f.n)
+	if err := f.solveVecAt("SolveVec", b, x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// SolveVecAt solves the linear system A x = b and stores x in target. target
+// may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of A, a non-nil
+// error is returned.
+func (f *BandedCholesky) SolveVecAt(b, target []float64) error {
+	if err := f.solveVecAt("SolveVecAt", b, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f *BandedCholesky) solveVecAt(operationName string, b, x []float64) *MatrixError {
+	if err := checkVecs(operationName, f.n, b, x); err != nil {
+		return err
+	}
+
+	n, k, l := f.n, f.k, f.l
+	at := func(x, y int) int { return y * (k + 1) + x - y + k }
+	copy(x, b)
+
+	// L y = b.
+	for y := 0; y < n; y++ {
+		sum := x[y]
+		for j := maxInt(0, y - k); j < y; j++ {
+			sum -= l[at(j, y)] * x[j]
+		}
+		x[y] = sum / l[at(y, y)]
+	}
+
+	// L^T x = y, using the rows of L as the columns of L^T.
+	for y := n - 1; y >= 0; y-- {
+		x[y] /= l[at(y, y)]
+		for j := maxInt(0, y - k); j < y; j++ {
+			x[j] -= l[at(j, y)] * x[y]
+		}
+	}
+	return nil
+}
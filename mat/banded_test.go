@@ -0,0 +1,141 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+// randomBanded returns a random n x n Banded Matrix. If spd is true, the
+// result is symmetric and diagonally dominant.
+func randomBanded(n, kl, ku int, spd bool) *Banded {
+	b, _ := NewBanded(n, kl, ku)
+	r := randomMatrix(n, n)
+	for y := 0; y < n; y++ {
+		for x := y - kl; x <= y + ku; x++ {
+			if x < 0 || x >= n {
+				continue
+			}
+			if spd {
+				b.Set(x, y, r.Get(minInt(x, y), maxInt(x, y)))
+			} else {
+				b.Set(x, y, r.Get(x, y))
+			}
+		}
+		if spd {
+			b.Set(y, y, float64(2 * kl + 1))
+		}
+	}
+	return b
+}
+
+func TestBanded(t *testing.T) {
+	tests := []struct {
+		n, kl, ku int
+	}{
+		{1, 0, 0}, {5, 0, 0}, {6, 1, 1}, {10, 2, 0}, {10, 0, 3}, {20, 3, 1},
+		{7, 6, 6}, {4, 10, 10},
+	}
+
+	for i, test := range tests {
+		b := randomBanded(test.n, test.kl, test.ku, false)
+		m := b.Dense()
+		fromDense, err := BandedFromDense(m, test.kl, test.ku)
+		if err != nil {
+			t.Errorf("%d) BandedFromDense returned error: %s", i, err)
+		} else if diff := maxDiff(fromDense.Dense(), m); diff != 0 {
+			t.Errorf("%d) BandedFromDense round trip differs by %g", i, diff)
+		}
+
+		x := randomMatrix(1, test.n).Slice()
+		prod, _ := b.MulVec(x)
+		want, _ := m.MulVec(x)
+		if diff := vecMaxDiff(prod, want); diff > 1e-13 {
+			t.Errorf("%d) MulVec differs from dense product by %g", i, diff)
+		}
+
+		f, err := NewBandedLU(b)
+		if err != nil {
+			t.Errorf("%d) NewBandedLU returned error: %s", i, err)
+			continue
+		}
+		sol, err := f.SolveVec(x)
+		want, _ = SolveVec(m, x)
+		if err != nil {
+			t.Errorf("%d) SolveVec returned error: %s", i, err)
+		} else if diff := vecMaxDiff(sol, want); diff > 1e-8 {
+			t.Errorf("%d) BandedLU solve differs from dense solve by %g", i, diff)
+		}
+		det, _ := m.Determinant()
+		if diff := math.Abs(f.Determinant() - det); diff > 1e-10 * math.Max(1, math.Abs(det)) {
+			t.Errorf("%d) Determinant = %g, wanted %g", i, f.Determinant(), det)
+		}
+
+		if test.kl != test.ku {
+			continue
+		}
+		spd := randomBanded(test.n, test.kl, test.ku, true)
+		c, err := NewBandedCholesky(spd)
+		if err != nil {
+			t.Errorf("%d) NewBandedCholesky returned error: %s", i, err)
+			continue
+		}
+		if err := c.SolveVecAt(x, sol); err != nil {
+			t.Errorf("%d) Cholesky SolveVecAt returned error: %s", i, err)
+		}
+		want, _ = SolveVec(spd.Dense(), x)
+		if diff := vecMaxDiff(sol, want); diff > 1e-12 {
+			t.Errorf("%d) BandedCholesky solve differs from dense solve by %g",
+				i, diff)
+		}
+		det, _ = spd.Dense().Determinant()
+		if diff := math.Abs(c.Determinant() - det); diff > 1e-10 * math.Abs(det) {
+			t.Errorf("%d) Cholesky Determinant = %g, wanted %g",
+				i, c.Determinant(), det)
+		}
+	}
+}
+
+func TestBandedErrors(t *testing.T) {
+	b, _ := NewBanded(3, 1, 1)
+	b.Set(0, 0, -1)
+	b.Set(1, 1, 1)
+	b.Set(2, 2, 1)
+
+	if _, err := NewBandedCholesky(b); err == nil {
+		t.Errorf("NewBandedCholesky on an indefinite Matrix gave no error.")
+	}
+	if f, _ := NewBandedLU(mustBanded(NewBanded(3, 1, 1))); !f.IsSingular() {
+		t.Errorf("NewBandedLU on a zero Matrix was not singular.")
+	} else if _, err := f.SolveVec(make([]float64, 3)); err == nil {
+		t.Errorf("SolveVec on a singular Matrix gave no error.")
+	}
+	if _, err := NewBandedCholesky(mustBanded(NewBanded(3, 1, 0))); err == nil {
+		t.Errorf("NewBandedCholesky with unequal bandwidths gave no error.")
+	}
+	if _, err := BandedFromDense(Identity(3).Scale(Identity(3), 1), 0, 0); err != nil {
+		t.Errorf("BandedFromDense on a diagonal Matrix gave error: %s", err)
+	}
+	if _, err := BandedFromDense(FromSlice(2, 2, []float64{1, 1, 1, 1}), 0, 1); err == nil {
+		t.Errorf("BandedFromDense with elements outside the band gave no error.")
+	}
+	if _, err := NewBanded(3, -1, 0); err == nil {
+		t.Errorf("NewBanded with a negative bandwidth gave no error.")
+	}
+	if b.At(2, 0) != 0 {
+		t.Errorf("At outside the band returned %g", b.At(2, 0))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Set outside the band did not panic.")
+		}
+	}()
+	b.Set(2, 0, 1)
+}
+
+func mustBanded(b *Banded, err error) *Banded {
+	if err != nil {
+		panic(err.Error())
+	}
+	return b
+}
@@ -0,0 +1,373 @@
+package mat
+
+import (
+	"fmt"
+)
+
+// HStack returns the Matrix formed by placing the given matrices side by side,
+// from left to right.
+//
+// If no matrices are given, if any of them are nil, or if they do not all
+// have the same height, an error Matrix is returned.
+func HStack(ms ...*Matrix) *Matrix {
+	width, height, err := stackShape("HStack", ms, true)
+	if err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(width, height).HStack(ms...)
+}
+
+// VStack returns the Matrix formed by placing the given matrices on top of
+// one another, from top to bottom.
+//
+// If no matrices are given, if any of them are nil, or if they do not all
+// have the same width, an error Matrix is returned.
+func VStack(ms ...*Matrix) *Matrix {
+	width, height, err := stackShape("VStack", ms, false)
+	if err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(width, height).VStack(ms...)
+}
+
+// BlockDiag returns the block diagonal Matrix with the given matrices along
+// its diagonal, from the upper left to the lower right, and zeroes
+// everywhere else. The matrices do not need to be square.
+//
+// If no matrices are given or if any of them are nil, an error Matrix is
+// returned.
+func BlockDiag(ms ...*Matrix) *Matrix {
+	width, height, err := blockDiagShape("BlockDiag", ms)
+	if err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(width, height).BlockDiag(ms...)
+}
+
+// HStack places the given matrices side by side in the target Matrix, from
+// left to right. The target Matrix is also returned.
+//
+// If no matrices are given, if any of them are nil, if they do not all have
+// the same height, or if target does not have the shape of the result,
+// target is set to an error Matrix.
+func (target *Matrix) HStack(ms ...*Matrix) *Matrix {
+	return target.stack("HStack", ms, true)
+}
+
+// VStack places the given matrices on top of one another in the target
+// Matrix, from top to bottom. The target Matrix is also returned.
+//
+// If no matrices are given, if any of them are nil, if they do not all have
+// the same width, or if target does not have the shape of the result, target
+// is set to an error Matrix.
+func (target *Matrix) VStack(ms ...*Matrix) *Matrix {
+	return target.stack("VStack", ms, false)
+}
+
+func (target *Matrix) stack(operationName string, ms []*Matrix, horizontal bool) *Matrix {
+	width, height, err := stackShape(operationName, ms, horizontal)
+	if err != nil {
+		return target.setError(err)
+	} else if err := target.checkShape(operationName, width, height); err != nil {
+		return target.setError(err)
+	}
+
+	ms = target.operands(ms)
+	x, y := 0, 0
+	for _, m := range ms {
+		target.copyBlock(m, x, y)
+		if horizontal {
+			x += m.width
+		} else {
+			y += m.height
+		}
+	}
+	target.err = nil
+	return target
+}
+
+// BlockDiag places the given matrices along the diagonal of the target
+// Matrix, from the upper left to the lower right, and sets every other
+// element to zero. The target Matrix is also returned.
+//
+// If no matrices are given, if any of them are nil, or if target does not
+// have the shape of the result, target is set to an error Matrix.
+func (target *Matrix) BlockDiag(ms ...*Matrix) *Matrix {
+	width, height, err := blockDiagShape("BlockDiag", ms)
+	if err != nil {
+		return target.setError(err)
+	} else if err := target.checkShape("BlockDiag", width, height); err != nil {
+		return target.setError(err)
+	}
+
+	ms = target.operands(ms)
+	for y := 0; y < target.height; y++ {
+		row := target.row(y)
+		for x := range row {
+			row[x] = 0
+		}
+	}
+	x, y := 0, 0
+	for _, m := range ms {
+		target.copyBlock(m, x, y)
+		x, y = x + m.width, y + m.height
+	}
+	target.err = nil
+	return target
+}
+
+// stackShape returns the shape of the Matrix formed by stacking ms
+// horizontally or vertically.
+func stackShape(
+	operationName string, ms []*Matrix, horizontal bool,
+) (width, height int, err *MatrixError) {
+	if len(ms) == 0 {
+		return 0, 0, newError(ParameterError, operationName, "No matrices given.")
+	} else if err := inputError(operationName, ms...); err != nil {
+		return 0, 0, err
+	}
+
+	for i, m := range ms {
+		if horizontal {
+			if m.height != ms[0].height {
+				desc := fmt.Sprintf("Matrix %d has height %d, but Matrix 0 has height %d.",
+					i, m.height, ms[0].height)
+				return 0, 0, newError(ShapeError, operationName, desc)
+			}
+			width, height = width + m.width, m.height
+		} else {
+			if m.width != ms[0].width {
+				desc := fmt.Sprintf("Matrix %d has width %d, but Matrix 0 has width %d.",
+					i, m.width, ms[0].width)
+				return 0, 0, newError(ShapeError, operationName, desc)
+			}
+			width, height = m.width, height + m.height
+		}
+	}
+	return width, height, nil
+}
+
+// blockDiagShape returns the shape of the block diagonal Matrix formed from
+// ms.
+func blockDiagShape(
+	operationName string, ms []*Matrix,
+) (width, height int, err *MatrixError) {
+	if len(ms) == 0 {
+		return 0, 0, newError(ParameterError, operationName, "No matrices given.")
+	} else if err := inputError(operationName, ms...); err != nil {
+		return 0, 0, err
+	}
+
+	for _, m := range ms {
+		width, height = width + m.width, height + m.height
+	}
+	return width, height, nil
+}
+
+// Kronecker returns the Kronecker product of a and b: the block Matrix whose
+// block at (x, y) is a.Get(x, y) * b.
+//
+// If a or b is nil, an error Matrix is returned.
+func Kronecker(a, b *Matrix) *Matrix {
+	if err := inputError("Kronecker", a, b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(a.width * b.width, a.height * b.height).Kronecker(a, b)
+}
+
+// Kronecker computes the Kronecker product of a and b and stores it in the
+// target Matrix. The target Matrix is also returned.
+//
+// If a or b is nil, or if target does not have the shape of the result,
+// target is set to an error Matrix.
+func (target *Matrix) Kronecker(a, b *Matrix) *Matrix {
+	if err := inputError("Kronecker", a, b); err != nil {
+		return target.setError(err)
+	} else if err := target.checkShape("Kronecker",
+		a.width * b.width, a.height * b.height); err != nil {
+		return target.setError(err)
+	}
+
+	// Every element of target depends on elements of both inputs, so any
+	// overlap requires a copy.
+	if overlaps(target, a) {
+		a = compact(a)
+	}
+	if overlaps(target, b) {
+		b = compact(b)
+	}
+
+	bw, bh := b.width, b.height
+	for ay := 0; ay < a.height; ay++ {
+		for by := 0; by < bh; by++ {
+			row, bRow := target.row(ay * bh + by), b.row(by)
+			for ax, av := range a.row(ay) {
+				block := row[ax * bw: (ax + 1) * bw]
+				for bx, bv := range bRow {
+					block[bx] = av * bv
+				}
+			}
+		}
+	}
+	target.err = nil
+	return target
+}
+
+// Hadamard returns the element-wise product of m1 and m2.
+//
+// If m1 and m2 are not the same shape or if either are nil, an error Matrix is
+// returned.
+func Hadamard(m1, m2 *Matrix) *Matrix {
+	if err := inputError("Hadamard", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m1.width, m1.height).Hadamard(m1, m2)
+}
+
+// Hadamard computes the element-wise product of m1 and m2 and stores the
+// result in the target Matrix. The target Matrix is also returned.
+//
+// If m1, m2, and target are not all the same size or if either input Matrix is
+// nil, target is set to an error Matrix.
+func (target *Matrix) Hadamard(m1, m2 *Matrix) *Matrix {
+	if err := target.checkElementwise("Hadamard", m1, m2); err != nil {
+		return target.setError(err)
+	}
+
+	m1, m2 = target.operand(m1), target.operand(m2)
+	for y := 0; y < target.height; y++ {
+		out, r1, r2 := target.row(y), m1.row(y), m2.row(y)
+		for x := range out {
+			out[x] = r1[x] * r2[x]
+		}
+	}
+	target.err = nil
+	return target
+}
+
+// PermuteRows returns the Matrix whose row i is row perm[i] of m. This is the
+// product P m for the permutation Matrix P whose row i has a one in column
+// perm[i], and matches the convention used by LU.Pivots.
+//
+// If m is nil, or if perm is not a permutation of 0, ..., m.Height() - 1, an
+// error Matrix is returned.
+func PermuteRows(m *Matrix, perm []int) *Matrix {
+	if err := inputError("PermuteRows", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).PermuteRows(m, perm)
+}
+
+// PermuteCols returns the Matrix whose column j is column perm[j] of m. This
+// is the product m P^T for the permutation Matrix P used by PermuteRows.
+//
+// If m is nil, or if perm is not a permutation of 0, ..., m.Width() - 1, an
+// error Matrix is returned.
+func PermuteCols(m *Matrix, perm []int) *Matrix {
+	if err := inputError("PermuteCols", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).PermuteCols(m, perm)
+}
+
+// PermuteRows stores the Matrix whose row i is row perm[i] of m in the
+// target Matrix. The target Matrix is also returned. target may be m.
+//
+// If m is nil, if perm is not a permutation of 0, ..., m.Height() - 1, or if
+// target is not the same shape as m, target is set to an error Matrix.
+func (target *Matrix) PermuteRows(m *Matrix, perm []int) *Matrix {
+	if err := target.checkElementwise("PermuteRows", m); err != nil {
+		return target.setError(err)
+	} else if err := checkPermutation("PermuteRows", perm, m.height); err != nil {
+		return target.setError(err)
+	}
+
+	if overlaps(target, m) {
+		m = compact(m)
+	}
+	for y, p := range perm {
+		copy(target.row(y), m.row(p))
+	}
+	target.err = nil
+	return target
+}
+
+// PermuteCols stores the Matrix whose column j is column perm[j] of m in the
+// target Matrix. The target Matrix is also returned. target may be m.
+//
+// If m is nil, if perm is not a permutation of 0, ..., m.Width() - 1, or if
+// target is not the same shape as m, target is set to an error Matrix.
+func (target *Matrix) PermuteCols(m *Matrix, perm []int) *Matrix {
+	if err := target.checkElementwise("PermuteCols", m); err != nil {
+		return target.setError(err)
+	} else if err := checkPermutation("PermuteCols", perm, m.width); err != nil {
+		return target.setError(err)
+	}
+
+	if overlaps(target, m) {
+		m = compact(m)
+	}
+	for y := 0; y < m.height; y++ {
+		out, row := target.row(y), m.row(y)
+		for x, p := range perm {
+			out[x] = row[p]
+		}
+	}
+	target.err = nil
+	return target
+}
+
+// checkPermutation returns an error if perm is not a permutation of
+// 0, ..., n - 1.
+func checkPermutation(operationName string, perm []int, n int) *MatrixError {
+	if len(perm) != n {
+		desc := fmt.Sprintf("Permutation length %d does not match dimension %d.",
+			len(perm), n)
+		return newError(ShapeError, operationName, desc)
+	}
+
+	seen := make([]bool, n)
+	for i, p := range perm {
+		if p < 0 || p >= n || seen[p] {
+			desc := fmt.Sprintf("Element %d of the permutation, %d, is out of "+
+				"range or repeated.", i, p)
+			return newError(ParameterError, operationName, desc)
+		}
+		seen[p] = true
+	}
+	return nil
+}
+
+// checkShape returns an error if target is nil or does not have the given
+// shape.
+func (target *Matrix) checkShape(operationName string, width, height int) *MatrixError {
+	if target == nil {
+		return newError(NilError, operationName, "Target Matrix is nil.")
+	} else if target.width != width || target.height != height {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match result shape (%d, %d).",
+			target.width, target.height, width, height)
+		return newError(ShapeError, operationName, desc)
+	}
+	return nil
+}
+
+// operands returns the inputs of an operation which writes to target, with
+// every input which overlaps target replaced by a contiguous copy.
+func (target *Matrix) operands(ms []*Matrix) []*Matrix {
+	out := make([]*Matrix, len(ms))
+	for i, m := range ms {
+		out[i] = m
+		if overlaps(target, m) {
+			out[i] = compact(m)
+		}
+	}
+	return out
+}
+
+// copyBlock copies m into the block of target whose upper left corner is at
+// (x, y).
+func (target *Matrix) copyBlock(m *Matrix, x, y int) {
+	for my := 0; my < m.height; my++ {
+		copy(target.values[(y + my) * target.stride + x:], m.row(my))
+	}
+}
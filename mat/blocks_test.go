@@ -0,0 +1,139 @@
+package mat
+
+import (
+	"testing"
+)
+
+func TestStack(t *testing.T) {
+	a := FromSlice(2, 2, []float64{1, 2, 3, 4})
+	b := FromSlice(1, 2, []float64{5, 6})
+	c := FromSlice(2, 1, []float64{7, 8})
+
+	tests := []struct {
+		name     string
+		out, exp *Matrix
+	}{
+		{"HStack", HStack(a, b), FromSlice(3, 2, []float64{1, 2, 5, 3, 4, 6})},
+		{"VStack", VStack(a, c), FromSlice(2, 3, []float64{1, 2, 3, 4, 7, 8})},
+		{"BlockDiag", BlockDiag(a, b), FromSlice(3, 4, []float64{
+			1, 2, 0,
+			3, 4, 0,
+			0, 0, 5,
+			0, 0, 6,
+		})},
+		{"Single", HStack(a), a},
+	}
+	for _, test := range tests {
+		if !AlmostEqual(test.out, test.exp) {
+			t.Errorf("%s gave %v, expected %v", test.name,
+				test.out.Grid(), test.exp.Grid())
+		}
+	}
+
+	// Stacking a Matrix with views of itself.
+	m := FromSlice(2, 2, []float64{1, 2, 3, 4})
+	big := New(2, 4)
+	big.Rows(0, 2).Copy(m)
+	big.VStack(big.Rows(0, 2), Transpose(big.Rows(0, 2)))
+	exp := FromSlice(2, 4, []float64{1, 2, 3, 4, 1, 3, 2, 4})
+	if !AlmostEqual(big, exp) {
+		t.Errorf("VStack of overlapping views gave %v", big.Grid())
+	}
+
+	errTests := []struct {
+		m    *Matrix
+		code int
+	}{
+		{HStack(), ParameterError},
+		{HStack(a, c), ShapeError},
+		{VStack(a, b), ShapeError},
+		{BlockDiag(a, nil), NilError},
+		{New(2, 2).HStack(a, b), ShapeError},
+	}
+	for i, test := range errTests {
+		if code := errorCode(test.m); code != test.code {
+			t.Errorf("%d) Expected error code %d, got %d", i, test.code, code)
+		}
+	}
+}
+
+func TestKroneckerHadamard(t *testing.T) {
+	a := FromSlice(2, 1, []float64{1, 2})
+	b := FromSlice(2, 2, []float64{1, 2, 3, 4})
+
+	kron := Kronecker(a, b)
+	exp := FromSlice(4, 2, []float64{1, 2, 2, 4, 3, 4, 6, 8})
+	if !AlmostEqual(kron, exp) {
+		t.Errorf("Kronecker gave %v, expected %v", kron.Grid(), exp.Grid())
+	}
+	kron = Kronecker(b, Identity(2))
+	exp = FromSlice(4, 4, []float64{
+		1, 0, 2, 0,
+		0, 1, 0, 2,
+		3, 0, 4, 0,
+		0, 3, 0, 4,
+	})
+	if !AlmostEqual(kron, exp) {
+		t.Errorf("Kronecker with identity gave %v", kron.Grid())
+	}
+
+	// (A x B)(C x D) = (AC) x (BD)
+	m1, m2, m3, m4 := randomMatrix(2, 3), randomMatrix(3, 2), randomMatrix(4, 2), randomMatrix(2, 4)
+	lhs := Mult(Kronecker(m1, m3), Kronecker(m2, m4))
+	rhs := Kronecker(Mult(m1, m2), Mult(m3, m4))
+	if diff := maxDiff(lhs, rhs); diff > 1e-14 {
+		t.Errorf("Kronecker mixed product property violated by %g", diff)
+	}
+
+	h := Hadamard(b, b)
+	if !AlmostEqual(h, FromSlice(2, 2, []float64{1, 4, 9, 16})) {
+		t.Errorf("Hadamard gave %v", h.Grid())
+	}
+	b.Hadamard(b, Transpose(b))
+	if !AlmostEqual(b, FromSlice(2, 2, []float64{1, 6, 6, 16})) {
+		t.Errorf("In-place Hadamard gave %v", b.Grid())
+	}
+	if code := errorCode(Hadamard(a, b)); code != ShapeError {
+		t.Errorf("Hadamard of mismatched shapes gave error code %d", code)
+	}
+}
+
+func TestPermute(t *testing.T) {
+	m := FromSlice(3, 2, []float64{1, 2, 3, 4, 5, 6})
+
+	rows := PermuteRows(m, []int{1, 0})
+	if !AlmostEqual(rows, FromSlice(3, 2, []float64{4, 5, 6, 1, 2, 3})) {
+		t.Errorf("PermuteRows gave %v", rows.Grid())
+	}
+	cols := PermuteCols(m, []int{2, 0, 1})
+	if !AlmostEqual(cols, FromSlice(3, 2, []float64{3, 1, 2, 6, 4, 5})) {
+		t.Errorf("PermuteCols gave %v", cols.Grid())
+	}
+
+	// PermuteRows with LU pivots gives P A = L U.
+	a := randomMatrix(5, 5)
+	f, _ := NewLU(a)
+	if diff := maxDiff(PermuteRows(a, f.Pivots()), Mult(f.L(), f.U())); diff > 1e-14 {
+		t.Errorf("P A and L U differ by %g", diff)
+	}
+
+	m.PermuteCols(m, []int{1, 2, 0})
+	if !AlmostEqual(m, FromSlice(3, 2, []float64{2, 3, 1, 5, 6, 4})) {
+		t.Errorf("In-place PermuteCols gave %v", m.Grid())
+	}
+
+	errTests := []struct {
+		m    *Matrix
+		code int
+	}{
+		{PermuteRows(m, []int{0}), ShapeError},
+		{PermuteRows(m, []int{0, 0}), ParameterError},
+		{PermuteCols(m, []int{0, 1, 3}), ParameterError},
+		{PermuteCols(nil, []int{0}), NilError},
+	}
+	for i, test := range errTests {
+		if code := errorCode(test.m); code != test.code {
+			t.Errorf("%d) Expected error code %d, got %d", i, test.code, code)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+// Cholesky represents the Cholesky decomposition of a symmetric positive
+// semidefinite Matrix, A. The decomposition satisfies P^T A P = L L^T, where
+// P is a permutation Matrix and L is lower triangular with a non-negative
+// diagonal. P is the identity unless the decomposition was computed by
+// NewCholeskyPivoted.
+//
+// Only the lower triangle of A is ever read, so the upper triangle is assumed
+// to match it.
+type Cholesky struct {
+	n    int
+	l    []float64 // L on and below the diagonal, zero above it.
+	perm []int     // P^T A P at (i, j) is A at (perm[i], perm[j]).
+	rank int
+}
+
+// NewCholesky computes the Cholesky decomposition of m. m is not modified.
+//
+// If m is nil, an error Matrix, or not square, a non-nil error is returned.
+// If m is not positive definite, a DefiniteError is returned.
+func NewCholesky(m *Matrix) (*Cholesky, error) {
+	f, err := newCholesky("NewCholesky", m)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newCholesky(operationName string, m *Matrix) (*Cholesky, *MatrixError) {
+	m = dense(m)
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	}
+
+	n := m.width
+	f := newCholeskyFactor(n)
+	f.rank = n
+	l := f.l
+	for j := 0; j < n; j++ {
+		lj := l[j * n: j * n + j]
+		d := m.values[j * n + j]
+		for _, v := range lj {
+			d -= v * v
+		}
+		if d <= 0 || math.IsNaN(d) {
+			desc := fmt.Sprintf("Matrix is not positive definite: leading minor %d is not positive.",
+				j + 1)
+			return nil, newError(DefiniteError, operationName, desc)
+		}
+		ljj := math.Sqrt(d)
+		l[j * n + j] = ljj
+
+		for i := j + 1; i < n; i++ {
+			li := l[i * n: i * n + j]
+			s := m.values[i * n + j]
+			for k, v := range li {
+				s -= v * lj[k]
+			}
+			l[i * n + j] = s / ljj
+		}
+	}
+
+	return f, nil
+}
+
+// NewCholeskyPivoted computes the Cholesky decomposition of m with complete
+// pivoting. At each step the largest remaining diagonal element is moved to
+// the front, and the decomposition stops once every remaining diagonal
+// element is at most tol. The number of completed steps is the numerical rank
+// of m. This allows positive semidefinite matrices to be decomposed.
+//
+// If tol is negative, a default tolerance of n * epsilon times the largest
+// diagonal element of m is used.
+//
+// If m is nil, an error Matrix, or not square, a non-nil error is returned.
+// If every remaining diagonal element is less than -tol at some step, m is
+// indefinite and a DefiniteError is returned.
+func NewCholeskyPivoted(m *Matrix, tol float64) (*Cholesky, error) {
+	f, err := newCholeskyPivoted("NewCholeskyPivoted", m, tol)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newCholeskyPivoted(
+	operationName string, m *Matrix, tol float64,
+) (*Cholesky, *MatrixError) {
+	m = dense(m)
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	}
+
+	n := m.width
+	f := newCholeskyFactor(n)
+	l, perm := f.l, f.perm
+
+	// a is a full symmetric copy of m so that rows and columns can be swapped
+	// freely. d holds the diagonal of the remaining Schur complement.
+	a := make([]float64, n * n)
+	d := make([]float64, n)
+	maxDiag := 0.0
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			a[i * n + j] = m.values[i * n + j]
+			a[j * n + i] = m.values[i * n + j]
+		}
+		d[i] = a[i * n + i]
+		maxDiag = math.Max(maxDiag, d[i])
+	}
+	if tol < 0 {
+		tol = float64(n) * machineEpsilon * maxDiag
+	}
+
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if d[i] > d[p] {
+				p = i
+			}
+		}
+
+		if d[p] < -tol || math.IsNaN(d[p]) {
+			return nil, newError(DefiniteError, operationName,
+				"Matrix is not positive semidefinite.")
+		} else if d[p] <= tol {
+			break
+		}
+
+		if p != k {
+			swapSymmetric(a, n, k, p)
+			d[k], d[p] = d[p], d[k]
+			perm[k], perm[p] = perm[p], perm[k]
+			lk, lp := l[k * n: k * n + k], l[p * n: p * n + k]
+			for c := range lk {
+				lk[c], lp[c] = lp[c], lk[c]
+			}
+		}
+
+		lkk := math.Sqrt(d[k])
+		l[k * n + k] = lkk
+		lk := l[k * n: k * n + k]
+		for i := k + 1; i < n; i++ {
+			li := l[i * n: i * n + k]
+			s := a[i * n + k]
+			for c, v := range li {
+				s -= v * lk[c]
+			}
+			lik := s / lkk
+			l[i * n + k] = lik
+			d[i] -= lik * lik
+		}
+		f.rank++
+	}
+
+	// Columns past the rank are left as zero.
+	for i := f.rank; i < n; i++ {
+		for j := f.rank; j <= i; j++ {
+			l[i * n + j] = 0
+		}
+	}
+
+	return f, nil
+}
+
+// newCholeskyFactor allocates an empty n x n factor with the identity
+// permutation.
+func newCholeskyFactor(n int) *Cholesky {
+	f := &Cholesky{n: n, l: make([]float64, n * n), perm: make([]int, n)}
+	for i := range f.perm {
+		f.perm[i] = i
+	}
+	return f
+}
+
+// swapSymmetric swaps rows i and j and columns i and j of the symmetric
+// row-major n x n matrix a.
+func swapSymmetric(a []float64, n, i, j int) {
+	for c := 0; c < n; c++ {
+		a[i * n + c], a[j * n + c] = a[j * n + c], a[i * n + c]
+	}
+	for r := 0; r < n; r++ {
+		a[r * n + i], a[r * n + j] = a[r * n + j], a[r * n + i]
+	}
+}
+
+// Rank returns the rank of the decomposition. This is only less than the
+// width of A for decompositions computed by NewCholeskyPivoted.
+func (f *Cholesky) Rank() int {
+	return f.rank
+}
+
+// L returns the lower triangular factor of the decomposition.
+func (f *Cholesky) L() *Matrix {
+	l := New(f.n, f.n)
+	copy(l.values, f.l)
+	return l
+}
+
+// Pivots returns the symmetric permutation of the decomposition: element
+// (i, j) of P^T A P is element (Pivots()[i], Pivots()[j]) of A.
+func (f *Cholesky) Pivots() []int {
+	perm := make([]int, f.n)
+	copy(perm, f.perm)
+	return perm
+}
+
+// Determinant returns the determinant of the decomposed Matrix.
+func (f *Cholesky) Determinant() float64 {
+	det := 1.0
+	for i := 0; i < f.n; i++ {
+		lii := f.l[i * f.n + i]
+		det *= lii * lii
+	}
+	return det
+}
+
+// LogDeterminant returns the natural logarithm of the determinant of the
+// decomposed Matrix. This is the quantity needed to evaluate Gaussian
+// likelihoods and does not overflow or underflow for large matrices. If the
+// Matrix is rank deficient, -Inf is returned.
+func (f *Cholesky) LogDeterminant() float64 {
+	if f.rank < f.n {
+		return math.Inf(-1)
+	}
+	sum := 0.0
+	for i := 0; i < f.n; i++ {
+		sum += math.Log(f.l[i * f.n + i])
+	}
+	return 2 * sum
+}
+
+// Solve solves the linear system A X = B, where each column of B is a
+// separate right-hand side, and returns X.
+//
+// If b is nil or does not have the same height as A, or if A is rank
+// deficient, an error Matrix is returned.
+func (f *Cholesky) Solve(b *Matrix) *Matrix {
+	if err := inputError("Solve", b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(b.width, b.height).solveCholesky("Solve", f, b)
+}
+
+// SolveCholesky solves the linear system A X = B using the decomposition f
+// and stores X in the target Matrix. The target Matrix is also returned. Each
+// column of B is a separate right-hand side.
+//
+// If b is nil or does not have the same height as A, if target is not the
+// same shape as b, or if A is rank deficient, target is set to an error
+// Matrix.
+func (target *Matrix) SolveCholesky(f *Cholesky, b *Matrix) *Matrix {
+	return target.solveCholesky("SolveCholesky", f, b)
+}
+
+func (target *Matrix) solveCholesky(
+	operationName string, f *Cholesky, b *Matrix,
+) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix {
+			return t.solveCholesky(operationName, f, b)
+		})
+	}
+	b = target.operand(b)
+
+	if err := inputError(operationName, b); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, operationName, "Target Matrix is nil.")
+	} else if b.height != f.n {
+		desc := fmt.Sprintf("Right-hand side height %d does not match Matrix height %d.",
+			b.height, f.n)
+		return target.setError(newError(ShapeError, operationName, desc))
+	} else if !Compatible(target, b) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match right-hand side shape (%d, %d).",
+			target.width, target.height, b.width, b.height)
+		return target.setError(newError(ShapeError, operationName, desc))
+	} else if f.rank < f.n {
+		return target.setError(newError(SingularError, operationName,
+			"Matrix is rank deficient."))
+	}
+
+	if target != b {
+		copy(target.values, b.values)
+	}
+	f.solveInPlace(target.values, b.width)
+	target.err = nil
+	return target
+}
+
+// SolveVec solves the linear system A x = b for a single right-hand side.
+//
+// If b does not have the same length as the height of A, or if A is rank
+// deficient, a non-nil error is returned.
+func (f *Cholesky) SolveVec(b []float64) ([]float64, error) {
+	if len(b) != f.n {
+		desc := fmt.Sprintf("Length of right-hand side, %d, does not match Matrix height %d.",
+			len(b), f.n)
+		return nil, newError(ShapeError, "SolveVec", desc)
+	} else if f.rank < f.n {
+		return nil, newError(SingularError, "SolveVec",
+			"Matrix is rank deficient.")
+	}
+
+	x := make([]float64, f.n)
+	copy(x, b)
+	f.solveInPlace(x, 1)
+	return x, nil
+}
+
+// Inverse returns the inverse of the decomposed Matrix.
+//
+// If A is rank deficient, an error Matrix is returned.
+func (f *Cholesky) Inverse() *Matrix {
+	if f.rank < f.n {
+		return newErrorMatrix(SingularError, "Inverse",
+			"Matrix is rank deficient.")
+	}
+	inv := Identity(f.n)
+	f.solveInPlace(inv.values, f.n)
+	return inv
+}
+
+// solveInPlace overwrites the row-major (n x w) matrix x with the solution to
+// A X = x.
+func (f *Cholesky) solveInPlace(x []float64, w int) {
+	n, l := f.n, f.l
+
+	// y = P^T x
+	tmp := make([]float64, n * w)
+	for i := 0; i < n; i++ {
+		copy(tmp[i * w: (i + 1) * w], x[f.perm[i] * w: (f.perm[i] + 1) * w])
+	}
+
+	// Forward substitution with L.
+	for i := 0; i < n; i++ {
+		yi := tmp[i * w: (i + 1) * w]
+		for k := 0; k < i; k++ {
+			lik := l[i * n + k]
+			yk := tmp[k * w: (k + 1) * w]
+			for j := range yi {
+				yi[j] -= lik * yk[j]
+			}
+		}
+		d := l[i * n + i]
+		for j := range yi {
+			yi[j] /= d
+		}
+	}
+
+	// Back substitution with L^T.
+	for i := n - 1; i >= 0; i-- {
+		yi := tmp[i * w: (i + 1) * w]
+		for k := i + 1; k < n; k++ {
+			lki := l[k * n + i]
+			yk := tmp[k * w: (k + 1) * w]
+			for j := range yi {
+				yi[j] -= lki * yk[j]
+			}
+		}
+		d := l[i * n + i]
+		for j := range yi {
+			yi[j] /= d
+		}
+	}
+
+	// x = P y
+	for i := 0; i < n; i++ {
+		copy(x[f.perm[i] * w: (f.perm[i] + 1) * w], tmp[i * w: (i + 1) * w])
+	}
+}
+
+// Update modifies the decomposition so that it represents A + x x^T instead
+// of A. This takes O(n^2) operations instead of the O(n^3) needed to compute
+// a new decomposition. x is not modified.
+//
+// If x does not have the same length as the width of A, or if A is rank
+// deficient, a non-nil error is returned and f is not modified.
+func (f *Cholesky) Update(x []float64) error {
+	if err := f.checkUpdate("Update", x); err != nil {
+		return err
+	}
+
+	n, l := f.n, f.l
+	v := f.permuted(x)
+	for k := 0; k < n; k++ {
+		lkk := l[k * n + k]
+		r := math.Hypot(lkk, v[k])
+		c, s := r / lkk, v[k] / lkk
+		l[k * n + k] = r
+		for i := k + 1; i < n; i++ {
+			l[i * n + k] = (l[i * n + k] + s * v[i]) / c
+			v[i] = c * v[i] - s * l[i * n + k]
+		}
+	}
+	return nil
+}
+
+// Downdate modifies the decomposition so that it represents A - x x^T instead
+// of A. This takes O(n^2) operations instead of the O(n^3) needed to compute
+// a new decomposition. x is not modified.
+//
+// If x does not have the same length as the width of A, or if A is rank
+// deficient, a non-nil error is returned. If A - x x^T is not positive
+// definite, a DefiniteError is returned. In all these cases, f is not
+// modified.
+func (f *Cholesky) Downdate(x []float64) error {
+	if err := f.checkUpdate("Downdate", x); err != nil {
+		return err
+	}
+
+	n := f.n
+	l := make([]float64, len(f.l))
+	copy(l, f.l)
+	v := f.permuted(x)
+	for k := 0; k < n; k++ {
+		lkk := l[k * n + k]
+		d := (lkk - v[k]) * (lkk + v[k])
+		if d <= 0 || math.IsNaN(d) {
+			return newError(DefiniteError, "Downdate",
+				"Downdated Matrix is not positive definite.")
+		}
+		r := math.Sqrt(d)
+		c, s := r / lkk, v[k] / lkk
+		l[k * n + k] = r
+		for i := k + 1; i < n; i++ {
+			l[i * n + k] = (l[i * n + k] - s * v[i]) / c
+			v[i] = c * v[i] - s * l[i * n + k]
+		}
+	}
+
+	copy(f.l, l)
+	return nil
+}
+
+// checkUpdate returns an error if x cannot be used to update or downdate f.
+func (f *Cholesky) checkUpdate(operationName string, x []float64) error {
+	if len(x) != f.n {
+		desc := fmt.Sprintf("Length of update vector, %d, does not match Matrix width %d.",
+			len(x), f.n)
+		return newError(ShapeError, operationName, desc)
+	} else if f.rank < f.n {
+		return newError(SingularError, operationName,
+			"Matrix is rank deficient.")
+	}
+	return nil
+}
+
+// permuted returns a copy of x permuted to match the rows of L, P^T x.
+func (f *Cholesky) permuted(x []float64) []float64 {
+	v := make([]float64, f.n)
+	for i, p := range f.perm {
+		v[i] = x[p]
+	}
+	return v
+}
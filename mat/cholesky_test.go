@@ -0,0 +1,166 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+// randomSPD returns a random n x n symmetric positive definite Matrix.
+func randomSPD(n int) *Matrix {
+	m := randomMatrix(n, n)
+	spd := Mult(Transpose(m), m)
+	for i := 0; i < n; i++ {
+		spd.Set(i, i, spd.Get(i, i)+1)
+	}
+	return spd
+}
+
+// permutedLLT returns P L L^T P^T for the decomposition f.
+func permutedLLT(f *Cholesky) *Matrix {
+	l := f.L()
+	llt := Mult(l, Transpose(l))
+	out := New(f.n, f.n)
+	for i, pi := range f.perm {
+		for j, pj := range f.perm {
+			out.Set(pj, pi, llt.Get(j, i))
+		}
+	}
+	return out
+}
+
+func TestCholesky(t *testing.T) {
+	tests := []*Matrix{
+		FromSlice(1, 1, []float64{4}),
+		FromSlice(3, 3, []float64{4, 12, -16, 12, 37, -43, -16, -43, 98}),
+		randomSPD(25),
+	}
+
+	for i, m := range tests {
+		f, err := NewCholesky(m)
+		if err != nil {
+			t.Errorf("%d) NewCholesky returned error: %s", i, err)
+			continue
+		}
+		if diff := maxDiff(permutedLLT(f), m); diff > 1e-12 {
+			t.Errorf("%d) L L^T and A differ by %g", i, diff)
+		}
+
+		p, err := NewCholeskyPivoted(m, -1)
+		if err != nil {
+			t.Errorf("%d) NewCholeskyPivoted returned error: %s", i, err)
+			continue
+		} else if p.Rank() != m.width {
+			t.Errorf("%d) Pivoted rank = %d, wanted %d", i, p.Rank(), m.width)
+		}
+		if diff := maxDiff(permutedLLT(p), m); diff > 1e-12 {
+			t.Errorf("%d) P L L^T P^T and A differ by %g", i, diff)
+		}
+
+		det, _ := m.Determinant()
+		if math.Abs(f.Determinant()-det) > 1e-9*math.Abs(det) {
+			t.Errorf("%d) f.Determinant() = %g, wanted %g",
+				i, f.Determinant(), det)
+		} else if math.Abs(p.LogDeterminant()-math.Log(det)) > 1e-9 {
+			t.Errorf("%d) p.LogDeterminant() = %g, wanted %g",
+				i, p.LogDeterminant(), math.Log(det))
+		}
+
+		x := randomMatrix(2, m.height)
+		b := Mult(m, x)
+		if diff := maxDiff(f.Solve(b), x); diff > 1e-9 {
+			t.Errorf("%d) f.Solve(b) differs from x by %g", i, diff)
+		}
+		if diff := maxDiff(p.Solve(b), x); diff > 1e-9 {
+			t.Errorf("%d) p.Solve(b) differs from x by %g", i, diff)
+		}
+		if diff := maxDiff(Mult(m, p.Inverse()), Identity(m.width)); diff > 1e-9 {
+			t.Errorf("%d) A * p.Inverse() differs from I by %g", i, diff)
+		}
+	}
+}
+
+func TestCholeskyErrors(t *testing.T) {
+	indefinite := FromSlice(2, 2, []float64{1, 2, 2, 1})
+	if _, err := NewCholesky(indefinite); err == nil ||
+		err.(*MatrixError).Code != DefiniteError {
+		t.Errorf("NewCholesky(indefinite) gave error %v, wanted DefiniteError",
+			err)
+	}
+	if _, err := NewCholeskyPivoted(Scale(Identity(2), -1), -1); err == nil {
+		t.Errorf("NewCholeskyPivoted(-I) returned nil error.")
+	}
+	if _, err := NewCholesky(New(2, 3)); err == nil {
+		t.Errorf("NewCholesky of non-square Matrix returned nil error.")
+	}
+
+	// A rank 2 positive semidefinite Matrix.
+	v := FromSlice(2, 4, []float64{1, 0, 2, 1, 0, 3, 1, 1})
+	psd := Mult(v, Transpose(v))
+	if _, err := NewCholesky(psd); err == nil {
+		t.Errorf("NewCholesky(semidefinite) returned nil error.")
+	}
+	f, err := NewCholeskyPivoted(psd, -1)
+	if err != nil {
+		t.Fatalf("NewCholeskyPivoted(semidefinite) returned error: %s", err)
+	} else if f.Rank() != 2 {
+		t.Errorf("NewCholeskyPivoted(semidefinite).Rank() = %d, wanted 2",
+			f.Rank())
+	}
+	if diff := maxDiff(permutedLLT(f), psd); diff > 1e-12 {
+		t.Errorf("P L L^T P^T and semidefinite A differ by %g", diff)
+	}
+	if errorCode(f.Solve(New(1, 4))) != SingularError {
+		t.Errorf("Solve with rank deficient factor did not give SingularError.")
+	}
+	if !math.IsInf(f.LogDeterminant(), -1) {
+		t.Errorf("LogDeterminant of rank deficient factor = %g, wanted -Inf",
+			f.LogDeterminant())
+	}
+}
+
+func TestCholeskyUpdate(t *testing.T) {
+	n := 12
+	a := randomSPD(n)
+	x := randomMatrix(1, n).Slice()
+	xxt := Mult(FromSlice(1, n, x), FromSlice(n, 1, x))
+
+	for _, pivot := range []bool{false, true} {
+		var f *Cholesky
+		if pivot {
+			f, _ = NewCholeskyPivoted(a, -1)
+		} else {
+			f, _ = NewCholesky(a)
+		}
+
+		if err := f.Update(x); err != nil {
+			t.Errorf("Update returned error: %s", err)
+		} else if diff := maxDiff(permutedLLT(f), Add(a, xxt)); diff > 1e-12 {
+			t.Errorf("Updated factor differs from A + x x^T by %g (pivot = %v)",
+				diff, pivot)
+		}
+
+		if err := f.Downdate(x); err != nil {
+			t.Errorf("Downdate returned error: %s", err)
+		} else if diff := maxDiff(permutedLLT(f), a); diff > 1e-12 {
+			t.Errorf("Downdated factor differs from A by %g (pivot = %v)",
+				diff, pivot)
+		}
+	}
+
+	// Downdating by a large vector makes the Matrix indefinite and must leave
+	// the factor unchanged.
+	f, _ := NewCholesky(a)
+	before := f.L()
+	big := make([]float64, n)
+	for i := range big {
+		big[i] = 1e3
+	}
+	if err := f.Downdate(big); err == nil {
+		t.Errorf("Downdate to indefinite Matrix returned nil error.")
+	} else if maxDiff(f.L(), before) != 0 {
+		t.Errorf("Failed Downdate modified the factor.")
+	}
+	if err := f.Update(make([]float64, n+1)); err == nil {
+		t.Errorf("Update with wrong length returned nil error.")
+	}
+}
@@ -0,0 +1,334 @@
+package cmat
+
+import (
+	"fmt"
+	"math/cmplx"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// Add computes m1 + m2 and returns the result.
+//
+// If m1 and m2 are not the same shape or if either are nil, an error Matrix is
+// returned.
+func Add(m1, m2 *Matrix) *Matrix {
+	if err := inputError("Add", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m1.width, m1.height).Add(m1, m2)
+}
+
+// Sub computes m1 - m2 and returns the result.
+//
+// If m1 and m2 are not the same shape or if either are nil, an error Matrix is
+// returned.
+func Sub(m1, m2 *Matrix) *Matrix {
+	if err := inputError("Sub", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m1.width, m1.height).Sub(m1, m2)
+}
+
+// Mult computes m1 * m2 and returns the result.
+//
+// If the width of m1 is not the same as the height of m2 or if either are
+// nil, an error Matrix is returned.
+func Mult(m1, m2 *Matrix) *Matrix {
+	if err := inputError("Mult", m1, m2); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m2.width, m1.height).Mult(m1, m2)
+}
+
+// Scale multiplies every element in m by c and returns the result.
+//
+// If m is nil, an error Matrix is returned.
+func Scale(m *Matrix, c complex128) *Matrix {
+	if err := inputError("Scale", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).Scale(m, c)
+}
+
+// Conj computes the element-wise complex conjugate of m and returns the
+// result.
+//
+// If m is nil, an error Matrix is returned.
+func Conj(m *Matrix) *Matrix {
+	if err := inputError("Conj", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).Conj(m)
+}
+
+// Transpose computes the transpose of m and returns the result. The elements
+// are not conjugated; see ConjTranspose.
+//
+// If m is nil, an error Matrix is returned.
+func Transpose(m *Matrix) *Matrix {
+	if err := inputError("Transpose", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.height, m.width).Transpose(m)
+}
+
+// ConjTranspose computes the conjugate transpose (Hermitian adjoint), m^H,
+// of m and returns the result.
+//
+// If m is nil, an error Matrix is returned.
+func ConjTranspose(m *Matrix) *Matrix {
+	if err := inputError("ConjTranspose", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.height, m.width).ConjTranspose(m)
+}
+
+// Add computes m1 + m2 and stores the result in the target Matrix. The target
+// matrix is also returned.
+//
+// If m1, m2, and target are not the same shape or if any are nil, target is
+// set to an error Matrix.
+func (target *Matrix) Add(m1, m2 *Matrix) *Matrix {
+	if err := target.checkElementwise("Add", m1, m2); err != nil {
+		return target.setError(err)
+	}
+
+	v1, v2, out := m1.values, m2.values, target.values
+	for i := range out {
+		out[i] = v1[i] + v2[i]
+	}
+	target.err = nil
+	return target
+}
+
+// Sub computes m1 - m2 and stores the result in the target Matrix. The target
+// matrix is also returned.
+//
+// If m1, m2, and target are not the same shape or if any are nil, target is
+// set to an error Matrix.
+func (target *Matrix) Sub(m1, m2 *Matrix) *Matrix {
+	if err := target.checkElementwise("Sub", m1, m2); err != nil {
+		return target.setError(err)
+	}
+
+	v1, v2, out := m1.values, m2.values, target.values
+	for i := range out {
+		out[i] = v1[i] - v2[i]
+	}
+	target.err = nil
+	return target
+}
+
+// Mult computes m1 * m2 and stores the result in the target matrix. The target
+// matrix is also returned.
+//
+// If the width of m1 is not the same as the height or m2, or if target does
+// not have the same width as m2 and the same height as m1, or if either input
+// Matrix is nil, target is set to an error Matrix.
+func (target *Matrix) Mult(m1, m2 *Matrix) *Matrix {
+	if err := inputError("Mult", m1, m2); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(mat.NilError, "Mult", "Target Matrix is nil.")
+	} else if !MultCompatible(m1, m2) {
+		desc := fmt.Sprintf("Input shapes (%d, %d) and (%d, %d) cannot be multiplied.",
+			m1.width, m1.height, m2.width, m2.height)
+		return target.setError(mat.NewError(mat.ShapeError, "cmat.Mult", desc))
+	} else if target.width != m2.width || target.height != m1.height {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match product shape (%d, %d).",
+			target.width, target.height, m2.width, m1.height)
+		return target.setError(mat.NewError(mat.ShapeError, "cmat.Mult", desc))
+	}
+
+	out := target.values
+	if overlaps(target, m1) || overlaps(target, m2) {
+		out = make([]complex128, len(target.values))
+	} else {
+		for i := range out {
+			out[i] = 0
+		}
+	}
+
+	n, k, w := m1.height, m1.width, m2.width
+	for y := 0; y < n; y++ {
+		row := out[y * w: (y + 1) * w]
+		for j := 0; j < k; j++ {
+			a := m1.values[y * k + j]
+			if a == 0 {
+				continue
+			}
+			bRow := m2.values[j * w: (j + 1) * w]
+			for x, b := range bRow {
+				row[x] += a * b
+			}
+		}
+	}
+
+	if &out[0] != &target.values[0] {
+		copy(target.values, out)
+	}
+	target.err = nil
+	return target
+}
+
+// Scale multiplies every element of m by c and stores the result in the
+// target Matrix. The target matrix is also returned.
+//
+// If m and target are not the same size or if m is nil, target is set to an
+// error Matrix.
+func (target *Matrix) Scale(m *Matrix, c complex128) *Matrix {
+	if err := target.checkElementwise("Scale", m); err != nil {
+		return target.setError(err)
+	}
+
+	v, out := m.values, target.values
+	for i := range out {
+		out[i] = c * v[i]
+	}
+	target.err = nil
+	return target
+}
+
+// Conj computes the element-wise complex conjugate of m and stores the result
+// in the target Matrix. The target matrix is also returned.
+//
+// If m and target are not the same size or if m is nil, target is set to an
+// error Matrix.
+func (target *Matrix) Conj(m *Matrix) *Matrix {
+	if err := target.checkElementwise("Conj", m); err != nil {
+		return target.setError(err)
+	}
+
+	v, out := m.values, target.values
+	for i := range out {
+		out[i] = cmplx.Conj(v[i])
+	}
+	target.err = nil
+	return target
+}
+
+// Transpose computes the transpose of m and stores the result in the target
+// Matrix. The target matrix is also returned.
+//
+// If target is not the same shape as the transpose of m or if m is nil,
+// target is set to an error Matrix.
+func (target *Matrix) Transpose(m *Matrix) *Matrix {
+	return target.transpose("Transpose", m, false)
+}
+
+// ConjTranspose computes the conjugate transpose of m and stores the result
+// in the target Matrix. The target matrix is also returned.
+//
+// If target is not the same shape as the transpose of m or if m is nil,
+// target is set to an error Matrix.
+func (target *Matrix) ConjTranspose(m *Matrix) *Matrix {
+	return target.transpose("ConjTranspose", m, true)
+}
+
+func (target *Matrix) transpose(operationName string, m *Matrix, conj bool) *Matrix {
+	if err := inputError(operationName, m); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(mat.NilError, operationName, "Target Matrix is nil.")
+	} else if !TransposeCompatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match transpose shape (%d, %d).",
+			target.width, target.height, m.height, m.width)
+		return target.setError(mat.NewError(mat.ShapeError, "cmat." + operationName, desc))
+	}
+
+	src := m.values
+	if overlaps(target, m) {
+		src = make([]complex128, len(m.values))
+		copy(src, m.values)
+	}
+
+	w, h := m.width, m.height
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			z := src[y * w + x]
+			if conj {
+				z = cmplx.Conj(z)
+			}
+			target.values[x * h + y] = z
+		}
+	}
+
+	target.err = nil
+	return target
+}
+
+// IsHermitian returns true if m is square and equal to its conjugate
+// transpose to within the library precision fraction, ConvergenceEpsilon, and
+// false otherwise. The diagonal of a Hermitian Matrix is real. If m is nil or
+// an error Matrix, IsHermitian returns false.
+func (m *Matrix) IsHermitian() bool {
+	if !valid(m) || m.width != m.height {
+		return false
+	}
+	return AlmostEqual(m, ConjTranspose(m))
+}
+
+// MulVec computes the matrix-vector product m * x and returns the result.
+//
+// If m is nil or an error Matrix, or if len(x) is not equal to the width of
+// m, a non-nil error is returned.
+func (m *Matrix) MulVec(x []complex128) ([]complex128, error) {
+	target := make([]complex128, m.height)
+	if err := m.mulVecAt("MulVec", x, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// MulVecAt computes the matrix-vector product m * x and stores the result in
+// target. target may be the same slice as x.
+//
+// If m is nil or an error Matrix, if len(x) is not equal to the width of m,
+// or if len(target) is not equal to the height of m, a non-nil error is
+// returned.
+func (m *Matrix) MulVecAt(x, target []complex128) error {
+	if err := m.mulVecAt("MulVecAt", x, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Matrix) mulVecAt(operationName string, x, target []complex128) *mat.MatrixError {
+	if err := inputError(operationName, m); err != nil {
+		return err
+	} else if len(x) != m.width {
+		desc := fmt.Sprintf("Length of input vector, %d, does not match Matrix width %d.",
+			len(x), m.width)
+		return mat.NewError(mat.ShapeError, "cmat." + operationName, desc)
+	} else if len(target) != m.height {
+		desc := fmt.Sprintf("Length of target vector, %d, does not match Matrix height %d.",
+			len(target), m.height)
+		return mat.NewError(mat.ShapeError, "cmat." + operationName, desc)
+	}
+
+	out := target
+	if &x[0] == &target[0] {
+		out = make([]complex128, len(target))
+	}
+	for y := 0; y < m.height; y++ {
+		sum := complex128(0)
+		for i, val := range m.values[y * m.width: (y + 1) * m.width] {
+			sum += val * x[i]
+		}
+		out[y] = sum
+	}
+	if &out[0] != &target[0] {
+		copy(target, out)
+	}
+	return nil
+}
+
+// overlaps returns true if the values of m1 and m2 share a backing array.
+func overlaps(m1, m2 *Matrix) bool {
+	c1, c2 := cap(m1.values), cap(m2.values)
+	if c1 == 0 || c2 == 0 {
+		return false
+	}
+	// Slices into the same array always end at the same element.
+	return &m1.values[:c1][c1 - 1] == &m2.values[:c2][c2 - 1]
+}
@@ -1,5 +1,380 @@
-// package cmat implements operations on complex-valued matrices. Placeholder
-// until the interface for num/mat is finalized.
+/*
+package cmat implements operations on complex-valued matrices. It follows the
+same conventions as package mat: every operation which results in a new
+Matrix is available both as a function which allocates its result and as a
+method on a target Matrix, and errors are reported through error Matrices
+which propagate through chains of operations.
+
+	a := cmat.FromSlice(2, 2, []complex128{1, 1i, -1i, 2})
+	h := cmat.ConjTranspose(a)
+	if !cmat.AlmostEqual(a, h) {
+		// a is not Hermitian.
+	}
+
+In addition to arithmetic, package cmat provides complex LU decompositions
+for solving linear systems and an eigensolver for Hermitian matrices.
+Conversions to and from the real matrices of package mat are provided by
+FromReal, FromParts, Real, and Imag.
+
+Errors are reported with the *mat.MatrixError type and the error codes of
+package mat.
+*/
 package cmat
 
-type Matrix struct {}
\ No newline at end of file
+import (
+	"fmt"
+	"math/cmplx"
+	"strings"
+
+	"github.com/phil-mansfield/num"
+	"github.com/phil-mansfield/num/mat"
+)
+
+// Matrix represents a two-dimensional rectangular array of complex values.
+// *Matrix implements the error interface.
+type Matrix struct {
+	values        []complex128
+	width, height int
+	err           *mat.MatrixError
+}
+
+// New returns a matrix with the given dimensions where all elements are
+// initialized to zero.
+//
+// If height or width is non-positive, an error Matrix will be returned.
+func New(width, height int) *Matrix {
+	if width <= 0 {
+		desc := fmt.Sprintf("Input width %d is non-positive.", width)
+		return newErrorMatrix(mat.ParameterError, "New", desc)
+	} else if height <= 0 {
+		desc := fmt.Sprintf("Input height %d is non-positive.", height)
+		return newErrorMatrix(mat.ParameterError, "New", desc)
+	}
+
+	return &Matrix{make([]complex128, width * height), width, height, nil}
+}
+
+// Identity returns a square matrix with the given width which contains ones
+// down its diagonal and zeroes everywhere else.
+//
+// If width is non-positive, an error Matrix will be returned.
+func Identity(width int) *Matrix {
+	m := New(width, width)
+	if m.IsError() {
+		m.err.OperationName = "cmat.Identity"
+		return m
+	}
+
+	for i := 0; i < width; i++ {
+		m.values[i + i * width] = 1
+	}
+	return m
+}
+
+// FromSlice converts a slice of complex values to a matrix with the given
+// dimensions. The element with zero-indexed coordinates (x, y) in the matrix
+// will be the same as that at index values[y * width + x].
+//
+// If width * height != len(values) or if height or width is non-positive, an
+// error Matrix will be returned.
+func FromSlice(width, height int, values []complex128) *Matrix {
+	m := New(width, height)
+	if m.IsError() {
+		m.err.OperationName = "cmat.FromSlice"
+		return m
+	} else if len(values) != width * height {
+		desc := fmt.Sprintf("Length of input slice, %d, does not match target dimensions, (%d, %d).",
+			len(values), width, height)
+		return newErrorMatrix(mat.ParameterError, "FromSlice", desc)
+	}
+
+	copy(m.values, values)
+	return m
+}
+
+// FromGrid converts a 2D slice of complex values to a matrix with the same
+// dimensions. The element with zero-indexed coordinates of (x, y) in the
+// matrix will be the same as that at index values[y][x].
+//
+// If any two rows in values have different lengths, or if len(values) == 0
+// or len(values[0]) == 0, an error Matrix will be returned.
+func FromGrid(values [][]complex128) *Matrix {
+	height := len(values)
+	if height == 0 {
+		desc := "Input grid has a height of 0."
+		return newErrorMatrix(mat.ParameterError, "FromGrid", desc)
+	}
+
+	width := len(values[0])
+	for y := 0; y < height; y++ {
+		if width != len(values[y]) {
+			desc := fmt.Sprintf("Input grid has width of %d at row 0, but a width of %d at row %d.",
+				width, len(values[y]), y)
+			return newErrorMatrix(mat.ParameterError, "FromGrid", desc)
+		}
+	}
+	if width == 0 {
+		desc := "Input grid has width of 0, but a positive width is required."
+		return newErrorMatrix(mat.ParameterError, "FromGrid", desc)
+	}
+
+	m := New(width, height)
+	for y := 0; y < height; y++ {
+		copy(m.values[y * width: (y + 1) * width], values[y])
+	}
+	return m
+}
+
+// FromReal converts a real Matrix to a complex Matrix with zero imaginary
+// parts.
+//
+// If re is nil or an error Matrix, an error Matrix is returned.
+func FromReal(re *mat.Matrix) *Matrix {
+	return FromParts(re, nil)
+}
+
+// FromParts creates a complex Matrix from its real and imaginary parts. If im
+// is nil, the imaginary parts are zero.
+//
+// If re is nil, if either input is an error Matrix, or if re and im have
+// different shapes, an error Matrix is returned.
+func FromParts(re, im *mat.Matrix) *Matrix {
+	if err := realInputError("FromParts", re); err != nil {
+		return newErrorMatrixFrom(err)
+	} else if im != nil {
+		if err := realInputError("FromParts", im); err != nil {
+			return newErrorMatrixFrom(err)
+		} else if !mat.Compatible(re, im) {
+			desc := fmt.Sprintf("Real shape (%d, %d) does not match imaginary shape (%d, %d).",
+				re.Width(), re.Height(), im.Width(), im.Height())
+			return newErrorMatrix(mat.ShapeError, "FromParts", desc)
+		}
+	}
+
+	m := New(re.Width(), re.Height())
+	for i, x := range re.Slice() {
+		m.values[i] = complex(x, 0)
+	}
+	if im != nil {
+		for i, y := range im.Slice() {
+			m.values[i] += complex(0, y)
+		}
+	}
+	return m
+}
+
+// Real returns the real part of m.
+//
+// If m is nil or an error Matrix, a real error Matrix is returned.
+func Real(m *Matrix) *mat.Matrix {
+	return part("Real", m, func(z complex128) float64 { return real(z) })
+}
+
+// Imag returns the imaginary part of m.
+//
+// If m is nil or an error Matrix, a real error Matrix is returned.
+func Imag(m *Matrix) *mat.Matrix {
+	return part("Imag", m, func(z complex128) float64 { return imag(z) })
+}
+
+func part(operationName string, m *Matrix, f func(complex128) float64) *mat.Matrix {
+	if err := inputError(operationName, m); err != nil {
+		// Producing a real error Matrix requires going through package
+		// mat, which only exposes error Matrices from invalid operations.
+		out := mat.New(0, 0)
+		*out.MatrixError() = *err
+		return out
+	}
+
+	values := make([]float64, len(m.values))
+	for i, z := range m.values {
+		values[i] = f(z)
+	}
+	return mat.FromSlice(m.width, m.height, values)
+}
+
+// AlmostEqual returns true if every element in the two given matrices is
+// equal to within the library precision fraction, ConvergenceEpsilon, as
+// defined in num/config.go. If the two matrices are not Compatible,
+// AlmostEqual returns false.
+func AlmostEqual(m1, m2 *Matrix) bool {
+	if !Compatible(m1, m2) {
+		return false
+	}
+
+	for i := range m1.values {
+		z1, z2 := m1.values[i], m2.values[i]
+		scale := cmplx.Abs(z1)
+		if abs2 := cmplx.Abs(z2); abs2 > scale {
+			scale = abs2
+		}
+		if !num.CloseEnough(scale, cmplx.Abs(z1 - z2)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compatible returns true if the two given matrices have the same shapes and
+// false otherwise. If either Matrix is nil or an error Matrix, Compatible
+// returns false.
+func Compatible(m1, m2 *Matrix) bool {
+	if !valid(m1) || !valid(m2) {
+		return false
+	}
+	return m1.width == m2.width && m1.height == m2.height
+}
+
+// MultCompatible returns true if the two given matrices can be multiplied
+// together and false otherwise. If either Matrix is nil or an error Matrix,
+// MultCompatible returns false.
+func MultCompatible(m1, m2 *Matrix) bool {
+	if !valid(m1) || !valid(m2) {
+		return false
+	}
+	return m1.width == m2.height
+}
+
+// TransposeCompatible returns true if m1 is the same shape as the transpose
+// of m2 and false otherwise. If either Matrix is nil or an error Matrix,
+// TransposeCompatible returns false.
+func TransposeCompatible(m1, m2 *Matrix) bool {
+	if !valid(m1) || !valid(m2) {
+		return false
+	}
+	return m1.width == m2.height && m1.height == m2.width
+}
+
+// valid returns true if m is neither nil nor an error Matrix.
+func valid(m *Matrix) bool {
+	return m != nil && m.err == nil
+}
+
+// Height returns the height of the matrix.
+func (m *Matrix) Height() int {
+	return m.height
+}
+
+// Width returns the width of the matrix.
+func (m *Matrix) Width() int {
+	return m.width
+}
+
+// Slice returns a slice containing all the values within m. The value at the
+// zero-indexed coordinates (x, y) will be placed at index x + m.Width() * y
+// in the slice.
+func (m *Matrix) Slice() []complex128 {
+	values := make([]complex128, len(m.values))
+	copy(values, m.values)
+	return values
+}
+
+// Grid returns a 2D slice containing all the values within m. The value at
+// the zero-indexed coordinates (x, y) will be placed at index grid[y][x] in
+// the output grid.
+func (m *Matrix) Grid() [][]complex128 {
+	grid := make([][]complex128, m.height)
+	for y := 0; y < m.height; y++ {
+		grid[y] = make([]complex128, m.width)
+		copy(grid[y], m.values[y * m.width: (y + 1) * m.width])
+	}
+	return grid
+}
+
+// InBounds returns true if the (x, y) coordinate pair is within the bounds
+// of m and false otherwise.
+func (m *Matrix) InBounds(x, y int) bool {
+	if m == nil {
+		return false
+	}
+	return x >= 0 && y >= 0 && x < m.width && y < m.height
+}
+
+// Get returns the element of the matrix with coordinates (x, y).
+//
+// Get and Set are unique in that they panic upon out of bounds input instead
+// of returning an error.
+func (m *Matrix) Get(x, y int) complex128 {
+	m.checkBounds(x, y, "Get")
+	return m.values[y * m.width + x]
+}
+
+// Set changes the element in the matrix with coordinates (x, y) so that it
+// has the given value.
+//
+// Get and Set are unique in that they panic upon out of bounds input instead
+// of returning an error.
+func (m *Matrix) Set(x, y int, val complex128) {
+	m.checkBounds(x, y, "Set")
+	m.values[y * m.width + x] = val
+}
+
+// checkBounds panics if m is nil or if (x, y) is out of bounds.
+func (m *Matrix) checkBounds(x, y int, operationName string) {
+	if m == nil {
+		panic(fmt.Sprintf("cmat.%s called on nil Matrix.", operationName))
+	} else if !m.InBounds(x, y) {
+		panic(fmt.Sprintf("cmat.%s given coordinates (%d, %d), which are "+
+			"out of bounds for a %d by %d Matrix.",
+			operationName, x, y, m.width, m.height))
+	}
+}
+
+// Print prints the contents of the matrix as a comma-separated array of
+// arrays. Each row in the matrix is given its own line.
+func (m *Matrix) Print() {
+	m.Printf("%g")
+}
+
+// Printf prints the contents of of the matrix as a comma-separated array of
+// arrays where each element is formatted according to the given format string.
+// Each row in the matrix is given its own line.
+func (m *Matrix) Printf(format string) {
+	fmt.Println(m.sprintf(format))
+}
+
+// sprintf returns the string printed by m.Printf(format), without the
+// trailing newline. nil and error matrices are written as "[]".
+func (m *Matrix) sprintf(format string) string {
+	if !valid(m) {
+		return "[]"
+	}
+
+	rows := make([]string, m.height)
+	elems := make([]string, m.width)
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			elems[x] = fmt.Sprintf(format, m.values[y * m.width + x])
+		}
+		rows[y] = "[" + strings.Join(elems, ", ") + "]"
+	}
+
+	return "[" + strings.Join(rows, ",\n ") + "]"
+}
+
+// Copy returns a copy of m.
+//
+// If m is nil, Copy returns an error Matrix.
+func Copy(m *Matrix) *Matrix {
+	if err := inputError("Copy", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+
+	target := New(m.width, m.height)
+	copy(target.values, m.values)
+	return target
+}
+
+// Copy copies the values in m to target. The target matrix is also returned.
+//
+// If target is not the same shape as m or if m is nil, target is set to an
+// error Matrix.
+func (target *Matrix) Copy(m *Matrix) *Matrix {
+	if err := target.checkElementwise("Copy", m); err != nil {
+		return target.setError(err)
+	}
+
+	copy(target.values, m.values)
+	target.err = nil
+	return target
+}
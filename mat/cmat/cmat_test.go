@@ -0,0 +1,160 @@
+package cmat
+
+import (
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// errorCode returns the error code of m, or 0 if m is not an error Matrix.
+func errorCode(m *Matrix) int {
+	if !m.IsError() {
+		return 0
+	}
+	return m.MatrixError().Code
+}
+
+// randomMatrix returns a Matrix whose real and imaginary parts are uniform
+// in [-0.5, 0.5).
+func randomMatrix(width, height int) *Matrix {
+	m := New(width, height)
+	for i := range m.values {
+		m.values[i] = complex(rand.Float64()-0.5, rand.Float64()-0.5)
+	}
+	return m
+}
+
+// maxDiff returns the largest absolute difference between the elements of
+// two matrices of the same shape.
+func maxDiff(m1, m2 *Matrix) float64 {
+	diff := 0.0
+	for i := range m1.values {
+		if d := cmplx.Abs(m1.values[i] - m2.values[i]); d > diff {
+			diff = d
+		}
+	}
+	return diff
+}
+
+func TestConstructors(t *testing.T) {
+	m := FromGrid([][]complex128{{1, 2i}, {3, 4 - 1i}})
+	if !AlmostEqual(m, FromSlice(2, 2, []complex128{1, 2i, 3, 4 - 1i})) {
+		t.Errorf("FromGrid and FromSlice disagree")
+	}
+	if m.Get(1, 1) != 4-1i {
+		t.Errorf("Get(1, 1) = %g", m.Get(1, 1))
+	}
+
+	re := mat.FromSlice(2, 1, []float64{1, 2})
+	im := mat.FromSlice(2, 1, []float64{3, 4})
+	z := FromParts(re, im)
+	if !AlmostEqual(z, FromSlice(2, 1, []complex128{1 + 3i, 2 + 4i})) {
+		t.Errorf("FromParts gave %v", z.Slice())
+	}
+	if !mat.AlmostEqual(Real(z), re) || !mat.AlmostEqual(Imag(z), im) {
+		t.Errorf("Real and Imag do not invert FromParts")
+	}
+	if !AlmostEqual(FromReal(re), FromSlice(2, 1, []complex128{1, 2})) {
+		t.Errorf("FromReal gave %v", FromReal(re).Slice())
+	}
+
+	errTests := []struct {
+		m    *Matrix
+		code int
+	}{
+		{New(0, 1), mat.ParameterError},
+		{FromSlice(2, 2, []complex128{1}), mat.ParameterError},
+		{FromGrid([][]complex128{{1}, {1, 2}}), mat.ParameterError},
+		{FromParts(re, mat.Identity(2)), mat.ShapeError},
+		{FromReal(nil), mat.NilError},
+	}
+	for i, test := range errTests {
+		if code := errorCode(test.m); code != test.code {
+			t.Errorf("%d) Expected error code %d, got %d", i, test.code, code)
+		}
+	}
+
+	if r := Real(New(0, 0)); !r.IsError() ||
+		r.MatrixError().Code != mat.ParameterError {
+		t.Errorf("Real did not propagate the error of its input")
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a := FromSlice(2, 2, []complex128{1, 1i, 2, 3 - 1i})
+	b := FromSlice(2, 2, []complex128{1i, 0, -1, 2})
+
+	tests := []struct {
+		name     string
+		out, exp *Matrix
+	}{
+		{"Add", Add(a, b), FromSlice(2, 2, []complex128{1 + 1i, 1i, 1, 5 - 1i})},
+		{"Sub", Sub(a, b), FromSlice(2, 2, []complex128{1 - 1i, 1i, 3, 1 - 1i})},
+		{"Mult", Mult(a, b), FromSlice(2, 2, []complex128{0, 2i, -3 + 3i, 6 - 2i})},
+		{"Scale", Scale(a, 1i), FromSlice(2, 2, []complex128{1i, -1, 2i, 1 + 3i})},
+		{"Conj", Conj(a), FromSlice(2, 2, []complex128{1, -1i, 2, 3 + 1i})},
+		{"Transpose", Transpose(a), FromSlice(2, 2, []complex128{1, 2, 1i, 3 - 1i})},
+		{"ConjTranspose", ConjTranspose(a), FromSlice(2, 2, []complex128{1, 2, -1i, 3 + 1i})},
+	}
+	for _, test := range tests {
+		if !AlmostEqual(test.out, test.exp) {
+			t.Errorf("%s gave %v, expected %v", test.name,
+				test.out.Slice(), test.exp.Slice())
+		}
+	}
+
+	// Targets may alias their inputs.
+	c := Copy(a)
+	c.Mult(c, c)
+	if !AlmostEqual(c, Mult(a, a)) {
+		t.Errorf("Aliased Mult gave %v", c.Slice())
+	}
+	r := randomMatrix(3, 2)
+	rt := Copy(r)
+	rt.ConjTranspose(rt)
+	if !rt.IsError() {
+		t.Errorf("ConjTranspose into a mismatched target succeeded")
+	}
+
+	x := []complex128{1, 1i}
+	y, err := a.MulVec(x)
+	if err != nil {
+		t.Fatalf("MulVec returned error: %s", err)
+	}
+	if y[0] != 0 || y[1] != 3+3i {
+		t.Errorf("MulVec gave %v", y)
+	}
+	if err := a.MulVecAt(x, x); err != nil || x[0] != y[0] || x[1] != y[1] {
+		t.Errorf("Aliased MulVecAt gave %v, %v", x, err)
+	}
+
+	if code := errorCode(Add(a, New(3, 2))); code != mat.ShapeError {
+		t.Errorf("Add of mismatched shapes gave error code %d", code)
+	}
+	if code := errorCode(Mult(New(3, 2), New(3, 2))); code != mat.ShapeError {
+		t.Errorf("Mult of mismatched shapes gave error code %d", code)
+	}
+	if code := errorCode(Add(a, Add(a, nil))); code != mat.NilError {
+		t.Errorf("Add did not propagate NilError, gave %d", code)
+	}
+}
+
+func TestIsHermitian(t *testing.T) {
+	tests := []struct {
+		m   *Matrix
+		exp bool
+	}{
+		{FromSlice(2, 2, []complex128{1, 1i, -1i, 2}), true},
+		{FromSlice(2, 2, []complex128{1, 1i, 1i, 2}), false},
+		{FromSlice(2, 2, []complex128{1i, 0, 0, 2}), false},
+		{New(2, 3), false},
+		{nil, false},
+	}
+	for i, test := range tests {
+		if test.m.IsHermitian() != test.exp {
+			t.Errorf("%d) Expected IsHermitian() = %v", i, test.exp)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package cmat
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+const (
+	// jacobiMaxSweeps is the maximum number of sweeps over the off-diagonal
+	// elements performed by NewHermEigen before it gives up.
+	jacobiMaxSweeps = 50
+	machineEpsilon  = 1.0 / (1 << 52)
+)
+
+// HermEigen represents the eigendecomposition of a Hermitian Matrix, A. The
+// decomposition satisfies A = V D V^H, where D is a real diagonal Matrix of
+// the eigenvalues of A in ascending order and the columns of V are the
+// corresponding orthonormal eigenvectors.
+type HermEigen struct {
+	n       int
+	values  []float64
+	vectors []complex128 // Column j is the eigenvector of values[j].
+}
+
+// NewHermEigen computes the eigendecomposition of the Hermitian Matrix m with
+// the cyclic complex Jacobi method. m is not modified.
+//
+// If m is nil, an error Matrix, or not square, or if m is not Hermitian to
+// within the library precision fraction, a non-nil error is returned.
+func NewHermEigen(m *Matrix) (*HermEigen, error) {
+	f, err := newHermEigen("NewHermEigen", m)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newHermEigen(operationName string, m *Matrix) (*HermEigen, *mat.MatrixError) {
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	} else if !m.IsHermitian() {
+		return nil, mat.NewError(mat.ParameterError, "cmat." + operationName,
+			"Matrix is not Hermitian.")
+	}
+
+	// Work on the exactly Hermitian part of m so that rounding in its
+	// construction does not leak into the eigenvalues.
+	n := m.width
+	a := make([]complex128, n * n)
+	for i := 0; i < n; i++ {
+		a[i * n + i] = complex(real(m.values[i * n + i]), 0)
+		for j := 0; j < i; j++ {
+			z := (m.values[i * n + j] + cmplx.Conj(m.values[j * n + i])) / 2
+			a[i * n + j], a[j * n + i] = z, cmplx.Conj(z)
+		}
+	}
+
+	f := &HermEigen{
+		n: n, values: make([]float64, n), vectors: make([]complex128, n * n),
+	}
+	if !hermJacobi(a, f.vectors, n) {
+		desc := fmt.Sprintf("Jacobi iteration did not converge in %d sweeps.",
+			jacobiMaxSweeps)
+		return nil, mat.NewError(mat.IterationError, "cmat." + operationName, desc)
+	}
+	for i := range f.values {
+		f.values[i] = real(a[i * n + i])
+	}
+
+	f.sort()
+	f.normalizePhases()
+	return f, nil
+}
+
+// Values returns the eigenvalues of A in ascending order.
+func (f *HermEigen) Values() []float64 {
+	values := make([]float64, f.n)
+	copy(values, f.values)
+	return values
+}
+
+// Vectors returns a Matrix whose columns are the orthonormal eigenvectors of
+// A, in the same order as Values(). The phase of each eigenvector is chosen
+// so that its element with the largest magnitude is real and positive.
+func (f *HermEigen) Vectors() *Matrix {
+	v := New(f.n, f.n)
+	copy(v.values, f.vectors)
+	return v
+}
+
+// Vector returns the eigenvector corresponding to Values()[j].
+//
+// Vector panics if j is out of range.
+func (f *HermEigen) Vector(j int) []complex128 {
+	vec := make([]complex128, f.n)
+	for i := range vec {
+		vec[i] = f.vectors[i * f.n + j]
+	}
+	return vec
+}
+
+// sort sorts the eigenvalues in ascending order and permutes the columns of
+// the eigenvectors to match.
+func (f *HermEigen) sort() {
+	n := f.n
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return f.values[order[i]] < f.values[order[j]]
+	})
+
+	values := make([]float64, n)
+	vectors := make([]complex128, n * n)
+	for j, p := range order {
+		values[j] = f.values[p]
+		for i := 0; i < n; i++ {
+			vectors[i * n + j] = f.vectors[i * n + p]
+		}
+	}
+	f.values, f.vectors = values, vectors
+}
+
+// normalizePhases multiplies each eigenvector by a unit complex number so
+// that its element with the largest magnitude is real and positive. This
+// makes the decomposition of a given Matrix deterministic.
+func (f *HermEigen) normalizePhases() {
+	n := f.n
+	for j := 0; j < n; j++ {
+		max := complex128(0)
+		for i := 0; i < n; i++ {
+			if v := f.vectors[i * n + j]; cmplx.Abs(v) > cmplx.Abs(max) {
+				max = v
+			}
+		}
+		if max == 0 {
+			continue
+		}
+		phase := cmplx.Conj(max) / complex(cmplx.Abs(max), 0)
+		for i := 0; i < n; i++ {
+			f.vectors[i * n + j] *= phase
+		}
+	}
+}
+
+// hermJacobi diagonalizes the Hermitian row-major n x n matrix a with cyclic
+// complex Jacobi rotations. On return, the diagonal of a holds the
+// eigenvalues and the columns of v hold the eigenvectors. hermJacobi returns
+// false if the off-diagonal elements did not become negligible within
+// jacobiMaxSweeps sweeps.
+//
+// Each rotation first applies a diagonal phase to row and column q which
+// makes a[p, q] real and then applies an ordinary real Jacobi rotation.
+func hermJacobi(a, v []complex128, n int) bool {
+	for i := 0; i < n; i++ {
+		v[i * n + i] = 1
+	}
+
+	norm := 0.0
+	for _, z := range a {
+		norm += real(z) * real(z) + imag(z) * imag(z)
+	}
+	tol := machineEpsilon * math.Sqrt(norm) / float64(n)
+
+	for sweep := 0; sweep < jacobiMaxSweeps; sweep++ {
+		rotated := false
+		for p := 0; p < n - 1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p * n + q]
+				r := cmplx.Abs(apq)
+				if r <= tol {
+					a[p * n + q], a[q * n + p] = 0, 0
+					continue
+				}
+				rotated = true
+
+				// Phase a[p, q] to the positive real number r.
+				w := cmplx.Conj(apq) / complex(r, 0)
+				for k := 0; k < n; k++ {
+					a[k * n + q] *= w
+					v[k * n + q] *= w
+				}
+				wc := cmplx.Conj(w)
+				for k := 0; k < n; k++ {
+					a[q * n + k] *= wc
+				}
+
+				app, aqq := real(a[p * n + p]), real(a[q * n + q])
+				theta := (aqq - app) / (2 * r)
+				t := 1 / (math.Abs(theta) + math.Sqrt(1 + theta * theta))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(1 + t * t)
+				s := t * c
+
+				for k := 0; k < n; k++ {
+					akp, akq := a[k * n + p], a[k * n + q]
+					a[k * n + p] = complex(c, 0) * akp - complex(s, 0) * akq
+					a[k * n + q] = complex(s, 0) * akp + complex(c, 0) * akq
+
+					vkp, vkq := v[k * n + p], v[k * n + q]
+					v[k * n + p] = complex(c, 0) * vkp - complex(s, 0) * vkq
+					v[k * n + q] = complex(s, 0) * vkp + complex(c, 0) * vkq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p * n + k], a[q * n + k]
+					a[p * n + k] = complex(c, 0) * apk - complex(s, 0) * aqk
+					a[q * n + k] = complex(s, 0) * apk + complex(c, 0) * aqk
+				}
+
+				a[p * n + p] = complex(app - t * r, 0)
+				a[q * n + q] = complex(aqq + t * r, 0)
+				a[p * n + q], a[q * n + p] = 0, 0
+			}
+		}
+		if !rotated {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package cmat
+
+import (
+	"fmt"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// IsError indicates whether m is an error Matrix. IsError returns true if m
+// is the result of an invalid operation or if one of the matrices used as
+// arguments to this operation was an error Matrix. If m was an error Matrix
+// prior to being the target of an operation and the operation succeeds, it
+// will no longer be an error Matrix.
+func (m *Matrix) IsError() bool {
+	return m.err != nil
+}
+
+// Error returns a string representing the first error that occured in the
+// creation of m. If no errors occured or if m is nil, an empty string is
+// returned.
+func (m *Matrix) Error() string {
+	if m == nil {
+		return ""
+	}
+	return m.err.Error()
+}
+
+// MatrixError returns a pointer to the struct representing the first error
+// that occured in the creation of m. If no such error occured, or if m is nil,
+// nil is returned.
+func (m *Matrix) MatrixError() *mat.MatrixError {
+	if m == nil {
+		return nil
+	}
+	return m.err
+}
+
+// newErrorMatrix creates a new error Matrix corresponding to the given error
+// code. Descriptions follow the same conventions as in package mat.
+func newErrorMatrix(code int, operationName, desc string) *Matrix {
+	return newErrorMatrixFrom(mat.NewError(code, "cmat." + operationName, desc))
+}
+
+// newErrorMatrixFrom creates a new error Matrix which contains an existing
+// error. This is used to propagate errors from input Matrices.
+func newErrorMatrixFrom(err *mat.MatrixError) *Matrix {
+	return &Matrix{[]complex128{}, 0, 0, err}
+}
+
+// setError turns target into an error Matrix containing err and returns it.
+// If target is nil, a new error Matrix is returned instead.
+func (target *Matrix) setError(err *mat.MatrixError) *Matrix {
+	if target == nil {
+		return newErrorMatrixFrom(err)
+	}
+
+	target.values = target.values[:0]
+	target.width, target.height = 0, 0
+	target.err = err
+	return target
+}
+
+// inputError checks the non-target Matrix arguments of an operation. If any
+// argument is nil, a new NilError is returned. If any argument is an error
+// Matrix, its error is returned so that it can be propagated to the result.
+// Otherwise nil is returned.
+func inputError(operationName string, ms ...*Matrix) *mat.MatrixError {
+	for _, m := range ms {
+		if m == nil {
+			return mat.NewError(mat.NilError, "cmat." + operationName, "Input Matrix is nil.")
+		} else if m.err != nil {
+			return m.err
+		}
+	}
+	return nil
+}
+
+// realInputError is inputError for real Matrix arguments.
+func realInputError(operationName string, m *mat.Matrix) *mat.MatrixError {
+	if m == nil {
+		return mat.NewError(mat.NilError, "cmat." + operationName, "Input Matrix is nil.")
+	}
+	return m.MatrixError()
+}
+
+// checkElementwise returns an error if any of the inputs are nil or error
+// Matrices, if target is nil, or if any input has a different shape than
+// target.
+func (target *Matrix) checkElementwise(operationName string, ms ...*Matrix) *mat.MatrixError {
+	if err := inputError(operationName, ms...); err != nil {
+		return err
+	} else if target == nil {
+		return mat.NewError(mat.NilError, "cmat." + operationName, "Target Matrix is nil.")
+	}
+
+	for _, m := range ms {
+		if target.width != m.width || target.height != m.height {
+			desc := fmt.Sprintf("Input shape (%d, %d) does not match target shape (%d, %d).",
+				m.width, m.height, target.width, target.height)
+			return mat.NewError(mat.ShapeError, "cmat." + operationName, desc)
+		}
+	}
+	return nil
+}
+
+// squareError returns an error if m is nil, an error Matrix, or not square.
+func squareError(operationName string, m *Matrix) *mat.MatrixError {
+	if err := inputError(operationName, m); err != nil {
+		return err
+	} else if m.width != m.height {
+		desc := fmt.Sprintf("Matrix shape (%d, %d) is not square.",
+			m.width, m.height)
+		return mat.NewError(mat.ShapeError, "cmat." + operationName, desc)
+	}
+	return nil
+}
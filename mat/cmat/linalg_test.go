@@ -0,0 +1,142 @@
+package cmat
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// randomHermitian returns a random n x n Hermitian Matrix.
+func randomHermitian(n int) *Matrix {
+	r := randomMatrix(n, n)
+	return Add(r, ConjTranspose(r))
+}
+
+func TestLU(t *testing.T) {
+	tests := []*Matrix{
+		FromSlice(2, 2, []complex128{0, 1i, 1i, 0}),
+		FromSlice(2, 2, []complex128{1, 1i, -1i, 2}),
+		randomMatrix(15, 15),
+	}
+
+	for i, m := range tests {
+		f, err := NewLU(m)
+		if err != nil {
+			t.Errorf("%d) NewLU returned error: %s", i, err)
+			continue
+		}
+
+		pa := New(m.width, m.height)
+		for r, p := range f.Pivots() {
+			copy(pa.values[r*m.width:(r+1)*m.width],
+				m.values[p*m.width:(p+1)*m.width])
+		}
+		if diff := maxDiff(pa, Mult(f.L(), f.U())); diff > 1e-13 {
+			t.Errorf("%d) P A and L U differ by %g", i, diff)
+		}
+
+		n := m.width
+		if diff := maxDiff(Mult(m, Invert(m)), Identity(n)); diff > 1e-11 {
+			t.Errorf("%d) A A^-1 differs from I by %g", i, diff)
+		}
+
+		b := randomMatrix(3, n)
+		x := Solve(m, b)
+		if diff := maxDiff(Mult(m, x), b); diff > 1e-12 {
+			t.Errorf("%d) Residual of Solve is %g", i, diff)
+		}
+		xv, err := f.SolveVec(b.Slice()[:n])
+		if err != nil {
+			t.Errorf("%d) SolveVec returned error: %s", i, err)
+		} else if bv, _ := m.MulVec(xv); maxDiff(FromSlice(n, 1, bv),
+			FromSlice(n, 1, b.Slice()[:n])) > 1e-12 {
+			t.Errorf("%d) Residual of SolveVec is too large", i)
+		}
+	}
+
+	det, _ := NewLU(FromSlice(2, 2, []complex128{1i, 2, 3, 4i}))
+	if d := det.Determinant(); cmplx.Abs(d-(-10)) > 1e-14 {
+		t.Errorf("Determinant = %g, expected -10", d)
+	}
+
+	singular := FromSlice(2, 2, []complex128{1, 1i, 1i, -1})
+	if code := errorCode(Invert(singular)); code != mat.SingularError {
+		t.Errorf("Invert of singular Matrix gave error code %d", code)
+	}
+	if code := errorCode(Solve(New(2, 3), New(1, 3))); code != mat.ShapeError {
+		t.Errorf("Solve with non-square Matrix gave error code %d", code)
+	}
+}
+
+func TestHermEigen(t *testing.T) {
+	pauliY := FromSlice(2, 2, []complex128{0, -1i, 1i, 0})
+	f, err := NewHermEigen(pauliY)
+	if err != nil {
+		t.Fatalf("NewHermEigen returned error: %s", err)
+	}
+	if vals := f.Values(); math.Abs(vals[0]+1) > 1e-14 ||
+		math.Abs(vals[1]-1) > 1e-14 {
+		t.Errorf("Eigenvalues of Pauli Y are %v", vals)
+	}
+
+	for _, n := range []int{1, 3, 10, 25} {
+		m := randomHermitian(n)
+		f, err := NewHermEigen(m)
+		if err != nil {
+			t.Errorf("n = %d) NewHermEigen returned error: %s", n, err)
+			continue
+		}
+
+		vals := f.Values()
+		for i := 1; i < n; i++ {
+			if vals[i] < vals[i-1] {
+				t.Errorf("n = %d) Eigenvalues not ascending: %v", n, vals)
+				break
+			}
+		}
+
+		v := f.Vectors()
+		if diff := maxDiff(Mult(ConjTranspose(v), v), Identity(n)); diff > 1e-12 {
+			t.Errorf("n = %d) V^H V differs from I by %g", n, diff)
+		}
+		d := New(n, n)
+		for i, val := range vals {
+			d.Set(i, i, complex(val, 0))
+		}
+		vdvh := Mult(Mult(v, d), ConjTranspose(v))
+		if diff := maxDiff(vdvh, m); diff > 1e-12 {
+			t.Errorf("n = %d) V D V^H differs from A by %g", n, diff)
+		}
+
+		// The real symmetric embedding [[Re, -Im], [Im, Re]] has every
+		// eigenvalue of A twice.
+		re, im := Real(m).Slice(), Imag(m).Slice()
+		emb := mat.New(2*n, 2*n)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				emb.Set(x, y, re[y*n+x])
+				emb.Set(x+n, y+n, re[y*n+x])
+				emb.Set(x+n, y, -im[y*n+x])
+				emb.Set(x, y+n, im[y*n+x])
+			}
+		}
+		sym, err := mat.NewSymEigen(emb, mat.Tridiagonal)
+		if err != nil {
+			t.Fatalf("n = %d) NewSymEigen returned error: %s", n, err)
+		}
+		for i, val := range sym.Values() {
+			if math.Abs(val-vals[i/2]) > 1e-12 {
+				t.Errorf("n = %d) Eigenvalue %d is %g, expected %g",
+					n, i/2, vals[i/2], val)
+				break
+			}
+		}
+	}
+
+	if _, err := NewHermEigen(FromSlice(2, 2, []complex128{1, 1i, 1i, 1})); err == nil ||
+		err.(*mat.MatrixError).Code != mat.ParameterError {
+		t.Errorf("NewHermEigen accepted a non-Hermitian Matrix")
+	}
+}
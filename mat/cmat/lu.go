@@ -0,0 +1,346 @@
+package cmat
+
+import (
+	"fmt"
+	"math/cmplx"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// LU represents the LU decomposition with partial pivoting of a square
+// complex Matrix, A. The decomposition satisfies P A = L U, where P is a
+// permutation Matrix, L is unit lower triangular, and U is upper triangular.
+//
+// An LU can be used to solve many linear systems with the same left-hand
+// side for the cost of a single decomposition.
+type LU struct {
+	n    int
+	lu   []complex128 // L below the diagonal and U on and above it.
+	perm []int        // Row i of P A is row perm[i] of A.
+	sign float64      // Determinant of P.
+
+	singular bool
+}
+
+// NewLU computes the LU decomposition of m. m is not modified.
+//
+// If m is nil, an error Matrix, or not square, a non-nil error is returned.
+// Singular matrices can be decomposed, but cannot be used to solve linear
+// systems or compute inverses.
+func NewLU(m *Matrix) (*LU, error) {
+	f, err := newLU("NewLU", m)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newLU(operationName string, m *Matrix) (*LU, *mat.MatrixError) {
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	}
+
+	n := m.width
+	f := &LU{
+		n: n, lu: make([]complex128, n * n), perm: make([]int, n), sign: 1,
+	}
+	copy(f.lu, m.values)
+	for i := range f.perm {
+		f.perm[i] = i
+	}
+
+	lu := f.lu
+	for k := 0; k < n; k++ {
+		p, max := k, cmplx.Abs(lu[k * n + k])
+		for r := k + 1; r < n; r++ {
+			if v := cmplx.Abs(lu[r * n + k]); v > max {
+				p, max = r, v
+			}
+		}
+
+		if p != k {
+			rowK, rowP := lu[k * n: (k + 1) * n], lu[p * n: (p + 1) * n]
+			for c := range rowK {
+				rowK[c], rowP[c] = rowP[c], rowK[c]
+			}
+			f.perm[k], f.perm[p] = f.perm[p], f.perm[k]
+			f.sign = -f.sign
+		}
+
+		pivot := lu[k * n + k]
+		if pivot == 0 {
+			f.singular = true
+			continue
+		}
+
+		rowK := lu[k * n + k + 1: (k + 1) * n]
+		for r := k + 1; r < n; r++ {
+			l := lu[r * n + k] / pivot
+			lu[r * n + k] = l
+			if l == 0 {
+				continue
+			}
+			rowR := lu[r * n + k + 1: (r + 1) * n]
+			for c, u := range rowK {
+				rowR[c] -= l * u
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// IsSingular returns true if the decomposed Matrix is exactly singular.
+func (f *LU) IsSingular() bool {
+	return f.singular
+}
+
+// L returns the unit lower triangular factor of the decomposition.
+func (f *LU) L() *Matrix {
+	n := f.n
+	l := Identity(n)
+	for r := 1; r < n; r++ {
+		copy(l.values[r * n: r * n + r], f.lu[r * n: r * n + r])
+	}
+	return l
+}
+
+// U returns the upper triangular factor of the decomposition.
+func (f *LU) U() *Matrix {
+	n := f.n
+	u := New(n, n)
+	for r := 0; r < n; r++ {
+		copy(u.values[r * n + r: (r + 1) * n], f.lu[r * n + r: (r + 1) * n])
+	}
+	return u
+}
+
+// Pivots returns the row permutation of the decomposition: row i of P A is
+// row Pivots()[i] of A.
+func (f *LU) Pivots() []int {
+	perm := make([]int, f.n)
+	copy(perm, f.perm)
+	return perm
+}
+
+// Determinant returns the determinant of the decomposed Matrix.
+func (f *LU) Determinant() complex128 {
+	det := complex(f.sign, 0)
+	for i := 0; i < f.n; i++ {
+		det *= f.lu[i * f.n + i]
+	}
+	return det
+}
+
+// Solve solves the linear system A X = B, where each column of B is a
+// separate right-hand side, and returns X.
+//
+// If b is nil or does not have the same height as A, or if A is singular,
+// an error Matrix is returned.
+func (f *LU) Solve(b *Matrix) *Matrix {
+	if err := inputError("Solve", b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(b.width, b.height).solveLU("Solve", f, b)
+}
+
+// SolveLU solves the linear system A X = B using the decomposition f and
+// stores X in the target Matrix. The target Matrix is also returned. Each
+// column of B is a separate right-hand side.
+//
+// If b is nil or does not have the same height as A, if target is not the
+// same shape as b, or if A is singular, target is set to an error Matrix.
+func (target *Matrix) SolveLU(f *LU, b *Matrix) *Matrix {
+	return target.solveLU("SolveLU", f, b)
+}
+
+func (target *Matrix) solveLU(operationName string, f *LU, b *Matrix) *Matrix {
+	if err := f.checkSolve(operationName, target, b); err != nil {
+		return target.setError(err)
+	}
+
+	if target != b {
+		copy(target.values, b.values)
+	}
+	f.solveInPlace(target.values, b.width)
+	target.err = nil
+	return target
+}
+
+// SolveVec solves the linear system A x = b for a single right-hand side.
+//
+// If b does not have the same length as the height of A, or if A is
+// singular, a non-nil error is returned.
+func (f *LU) SolveVec(b []complex128) ([]complex128, error) {
+	if len(b) != f.n {
+		desc := fmt.Sprintf("Length of right-hand side, %d, does not match Matrix height %d.",
+			len(b), f.n)
+		return nil, mat.NewError(mat.ShapeError, "cmat.SolveVec", desc)
+	} else if f.singular {
+		return nil, mat.NewError(mat.SingularError, "cmat.SolveVec", "Matrix is singular.")
+	}
+
+	x := make([]complex128, f.n)
+	copy(x, b)
+	f.solveInPlace(x, 1)
+	return x, nil
+}
+
+// Inverse returns the inverse of the decomposed Matrix.
+//
+// If A is singular, an error Matrix is returned.
+func (f *LU) Inverse() *Matrix {
+	if f.singular {
+		return newErrorMatrix(mat.SingularError, "Inverse", "Matrix is singular.")
+	}
+	inv := Identity(f.n)
+	f.solveInPlace(inv.values, f.n)
+	return inv
+}
+
+// checkSolve returns an error if b cannot be used as the right-hand side of
+// a linear system with f or if target cannot hold the solution.
+func (f *LU) checkSolve(operationName string, target, b *Matrix) *mat.MatrixError {
+	if err := inputError(operationName, b); err != nil {
+		return err
+	} else if target == nil {
+		return mat.NewError(mat.NilError, "cmat." + operationName, "Target Matrix is nil.")
+	} else if b.height != f.n {
+		desc := fmt.Sprintf("Right-hand side height %d does not match Matrix height %d.",
+			b.height, f.n)
+		return mat.NewError(mat.ShapeError, "cmat." + operationName, desc)
+	} else if !Compatible(target, b) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match right-hand side shape (%d, %d).",
+			target.width, target.height, b.width, b.height)
+		return mat.NewError(mat.ShapeError, "cmat." + operationName, desc)
+	} else if f.singular {
+		return mat.NewError(mat.SingularError, "cmat." + operationName, "Matrix is singular.")
+	}
+	return nil
+}
+
+// solveInPlace overwrites the row-major (n x w) matrix x with the solution to
+// A X = x.
+func (f *LU) solveInPlace(x []complex128, w int) {
+	n, lu := f.n, f.lu
+
+	// Apply the permutation.
+	tmp := make([]complex128, n * w)
+	for i := 0; i < n; i++ {
+		copy(tmp[i * w: (i + 1) * w], x[f.perm[i] * w: (f.perm[i] + 1) * w])
+	}
+	copy(x, tmp)
+
+	// Forward substitution with L.
+	for i := 1; i < n; i++ {
+		xi := x[i * w: (i + 1) * w]
+		for k := 0; k < i; k++ {
+			l := lu[i * n + k]
+			if l == 0 {
+				continue
+			}
+			xk := x[k * w: (k + 1) * w]
+			for j := range xi {
+				xi[j] -= l * xk[j]
+			}
+		}
+	}
+
+	// Back substitution with U.
+	for i := n - 1; i >= 0; i-- {
+		xi := x[i * w: (i + 1) * w]
+		for k := i + 1; k < n; k++ {
+			u := lu[i * n + k]
+			if u == 0 {
+				continue
+			}
+			xk := x[k * w: (k + 1) * w]
+			for j := range xi {
+				xi[j] -= u * xk[j]
+			}
+		}
+		d := lu[i * n + i]
+		for j := range xi {
+			xi[j] /= d
+		}
+	}
+}
+
+// Solve solves the linear system A X = B, where each column of B is a
+// separate right-hand side, and returns X.
+//
+// If a or b is nil, if a is not square, if b does not have the same height
+// as a, or if a is singular, an error Matrix is returned.
+func Solve(a, b *Matrix) *Matrix {
+	if err := inputError("Solve", a, b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(b.width, b.height).Solve(a, b)
+}
+
+// Solve solves the linear system A X = B and stores X in the target Matrix.
+// The target Matrix is also returned.
+//
+// If a or b is nil, if a is not square, if b does not have the same height
+// as a, if target is not the same shape as b, or if a is singular, target is
+// set to an error Matrix.
+func (target *Matrix) Solve(a, b *Matrix) *Matrix {
+	f, err := newLU("Solve", a)
+	if err != nil {
+		return target.setError(err)
+	}
+	return target.solveLU("Solve", f, b)
+}
+
+// SolveVec solves the linear system A x = b for a single right-hand side.
+//
+// If a is nil or not square, if b does not have the same length as the
+// height of a, or if a is singular, a non-nil error is returned.
+func SolveVec(a *Matrix, b []complex128) ([]complex128, error) {
+	f, err := newLU("SolveVec", a)
+	if err != nil {
+		return nil, err
+	}
+	return f.SolveVec(b)
+}
+
+// Invert returns the inverse of m.
+//
+// If m is nil or not a square matrix or is singular, an error Matrix is
+// returned.
+func Invert(m *Matrix) *Matrix {
+	if err := inputError("Invert", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.height, m.width).Invert(m)
+}
+
+// Invert calculates the inverse of m and places it in the target Matrix.
+//
+// If m is nil or not a square Matrix or is singular, target is set to an
+// error Matrix. target is also set to an error Matrix if it is not the same
+// shape as m.
+func (target *Matrix) Invert(m *Matrix) *Matrix {
+	f, err := newLU("Invert", m)
+	if err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(mat.NilError, "Invert", "Target Matrix is nil.")
+	} else if !Compatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match inverse shape (%d, %d).",
+			target.width, target.height, m.width, m.height)
+		return target.setError(mat.NewError(mat.ShapeError, "cmat.Invert", desc))
+	} else if f.singular {
+		return target.setError(mat.NewError(mat.SingularError, "cmat.Invert", "Matrix is singular."))
+	}
+
+	for i := range target.values {
+		target.values[i] = 0
+	}
+	for i := 0; i < f.n; i++ {
+		target.values[i * f.n + i] = 1
+	}
+	f.solveInPlace(target.values, f.n)
+	target.err = nil
+	return target
+}
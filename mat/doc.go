@@ -47,7 +47,7 @@ it is always safe to call that method, even if that Matrix is a method
 argument. For certain operations this may result in a temporary matrix being
 allocated underneath the hood. It is garuanteed that regardless of
 implementation Add(m1, m2), Sub(m1, m2), and Scale(m1, m2) will not allocate
-any temporary matrices.
+any temporary matrices unless they are given views, as described below.
 
 Routines which return a value that is not a Matrix and only depend on a
 single Matrix, such as m.Eigenvectors() or m.Determinant(), will always be
@@ -59,6 +59,17 @@ unary Marix operation are Eigenvalues, Eigenvectors, Trace, Determinant,
 Transpose, Inverse, Scale, and Copy. Supported special functions are Exp, Sin,
 Cos, Sinh, Cosh, Log, Sqrt, and the general purpose Func.
 
+The 1-, infinity-, Frobenius, and max-element norms are available through
+m.Norm1(), m.NormInf(), m.NormFrobenius(), and m.NormMax(), and
+m.ConditionEstimate() cheaply estimates the 1-norm condition number from an LU
+factorization. AlmostEqualTol(m1, m2, rtol, atol) compares matrices with
+explicit tolerances and reports the worst-offending element, which is useful
+in tests.
+
+Matrices with band structure can be stored compactly in the TridiagonalMatrix,
+CyclicTridiagonal, and Banded types, which provide fast linear solves and can
+be converted to Matrices with their Dense methods.
+
 Usage examples can be found in mat/operation_examples.go
 
 Allocation and Manipulation:
@@ -80,6 +91,25 @@ element coordinates which are out of bounds of if called on a nil Matrix.
 Bounds checking can be done via the m.InBounds(x, y) method. This is done
 because inlcuding it would drastically reduce the utility of these operations.
 
+Parts of a Matrix can be worked on without copying through views, which share
+storage with the Matrix they were created from. m.View(x, y, width, height)
+returns a rectangular block, m.Rows(start, end) and m.Cols(start, end) return
+ranges of rows and columns, m.Row(y) and m.Col(x) return single rows and
+columns, and m.Diag() returns the main diagonal as a column. Views can be used
+as both the inputs and the targets of every operation:
+
+	// Replace the upper right 2 x 2 block of m with its inverse.
+	block := m.View(m.Width() - 2, 0, 2, 2)
+	block.Invert(block)
+
+Operations may copy views which do not span the full width of their parent
+Matrix, and inputs which partially overlap the target, into temporary
+Matrices, so even Add, Sub, and Scale may allocate in these cases.
+
+Larger Matrices can be assembled from smaller ones with HStack, VStack, and
+BlockDiag, and Kronecker, Hadamard, PermuteRows, and PermuteCols compute
+Kronecker products, element-wise products, and row and column permutations.
+
 Usage examples can be found in mat/manipulation_examples.go
 
 Utilities:
@@ -112,9 +142,14 @@ Usage examples can be found in mat/util_examples.go
 Error Handling:
 
 With the exception of bounds errors in m.Get(x, y) and m.Set(x, y, value),
-functions in package mat do not panic by default and instead return error
-structs. This default behavior can be switched by calling TogglePanic(). The
-use of this function in non-debugging contexts is strongly discouraged.
+functions in package mat do not panic and instead return error structs.
+Callers which would rather panic can check results under an ErrorPolicy:
+PanicOnError.Check(m) panics if m is an error Matrix and
+PanicOnError.CheckErr(err) panics if err is non-nil. Policies are plain values
+and can be carried through a pipeline with WithErrorPolicy(ctx, policy) and
+ErrorPolicyFrom(ctx), so one goroutine, request, or test can panic on errors
+without affecting any other. The older TogglePanic() switch is global and
+deprecated.
 
 All explicit errors which are returned by funcitons in package mat are
 pointers to the MatrixError struct. Most operations do not return explicit 
@@ -149,15 +184,40 @@ error:
 Error strings will not contain stacktraces, but these can be obtained from the
 Stack field of MatrixError.
 
+Error codes can be tested with errors.Is and the sentinel errors ErrNil,
+ErrShape, ErrSingular, ErrParameter, ErrDefinite, and ErrIteration. This works
+on error Matrices as well as on returned errors:
+
+	if errors.Is(mat.Mult(a, b), mat.ErrShape) {
+		// Handle mismatched shapes.
+	}
+
 Usage examples can be found in mat/error_examples.go
 
 Subpackages:
 
-The subpackage cmat/ implements the same Matrix operations as package mat for
-complex-valued Matrices. In the interest of providing clean interfaces several
+The subpackage cmat/ implements complex-valued Matrices with the same
+constructor, target method, and error Matrix conventions as package mat. It
+provides arithmetic, conjugate transposes, LU decompositions for solving
+linear systems, and eigendecompositions of Hermitian Matrices. In the interest of providing clean interfaces several
 non-trivial optimizations based on parameter-spamming and algorithm selection
 have been ignored. These clunkier, optimized interfaces can be found in the
-optmat/ and optcmat/ subpackages. These pacakges are not currently implemented
-and will remain that way until package mat is completed.
+optmat/ and optcmat/ subpackages. optmat/ provides BLAS-style kernels (Gemm,
+Gemv, Syrk, Trsm, and Axpy) on raw slices with leading dimensions and
+transpose flags, and Mult delegates to its parallel Gemm for large Matrices.
+optcmat/ is not currently implemented.
+
+The subpackage sparse/ implements sparse matrices in coordinate (COO),
+compressed sparse row (CSR), and compressed sparse column (CSC) formats for
+problems which are too large to be stored as dense Matrices. The subpackage
+krylov/ implements preconditioned iterative solvers (CG, MINRES, GMRES, and
+BiCGSTAB) which can be used with dense Matrices, sparse matrices, or
+matrix-free operators.
+
+The subpackage lp/ solves linear programs with a two-phase revised simplex
+method and reports the dual values of every constraint.
+
+The subpackage matio/ reads and writes Matrices and Vectors in the Matrix
+Market, NumPy .npy and .npz, and whitespace-separated table formats.
 */
 package mat
@@ -0,0 +1,446 @@
+package mat
+
+import (
+	"math"
+	"sort"
+)
+
+// SymEigenMethod is the algorithm used to compute the eigendecomposition of a
+// symmetric Matrix.
+type SymEigenMethod int
+
+const (
+	// Tridiagonal reduces the Matrix to tridiagonal form with Householder
+	// reflections and then diagonalizes it with the implicit QL algorithm.
+	// This is the fastest method for all but the smallest matrices.
+	Tridiagonal SymEigenMethod = iota
+	// Jacobi diagonalizes the Matrix with cyclic Jacobi rotations. It is
+	// slower than Tridiagonal, but can be more accurate for small matrices,
+	// particularly when computing small eigenvalues to high relative
+	// precision.
+	Jacobi
+)
+
+const (
+	// jacobiMaxSweeps is the maximum number of sweeps over the off-diagonal
+	// elements performed by the Jacobi method.
+	jacobiMaxSweeps = 50
+)
+
+// SymEigen represents the eigendecomposition of a symmetric Matrix, A. The
+// decomposition satisfies A = V D V^T, where D is a diagonal Matrix of the
+// eigenvalues of A in ascending order and the columns of V are the
+// corresponding orthonormal eigenvectors.
+//
+// Only the lower triangle of A is ever read, so the upper triangle is assumed
+// to match it.
+type SymEigen struct {
+	n       int
+	values  []float64
+	vectors []float64 // Column j is the eigenvector of values[j].
+}
+
+// NewSymEigen computes the eigendecomposition of the symmetric Matrix m using
+// the given method. m is not modified.
+//
+// If m is nil, an error Matrix, or not square, a non-nil error is returned.
+func NewSymEigen(m *Matrix, method SymEigenMethod) (*SymEigen, error) {
+	f, err := newSymEigen("NewSymEigen", m, method)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newSymEigen(
+	operationName string, m *Matrix, method SymEigenMethod,
+) (*SymEigen, *MatrixError) {
+	m = dense(m)
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	}
+
+	n := m.width
+	f := &SymEigen{
+		n: n, values: make([]float64, n), vectors: make([]float64, n * n),
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			f.vectors[i * n + j] = m.values[i * n + j]
+			f.vectors[j * n + i] = m.values[i * n + j]
+		}
+	}
+
+	switch method {
+	case Tridiagonal:
+		e := make([]float64, n)
+		tridiagonalize(f.vectors, f.values, e, n)
+		tridiagonalQL(f.vectors, f.values, e, n)
+	case Jacobi:
+		a := f.vectors
+		f.vectors = make([]float64, n * n)
+		jacobiEigen(a, f.values, f.vectors, n)
+	default:
+		return nil, newError(ParameterError, operationName,
+			"Unrecognized SymEigenMethod.")
+	}
+
+	f.sort()
+	f.normalizeSigns()
+	return f, nil
+}
+
+// Values returns the eigenvalues of A in ascending order.
+func (f *SymEigen) Values() []float64 {
+	values := make([]float64, f.n)
+	copy(values, f.values)
+	return values
+}
+
+// Vectors returns a Matrix whose columns are the orthonormal eigenvectors of
+// A, in the same order as Values(). The sign of each eigenvector is chosen so
+// that its largest element is positive.
+func (f *SymEigen) Vectors() *Matrix {
+	v := New(f.n, f.n)
+	copy(v.values, f.vectors)
+	return v
+}
+
+// Vector returns the eigenvector corresponding to Values()[j].
+//
+// Vector panics if j is out of range.
+func (f *SymEigen) Vector(j int) []float64 {
+	vec := make([]float64, f.n)
+	for i := range vec {
+		vec[i] = f.vectors[i * f.n + j]
+	}
+	return vec
+}
+
+// sort sorts the eigenvalues in ascending order and permutes the columns of
+// the eigenvectors to match.
+func (f *SymEigen) sort() {
+	n := f.n
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return f.values[order[i]] < f.values[order[j]]
+	})
+
+	values := make([]float64, n)
+	vectors := make([]float64, n * n)
+	for j, p := range order {
+		values[j] = f.values[p]
+		for i := 0; i < n; i++ {
+			vectors[i * n + j] = f.vectors[i * n + p]
+		}
+	}
+	f.values, f.vectors = values, vectors
+}
+
+// normalizeSigns flips the sign of each eigenvector so that its element with
+// the largest magnitude is positive. This makes the decomposition of a given
+// Matrix deterministic.
+func (f *SymEigen) normalizeSigns() {
+	n := f.n
+	for j := 0; j < n; j++ {
+		max := 0.0
+		for i := 0; i < n; i++ {
+			if v := f.vectors[i * n + j]; math.Abs(v) > math.Abs(max) {
+				max = v
+			}
+		}
+		if max < 0 {
+			for i := 0; i < n; i++ {
+				f.vectors[i * n + j] = -f.vectors[i * n + j]
+			}
+		}
+	}
+}
+
+// tridiagonalize reduces the symmetric row-major n x n matrix v to
+// tridiagonal form with Householder reflections. On return, d holds the
+// diagonal, e[1:] holds the subdiagonal, and v holds the accumulated
+// orthogonal transformation.
+//
+// This follows the EISPACK routine tred2.
+func tridiagonalize(v, d, e []float64, n int) {
+	for j := 0; j < n; j++ {
+		d[j] = v[(n - 1) * n + j]
+	}
+
+	for i := n - 1; i > 0; i-- {
+		scale, h := 0.0, 0.0
+		for k := 0; k < i; k++ {
+			scale += math.Abs(d[k])
+		}
+
+		if scale == 0 {
+			e[i] = d[i - 1]
+			for j := 0; j < i; j++ {
+				d[j] = v[(i - 1) * n + j]
+				v[i * n + j] = 0
+				v[j * n + i] = 0
+			}
+		} else {
+			// Generate the Householder vector.
+			for k := 0; k < i; k++ {
+				d[k] /= scale
+				h += d[k] * d[k]
+			}
+			f := d[i - 1]
+			g := math.Sqrt(h)
+			if f > 0 {
+				g = -g
+			}
+			e[i] = scale * g
+			h -= f * g
+			d[i - 1] = f - g
+			for j := 0; j < i; j++ {
+				e[j] = 0
+			}
+
+			// Apply the similarity transformation to the remaining columns.
+			for j := 0; j < i; j++ {
+				f = d[j]
+				v[j * n + i] = f
+				g = e[j] + v[j * n + j] * f
+				for k := j + 1; k < i; k++ {
+					g += v[k * n + j] * d[k]
+					e[k] += v[k * n + j] * f
+				}
+				e[j] = g
+			}
+			f = 0
+			for j := 0; j < i; j++ {
+				e[j] /= h
+				f += e[j] * d[j]
+			}
+			hh := f / (h + h)
+			for j := 0; j < i; j++ {
+				e[j] -= hh * d[j]
+			}
+			for j := 0; j < i; j++ {
+				f, g = d[j], e[j]
+				for k := j; k < i; k++ {
+					v[k * n + j] -= f * e[k] + g * d[k]
+				}
+				d[j] = v[(i - 1) * n + j]
+				v[i * n + j] = 0
+			}
+		}
+		d[i] = h
+	}
+
+	// Accumulate the transformations.
+	for i := 0; i < n - 1; i++ {
+		v[(n - 1) * n + i] = v[i * n + i]
+		v[i * n + i] = 1
+		h := d[i + 1]
+		if h != 0 {
+			for k := 0; k <= i; k++ {
+				d[k] = v[k * n + i + 1] / h
+			}
+			for j := 0; j <= i; j++ {
+				g := 0.0
+				for k := 0; k <= i; k++ {
+					g += v[k * n + i + 1] * v[k * n + j]
+				}
+				for k := 0; k <= i; k++ {
+					v[k * n + j] -= g * d[k]
+				}
+			}
+		}
+		for k := 0; k <= i; k++ {
+			v[k * n + i + 1] = 0
+		}
+	}
+	for j := 0; j < n; j++ {
+		d[j] = v[(n - 1) * n + j]
+		v[(n - 1) * n + j] = 0
+	}
+	v[(n - 1) * n + n - 1] = 1
+	e[0] = 0
+}
+
+// tridiagonalQL diagonalizes the symmetric tridiagonal matrix with diagonal d
+// and subdiagonal e[1:] using the implicit QL algorithm with Wilkinson
+// shifts. On return, d holds the eigenvalues and the rotations have been
+// applied to the columns of the row-major n x n matrix v.
+//
+// This follows the EISPACK routine tql2.
+func tridiagonalQL(v, d, e []float64, n int) {
+	for i := 1; i < n; i++ {
+		e[i - 1] = e[i]
+	}
+	e[n - 1] = 0
+
+	f, tst1 := 0.0, 0.0
+	for l := 0; l < n; l++ {
+		// Find a small subdiagonal element.
+		tst1 = math.Max(tst1, math.Abs(d[l]) + math.Abs(e[l]))
+		m := l
+		for m < n - 1 && math.Abs(e[m]) > machineEpsilon * tst1 {
+			m++
+		}
+
+		// If m == l, d[l] is already an eigenvalue. Otherwise iterate.
+		for m > l && math.Abs(e[l]) > machineEpsilon * tst1 {
+			// Compute the implicit shift.
+			g := d[l]
+			p := (d[l + 1] - g) / (2 * e[l])
+			r := math.Copysign(math.Hypot(p, 1), p)
+			d[l] = e[l] / (p + r)
+			d[l + 1] = e[l] * (p + r)
+			dl1 := d[l + 1]
+			h := g - d[l]
+			for i := l + 2; i < n; i++ {
+				d[i] -= h
+			}
+			f += h
+
+			// Implicit QL transformation.
+			p = d[m]
+			c, c2, c3 := 1.0, 1.0, 1.0
+			el1 := e[l + 1]
+			s, s2 := 0.0, 0.0
+			for i := m - 1; i >= l; i-- {
+				c3, c2, s2 = c2, c, s
+				g = c * e[i]
+				h = c * p
+				r = math.Hypot(p, e[i])
+				e[i + 1] = s * r
+				s, c = e[i] / r, p / r
+				p = c * d[i] - s * g
+				d[i + 1] = h + s * (c * g + s * d[i])
+
+				// Accumulate the transformation.
+				for k := 0; k < n; k++ {
+					vk := v[k * n: (k + 1) * n]
+					h = vk[i + 1]
+					vk[i + 1] = s * vk[i] + c * h
+					vk[i] = c * vk[i] - s * h
+				}
+			}
+			p = -s * s2 * c3 * el1 * e[l] / dl1
+			e[l] = s * p
+			d[l] = c * p
+		}
+		d[l] += f
+		e[l] = 0
+	}
+}
+
+// jacobiEigen diagonalizes the symmetric row-major n x n matrix a with cyclic
+// Jacobi rotations. The upper triangle of a is destroyed. On return, d holds
+// the eigenvalues and the columns of v hold the eigenvectors.
+func jacobiEigen(a, d, v []float64, n int) {
+	b, z := make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		v[i * n + i] = 1
+		b[i], d[i] = a[i * n + i], a[i * n + i]
+	}
+
+	for sweep := 0; sweep < jacobiMaxSweeps; sweep++ {
+		off := 0.0
+		for p := 0; p < n - 1; p++ {
+			for q := p + 1; q < n; q++ {
+				off += math.Abs(a[p * n + q])
+			}
+		}
+		if off == 0 {
+			return
+		}
+
+		// Skip small rotations during the first few sweeps.
+		thresh := 0.0
+		if sweep < 3 {
+			thresh = 0.2 * off / float64(n * n)
+		}
+
+		for p := 0; p < n - 1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := a[p * n + q]
+				g := 100 * math.Abs(apq)
+
+				// Rotations which can't change the diagonal are skipped
+				// after the first few sweeps.
+				if sweep > 3 && math.Abs(d[p]) + g == math.Abs(d[p]) &&
+					math.Abs(d[q]) + g == math.Abs(d[q]) {
+					a[p * n + q] = 0
+					continue
+				} else if math.Abs(apq) <= thresh {
+					continue
+				}
+
+				h := d[q] - d[p]
+				var t float64
+				if math.Abs(h) + g == math.Abs(h) {
+					t = apq / h
+				} else {
+					theta := 0.5 * h / apq
+					t = 1 / (math.Abs(theta) + math.Sqrt(1 + theta * theta))
+					if theta < 0 {
+						t = -t
+					}
+				}
+				c := 1 / math.Sqrt(1 + t * t)
+				s := t * c
+				tau := s / (1 + c)
+				h = t * apq
+				z[p] -= h
+				z[q] += h
+				d[p] -= h
+				d[q] += h
+				a[p * n + q] = 0
+
+				for j := 0; j < p; j++ {
+					jacobiRotate(a, s, tau, j * n + p, j * n + q)
+				}
+				for j := p + 1; j < q; j++ {
+					jacobiRotate(a, s, tau, p * n + j, j * n + q)
+				}
+				for j := q + 1; j < n; j++ {
+					jacobiRotate(a, s, tau, p * n + j, q * n + j)
+				}
+				for j := 0; j < n; j++ {
+					jacobiRotate(v, s, tau, j * n + p, j * n + q)
+				}
+			}
+		}
+
+		// Recompute the diagonal from its starting point to limit the
+		// accumulation of rounding errors.
+		for p := 0; p < n; p++ {
+			b[p] += z[p]
+			d[p] = b[p]
+			z[p] = 0
+		}
+	}
+}
+
+// jacobiRotate applies a Jacobi rotation to the elements at indices i and j
+// of a.
+func jacobiRotate(a []float64, s, tau float64, i, j int) {
+	g, h := a[i], a[j]
+	a[i] = g - s * (h + g * tau)
+	a[j] = h + s * (g - h * tau)
+}
+
+// isSymmetric returns true if m is square and exactly equal to its transpose.
+func isSymmetric(m *Matrix) bool {
+	if m.width != m.height {
+		return false
+	}
+	n := m.width
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			if m.values[i * m.stride + j] != m.values[j * m.stride + i] {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package mat
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+// randomSymmetric returns a random n x n symmetric Matrix.
+func randomSymmetric(n int) *Matrix {
+	m := randomMatrix(n, n)
+	return Scale(Add(m, Transpose(m)), 0.5)
+}
+
+func TestSymEigen(t *testing.T) {
+	tests := []struct {
+		m      *Matrix
+		values []float64
+	}{
+		{FromSlice(1, 1, []float64{3}), []float64{3}},
+		{FromSlice(2, 2, []float64{2, 1, 1, 2}), []float64{1, 3}},
+		{FromSlice(3, 3, []float64{2, -1, 0, -1, 2, -1, 0, -1, 2}),
+			[]float64{2 - math.Sqrt2, 2, 2 + math.Sqrt2}},
+		{FromSlice(3, 3, []float64{5, 0, 0, 0, -1, 0, 0, 0, 2}),
+			[]float64{-1, 2, 5}},
+		{Identity(4), []float64{1, 1, 1, 1}},
+		{randomSymmetric(30), nil},
+	}
+
+	for i, test := range tests {
+		for _, method := range []SymEigenMethod{Tridiagonal, Jacobi} {
+			f, err := NewSymEigen(test.m, method)
+			if err != nil {
+				t.Errorf("%d) NewSymEigen(%d) returned error: %s",
+					i, method, err)
+				continue
+			}
+
+			values, v := f.Values(), f.Vectors()
+			if !sort.Float64sAreSorted(values) {
+				t.Errorf("%d) Eigenvalues %v are not sorted (method %d)",
+					i, values, method)
+			}
+			for j := range test.values {
+				if math.Abs(values[j]-test.values[j]) > 1e-13 {
+					t.Errorf("%d) Eigenvalues = %v, wanted %v (method %d)",
+						i, values, test.values, method)
+					break
+				}
+			}
+
+			n := test.m.width
+			if diff := maxDiff(Mult(Transpose(v), v), Identity(n)); diff > 1e-12 {
+				t.Errorf("%d) V^T V differs from I by %g (method %d)",
+					i, diff, method)
+			}
+
+			// A V = V D
+			vd := Copy(v)
+			for y := 0; y < n; y++ {
+				for x := 0; x < n; x++ {
+					vd.Set(x, y, vd.Get(x, y)*values[x])
+				}
+			}
+			if diff := maxDiff(Mult(test.m, v), vd); diff > 1e-12 {
+				t.Errorf("%d) A V differs from V D by %g (method %d)",
+					i, diff, method)
+			}
+		}
+	}
+
+	// Both methods should agree on the eigenvectors, including their signs.
+	m := randomSymmetric(8)
+	f1, _ := NewSymEigen(m, Tridiagonal)
+	f2, _ := NewSymEigen(m, Jacobi)
+	if diff := maxDiff(f1.Vectors(), f2.Vectors()); diff > 1e-10 {
+		t.Errorf("Tridiagonal and Jacobi eigenvectors differ by %g", diff)
+	}
+	if vec := f1.Vector(3); vec[5] != f1.Vectors().Get(3, 5) {
+		t.Errorf("f.Vector(3) = %v does not match column 3 of f.Vectors()", vec)
+	}
+
+	if _, err := NewSymEigen(New(3, 2), Tridiagonal); err == nil {
+		t.Errorf("NewSymEigen of non-square Matrix returned nil error.")
+	}
+	if _, err := NewSymEigen(Identity(2), SymEigenMethod(-1)); err == nil {
+		t.Errorf("NewSymEigen with invalid method returned nil error.")
+	}
+}
+
+func TestEigenSymmetric(t *testing.T) {
+	m := FromSlice(2, 2, []float64{2, 1, 1, 2})
+	values, err := m.Eigenvalues()
+	if err != nil || len(values) != 2 || math.Abs(real(values[0])-1) > 1e-14 ||
+		math.Abs(real(values[1])-3) > 1e-14 {
+		t.Errorf("m.Eigenvalues() = (%v, %v), wanted [1 3]", values, err)
+	}
+
+	vectors, err := m.Eigenvectors()
+	if err != nil || len(vectors) != 2 {
+		t.Fatalf("m.Eigenvectors() = (%v, %v)", vectors, err)
+	}
+	r := 1 / math.Sqrt2
+	if math.Abs(real(vectors[1][0])-r) > 1e-14 ||
+		math.Abs(real(vectors[1][1])-r) > 1e-14 {
+		t.Errorf("m.Eigenvectors()[1] = %v, wanted [%g %g]", vectors[1], r, r)
+	}
+
+	if _, err := New(2, 3).Eigenvalues(); err == nil {
+		t.Errorf("Eigenvalues of non-square Matrix returned nil error.")
+	}
+	var nilMatrix *Matrix
+	if _, err := nilMatrix.Eigenvectors(); err == nil {
+		t.Errorf("Eigenvectors of nil Matrix returned nil error.")
+	}
+}
@@ -1,8 +1,10 @@
 package mat
 
 import (
+	"context"
 	"fmt"
 	"runtime"
+	"sync/atomic"
 )
 
 // MatrixError is error type returned by functions which do not result in
@@ -23,25 +25,108 @@ const (
 	              // allowed.
 	ParameterError // A non-Matrix function parameter was outside the
 	               // aceptable range.
+	DefiniteError // Matrix was not positive definite in a context where
+	              // this was required.
+	IterationError // An iterative algorithm failed to converge.
 
 	defaultStackSize = 1 << 9 
 )
 
 var (
-	willPanic bool = false
+	// Sentinel errors which can be used with errors.Is to test the code of
+	// a *MatrixError or of an error Matrix, e.g.
+	// errors.Is(mat.Mult(a, b), mat.ErrShape).
+	ErrNil = &MatrixError{Code: NilError}
+	ErrShape = &MatrixError{Code: ShapeError}
+	ErrSingular = &MatrixError{Code: SingularError}
+	ErrParameter = &MatrixError{Code: ParameterError}
+	ErrDefinite = &MatrixError{Code: DefiniteError}
+	ErrIteration = &MatrixError{Code: IterationError}
+
+	// legacyPanic is the global switch flipped by TogglePanic.
+	legacyPanic int32
+)
+
+// ErrorPolicy describes what should happen when an operation in package mat
+// fails. Policies are values rather than global state, so different
+// goroutines, requests, or tests can use different policies without
+// interfering with one another.
+type ErrorPolicy int
+
+const (
+	// ReturnErrors leaves errors in error Matrices and returned
+	// *MatrixErrors. This is the default.
+	ReturnErrors ErrorPolicy = iota
+	// PanicOnError panics with the *MatrixError of any failed operation
+	// that is checked under the policy.
+	PanicOnError
 )
 
+// errorPolicyKey is the context key under which ErrorPolicies are stored.
+type errorPolicyKey struct{}
+
+// WithErrorPolicy returns a copy of ctx which carries the given ErrorPolicy.
+func WithErrorPolicy(ctx context.Context, policy ErrorPolicy) context.Context {
+	return context.WithValue(ctx, errorPolicyKey{}, policy)
+}
+
+// ErrorPolicyFrom returns the ErrorPolicy carried by ctx. If ctx is nil or
+// does not carry a policy, ReturnErrors is returned.
+func ErrorPolicyFrom(ctx context.Context) ErrorPolicy {
+	if ctx == nil {
+		return ReturnErrors
+	}
+	policy, ok := ctx.Value(errorPolicyKey{}).(ErrorPolicy)
+	if !ok {
+		return ReturnErrors
+	}
+	return policy
+}
+
+// Check applies policy to the result of an operation and returns m. If
+// policy is PanicOnError and m is nil or an error Matrix, Check panics with
+// the corresponding *MatrixError, so the failure can be recovered and
+// inspected with errors.As. The Stack field of that error records where the
+// operation failed, not where it was checked.
+func (policy ErrorPolicy) Check(m *Matrix) *Matrix {
+	if policy == PanicOnError {
+		if m == nil {
+			panic(newError(NilError, "Check", "Matrix is nil."))
+		} else if m.err != nil {
+			panic(m.err)
+		}
+	}
+	return m
+}
+
+// CheckErr applies policy to an error returned by an operation and returns
+// err. If policy is PanicOnError and err is non-nil, CheckErr panics with
+// err.
+func (policy ErrorPolicy) CheckErr(err error) error {
+	if policy == PanicOnError && err != nil {
+		panic(err)
+	}
+	return err
+}
+
 // TogglePanic changes the behavior of all functions in package mat when they
 // encounter an error. If they currently return error structs, they will
 // instead panic with the Error string of that struct as a parameter and
 // visa-versa. If the behavior after the funciton has been called is to panic,
 // true is returned, otherwise false is returned.
 //
-// By default, operations will not panic. Changing this behavior outside of a
-// debugging context is strongly discouraged.
+// TogglePanic is safe to call concurrently, but it changes the behavior of
+// every goroutine in the program.
+//
+// Deprecated: Use an ErrorPolicy, which can be scoped to a single goroutine,
+// request, or test.
 func TogglePanic() bool {
-	willPanic = !willPanic
-	return willPanic
+	for {
+		old := atomic.LoadInt32(&legacyPanic)
+		if atomic.CompareAndSwapInt32(&legacyPanic, old, 1 - old) {
+			return old == 0
+		}
+	}
 }
 
 // IsError indicates whether m is an error Matrix. IsError returns true if m
@@ -57,13 +142,29 @@ func (m *Matrix) IsError() bool {
 // creation of m. If no errors occured or if m is nil, an empty string is
 // returned.
 func (m *Matrix) Error() string {
+	if m == nil {
+		return ""
+	}
 	return m.err.Error()
 }
 
+// Unwrap returns the *MatrixError of an error Matrix, so that errors.Is and
+// errors.As can be used on error Matrices. If m is nil or is not an error
+// Matrix, nil is returned.
+func (m *Matrix) Unwrap() error {
+	if m == nil || m.err == nil {
+		return nil
+	}
+	return m.err
+}
+
 // MatrixError returns a pointer to the struct representing the first error
 // that occured in the creation of m. If no such error occured, or if m is nil,
 // nil is returned.
 func (m *Matrix) MatrixError() *MatrixError {
+	if m == nil {
+		return nil
+	}
 	return m.err
 }
 
@@ -75,11 +176,25 @@ func (err *MatrixError) Error() string {
 	}
 
 	name := codeName(err.Code)
+	if err.OperationName == "" && err.Description == "" {
+		return name
+	}
 
 	return fmt.Sprintf("%s: %s - mat.%s", name,
 		err.Description, err.OperationName)
 }
 
+// Is reports whether err matches target for errors.Is. A *MatrixError
+// matches one of the sentinel errors, such as ErrShape, if their codes are
+// the same. Otherwise, errors only match themselves.
+func (err *MatrixError) Is(target error) bool {
+	t, ok := target.(*MatrixError)
+	if !ok || err == nil || t == nil {
+		return false
+	}
+	return t.OperationName == "" && t.Description == "" && t.Code == err.Code
+}
+
 // codeName returns a string representation of the given error code.
 func codeName(code int) string {
 	switch code {
@@ -91,11 +206,26 @@ func codeName(code int) string {
 		return "Singular Error"
 	case ParameterError:
 		return "Parameter Error"
+	case DefiniteError:
+		return "Definite Error"
+	case IterationError:
+		return "Iteration Error"
 	default:
 		panic(fmt.Sprintf("Internal Error: Unrecognized error code: %d", code))
 	}
 }
 
+// NewError creates a new *MatrixError with the given code for use by packages
+// which build on package mat, so that their errors are created in the same
+// way as those of package mat. operationName should be qualified with the
+// name of the calling package, e.g. "sparse.NewCSR", and desc should be a
+// full sentence which does not mention the operation. Like the errors of
+// package mat, NewError panics if TogglePanic has been used to turn on
+// panicking.
+func NewError(code int, operationName, desc string) *MatrixError {
+	return newError(code, operationName, desc)
+}
+
 // newErrorMatrix creates a new error Matrix corresponding to the given error
 // code. operationName should given the name of the function which this function
 // is being called from (this will not neccesarily be the name seen by the
@@ -105,7 +235,41 @@ func codeName(code int) string {
 // as the error is propogated up the stack. All descriptions must be full
 // sentences.
 func newErrorMatrix(code int, operationName, desc string) *Matrix {
-	return &Matrix{[]float64{}, 0, 0, newError(code, operationName, desc)}
+	return &Matrix{[]float64{}, 0, 0, 0, newError(code, operationName, desc)}
+}
+
+// newErrorMatrixFrom creates a new error Matrix which contains an existing
+// error. This is used to propagate errors from input Matrices.
+func newErrorMatrixFrom(err *MatrixError) *Matrix {
+	return &Matrix{[]float64{}, 0, 0, 0, err}
+}
+
+// setError turns target into an error Matrix containing err and returns it.
+// If target is nil, a new error Matrix is returned instead.
+func (target *Matrix) setError(err *MatrixError) *Matrix {
+	if target == nil {
+		return newErrorMatrixFrom(err)
+	}
+
+	target.values = target.values[:0]
+	target.width, target.height, target.stride = 0, 0, 0
+	target.err = err
+	return target
+}
+
+// inputError checks the non-target Matrix arguments of an operation. If any
+// argument is nil, a new NilError is returned. If any argument is an error
+// Matrix, its error is returned so that it can be propagated to the result.
+// Otherwise nil is returned.
+func inputError(operationName string, ms ...*Matrix) *MatrixError {
+	for _, m := range ms {
+		if m == nil {
+			return newError(NilError, operationName, "Input Matrix is nil.")
+		} else if m.err != nil {
+			return m.err
+		}
+	}
+	return nil
 }
 
 // newError creates a new MatrixError corresponding to the given error code.
@@ -117,16 +281,17 @@ func newErrorMatrix(code int, operationName, desc string) *Matrix {
 // error is propogated up the stack. All descriptions must be full sentences.
 func newError(code int, operationName, desc string) *MatrixError {
 	err := &MatrixError{code, operationName, desc, ""}
-	if willPanic {
+	if atomic.LoadInt32(&legacyPanic) != 0 {
 		panic(err.Error())
 	}
 
-	bytesRead, stackSize := 0, defaultStackSize
-	var stackBuf []byte
-	for stackSize != bytesRead {
-		stackBuf := make([]byte, stackSize)
+	// runtime.Stack truncates the trace if it doesn't fit in the buffer, so
+	// keep doubling the buffer until the trace fits.
+	stackBuf := make([]byte, defaultStackSize)
+	bytesRead := runtime.Stack(stackBuf, false)
+	for bytesRead == len(stackBuf) {
+		stackBuf = make([]byte, len(stackBuf) << 1)
 		bytesRead = runtime.Stack(stackBuf, false)
-		stackSize = stackSize << 1
 	}
 	err.Stack = string(stackBuf[:bytesRead])
 	
@@ -0,0 +1,110 @@
+package mat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestErrorsIs(t *testing.T) {
+	a, b := New(3, 2), New(2, 2)
+
+	tests := []struct {
+		err      error
+		sentinel error
+	}{
+		{Mult(a, b), ErrShape},
+		{Add(nil, b), ErrNil},
+		{Invert(New(2, 2)), ErrSingular},
+		{a.View(0, 0, 5, 5), ErrParameter},
+		{Exp(Add(nil, b)).MatrixError(), ErrNil},
+	}
+
+	sentinels := []error{ErrNil, ErrShape, ErrSingular, ErrParameter,
+		ErrDefinite, ErrIteration}
+	for i, test := range tests {
+		for _, s := range sentinels {
+			if is := errors.Is(test.err, s); is != (s == test.sentinel) {
+				t.Errorf("%d) errors.Is(%v, %v) = %v", i, test.err, s, is)
+			}
+		}
+	}
+
+	var merr *MatrixError
+	if !errors.As(Mult(a, b), &merr) || merr.OperationName != "Mult" {
+		t.Errorf("errors.As did not extract the MatrixError of an error Matrix")
+	}
+	if errors.Is(New(2, 2), ErrShape) {
+		t.Errorf("Valid Matrix matched ErrShape")
+	}
+	if errors.Is(Mult(a, b).MatrixError(), Mult(a, b).MatrixError()) {
+		t.Errorf("Distinct non-sentinel errors matched")
+	}
+	if ErrShape.Error() != "Shape Error" {
+		t.Errorf("ErrShape.Error() = %q", ErrShape.Error())
+	}
+}
+
+func TestErrorStack(t *testing.T) {
+	err := Mult(New(3, 2), New(2, 2)).MatrixError()
+	if !strings.Contains(err.Stack, "TestErrorStack") {
+		t.Errorf("Stack does not contain the calling function:\n%s", err.Stack)
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	if p := ErrorPolicyFrom(context.Background()); p != ReturnErrors {
+		t.Errorf("Default ErrorPolicy is %d", p)
+	}
+	ctx := WithErrorPolicy(context.Background(), PanicOnError)
+	if p := ErrorPolicyFrom(ctx); p != PanicOnError {
+		t.Errorf("ErrorPolicyFrom gave %d, expected PanicOnError", p)
+	}
+
+	m := New(2, 2)
+	if ReturnErrors.Check(Mult(New(3, 2), m)) == nil {
+		t.Errorf("ReturnErrors.Check returned nil")
+	}
+	if PanicOnError.Check(m) != m {
+		t.Errorf("PanicOnError.Check did not return its argument")
+	}
+
+	// Goroutines with different policies do not interfere with one another.
+	var wg sync.WaitGroup
+	panics := make([]bool, 16)
+	for i := range panics {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				r := recover()
+				err, ok := r.(error)
+				panics[i] = ok && errors.Is(err, ErrShape)
+			}()
+			policy := ReturnErrors
+			if i%2 == 1 {
+				policy = PanicOnError
+			}
+			ctx := WithErrorPolicy(context.Background(), policy)
+			ErrorPolicyFrom(ctx).Check(Mult(New(3, 2), New(2, 2)))
+		}(i)
+	}
+	wg.Wait()
+	for i, p := range panics {
+		if p != (i%2 == 1) {
+			t.Errorf("Goroutine %d panicked = %v", i, p)
+		}
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("PanicOnError.CheckErr did not panic")
+			}
+		}()
+		_, err := New(2, 3).Determinant()
+		PanicOnError.CheckErr(err)
+	}()
+}
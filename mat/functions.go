@@ -1,63 +1,132 @@
 package mat
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/phil-mansfield/num"
 )
 
+const (
+	// maxSqrtIters is the maximum number of Denman-Beavers iterations used by
+	// Sqrt.
+	maxSqrtIters = 100
+	// maxLogSqrts is the maximum number of square roots taken by Log before
+	// the Matrix is close enough to the identity for a Pade approximant.
+	maxLogSqrts = 64
+	// logPadeRadius is the 1-norm of A - I below which the Pade approximant
+	// used by Log is accurate to machine precision.
+	logPadeRadius = 0.25
+	// logPadeOrder is the order of the Pade approximant used by Log.
+	logPadeOrder = 8
+	// trigRadius is the 1-norm below which the Taylor series used by Sin,
+	// Cos, Sinh, and Cosh are evaluated directly.
+	trigRadius = 0.5
+	// maxTaylorTerms is the maximum number of Taylor series terms used by
+	// Sin, Cos, Sinh, and Cosh.
+	maxTaylorTerms = 30
+	// funcMaxCondition is the largest condition number of the eigenvector
+	// Matrix of a non-symmetric Matrix which Func accepts. Above this, the
+	// Matrix is treated as defective, since the result would have fewer than
+	// about half the digits of machine precision.
+	funcMaxCondition = 1e8
+)
+
+var (
+	// Degrees of the Pade approximants used by Exp and the largest 1-norms
+	// for which they are accurate to double precision. Taken from Higham,
+	// 2005, "The Scaling and Squaring Method for the Matrix Exponential
+	// Revisited".
+	expPadeDegrees = []int{3, 5, 7, 9, 13}
+	expPadeThetas = []float64{
+		1.495585217958292e-2, 2.539398330063230e-1, 9.504178996162932e-1,
+		2.097847961257068e0, 5.371920351148152e0,
+	}
+	expPadeCoeffs = map[int][]float64{
+		3: {120, 60, 12, 1},
+		5: {30240, 15120, 3360, 420, 30, 1},
+		7: {17297280, 8648640, 1995840, 277200, 25200, 1512, 56, 1},
+		9: {17643225600, 8821612800, 2075673600, 302702400, 30270240,
+			2162160, 110880, 3960, 90, 1},
+		13: {64764752532480000, 32382376266240000, 7771770303897600,
+			1187353796428800, 129060195264000, 10559470521600,
+			670442572800, 33522128640, 1323241920, 40840800, 960960,
+			16380, 182, 1},
+	}
+)
+
+// matrixFunc computes a Matrix function of a square Matrix.
+type matrixFunc func(operationName string, m *Matrix) (*Matrix, *MatrixError)
+
 // Exp computes the matrix exponential of m and returns the result.
 //
 // If m is nil or not a square Matrix, an error Matrix is returned.
 func Exp(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Exp", expm, m)
 }
 
 // Sin computes the matrix radian sine of m and returns the result.
 //
 // If m is nil or not a square Matrix, an error Matrix is returned.
 func Sin(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Sin", sinm, m)
 }
 
 // Sinh computes the matrix hyperbolic sine of m and returns the result.
 //
 // If m is nil or not a square Matrix, an error Matrix is returned.
 func Sinh(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Sinh", sinhm, m)
 }
 
 // Cos computes the matrix radian cosine of m and returns the result.
 //
 // If m is nil or not a square Matrix, an error Matrix is returned.
 func Cos(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Cos", cosm, m)
 }
 
 // Cosh computes the matrix hyperbolic cosine of m and returns the result.
 //
 // If m is nil or not a square Matrix, an error Matrix is returned.
 func Cosh(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Cosh", coshm, m)
 }
 
-// Log computes the matrix natural logarithm of m and returns the result.
+// Log computes the matrix natural logarithm of m and returns the result. The
+// principal logarithm is returned, whose eigenvalues have imaginary parts in
+// (-pi, pi).
 //
-// If m is nil or not a square Matrix, an error Matrix is returned.
+// If m is nil or not a square Matrix, or if m has eigenvalues on the closed
+// negative real axis (in which case it has no real principal logarithm), an
+// error Matrix is returned.
 func Log(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Log", logm, m)
 }
 
-// Sqrt computes the matrix square root of m and returns the result.
+// Sqrt computes the matrix square root of m and returns the result. The
+// principal square root is returned, whose eigenvalues have positive real
+// parts.
 //
-// If m is nil or not a square Matrix, an error Matrix is returned.
+// If m is nil or not a square Matrix, or if m has eigenvalues on the closed
+// negative real axis (in which case it has no real principal square root), an
+// error Matrix is returned.
 func Sqrt(m *Matrix) *Matrix {
-	return nil
+	return applyNew("Sqrt", sqrtm, m)
 }
 
 // Func computes the matrix function f(m) and returns the result.
 //
-// If m is nil or not a square Matrix, an error Matrix is returned.
+// Func diagonalizes m and applies f to its eigenvalues, so m must be
+// diagonalizable with real eigenvalues. Symmetric matrices always satisfy
+// this. For other matrices, the accuracy of the result depends on the
+// conditioning of the eigenvectors of m, and matrices whose eigenvectors have
+// a condition number above 1e8 are treated as not diagonalizable.
+//
+// If m is nil or not a square Matrix, or if m has complex eigenvalues or is
+// not diagonalizable, an error Matrix is returned.
 func Func(f num.Func1D, m *Matrix) *Matrix {
-	return nil
+	return applyNew("Func", funcOf(f), m)
 }
 
 // Exp computes the matrix exponential of m and stores the result in the
@@ -66,7 +135,7 @@ func Func(f num.Func1D, m *Matrix) *Matrix {
 // If m is nil or not a square Matrix or if target is not the same shape as m,
 // target is set to an error Matrix.
 func (target *Matrix) Exp(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Exp", expm, m)
 }
 
 // Sin computes the matrix radian sine of m and stores the result in the
@@ -75,7 +144,7 @@ func (target *Matrix) Exp(m *Matrix) *Matrix {
 // If m is nil or not a square Matrix or if target is not the same shape as m,
 // target is set to an error Matrix.
 func (target *Matrix) Sin(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Sin", sinm, m)
 }
 
 // Sinh computes the matrix hyperbolic sine of m and stores the result in the
@@ -84,7 +153,7 @@ func (target *Matrix) Sin(m *Matrix) *Matrix {
 // If m is nil or not a square Matrix or if target is not the same shape as m,
 // target is set to an error Matrix.
 func (target *Matrix) Sinh(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Sinh", sinhm, m)
 }
 
 // Cos computes the matrix radian cosine of m and stores the result in the
@@ -93,7 +162,7 @@ func (target *Matrix) Sinh(m *Matrix) *Matrix {
 // If m is nil or not a square Matrix or if target is not the same shape as m,
 // target is set to an error Matrix.
 func (target *Matrix) Cos(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Cos", cosm, m)
 }
 
 // Cosh computes the matrix hyperbolic cosine of m and stores the result in the
@@ -102,32 +171,450 @@ func (target *Matrix) Cos(m *Matrix) *Matrix {
 // If m is nil or not a square Matrix or if target is not the same shape as m,
 // target is set to an error Matrix.
 func (target *Matrix) Cosh(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Cosh", coshm, m)
 }
 
 // Log computes the matrix natural logarithm of m and stores the result in the
 // target Matrix. The target matrix is also returned.
 //
-// If m is nil or not a square Matrix or if target is not the same shape as m,
-// target is set to an error Matrix.
+// If m is nil or not a square Matrix, if m has eigenvalues on the closed
+// negative real axis, or if target is not the same shape as m, target is set
+// to an error Matrix.
 func (target *Matrix) Log(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Log", logm, m)
 }
 
 // Sqrt computes the matrix square root of m and stores the result in the
 // target Matrix. The target matrix is also returned.
 //
-// If m is nil or not a square Matrix or if target is not the same shape as m,
-// target is set to an error Matrix.
+// If m is nil or not a square Matrix, if m has eigenvalues on the closed
+// negative real axis, or if target is not the same shape as m, target is set
+// to an error Matrix.
 func (target *Matrix) Sqrt(m *Matrix) *Matrix {
-	return nil
+	return target.apply("Sqrt", sqrtm, m)
 }
 
 // Func computes the matrix function f(m) and stores the result in the target
 // Matrix. The target matrix is also returned.
 //
-// If m is nil or not a square Matrix or if target is not the same shape as m,
-// target is set to an error Matrix.
+// If m is nil or not a square Matrix, if m has complex eigenvalues or is not
+// diagonalizable, or if target is not the same shape as m, target is set to
+// an error Matrix.
 func (target *Matrix) Func(f num.Func1D, m *Matrix) *Matrix {
-	return nil
+	return target.apply("Func", funcOf(f), m)
+}
+
+// applyNew evaluates fn on m and returns the result in a new Matrix.
+func applyNew(operationName string, fn matrixFunc, m *Matrix) *Matrix {
+	if err := inputError(operationName, m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).apply(operationName, fn, m)
+}
+
+// apply evaluates fn on m and stores the result in target. The result is
+// computed in temporary storage, so target may be m.
+func (target *Matrix) apply(operationName string, fn matrixFunc, m *Matrix) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.apply(operationName, fn, m) })
+	}
+	m = target.operand(m)
+
+	if err := squareError(operationName, m); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, operationName, "Target Matrix is nil.")
+	} else if !Compatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match input shape (%d, %d).",
+			target.width, target.height, m.width, m.height)
+		return target.setError(newError(ShapeError, operationName, desc))
+	}
+
+	res, err := fn(operationName, m)
+	if err != nil {
+		return target.setError(err)
+	}
+	copy(target.values, res.values)
+	target.err = nil
+	return target
+}
+
+// addIdentity adds c times the identity to the square Matrix m in place and
+// returns it.
+func addIdentity(m *Matrix, c float64) *Matrix {
+	for i := 0; i < m.width; i++ {
+		m.values[i * m.width + i] += c
+	}
+	return m
+}
+
+// solveSquare solves A X = B for X.
+func solveSquare(operationName string, a, b *Matrix) (*Matrix, *MatrixError) {
+	f, err := newLU(operationName, a)
+	if err != nil {
+		return nil, err
+	}
+	x := New(b.width, b.height).solveLU(operationName, f, b)
+	if x.IsError() {
+		return nil, x.err
+	}
+	return x, nil
+}
+
+// expm computes the matrix exponential with the scaling and squaring
+// algorithm of Higham (2005): A is scaled by a power of two until a Pade
+// approximant of exp is accurate, and the result is repeatedly squared.
+func expm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	a := Copy(m)
+	norm := norm1(a)
+
+	// Use the cheapest approximant which is accurate without scaling.
+	for i, degree := range expPadeDegrees[:len(expPadeDegrees) - 1] {
+		if norm <= expPadeThetas[i] {
+			u, v := expPade(a, degree)
+			return expPadeSolve(operationName, u, v, 0)
+		}
+	}
+
+	s := 0
+	if theta := expPadeThetas[len(expPadeThetas) - 1]; norm > theta {
+		s = int(math.Ceil(math.Log2(norm / theta)))
+		a.Scale(a, math.Ldexp(1, -s))
+	}
+	u, v := expPade(a, 13)
+	return expPadeSolve(operationName, u, v, s)
+}
+
+// expPade returns the odd and even parts, U and V, of the numerator of the
+// degree-d Pade approximant of exp(A). The approximant is (V - U)^-1 (V + U).
+func expPade(a *Matrix, degree int) (u, v *Matrix) {
+	n := a.width
+	b := expPadeCoeffs[degree]
+	a2 := Mult(a, a)
+
+	if degree < 13 {
+		// U = A sum b[2k+1] A^2k, V = sum b[2k] A^2k
+		uSum := addIdentity(New(n, n), b[1])
+		v = addIdentity(New(n, n), b[0])
+		pow := Copy(a2)
+		for k := 1; 2 * k <= degree; k++ {
+			v.Add(v, Scale(pow, b[2 * k]))
+			uSum.Add(uSum, Scale(pow, b[2 * k + 1]))
+			if 2 * k + 2 <= degree {
+				pow.Mult(pow, a2)
+			}
+		}
+		return Mult(a, uSum), v
+	}
+
+	a4 := Mult(a2, a2)
+	a6 := Mult(a4, a2)
+	combine := func(c6, c4, c2, c0 float64) *Matrix {
+		out := Scale(a6, c6)
+		out.Add(out, Scale(a4, c4))
+		out.Add(out, Scale(a2, c2))
+		return addIdentity(out, c0)
+	}
+
+	inner := Scale(a6, b[13])
+	inner.Add(inner, Scale(a4, b[11]))
+	inner.Add(inner, Scale(a2, b[9]))
+	u = Add(Mult(a6, inner), combine(b[7], b[5], b[3], b[1]))
+	u = Mult(a, u)
+
+	inner = Scale(a6, b[12])
+	inner.Add(inner, Scale(a4, b[10]))
+	inner.Add(inner, Scale(a2, b[8]))
+	v = Add(Mult(a6, inner), combine(b[6], b[4], b[2], b[0]))
+	return u, v
+}
+
+// expPadeSolve evaluates the Pade approximant (V - U)^-1 (V + U) and squares
+// it s times.
+func expPadeSolve(
+	operationName string, u, v *Matrix, s int,
+) (*Matrix, *MatrixError) {
+	res, err := solveSquare(operationName, Sub(v, u), Add(v, u))
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < s; i++ {
+		res.Mult(res, res)
+	}
+	return res, nil
+}
+
+// sqrtm computes the principal square root with the scaled product form of
+// the Denman-Beavers iteration:
+//
+//     M_{k+1} = (I + (mu^2 M_k + mu^-2 M_k^-1) / 2) / 2
+//     Y_{k+1} = mu Y_k (I + mu^-2 M_k^-1) / 2
+//
+// with M_0 = Y_0 = A. Y_k converges quadratically to sqrt(A) and M_k to I.
+// The determinant scaling factor mu speeds up the initial iterations.
+func sqrtm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	n := m.width
+	mk, y := Copy(m), Copy(m)
+	scaling := true
+	tol := math.Sqrt(machineEpsilon)
+
+	for iter := 0; iter < maxSqrtIters; iter++ {
+		f, err := newLU(operationName, mk)
+		if err != nil {
+			return nil, err
+		} else if f.singular && iter == 0 {
+			return nil, newError(SingularError, operationName,
+				"Matrix is singular and has no principal square root.")
+		} else if f.singular {
+			// Iterates only become singular when A has eigenvalues on
+			// the negative real axis.
+			break
+		}
+		inv := f.Inverse()
+
+		mu := 1.0
+		if scaling {
+			logAbs, _ := f.LogDeterminant()
+			mu = math.Exp(-logAbs / float64(2 * n))
+		}
+
+		// Y_{k+1} = mu Y_k (I + mu^-2 M_k^-1) / 2
+		next := addIdentity(Scale(inv, 1 / (mu * mu)), 1)
+		next = Scale(Mult(y, next), mu / 2)
+
+		// M_{k+1} = (I + (mu^2 M_k + mu^-2 M_k^-1) / 2) / 2
+		mk.Scale(mk, mu * mu)
+		mk.Add(mk, Scale(inv, 1 / (mu * mu)))
+		addIdentity(mk.Scale(mk, 0.5), 1).Scale(mk, 0.5)
+
+		change := norm1(Sub(next, y))
+		y = next
+		if !isFinite(y) {
+			break
+		}
+
+		dev := norm1(addIdentity(Copy(mk), -1))
+		if dev < 1e-2 {
+			scaling = false
+		}
+		if change <= tol * norm1(y) && dev <= tol {
+			return y, nil
+		}
+	}
+
+	return nil, newError(IterationError, operationName,
+		"Denman-Beavers iteration failed to converge. The Matrix may have eigenvalues on the negative real axis.")
+}
+
+// isFinite returns true if every element of m is finite.
+func isFinite(m *Matrix) bool {
+	for _, x := range m.values {
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return false
+		}
+	}
+	return true
+}
+
+// logm computes the principal logarithm with the inverse scaling and squaring
+// method: square roots of A are taken until A^(1/2^k) is close to I, then
+// log(A^(1/2^k)) is computed with a Pade approximant in partial fraction form
+// and multiplied by 2^k.
+func logm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	a := Copy(m)
+	k := 0
+	for norm1(addIdentity(Copy(a), -1)) > logPadeRadius {
+		if k == maxLogSqrts {
+			return nil, newError(IterationError, operationName,
+				"Matrix did not approach the identity after repeated square roots.")
+		}
+		var err *MatrixError
+		a, err = sqrtm(operationName, a)
+		if err != nil {
+			return nil, err
+		}
+		k++
+	}
+
+	// log(I + X) = sum_j w_j X (I + x_j X)^-1, where x_j and w_j are the
+	// Gauss-Legendre nodes and weights on [0, 1].
+	x := addIdentity(a, -1)
+	res := New(x.width, x.height)
+	nodes, weights := gaussLegendre(logPadeOrder)
+	for j, node := range nodes {
+		denom := addIdentity(Scale(x, node), 1)
+		term, err := solveSquare(operationName, denom, x)
+		if err != nil {
+			return nil, err
+		}
+		res.Add(res, term.Scale(term, weights[j]))
+	}
+
+	return res.Scale(res, math.Ldexp(1, k)), nil
+}
+
+// gaussLegendre returns the n-point Gauss-Legendre nodes and weights on the
+// interval [0, 1].
+func gaussLegendre(n int) (nodes, weights []float64) {
+	nodes, weights = make([]float64, n), make([]float64, n)
+	for i := 0; i < n; i++ {
+		// Newton's method on P_n, starting from an asymptotic estimate of the
+		// i-th root.
+		x := math.Cos(math.Pi * (float64(i) + 0.75) / (float64(n) + 0.5))
+		var dp float64
+		for iter := 0; iter < 100; iter++ {
+			p0, p1 := 1.0, x
+			for k := 2; k <= n; k++ {
+				p0, p1 = p1, ((2 * float64(k) - 1) * x * p1 - (float64(k) - 1) * p0) / float64(k)
+			}
+			dp = float64(n) * (x * p1 - p0) / (x * x - 1)
+			dx := p1 / dp
+			x -= dx
+			if math.Abs(dx) <= machineEpsilon {
+				break
+			}
+		}
+		nodes[i] = (1 - x) / 2
+		weights[i] = 1 / ((1 - x * x) * dp * dp)
+	}
+	return nodes, weights
+}
+
+func sinm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	s, _ := trigm(m, false)
+	return s, nil
+}
+
+func cosm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	_, c := trigm(m, false)
+	return c, nil
+}
+
+func sinhm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	s, _ := trigm(m, true)
+	return s, nil
+}
+
+func coshm(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+	_, c := trigm(m, true)
+	return c, nil
+}
+
+// trigm computes the sine and cosine of m, or the hyperbolic sine and cosine
+// if hyperbolic is true. m is scaled by a power of two until its Taylor
+// series converge quickly, and then the double angle formulas
+//
+//     sin(2X) = 2 sin(X) cos(X),   cos(2X) = 2 cos(X)^2 - I
+//
+// (which also hold for the hyperbolic functions) are applied to undo the
+// scaling.
+func trigm(m *Matrix, hyperbolic bool) (sin, cos *Matrix) {
+	n := m.width
+	x := Copy(m)
+	s := 0
+	if norm := norm1(x); norm > trigRadius {
+		s = int(math.Ceil(math.Log2(norm / trigRadius)))
+		x.Scale(x, math.Ldexp(1, -s))
+	}
+
+	sign := -1.0
+	if hyperbolic {
+		sign = 1
+	}
+
+	// cTerm = sign^k X^2k / (2k)!, sTerm = sign^k X^(2k+1) / (2k+1)!
+	x2 := Mult(x, x)
+	cos, sin = Identity(n), Copy(x)
+	cTerm, sTerm := Identity(n), Copy(x)
+	for k := 1; k <= maxTaylorTerms; k++ {
+		fk := float64(2 * k)
+		cTerm = Mult(cTerm, x2)
+		cTerm.Scale(cTerm, sign / ((fk - 1) * fk))
+		sTerm = Mult(sTerm, x2)
+		sTerm.Scale(sTerm, sign / (fk * (fk + 1)))
+		cos.Add(cos, cTerm)
+		sin.Add(sin, sTerm)
+
+		if norm1(cTerm) <= machineEpsilon * norm1(cos) &&
+			norm1(sTerm) <= machineEpsilon * norm1(sin) {
+			break
+		}
+	}
+
+	for i := 0; i < s; i++ {
+		sin = Mult(sin, cos)
+		sin.Scale(sin, 2)
+		cos = Mult(cos, cos)
+		addIdentity(cos.Scale(cos, 2), -1)
+	}
+	return sin, cos
+}
+
+// funcOf returns a matrixFunc which applies f to the eigenvalues of a Matrix.
+func funcOf(f num.Func1D) matrixFunc {
+	return func(operationName string, m *Matrix) (*Matrix, *MatrixError) {
+		return funcm(operationName, f, m)
+	}
+}
+
+// funcm computes f(A) = V f(D) V^-1, where A = V D V^-1 is the
+// eigendecomposition of A. If A is symmetric, V is orthogonal and
+// V^-1 = V^T.
+func funcm(operationName string, f num.Func1D, m *Matrix) (*Matrix, *MatrixError) {
+	n := m.width
+
+	if isSymmetric(m) {
+		eig, err := newSymEigen(operationName, m, Tridiagonal)
+		if err != nil {
+			return nil, err
+		}
+		v := eig.Vectors()
+		fv := Copy(v)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				fv.values[y * n + x] *= f(eig.values[x])
+			}
+		}
+		return Mult(fv, Transpose(v)), nil
+	}
+
+	eig, err := newEigen(operationName, m)
+	if err != nil {
+		return nil, err
+	}
+	for _, lambda := range eig.values {
+		if imag(lambda) != 0 {
+			return nil, newError(ParameterError, operationName,
+				"Matrix has complex eigenvalues.")
+		}
+	}
+
+	// F = V f(D) V^-1, so V^T F^T = (V f(D))^T = f(D) V^T.
+	vt, fdvt := New(n, n), New(n, n)
+	for j, vec := range eig.Vectors() {
+		fj := f(real(eig.values[j]))
+		for i, x := range vec {
+			vt.values[j * n + i] = real(x)
+			fdvt.values[j * n + i] = fj * real(x)
+		}
+	}
+
+	lu, err := newLU(operationName, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Defective matrices have (nearly) parallel eigenvectors, so V is
+	// singular or badly conditioned.
+	cond := math.Inf(+1)
+	if !lu.singular {
+		vtInv := New(n, n).solveLU(operationName, lu, Identity(n))
+		cond = norm1(vt) * norm1(vtInv)
+	}
+	if !(cond <= funcMaxCondition) {
+		return nil, newError(SingularError, operationName,
+			"Matrix is not diagonalizable.")
+	}
+	ft := New(n, n).solveLU(operationName, lu, fdvt)
+	return Transpose(ft), nil
 }
@@ -0,0 +1,203 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+// relDiff returns the largest absolute difference between m1 and m2 relative
+// to the 1-norm of m2.
+func relDiff(m1, m2 *Matrix) float64 {
+	return maxDiff(m1, m2) / math.Max(1, norm1(m2))
+}
+
+func TestExp(t *testing.T) {
+	th := 0.7
+	tests := []struct {
+		m, exp *Matrix
+	}{
+		{FromSlice(1, 1, []float64{2}), FromSlice(1, 1, []float64{math.Exp(2)})},
+		{New(2, 2), Identity(2)},
+		{FromSlice(2, 2, []float64{1, 0, 0, -30}),
+			FromSlice(2, 2, []float64{math.E, 0, 0, math.Exp(-30)})},
+		{FromSlice(2, 2, []float64{0, 1, 0, 0}),
+			FromSlice(2, 2, []float64{1, 1, 0, 1})},
+		{FromSlice(2, 2, []float64{0, -th, th, 0}),
+			FromSlice(2, 2, []float64{math.Cos(th), -math.Sin(th),
+				math.Sin(th), math.Cos(th)})},
+		// Large enough to need scaling and squaring.
+		{FromSlice(2, 2, []float64{0, -20 * th, 20 * th, 0}),
+			FromSlice(2, 2, []float64{math.Cos(20 * th), -math.Sin(20 * th),
+				math.Sin(20 * th), math.Cos(20 * th)})},
+	}
+
+	for i, test := range tests {
+		if diff := relDiff(Exp(test.m), test.exp); diff > 1e-13 {
+			t.Errorf("%d) Exp(%v) = %v, wanted %v", i, test.m.Slice(),
+				Exp(test.m).Slice(), test.exp.Slice())
+		}
+	}
+
+	// exp(A) exp(-A) = I for matrices of every size class.
+	for _, scale := range []float64{0.01, 0.2, 0.9, 2, 5, 10} {
+		a := Scale(randomMatrix(6, 6), scale)
+		ea, eaInv := Exp(a), Exp(Scale(a, -1))
+		tol := 1e-14 * norm1(ea) * norm1(eaInv)
+		if diff := maxDiff(Mult(ea, eaInv), Identity(6)); diff > tol {
+			t.Errorf("exp(A) exp(-A) differs from I by %g for scale %g",
+				diff, scale)
+		}
+	}
+
+	// Exponentiating in place.
+	a := FromSlice(2, 2, []float64{0, 1, 0, 0})
+	if res := a.Exp(a); res != a || !AlmostEqual(a, tests[3].exp) {
+		t.Errorf("a.Exp(a) = %v, wanted %v", a.Slice(), tests[3].exp.Slice())
+	}
+}
+
+func TestSqrtLog(t *testing.T) {
+	tests := []*Matrix{
+		Identity(3),
+		FromSlice(2, 2, []float64{4, 0, 0, 9}),
+		randomSPD(6),
+		// Non-symmetric with positive eigenvalues.
+		FromSlice(3, 3, []float64{4, 1, 0, 0, 9, 2, 1, 0, 16}),
+		// Complex eigenvalues with positive real parts.
+		FromSlice(2, 2, []float64{1, -2, 2, 1}),
+		// Large eigenvalue spread.
+		FromSlice(2, 2, []float64{1e-4, 1, 0, 1e4}),
+	}
+
+	for i, m := range tests {
+		s := Sqrt(m)
+		if s.IsError() {
+			t.Errorf("%d) Sqrt returned error: %s", i, s.Error())
+			continue
+		} else if diff := relDiff(Mult(s, s), m); diff > 1e-12 {
+			t.Errorf("%d) Sqrt(A)^2 differs from A by %g", i, diff)
+		}
+
+		l := Log(m)
+		if l.IsError() {
+			t.Errorf("%d) Log returned error: %s", i, l.Error())
+			continue
+		} else if diff := relDiff(Exp(l), m); diff > 1e-11 {
+			t.Errorf("%d) exp(Log(A)) differs from A by %g", i, diff)
+		}
+	}
+
+	if diff := maxDiff(Sqrt(FromSlice(2, 2, []float64{4, 0, 0, 9})),
+		FromSlice(2, 2, []float64{2, 0, 0, 3})); diff > 1e-14 {
+		t.Errorf("Sqrt(diag(4, 9)) differs from diag(2, 3) by %g", diff)
+	}
+	if diff := maxDiff(Log(Identity(3)), New(3, 3)); diff != 0 {
+		t.Errorf("Log(I) differs from zero by %g", diff)
+	}
+	a := Scale(randomMatrix(4, 4), 0.3)
+	if diff := maxDiff(Log(Exp(a)), a); diff > 1e-12 {
+		t.Errorf("Log(Exp(A)) differs from A by %g", diff)
+	}
+
+	errTests := []struct {
+		name string
+		m    *Matrix
+		code int
+	}{
+		{"Sqrt(singular)", Sqrt(New(2, 2)), SingularError},
+		{"Sqrt(negative)", Sqrt(FromSlice(2, 2, []float64{-1, 0, 0, 1})),
+			IterationError},
+		{"Log(negative)", Log(Scale(Identity(2), -1)), IterationError},
+		{"Log(non-square)", Log(New(2, 3)), ShapeError},
+		{"Sqrt(nil)", Sqrt(nil), NilError},
+		{"target.Sqrt(shape)", New(3, 3).Sqrt(Identity(2)), ShapeError},
+	}
+	for _, test := range errTests {
+		if errorCode(test.m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(test.m), test.code)
+		}
+	}
+}
+
+func TestTrig(t *testing.T) {
+	d := FromSlice(2, 2, []float64{0.3, 0, 0, -4})
+	diag := func(f func(float64) float64) *Matrix {
+		return FromSlice(2, 2, []float64{f(0.3), 0, 0, f(-4)})
+	}
+	tests := []struct {
+		name      string
+		got, want *Matrix
+	}{
+		{"Sin", Sin(d), diag(math.Sin)},
+		{"Cos", Cos(d), diag(math.Cos)},
+		{"Sinh", Sinh(d), diag(math.Sinh)},
+		{"Cosh", Cosh(d), diag(math.Cosh)},
+	}
+	for _, test := range tests {
+		if diff := relDiff(test.got, test.want); diff > 1e-13 {
+			t.Errorf("%s(diag) = %v, wanted %v",
+				test.name, test.got.Slice(), test.want.Slice())
+		}
+	}
+
+	for _, scale := range []float64{0.1, 1, 6} {
+		a := Scale(randomMatrix(5, 5), scale)
+		s, c := Sin(a), Cos(a)
+		id := Add(Mult(s, s), Mult(c, c))
+		if diff := maxDiff(id, Identity(5)); diff > 1e-9 {
+			t.Errorf("sin^2 + cos^2 differs from I by %g (scale %g)",
+				diff, scale)
+		}
+
+		sh, ch := Sinh(a), Cosh(a)
+		ep, em := Exp(a), Exp(Scale(a, -1))
+		if diff := relDiff(sh, Scale(Sub(ep, em), 0.5)); diff > 1e-11 {
+			t.Errorf("Sinh differs from (e^A - e^-A) / 2 by %g (scale %g)",
+				diff, scale)
+		}
+		if diff := relDiff(ch, Scale(Add(ep, em), 0.5)); diff > 1e-11 {
+			t.Errorf("Cosh differs from (e^A + e^-A) / 2 by %g (scale %g)",
+				diff, scale)
+		}
+	}
+}
+
+func TestFunc(t *testing.T) {
+	sym := randomSymmetric(5)
+	if diff := relDiff(Func(math.Exp, sym), Exp(sym)); diff > 1e-12 {
+		t.Errorf("Func(exp, symmetric) differs from Exp by %g", diff)
+	}
+
+	// Upper triangular with distinct real eigenvalues.
+	tri := FromSlice(3, 3, []float64{1, 2, 3, 0, 2, 1, 0, 0, 4})
+	if diff := relDiff(Func(math.Exp, tri), Exp(tri)); diff > 1e-12 {
+		t.Errorf("Func(exp, triangular) differs from Exp by %g", diff)
+	}
+	square := func(x float64) float64 { return x * x }
+	if diff := relDiff(Func(square, tri), Mult(tri, tri)); diff > 1e-12 {
+		t.Errorf("Func(x^2, triangular) differs from A^2 by %g", diff)
+	}
+
+	// In place.
+	c := Copy(tri)
+	if diff := relDiff(c.Func(square, c), Mult(tri, tri)); diff > 1e-12 {
+		t.Errorf("c.Func(x^2, c) differs from A^2 by %g", diff)
+	}
+
+	// Jordan blocks are defective, so their eigenvectors are (numerically)
+	// parallel and the eigendecomposition can't be used.
+	for i, jordan := range []*Matrix{
+		FromSlice(2, 2, []float64{1, 1, 0, 1}),
+		FromSlice(3, 3, []float64{2, 1, 0, 0, 2, 1, 0, 0, 2}),
+	} {
+		if errorCode(Func(math.Exp, jordan)) != SingularError {
+			t.Errorf("%d) Func of Jordan block did not give SingularError.", i)
+		}
+	}
+
+	rot := FromSlice(2, 2, []float64{0, -1, 1, 0})
+	if errorCode(Func(math.Exp, rot)) != ParameterError {
+		t.Errorf("Func with complex eigenvalues did not give ParameterError.")
+	}
+}
@@ -0,0 +1,219 @@
+package krylov
+
+import (
+	"math"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// CG solves A x = b with the preconditioned conjugate gradient method. A and
+// the preconditioner must both be symmetric positive definite. b is not
+// modified.
+//
+// The solution and a record of the iteration are returned. If the iteration
+// does not converge, a non-nil error is returned along with the last
+// iterate. If a direction of non-positive curvature is found, showing that A
+// is not positive definite, a DefiniteError is returned.
+//
+// Supported options are:
+//
+//     Tolerance(tol)
+//     MaxIters(iters)
+//     Precondition(pc)
+//     Guess(x0)
+func CG(a Operator, b []float64, opts ...Option) (
+	x []float64, hist *History, err error,
+) {
+	p := &params{}
+	if err := p.load("CG", a, b, opts); err != nil {
+		return nil, nil, err
+	}
+
+	n := len(b)
+	hist = &History{}
+	bNorm := norm(b)
+	if bNorm == 0 {
+		hist.record(0)
+		hist.Converged = true
+		return make([]float64, n), hist, nil
+	}
+
+	x, r, err := p.start(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	z, q := make([]float64, n), make([]float64, n)
+	if err := p.precondition(r, z); err != nil {
+		return nil, nil, err
+	}
+	dir := append([]float64{}, z...)
+	rz := dot(r, z)
+
+	hist.record(norm(r) / bNorm)
+	hist.Converged = hist.Residuals[0] <= p.tol
+	for !hist.Converged && hist.Iters < p.iters {
+		if err := a.MulVecAt(dir, q); err != nil {
+			return nil, nil, err
+		}
+		curv := dot(dir, q)
+		if !(curv > 0) {
+			return x, hist, mat.NewError(mat.DefiniteError, "krylov.CG",
+				"Operator is not positive definite.")
+		}
+
+		alpha := rz / curv
+		axpy(alpha, dir, x)
+		axpy(-alpha, q, r)
+		hist.Iters++
+		hist.record(norm(r) / bNorm)
+		if hist.Residuals[hist.Iters] <= p.tol {
+			hist.Converged = true
+			break
+		}
+
+		if err := p.precondition(r, z); err != nil {
+			return nil, nil, err
+		}
+		rzNext := dot(r, z)
+		beta := rzNext / rz
+		rz = rzNext
+		for i := range dir {
+			dir[i] = z[i] + beta * dir[i]
+		}
+	}
+
+	return finish("CG", x, hist, limitDesc(hist))
+}
+
+// MINRES solves A x = b with the preconditioned minimum residual method of
+// Paige and Saunders. A must be symmetric, but may be indefinite or
+// singular; the preconditioner must be symmetric positive definite. b is not
+// modified.
+//
+// The solution and a record of the iteration are returned. If the iteration
+// does not converge, a non-nil error is returned along with the last
+// iterate. If the preconditioner is found not to be positive definite, a
+// DefiniteError is returned.
+//
+// Supported options are:
+//
+//     Tolerance(tol)
+//     MaxIters(iters)
+//     Precondition(pc)
+//     Guess(x0)
+func MINRES(a Operator, b []float64, opts ...Option) (
+	x []float64, hist *History, err error,
+) {
+	p := &params{}
+	if err := p.load("MINRES", a, b, opts); err != nil {
+		return nil, nil, err
+	}
+
+	n := len(b)
+	hist = &History{}
+
+	// Residuals are measured in the M^-1 norm, |r|_M = sqrt(r^T M^-1 r).
+	y := make([]float64, n)
+	if err := p.precondition(b, y); err != nil {
+		return nil, nil, err
+	}
+	bNorm2 := dot(b, y)
+	if bNorm2 < 0 {
+		return nil, nil, mat.NewError(mat.DefiniteError, "krylov.MINRES",
+			"Preconditioner is not positive definite.")
+	} else if bNorm2 == 0 {
+		hist.record(0)
+		hist.Converged = true
+		return make([]float64, n), hist, nil
+	}
+	bNorm := math.Sqrt(bNorm2)
+
+	x, r1, err := p.start(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.precondition(r1, y); err != nil {
+		return nil, nil, err
+	}
+	beta1 := dot(r1, y)
+	if beta1 < 0 {
+		return nil, nil, mat.NewError(mat.DefiniteError, "krylov.MINRES",
+			"Preconditioner is not positive definite.")
+	}
+	beta1 = math.Sqrt(beta1)
+
+	hist.record(beta1 / bNorm)
+	hist.Converged = hist.Residuals[0] <= p.tol
+	if hist.Converged {
+		return x, hist, nil
+	}
+
+	r2 := append([]float64{}, r1...)
+	v := make([]float64, n)
+	w, w1, w2 := make([]float64, n), make([]float64, n), make([]float64, n)
+
+	// Lanczos and QR state.
+	oldBeta, beta := 0.0, beta1
+	dBar, eps := 0.0, 0.0
+	phiBar := beta1
+	cs, sn := -1.0, 0.0
+
+	for hist.Iters < p.iters {
+		// Lanczos step: v_k = y / beta_k, y = A v_k - ...
+		for i := range v {
+			v[i] = y[i] / beta
+		}
+		if err := a.MulVecAt(v, y); err != nil {
+			return nil, nil, err
+		}
+		if hist.Iters > 0 {
+			axpy(-beta / oldBeta, r1, y)
+		}
+		alpha := dot(v, y)
+		axpy(-alpha / beta, r2, y)
+		r1, r2 = r2, r1
+		copy(r2, y)
+		if err := p.precondition(r2, y); err != nil {
+			return nil, nil, err
+		}
+		oldBeta = beta
+		beta = dot(r2, y)
+		if beta < 0 {
+			return x, hist, mat.NewError(mat.DefiniteError, "krylov.MINRES",
+				"Preconditioner is not positive definite.")
+		}
+		beta = math.Sqrt(beta)
+
+		// Apply the previous rotation and compute the next one.
+		oldEps := eps
+		delta := cs * dBar + sn * alpha
+		gBar := sn * dBar - cs * alpha
+		eps = sn * beta
+		dBar = -cs * beta
+		gamma := math.Max(math.Hypot(gBar, beta), machineEpsilon)
+		cs, sn = gBar / gamma, beta / gamma
+		phi := cs * phiBar
+		phiBar = sn * phiBar
+
+		// Update the solution.
+		w1, w2, w = w2, w, w1
+		for i := range w {
+			w[i] = (v[i] - oldEps * w1[i] - delta * w2[i]) / gamma
+		}
+		axpy(phi, w, x)
+
+		hist.Iters++
+		hist.record(phiBar / bNorm)
+		if hist.Residuals[hist.Iters] <= p.tol {
+			hist.Converged = true
+			break
+		} else if beta == 0 {
+			// The Krylov space is invariant under A, so x is the
+			// minimum residual solution and cannot be improved.
+			return x, hist, mat.NewError(mat.IterationError, "krylov.MINRES",
+				"Krylov space is exhausted, so the system is inconsistent.")
+		}
+	}
+
+	return finish("MINRES", x, hist, limitDesc(hist))
+}
@@ -0,0 +1,267 @@
+package krylov
+
+import (
+	"math"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// GMRES solves A x = b with the restarted generalized minimum residual
+// method, GMRES(m), where m is set by the Restart option. A may be any
+// non-singular square operator. The preconditioner is applied on the right,
+// so the recorded residuals are those of the original system. b is not
+// modified.
+//
+// The solution and a record of the iteration are returned. If the iteration
+// does not converge, a non-nil error is returned along with the last
+// iterate.
+//
+// Supported options are:
+//
+//     Tolerance(tol)
+//     MaxIters(iters)
+//     Restart(m)
+//     Precondition(pc)
+//     Guess(x0)
+func GMRES(a Operator, b []float64, opts ...Option) (
+	x []float64, hist *History, err error,
+) {
+	p := &params{}
+	if err := p.load("GMRES", a, b, opts); err != nil {
+		return nil, nil, err
+	}
+
+	n := len(b)
+	hist = &History{}
+	bNorm := norm(b)
+	if bNorm == 0 {
+		hist.record(0)
+		hist.Converged = true
+		return make([]float64, n), hist, nil
+	}
+
+	x, r, err := p.start(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	hist.record(norm(r) / bNorm)
+	hist.Converged = hist.Residuals[0] <= p.tol
+
+	m := p.restart
+	vs := make([][]float64, m + 1)
+	for i := range vs {
+		vs[i] = make([]float64, n)
+	}
+	// h holds the Hessenberg matrix column by column, reduced to upper
+	// triangular form by the Givens rotations (cs, sn). g is the rotated
+	// right-hand side, beta e_1.
+	h := make([][]float64, m)
+	for j := range h {
+		h[j] = make([]float64, m + 1)
+	}
+	cs, sn, g := make([]float64, m), make([]float64, m), make([]float64, m + 1)
+	z, u := make([]float64, n), make([]float64, n)
+
+	for !hist.Converged && hist.Iters < p.iters {
+		beta := norm(r)
+		for i := range r {
+			vs[0][i] = r[i] / beta
+		}
+		for i := range g {
+			g[i] = 0
+		}
+		g[0] = beta
+
+		k, singular := 0, false
+		for k < m && hist.Iters < p.iters {
+			j := k
+			if err := p.precondition(vs[j], z); err != nil {
+				return nil, nil, err
+			}
+			w := vs[j + 1]
+			if err := a.MulVecAt(z, w); err != nil {
+				return nil, nil, err
+			}
+
+			// Modified Gram-Schmidt.
+			for i := 0; i <= j; i++ {
+				h[j][i] = dot(w, vs[i])
+				axpy(-h[j][i], vs[i], w)
+			}
+			hNext := norm(w)
+			h[j][j + 1] = hNext
+			if hNext != 0 {
+				for i := range w {
+					w[i] /= hNext
+				}
+			}
+
+			// Apply the previous rotations, then eliminate h[j][j + 1].
+			for i := 0; i < j; i++ {
+				hi, hi1 := h[j][i], h[j][i + 1]
+				h[j][i] = cs[i] * hi + sn[i] * hi1
+				h[j][i + 1] = -sn[i] * hi + cs[i] * hi1
+			}
+			rho := math.Hypot(h[j][j], h[j][j + 1])
+			if rho == 0 {
+				// Keep the progress made by the first k steps.
+				singular = true
+				break
+			}
+			cs[j], sn[j] = h[j][j] / rho, h[j][j + 1] / rho
+			h[j][j], h[j][j + 1] = rho, 0
+			g[j + 1] = -sn[j] * g[j]
+			g[j] = cs[j] * g[j]
+
+			k++
+			hist.Iters++
+			hist.record(math.Abs(g[j + 1]) / bNorm)
+			if hist.Residuals[hist.Iters] <= p.tol {
+				hist.Converged = true
+				break
+			} else if hNext == 0 {
+				// Lucky breakdown: the Krylov space is invariant, so
+				// the solution is exact up to rounding.
+				hist.Converged = true
+				break
+			}
+		}
+
+		// Solve the triangular system H y = g and update x += M^-1 V y.
+		y := g[:k]
+		for i := k - 1; i >= 0; i-- {
+			for l := i + 1; l < k; l++ {
+				y[i] -= h[l][i] * y[l]
+			}
+			y[i] /= h[i][i]
+		}
+		for i := range u {
+			u[i] = 0
+		}
+		for i := 0; i < k; i++ {
+			axpy(y[i], vs[i], u)
+		}
+		if err := p.precondition(u, z); err != nil {
+			return nil, nil, err
+		}
+		axpy(1, z, x)
+		if singular {
+			return x, hist, mat.NewError(mat.SingularError, "krylov.GMRES",
+				"Operator is singular.")
+		}
+
+		// Recompute the true residual before restarting.
+		if err := a.MulVecAt(x, r); err != nil {
+			return nil, nil, err
+		}
+		for i := range r {
+			r[i] = b[i] - r[i]
+		}
+	}
+
+	return finish("GMRES", x, hist, limitDesc(hist))
+}
+
+// BiCGSTAB solves A x = b with the stabilized biconjugate gradient method of
+// van der Vorst. A may be any non-singular square operator. The
+// preconditioner is applied on the right, so the recorded residuals are those
+// of the original system. b is not modified.
+//
+// The solution and a record of the iteration are returned. If the iteration
+// does not converge or breaks down, a non-nil error is returned along with
+// the last iterate.
+//
+// Supported options are:
+//
+//     Tolerance(tol)
+//     MaxIters(iters)
+//     Precondition(pc)
+//     Guess(x0)
+func BiCGSTAB(a Operator, b []float64, opts ...Option) (
+	x []float64, hist *History, err error,
+) {
+	p := &params{}
+	if err := p.load("BiCGSTAB", a, b, opts); err != nil {
+		return nil, nil, err
+	}
+
+	n := len(b)
+	hist = &History{}
+	bNorm := norm(b)
+	if bNorm == 0 {
+		hist.record(0)
+		hist.Converged = true
+		return make([]float64, n), hist, nil
+	}
+
+	x, r, err := p.start(a, b)
+	if err != nil {
+		return nil, nil, err
+	}
+	hist.record(norm(r) / bNorm)
+	hist.Converged = hist.Residuals[0] <= p.tol
+
+	rHat := append([]float64{}, r...)
+	dir, v := make([]float64, n), make([]float64, n)
+	pHat, sHat, t := make([]float64, n), make([]float64, n), make([]float64, n)
+	rho, alpha, omega := 1.0, 1.0, 1.0
+
+	breakdown := "Iteration broke down because of a zero inner product."
+	for !hist.Converged && hist.Iters < p.iters {
+		rhoNext := dot(rHat, r)
+		if rhoNext == 0 {
+			return finish("BiCGSTAB", x, hist, breakdown)
+		}
+		beta := (rhoNext / rho) * (alpha / omega)
+		rho = rhoNext
+		for i := range dir {
+			dir[i] = r[i] + beta * (dir[i] - omega * v[i])
+		}
+
+		if err := p.precondition(dir, pHat); err != nil {
+			return nil, nil, err
+		}
+		if err := a.MulVecAt(pHat, v); err != nil {
+			return nil, nil, err
+		}
+		rv := dot(rHat, v)
+		if rv == 0 {
+			return finish("BiCGSTAB", x, hist, breakdown)
+		}
+		alpha = rho / rv
+
+		// r now holds s = r - alpha v.
+		axpy(-alpha, v, r)
+		axpy(alpha, pHat, x)
+		hist.Iters++
+		if sNorm := norm(r) / bNorm; sNorm <= p.tol {
+			hist.record(sNorm)
+			hist.Converged = true
+			break
+		}
+
+		if err := p.precondition(r, sHat); err != nil {
+			return nil, nil, err
+		}
+		if err := a.MulVecAt(sHat, t); err != nil {
+			return nil, nil, err
+		}
+		tt := dot(t, t)
+		if tt == 0 {
+			hist.record(norm(r) / bNorm)
+			return finish("BiCGSTAB", x, hist, breakdown)
+		}
+		omega = dot(t, r) / tt
+		axpy(omega, sHat, x)
+		axpy(-omega, t, r)
+
+		hist.record(norm(r) / bNorm)
+		if hist.Residuals[hist.Iters] <= p.tol {
+			hist.Converged = true
+		} else if omega == 0 {
+			return finish("BiCGSTAB", x, hist, breakdown)
+		}
+	}
+
+	return finish("BiCGSTAB", x, hist, limitDesc(hist))
+}
@@ -0,0 +1,237 @@
+/*
+package krylov implements iterative Krylov subspace solvers for large linear
+systems, A x = b.
+
+The solvers only access A through matrix-vector products, so they accept any
+type implementing the Operator interface. This includes dense *mat.Matrix
+values, the *sparse.CSR and *sparse.CSC types from package mat/sparse, and
+user-defined matrix-free operators.
+
+Four solvers are provided:
+
+	CG        - conjugate gradient, for symmetric positive definite A.
+	MINRES    - minimum residual, for symmetric (possibly indefinite) A.
+	GMRES     - restarted generalized minimum residual, for general A.
+	BiCGSTAB  - stabilized biconjugate gradient, for general A.
+
+Convergence can be accelerated with preconditioners. Jacobi, SSOR,
+incomplete Cholesky (IC(0)), and incomplete LU (ILU(0)) preconditioners
+constructed from a *sparse.CSR are provided, and user-defined
+preconditioners can be used by implementing the Preconditioner interface.
+CG and MINRES require a symmetric positive definite preconditioner, so they
+should only be used with Jacobi, SSOR, or IC preconditioners.
+
+	a := c.CSR() // Assembled from a sparse.COO.
+	p, err := krylov.NewIC(a)
+	if err != nil {
+		// Error handling.
+	}
+
+	x, hist, err := krylov.CG(a, b, krylov.Precondition(p))
+	if err != nil {
+		// Error handling. x contains the last iterate and hist records
+		// the progress of the iteration.
+	}
+
+Errors are of type *mat.MatrixError. Solvers which fail to converge return
+an IterationError along with their last iterate.
+*/
+package krylov
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/phil-mansfield/num"
+	"github.com/phil-mansfield/num/mat"
+)
+
+const (
+	defaultRestart = 30
+	// defaultItersPerRow is the default iteration limit per row of A.
+	defaultItersPerRow = 10
+	machineEpsilon = 1.0 / (1 << 52)
+)
+
+// Operator is a linear operator which can be applied to vectors. MulVecAt
+// must store A x in target, which will have a length equal to Height(),
+// and must not modify x. x will have a length equal to Width().
+type Operator interface {
+	Width() int
+	Height() int
+	MulVecAt(x, target []float64) error
+}
+
+// Preconditioner approximates the inverse of an Operator. SolveVecAt must
+// store an approximate solution of A x = b in target and must not modify b.
+type Preconditioner interface {
+	SolveVecAt(b, target []float64) error
+}
+
+// History records the progress of an iterative solve.
+type History struct {
+	// Residuals[k] is the relative residual norm, |b - A x_k| / |b|, after
+	// k iterations, as estimated by the solver's recurrences. For MINRES
+	// with a preconditioner, the norm is the one induced by the inverse of
+	// the preconditioner.
+	Residuals []float64
+	// Iters is the number of iterations performed. Each iteration uses
+	// one matrix-vector product, except for BiCGSTAB, which uses two.
+	Iters int
+	// Converged is true if the relative residual fell below the tolerance.
+	Converged bool
+}
+
+func (h *History) record(resid float64) {
+	h.Residuals = append(h.Residuals, resid)
+}
+
+type params struct {
+	tol     float64
+	iters   int
+	restart int
+	precond Preconditioner
+	guess   []float64
+}
+
+type option func(*params)
+
+// Options are passed to the solvers as variadic arguments to customize
+// their behavior.
+type Option option
+
+// Tolerance sets the relative residual norm at which iteration stops. By
+// default, num.ConvergenceEpsilon is used.
+func Tolerance(tol float64) Option {
+	return func(p *params) { p.tol = tol }
+}
+
+// MaxIters sets the maximum number of iterations. By default, ten times the
+// height of A is used.
+func MaxIters(iters int) Option {
+	return func(p *params) { p.iters = iters }
+}
+
+// Restart sets the number of iterations between restarts of GMRES. It is
+// ignored by the other solvers. By default, GMRES restarts every 30
+// iterations.
+func Restart(m int) Option {
+	return func(p *params) { p.restart = m }
+}
+
+// Precondition sets the preconditioner used by the solver. By default, no
+// preconditioner is used.
+func Precondition(pc Preconditioner) Option {
+	return func(p *params) { p.precond = pc }
+}
+
+// Guess sets the initial guess for the solution. guess is not modified. By
+// default, the initial guess is zero.
+func Guess(guess []float64) Option {
+	return func(p *params) { p.guess = guess }
+}
+
+// load applies opts and checks that the results and the shapes of a and b
+// are valid.
+func (p *params) load(
+	operationName string, a Operator, b []float64, opts []Option,
+) *mat.MatrixError {
+	if m, ok := a.(*mat.Matrix); a == nil || (ok && m == nil) {
+		return mat.NewError(mat.NilError, "krylov." + operationName, "Input Operator is nil.")
+	} else if a.Width() != a.Height() {
+		desc := fmt.Sprintf("Operator shape (%d, %d) is not square.",
+			a.Width(), a.Height())
+		return mat.NewError(mat.ShapeError, "krylov." + operationName, desc)
+	} else if len(b) != a.Height() {
+		desc := fmt.Sprintf("Length of right-hand side, %d, does not match Operator height %d.",
+			len(b), a.Height())
+		return mat.NewError(mat.ShapeError, "krylov." + operationName, desc)
+	}
+
+	p.tol, p.iters = num.ConvergenceEpsilon, defaultItersPerRow * len(b)
+	p.restart = defaultRestart
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if !(p.tol > 0) {
+		desc := fmt.Sprintf("Tolerance %g is not positive.", p.tol)
+		return mat.NewError(mat.ParameterError, "krylov." + operationName, desc)
+	} else if p.iters <= 0 {
+		desc := fmt.Sprintf("Iteration limit %d is non-positive.", p.iters)
+		return mat.NewError(mat.ParameterError, "krylov." + operationName, desc)
+	} else if p.restart <= 0 {
+		desc := fmt.Sprintf("Restart length %d is non-positive.", p.restart)
+		return mat.NewError(mat.ParameterError, "krylov." + operationName, desc)
+	} else if p.guess != nil && len(p.guess) != len(b) {
+		desc := fmt.Sprintf("Length of initial guess, %d, does not match Operator width %d.",
+			len(p.guess), len(b))
+		return mat.NewError(mat.ShapeError, "krylov." + operationName, desc)
+	}
+	return nil
+}
+
+// start returns the initial guess and its residual, b - A x.
+func (p *params) start(a Operator, b []float64) (x, r []float64, err error) {
+	x, r = make([]float64, len(b)), make([]float64, len(b))
+	if p.guess == nil {
+		copy(r, b)
+		return x, r, nil
+	}
+
+	copy(x, p.guess)
+	if err := a.MulVecAt(x, r); err != nil {
+		return nil, nil, err
+	}
+	for i := range r {
+		r[i] = b[i] - r[i]
+	}
+	return x, r, nil
+}
+
+// precondition stores M^-1 r in z. If there is no preconditioner, r is
+// copied.
+func (p *params) precondition(r, z []float64) error {
+	if p.precond == nil {
+		copy(z, r)
+		return nil
+	}
+	return p.precond.SolveVecAt(r, z)
+}
+
+// finish returns the results of a solver which has stopped iterating. If
+// the solver did not converge, an IterationError with the given description
+// is returned.
+func finish(
+	operationName string, x []float64, hist *History, desc string,
+) ([]float64, *History, error) {
+	if hist.Converged {
+		return x, hist, nil
+	}
+	return x, hist, mat.NewError(mat.IterationError, "krylov." + operationName, desc)
+}
+
+// limitDesc describes a solver which reached its iteration limit.
+func limitDesc(hist *History) string {
+	return fmt.Sprintf("Iteration limit of %d reached with relative residual %g.",
+		hist.Iters, hist.Residuals[len(hist.Residuals) - 1])
+}
+
+func dot(x, y []float64) float64 {
+	sum := 0.0
+	for i := range x {
+		sum += x[i] * y[i]
+	}
+	return sum
+}
+
+func norm(x []float64) float64 {
+	return math.Sqrt(dot(x, x))
+}
+
+// axpy computes y = alpha x + y.
+func axpy(alpha float64, x, y []float64) {
+	for i := range x {
+		y[i] += alpha * x[i]
+	}
+}
@@ -0,0 +1,270 @@
+package krylov
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/mat/sparse"
+)
+
+// laplacian returns the five-point finite difference Laplacian on an n x n
+// grid plus shift times the identity. The convection term adds c times a
+// one-sided x derivative, which makes the matrix non-symmetric.
+func laplacian(n int, shift, c float64) *sparse.CSR {
+	coo, _ := sparse.NewCOO(n * n, n * n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			i := y * n + x
+			coo.Add(i, i, 4 + shift + c)
+			if x > 0 {
+				coo.Add(i - 1, i, -1 - c)
+			}
+			if x < n - 1 {
+				coo.Add(i + 1, i, -1)
+			}
+			if y > 0 {
+				coo.Add(i - n, i, -1)
+			}
+			if y < n - 1 {
+				coo.Add(i + n, i, -1)
+			}
+		}
+	}
+	return coo.CSR()
+}
+
+// tridiagonal returns the 1D Laplacian with n rows.
+func tridiagonal(n int) *sparse.CSR {
+	coo, _ := sparse.NewCOO(n, n)
+	for i := 0; i < n; i++ {
+		coo.Add(i, i, 2)
+		if i > 0 {
+			coo.Add(i - 1, i, -1)
+			coo.Add(i, i - 1, -1)
+		}
+	}
+	return coo.CSR()
+}
+
+func randomVec(n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rand.Float64() - 0.5
+	}
+	return x
+}
+
+// relResidual returns |b - A x| / |b|.
+func relResidual(a Operator, x, b []float64) float64 {
+	r := make([]float64, len(b))
+	a.MulVecAt(x, r)
+	for i := range r {
+		r[i] -= b[i]
+	}
+	return norm(r) / norm(b)
+}
+
+type solver func(Operator, []float64, ...Option) ([]float64, *History, error)
+
+// shiftOperator is a matrix-free operator computing (A + shift I) x.
+type shiftOperator struct {
+	a     Operator
+	shift float64
+}
+
+func (op shiftOperator) Width() int  { return op.a.Width() }
+func (op shiftOperator) Height() int { return op.a.Height() }
+func (op shiftOperator) MulVecAt(x, target []float64) error {
+	if err := op.a.MulVecAt(x, target); err != nil {
+		return err
+	}
+	axpy(op.shift, x, target)
+	return nil
+}
+
+// fullGMRES runs GMRES without restarts, which restarted GMRES needs to
+// converge for indefinite matrices.
+func fullGMRES(a Operator, b []float64, opts ...Option) ([]float64, *History, error) {
+	return GMRES(a, b, append(opts, Restart(a.Height()))...)
+}
+
+func TestSolvers(t *testing.T) {
+	spd := laplacian(12, 0, 0)
+	indef := laplacian(12, -2.5, 0)
+	nonsym := laplacian(12, 0, 1.5)
+
+	jac, _ := NewJacobi(spd)
+	ssor, _ := NewSSOR(spd, 1.2)
+	ic, _ := NewIC(spd)
+	ilu, _ := NewILU(nonsym)
+	jacNonsym, _ := NewJacobi(nonsym)
+
+	tests := []struct {
+		name   string
+		solve  solver
+		a      Operator
+		pc     Preconditioner
+	}{
+		{"CG", CG, spd, nil},
+		{"CG+Jacobi", CG, spd, jac},
+		{"CG+SSOR", CG, spd, ssor},
+		{"CG+IC", CG, spd, ic},
+		{"CG(dense)", CG, spd.Dense(), nil},
+		{"CG(matrix-free)", CG, shiftOperator{spd, 0.5}, nil},
+		{"MINRES", MINRES, spd, nil},
+		{"MINRES+IC", MINRES, spd, ic},
+		{"MINRES(indefinite)", MINRES, indef, nil},
+		{"GMRES", GMRES, nonsym, nil},
+		{"GMRES+ILU", GMRES, nonsym, ilu},
+		{"GMRES+Jacobi", GMRES, nonsym, jacNonsym},
+		{"GMRES(indefinite)", fullGMRES, indef, nil},
+		{"BiCGSTAB", BiCGSTAB, nonsym, nil},
+		{"BiCGSTAB+ILU", BiCGSTAB, nonsym, ilu},
+		{"BiCGSTAB(csc)", BiCGSTAB, nonsym.CSC(), nil},
+	}
+
+	for _, test := range tests {
+		b := randomVec(test.a.Height())
+		x, hist, err := test.solve(test.a, b, Tolerance(1e-10),
+			Precondition(test.pc))
+		if err != nil {
+			t.Errorf("%s returned error: %s", test.name, err)
+			continue
+		} else if !hist.Converged {
+			t.Errorf("%s did not set Converged.", test.name)
+		} else if len(hist.Residuals) != hist.Iters + 1 {
+			t.Errorf("%s recorded %d residuals for %d iterations.",
+				test.name, len(hist.Residuals), hist.Iters)
+		}
+
+		if res := relResidual(test.a, x, b); res > 1e-8 {
+			t.Errorf("%s gave relative residual %g", test.name, res)
+		}
+	}
+}
+
+func TestPreconditionerQuality(t *testing.T) {
+	a := laplacian(20, 0, 0)
+	b := randomVec(a.Height())
+	_, plain, _ := CG(a, b)
+	ic, _ := NewIC(a)
+	_, withIC, _ := CG(a, b, Precondition(ic))
+	if withIC.Iters >= plain.Iters {
+		t.Errorf("IC preconditioned CG took %d iterations, but plain CG "+
+			"took %d", withIC.Iters, plain.Iters)
+	}
+
+	// IC and ILU have no fill-in for tridiagonal matrices, so they are
+	// exact and the solvers converge in one iteration.
+	tri := tridiagonal(50)
+	b = randomVec(50)
+	ic, _ = NewIC(tri)
+	ilu, _ := NewILU(tri)
+	if _, hist, err := CG(tri, b, Precondition(ic)); err != nil || hist.Iters != 1 {
+		t.Errorf("CG with exact IC took %d iterations, wanted 1", hist.Iters)
+	}
+	if _, hist, err := GMRES(tri, b, Precondition(ilu)); err != nil || hist.Iters != 1 {
+		t.Errorf("GMRES with exact ILU took %d iterations, wanted 1", hist.Iters)
+	}
+
+	// Guessing the solution converges immediately.
+	x, _, _ := CG(tri, b, Tolerance(1e-12))
+	if _, hist, err := CG(tri, b, Guess(x), Tolerance(1e-6)); err != nil || hist.Iters != 0 {
+		t.Errorf("CG from the solution took %d iterations, wanted 0", hist.Iters)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	a := laplacian(10, 0, 2)
+	b := randomVec(a.Height())
+	for _, m := range []int{1, 5, 200} {
+		x, _, err := GMRES(a, b, Restart(m), Tolerance(1e-10))
+		if err != nil {
+			t.Errorf("GMRES(%d) returned error: %s", m, err)
+		} else if res := relResidual(a, x, b); res > 1e-8 {
+			t.Errorf("GMRES(%d) gave relative residual %g", m, res)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	spd := laplacian(10, 0, 0)
+	indef := laplacian(10, -2.5, 0)
+	b := randomVec(spd.Height())
+	negDiag, _ := sparse.CSRFromDense(mat.FromSlice(2, 2, []float64{-1, 0, 0, 1}))
+	zeroDiag, _ := sparse.CSRFromDense(mat.FromSlice(2, 2, []float64{0, 1, 1, 0}))
+
+	x, hist, err := CG(spd, b, MaxIters(3))
+	if code(err) != mat.IterationError {
+		t.Errorf("CG with MaxIters(3) gave %v, wanted an IterationError", err)
+	} else if hist.Converged || hist.Iters != 3 || x == nil {
+		t.Errorf("CG with MaxIters(3) gave Converged = %v, Iters = %d",
+			hist.Converged, hist.Iters)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"CG(indefinite)", third(CG(indef, b)), mat.DefiniteError},
+		{"CG(nil)", third(CG(nil, b)), mat.NilError},
+		{"CG(nil *Matrix)", third(CG((*mat.Matrix)(nil), b)), mat.NilError},
+		{"GMRES(short b)", third(GMRES(spd, b[1:])), mat.ShapeError},
+		{"BiCGSTAB(non-square)", third(BiCGSTAB(mat.New(3, 2), b[:2])),
+			mat.ShapeError},
+		{"MINRES(tol)", third(MINRES(spd, b, Tolerance(0))),
+			mat.ParameterError},
+		{"GMRES(restart)", third(GMRES(spd, b, Restart(0))),
+			mat.ParameterError},
+		{"CG(guess)", third(CG(spd, b, Guess(b[1:]))), mat.ShapeError},
+		{"NewSSOR(omega)", second(NewSSOR(spd, 2)), mat.ParameterError},
+		{"NewJacobi(zero diagonal)", second(NewJacobi(zeroDiag)),
+			mat.SingularError},
+		{"NewIC(negative diagonal)", second(NewIC(negDiag)),
+			mat.DefiniteError},
+		{"NewILU(zero diagonal)", second(NewILU(zeroDiag)),
+			mat.SingularError},
+		{"NewILU(nil)", second(NewILU(nil)), mat.NilError},
+	}
+
+	for _, test := range tests {
+		if code(test.err) != test.code {
+			t.Errorf("%s gave error %v, wanted code %d",
+				test.name, test.err, test.code)
+		}
+	}
+
+	// GMRES keeps the steps taken before a singular operator is detected.
+	singular := mat.FromSlice(2, 2, []float64{1, 0, 1, 0})
+	x, _, err = GMRES(singular, []float64{1, 0})
+	if code(err) != mat.SingularError {
+		t.Errorf("GMRES with a singular operator gave %v, wanted a SingularError",
+			err)
+	} else if math.Abs(x[0]-0.5) > 1e-12 {
+		t.Errorf("GMRES with a singular operator gave x = %v, wanted x[0] = 0.5", x)
+	}
+
+	// Zero right-hand sides have zero solutions.
+	x, hist, err = MINRES(spd, make([]float64, spd.Height()))
+	if err != nil || !hist.Converged || math.Abs(norm(x)) != 0 {
+		t.Errorf("MINRES with b = 0 gave |x| = %g, error %v", norm(x), err)
+	}
+}
+
+func code(err error) int {
+	if e, ok := err.(*mat.MatrixError); ok && e != nil {
+		return e.Code
+	}
+	return -1
+}
+
+func second(_ interface{}, err error) error {
+	return err
+}
+
+func third(_ []float64, _ *History, err error) error {
+	return err
+}
@@ -0,0 +1,351 @@
+package krylov
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/mat/sparse"
+)
+
+// Jacobi is a diagonal preconditioner, M = diag(A). It is symmetric
+// positive definite whenever the diagonal of A is positive.
+type Jacobi struct {
+	invDiag []float64
+}
+
+// SSOR is a symmetric successive over-relaxation preconditioner with
+// relaxation parameter omega,
+//
+//     M = (D / omega + L) (D / omega)^-1 (D / omega + U) omega / (2 - omega),
+//
+// where D, L, and U are the diagonal, strictly lower, and strictly upper
+// parts of A. It is symmetric positive definite whenever A is.
+type SSOR struct {
+	a     *rows
+	omega float64
+}
+
+// IC is a zero fill-in incomplete Cholesky preconditioner, M = L L^T, where
+// L has the same sparsity pattern as the lower triangle of A.
+type IC struct {
+	l *rows // Diagonal element is the last entry of each row.
+}
+
+// ILU is a zero fill-in incomplete LU preconditioner, M = L U, where L is
+// unit lower triangular and L + U has the same sparsity pattern as A.
+type ILU struct {
+	lu *rows
+}
+
+// rows is a mutable copy of the rows of a square sparse.CSR.
+type rows struct {
+	n      int
+	ptr    []int
+	idx    []int
+	values []float64
+	diag   []int // Index of the diagonal entry of each row, or -1.
+}
+
+// loadRows copies the rows of a. If lower is true, only the lower triangle
+// is copied.
+func loadRows(operationName string, a *sparse.CSR, lower bool) (*rows, *mat.MatrixError) {
+	if a == nil {
+		return nil, mat.NewError(mat.NilError, "krylov." + operationName, "Input Matrix is nil.")
+	} else if a.Width() != a.Height() {
+		desc := fmt.Sprintf("Matrix shape (%d, %d) is not square.",
+			a.Width(), a.Height())
+		return nil, mat.NewError(mat.ShapeError, "krylov." + operationName, desc)
+	}
+
+	n := a.Height()
+	r := &rows{n: n, ptr: make([]int, n + 1), diag: make([]int, n)}
+	for y := 0; y < n; y++ {
+		r.diag[y] = -1
+		cols, values := a.Row(y)
+		for k, x := range cols {
+			if lower && x > y {
+				break
+			} else if x == y {
+				r.diag[y] = len(r.idx)
+			}
+			r.idx = append(r.idx, x)
+			r.values = append(r.values, values[k])
+		}
+		r.ptr[y + 1] = len(r.idx)
+	}
+	return r, nil
+}
+
+// zeroDiagError returns a SingularError if any diagonal element of r is
+// missing or zero.
+func (r *rows) zeroDiagError(operationName string) *mat.MatrixError {
+	for y, k := range r.diag {
+		if k < 0 || r.values[k] == 0 {
+			desc := fmt.Sprintf("Diagonal element %d is zero.", y)
+			return mat.NewError(mat.SingularError, "krylov." + operationName, desc)
+		}
+	}
+	return nil
+}
+
+// checkSolve returns an error if b and target do not have length n. If they
+// do, b is copied into target.
+func checkSolve(operationName string, n int, b, target []float64) error {
+	if len(b) != n {
+		desc := fmt.Sprintf("Length of right-hand side, %d, does not match Matrix height %d.",
+			len(b), n)
+		return mat.NewError(mat.ShapeError, "krylov." + operationName, desc)
+	} else if len(target) != n {
+		desc := fmt.Sprintf("Length of target, %d, does not match Matrix height %d.",
+			len(target), n)
+		return mat.NewError(mat.ShapeError, "krylov." + operationName, desc)
+	}
+	copy(target, b)
+	return nil
+}
+
+// NewJacobi creates a Jacobi preconditioner for a.
+//
+// If a is nil or not square, or if any diagonal element of a is zero, a
+// non-nil error is returned.
+func NewJacobi(a *sparse.CSR) (*Jacobi, error) {
+	r, err := loadRows("NewJacobi", a, true)
+	if err != nil {
+		return nil, err
+	} else if err := r.zeroDiagError("NewJacobi"); err != nil {
+		return nil, err
+	}
+
+	p := &Jacobi{make([]float64, r.n)}
+	for y, k := range r.diag {
+		p.invDiag[y] = 1 / r.values[k]
+	}
+	return p, nil
+}
+
+// SolveVecAt stores M^-1 b in target. target may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of A, a
+// non-nil error is returned.
+func (p *Jacobi) SolveVecAt(b, target []float64) error {
+	if err := checkSolve("SolveVecAt", len(p.invDiag), b, target); err != nil {
+		return err
+	}
+	for i, d := range p.invDiag {
+		target[i] *= d
+	}
+	return nil
+}
+
+// NewSSOR creates an SSOR preconditioner for a with the relaxation parameter
+// omega. omega = 1 gives symmetric Gauss-Seidel.
+//
+// If a is nil or not square, if any diagonal element of a is zero, or if
+// omega is not in (0, 2), a non-nil error is returned.
+func NewSSOR(a *sparse.CSR, omega float64) (*SSOR, error) {
+	if !(omega > 0 && omega < 2) {
+		desc := fmt.Sprintf("Relaxation parameter %g is not in (0, 2).", omega)
+		return nil, mat.NewError(mat.ParameterError, "krylov.NewSSOR", desc)
+	}
+	r, err := loadRows("NewSSOR", a, false)
+	if err != nil {
+		return nil, err
+	} else if err := r.zeroDiagError("NewSSOR"); err != nil {
+		return nil, err
+	}
+	return &SSOR{r, omega}, nil
+}
+
+// SolveVecAt stores M^-1 b in target. target may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of A, a
+// non-nil error is returned.
+func (p *SSOR) SolveVecAt(b, target []float64) error {
+	a, omega := p.a, p.omega
+	if err := checkSolve("SolveVecAt", a.n, b, target); err != nil {
+		return err
+	}
+
+	// Forward sweep: (D / omega + L) y = b.
+	for y := 0; y < a.n; y++ {
+		sum := target[y]
+		for k := a.ptr[y]; k < a.diag[y]; k++ {
+			sum -= a.values[k] * target[a.idx[k]]
+		}
+		target[y] = sum * omega / a.values[a.diag[y]]
+	}
+
+	// Backward sweep: (D / omega + U) z = (D / omega) y.
+	for y := a.n - 1; y >= 0; y-- {
+		d := a.values[a.diag[y]]
+		sum := target[y] * d / omega
+		for k := a.diag[y] + 1; k < a.ptr[y + 1]; k++ {
+			sum -= a.values[k] * target[a.idx[k]]
+		}
+		target[y] = sum * omega / d
+	}
+
+	scale := (2 - omega) / omega
+	for i := range target {
+		target[i] *= scale
+	}
+	return nil
+}
+
+// NewIC computes the incomplete Cholesky factorization of a. Only the lower
+// triangle of a is read.
+//
+// If a is nil or not square, or if the factorization encounters a
+// non-positive pivot, a non-nil error is returned. Incomplete Cholesky
+// factorizations exist for all M-matrices, such as standard finite
+// difference Laplacians, but can break down for other symmetric positive
+// definite matrices.
+func NewIC(a *sparse.CSR) (*IC, error) {
+	l, err := loadRows("NewIC", a, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// work[x] holds the already-computed elements of the current row.
+	work := make([]float64, l.n)
+	for y := 0; y < l.n; y++ {
+		if l.diag[y] < 0 {
+			desc := fmt.Sprintf("Diagonal element %d is zero.", y)
+			return nil, mat.NewError(mat.DefiniteError, "krylov.NewIC", desc)
+		}
+
+		diag := l.values[l.diag[y]]
+		for k := l.ptr[y]; k < l.diag[y]; k++ {
+			x := l.idx[k]
+			sum := l.values[k]
+			for kx := l.ptr[x]; kx < l.diag[x]; kx++ {
+				sum -= work[l.idx[kx]] * l.values[kx]
+			}
+			val := sum / l.values[l.diag[x]]
+			l.values[k] = val
+			work[x] = val
+			diag -= val * val
+		}
+
+		if !(diag > 0) {
+			desc := fmt.Sprintf("Non-positive pivot %g found in row %d.", diag, y)
+			return nil, mat.NewError(mat.DefiniteError, "krylov.NewIC", desc)
+		}
+		l.values[l.diag[y]] = math.Sqrt(diag)
+
+		for k := l.ptr[y]; k < l.diag[y]; k++ {
+			work[l.idx[k]] = 0
+		}
+	}
+
+	return &IC{l}, nil
+}
+
+// SolveVecAt stores M^-1 b in target. target may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of A, a
+// non-nil error is returned.
+func (p *IC) SolveVecAt(b, target []float64) error {
+	l := p.l
+	if err := checkSolve("SolveVecAt", l.n, b, target); err != nil {
+		return err
+	}
+
+	// L y = b.
+	for y := 0; y < l.n; y++ {
+		sum := target[y]
+		for k := l.ptr[y]; k < l.diag[y]; k++ {
+			sum -= l.values[k] * target[l.idx[k]]
+		}
+		target[y] = sum / l.values[l.diag[y]]
+	}
+
+	// L^T z = y, using the rows of L as the columns of L^T.
+	for y := l.n - 1; y >= 0; y-- {
+		target[y] /= l.values[l.diag[y]]
+		zy := target[y]
+		for k := l.ptr[y]; k < l.diag[y]; k++ {
+			target[l.idx[k]] -= l.values[k] * zy
+		}
+	}
+	return nil
+}
+
+// NewILU computes the incomplete LU factorization of a.
+//
+// If a is nil or not square, or if the factorization encounters a zero
+// pivot, a non-nil error is returned.
+func NewILU(a *sparse.CSR) (*ILU, error) {
+	lu, err := loadRows("NewILU", a, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// pos[x] is the index of column x in the current row, or -1.
+	pos := make([]int, lu.n)
+	for x := range pos {
+		pos[x] = -1
+	}
+
+	for y := 0; y < lu.n; y++ {
+		if lu.diag[y] < 0 {
+			desc := fmt.Sprintf("Diagonal element %d is zero.", y)
+			return nil, mat.NewError(mat.SingularError, "krylov.NewILU", desc)
+		}
+		for k := lu.ptr[y]; k < lu.ptr[y + 1]; k++ {
+			pos[lu.idx[k]] = k
+		}
+
+		for k := lu.ptr[y]; k < lu.diag[y]; k++ {
+			x := lu.idx[k]
+			lu.values[k] /= lu.values[lu.diag[x]]
+			factor := lu.values[k]
+			for kx := lu.diag[x] + 1; kx < lu.ptr[x + 1]; kx++ {
+				if j := pos[lu.idx[kx]]; j >= 0 {
+					lu.values[j] -= factor * lu.values[kx]
+				}
+			}
+		}
+
+		if lu.values[lu.diag[y]] == 0 {
+			desc := fmt.Sprintf("Zero pivot found in row %d.", y)
+			return nil, mat.NewError(mat.SingularError, "krylov.NewILU", desc)
+		}
+		for k := lu.ptr[y]; k < lu.ptr[y + 1]; k++ {
+			pos[lu.idx[k]] = -1
+		}
+	}
+
+	return &ILU{lu}, nil
+}
+
+// SolveVecAt stores M^-1 b in target. target may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of A, a
+// non-nil error is returned.
+func (p *ILU) SolveVecAt(b, target []float64) error {
+	lu := p.lu
+	if err := checkSolve("SolveVecAt", lu.n, b, target); err != nil {
+		return err
+	}
+
+	// L y = b, with L unit lower triangular.
+	for y := 0; y < lu.n; y++ {
+		sum := target[y]
+		for k := lu.ptr[y]; k < lu.diag[y]; k++ {
+			sum -= lu.values[k] * target[lu.idx[k]]
+		}
+		target[y] = sum
+	}
+
+	// U z = y.
+	for y := lu.n - 1; y >= 0; y-- {
+		sum := target[y]
+		for k := lu.diag[y] + 1; k < lu.ptr[y + 1]; k++ {
+			sum -= lu.values[k] * target[lu.idx[k]]
+		}
+		target[y] = sum / lu.values[lu.diag[y]]
+	}
+	return nil
+}
@@ -1,35 +1,115 @@
 package mat
 
+import (
+	"fmt"
+)
+
 // Eigenvalues returns a slice containing the eigenvalues of m. The order
 // of the eigenvalues is the same as the order of the corresponding
 // eigenvectors returned by m.Eigenvectors().
 //
+// If m is symmetric, the eigenvalues are computed with NewSymEigen and are
+// real and in ascending order. Otherwise they are computed with NewEigen, and
+// complex conjugate pairs are adjacent.
+//
 // If m is nil or not square, a non-nil error is returned.
 func (m *Matrix) Eigenvalues() ([]complex128, error) {
-	return nil, nil
+	if err := squareError("Eigenvalues", m); err != nil {
+		return nil, err
+	}
+
+	if isSymmetric(m) {
+		f, err := newSymEigen("Eigenvalues", m, Tridiagonal)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]complex128, f.n)
+		for i, v := range f.values {
+			values[i] = complex(v, 0)
+		}
+		return values, nil
+	}
+
+	f, err := newEigen("Eigenvalues", m)
+	if err != nil {
+		return nil, err
+	}
+	return f.values, nil
 }
 
 // Eigenvectors returns a 2D slice containing the eigenvectors of m.
 // The order of the eigenvectos is the same as the order of the corresponding
-// eigenvalues returned by m.Eigenvalues().
+// eigenvalues returned by m.Eigenvalues(). Each eigenvector has a 2-norm of
+// one.
+//
+// If m is symmetric, the eigenvectors are computed with NewSymEigen and are
+// real and orthonormal. Otherwise they are computed with NewEigen.
 //
 // If m is nil or not a square matrix, a non-nil error is returned.
 func (m *Matrix) Eigenvectors() ([][]complex128, error) {
-	return nil, nil
+	if err := squareError("Eigenvectors", m); err != nil {
+		return nil, err
+	}
+
+	if isSymmetric(m) {
+		f, err := newSymEigen("Eigenvectors", m, Tridiagonal)
+		if err != nil {
+			return nil, err
+		}
+		vectors := make([][]complex128, f.n)
+		for j := range vectors {
+			vectors[j] = make([]complex128, f.n)
+			for i := range vectors[j] {
+				vectors[j][i] = complex(f.vectors[i * f.n + j], 0)
+			}
+		}
+		return vectors, nil
+	}
+
+	f, err := newEigen("Eigenvectors", m)
+	if err != nil {
+		return nil, err
+	}
+	return f.Vectors(), nil
 }
 
 // Determinant returns the determinant of m.
 //
 // If m is nil or not a square Matrix, a non-nil error is returned.
 func (m *Matrix) Determinant() (float64, error) {
-	return 0, nil
+	f, err := newLU("Determinant", m)
+	if err != nil {
+		return 0, err
+	}
+	return f.Determinant(), nil
+}
+
+// LogDeterminant returns the natural logarithm of the absolute value of the
+// determinant of m along with its sign, which is one of -1, 0, or +1.
+//
+// If m is nil or not a square Matrix, a non-nil error is returned.
+func (m *Matrix) LogDeterminant() (logAbs, sign float64, err error) {
+	f, lerr := newLU("LogDeterminant", m)
+	if lerr != nil {
+		return 0, 0, lerr
+	}
+	logAbs, sign = f.LogDeterminant()
+	return logAbs, sign, nil
 }
 
 // Trace returns the trace of m.
 //
 // Trace returns an error if m is nil or not a square Matrix.
 func (m *Matrix) Trace() (float64, error) {
-	return 0, nil
+	if err := squareError("Trace", m); err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+	for i := 0; i < m.width; i++ {
+		sum += m.values[i * m.stride + i]
+	}
+	return sum, nil
 }
 
 // Invert returns the inverse of m.
@@ -37,7 +117,10 @@ func (m *Matrix) Trace() (float64, error) {
 // If m is nil or not a square matrix or is singular, an error Matrix is
 // returned.
 func Invert(m *Matrix) *Matrix {
-	return nil
+	if err := inputError("Invert", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.height, m.width).Invert(m)
 }
 
 // Invert calculates the inverse of m and places it in the target Matrix.
@@ -46,14 +129,43 @@ func Invert(m *Matrix) *Matrix {
 // Matrix. target is also set to an error Matrix if it is not the same shape as
 // the transpose of m.
 func (target *Matrix) Invert(m *Matrix) *Matrix {
-	return nil
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Invert(m) })
+	}
+
+	f, err := newLU("Invert", m)
+	if err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "Invert", "Target Matrix is nil.")
+	} else if !TransposeCompatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match inverse shape (%d, %d).",
+			target.width, target.height, m.height, m.width)
+		return target.setError(newError(ShapeError, "Invert", desc))
+	} else if f.singular {
+		return target.setError(newError(SingularError, "Invert", "Matrix is singular."))
+	}
+
+	for i := range target.values {
+		target.values[i] = 0
+	}
+	for i := 0; i < f.n; i++ {
+		target.values[i * f.n + i] = 1
+	}
+	f.solveInPlace(target.values, f.n)
+
+	target.err = nil
+	return target
 }
 
 // Transpose returns the transpose of m.
 //
 // If m is nil, an error Matrix is returned.
 func Transpose(m *Matrix) *Matrix {
-	return nil
+	if err := inputError("Transpose", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.height, m.width).Transpose(m)
 }
 
 // Transpose computes the transpose of m and places it in the target Matrix.
@@ -61,5 +173,34 @@ func Transpose(m *Matrix) *Matrix {
 // If m is nil or if target is not hte same shape as the transpose of m, target
 // is set to an error Matrix.
 func (target *Matrix) Transpose(m *Matrix) *Matrix {
-	return nil
-}
\ No newline at end of file
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Transpose(m) })
+	}
+	m = target.operand(m)
+
+	if err := inputError("Transpose", m); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "Transpose", "Target Matrix is nil.")
+	} else if !TransposeCompatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match transpose shape (%d, %d).",
+			target.width, target.height, m.height, m.width)
+		return target.setError(newError(ShapeError, "Transpose", desc))
+	}
+
+	src := m.values
+	if overlaps(target, m) {
+		src = make([]float64, len(m.values))
+		copy(src, m.values)
+	}
+
+	w, h := m.width, m.height
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			target.values[x * h + y] = src[y * w + x]
+		}
+	}
+
+	target.err = nil
+	return target
+}
@@ -0,0 +1,240 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+// maxDiff returns the largest absolute difference between the elements of
+// two matrices of the same shape.
+func maxDiff(m1, m2 *Matrix) float64 {
+	diff := 0.0
+	for i := range m1.values {
+		diff = math.Max(diff, math.Abs(m1.values[i]-m2.values[i]))
+	}
+	return diff
+}
+
+// scaledHilbert returns the n x n Hilbert Matrix multiplied by the least
+// common multiple of 1, ..., 2n - 1 so that every element is an exactly
+// representable integer. n must be at most 10.
+func scaledHilbert(n int) *Matrix {
+	lcm := 232792560.0 // lcm(1, ..., 19)
+	m := New(n, n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			m.Set(x, y, lcm/float64(x+y+1))
+		}
+	}
+	return m
+}
+
+func TestLU(t *testing.T) {
+	tests := []*Matrix{
+		FromSlice(2, 2, []float64{0, 1, 1, 0}),
+		FromSlice(3, 3, []float64{2, -1, 0, -1, 2, -1, 0, -1, 2}),
+		FromSlice(3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 10}),
+		randomMatrix(20, 20),
+	}
+
+	for i, m := range tests {
+		f, err := NewLU(m)
+		if err != nil {
+			t.Errorf("%d) NewLU returned error: %s", i, err)
+			continue
+		}
+
+		// Check that P A = L U.
+		pa := New(m.width, m.height)
+		for r, p := range f.Pivots() {
+			copy(pa.values[r*m.width:(r+1)*m.width],
+				m.values[p*m.width:(p+1)*m.width])
+		}
+		if diff := maxDiff(pa, Mult(f.L(), f.U())); diff > 1e-13 {
+			t.Errorf("%d) P A and L U differ by %g", i, diff)
+		}
+	}
+}
+
+func TestDeterminant(t *testing.T) {
+	tests := []struct {
+		m   *Matrix
+		det float64
+	}{
+		{FromSlice(1, 1, []float64{-3}), -3},
+		{FromSlice(2, 2, []float64{0, 1, 1, 0}), -1},
+		{FromSlice(2, 2, []float64{1, 2, 2, 4}), 0},
+		{FromSlice(3, 3, []float64{2, -1, 0, -1, 2, -1, 0, -1, 2}), 4},
+		{FromSlice(3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 10}), -3},
+		{Scale(Identity(4), 2), 16},
+	}
+
+	for _, test := range tests {
+		det, err := test.m.Determinant()
+		if err != nil {
+			t.Errorf("%v.Determinant() returned error: %s", test.m.Slice(), err)
+		} else if math.Abs(det-test.det) > 1e-12 {
+			t.Errorf("%v.Determinant() = %g, wanted %g",
+				test.m.Slice(), det, test.det)
+		}
+
+		logAbs, sign, err := test.m.LogDeterminant()
+		if err != nil {
+			t.Errorf("%v.LogDeterminant() returned error: %s",
+				test.m.Slice(), err)
+		} else if test.det == 0 && sign != 0 {
+			t.Errorf("%v.LogDeterminant() gave sign %g, wanted 0",
+				test.m.Slice(), sign)
+		} else if test.det != 0 &&
+			math.Abs(sign*math.Exp(logAbs)-test.det) > 1e-12 {
+			t.Errorf("%v.LogDeterminant() = (%g, %g), wanted det = %g",
+				test.m.Slice(), logAbs, sign, test.det)
+		}
+	}
+
+	// The determinant of a large multiple of the identity overflows, but its
+	// log doesn't.
+	logAbs, sign, _ := Scale(Identity(400), 10).LogDeterminant()
+	if sign != 1 || math.Abs(logAbs-400*math.Log(10)) > 1e-9 {
+		t.Errorf("LogDeterminant(10 I) = (%g, %g), wanted (%g, 1)",
+			logAbs, sign, 400*math.Log(10))
+	}
+
+	if _, err := New(3, 2).Determinant(); err == nil {
+		t.Errorf("Determinant of non-square Matrix returned nil error.")
+	}
+	var nilMatrix *Matrix
+	if _, err := nilMatrix.Determinant(); err == nil {
+		t.Errorf("Determinant of nil Matrix returned nil error.")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	tr, err := FromSlice(2, 2, []float64{1, 2, 3, 4}).Trace()
+	if err != nil || tr != 5 {
+		t.Errorf("Trace() = (%g, %v), wanted (5, nil)", tr, err)
+	}
+	if _, err := New(2, 3).Trace(); err == nil {
+		t.Errorf("Trace of non-square Matrix returned nil error.")
+	}
+}
+
+func TestInvert(t *testing.T) {
+	tests := []*Matrix{
+		FromSlice(2, 2, []float64{4, 7, 2, 6}),
+		FromSlice(3, 3, []float64{2, -1, 0, -1, 2, -1, 0, -1, 2}),
+		randomMatrix(30, 30),
+	}
+
+	for i, m := range tests {
+		inv := Invert(m)
+		if inv.IsError() {
+			t.Errorf("%d) Invert returned error: %s", i, inv.Error())
+			continue
+		}
+		id := Identity(m.width)
+		if diff := maxDiff(Mult(m, inv), id); diff > 1e-10 {
+			t.Errorf("%d) m * Invert(m) differs from I by %g", i, diff)
+		}
+
+		// Inverting in place.
+		c := Copy(m)
+		if diff := maxDiff(c.Invert(c), inv); diff > 1e-12 {
+			t.Errorf("%d) m.Invert(m) differs from Invert(m) by %g", i, diff)
+		}
+	}
+
+	errTests := []struct {
+		name string
+		m    *Matrix
+		code int
+	}{
+		{"Invert(singular)", Invert(FromSlice(2, 2, []float64{1, 2, 2, 4})),
+			SingularError},
+		{"Invert(non-square)", Invert(New(2, 3)), ShapeError},
+		{"Invert(nil)", Invert(nil), NilError},
+		{"target.Invert(shape)", New(3, 3).Invert(Identity(2)), ShapeError},
+	}
+
+	for _, test := range errTests {
+		if errorCode(test.m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(test.m), test.code)
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	m := FromSlice(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	exp := []float64{1, 4, 2, 5, 3, 6}
+
+	if res := Transpose(m); res.Width() != 2 || !sliceEq(res.Slice(), exp) {
+		t.Errorf("Transpose(m) = %v, wanted %v", res.Slice(), exp)
+	}
+
+	sq := FromSlice(2, 2, []float64{1, 2, 3, 4})
+	if res := sq.Transpose(sq); !sliceEq(res.Slice(), []float64{1, 3, 2, 4}) {
+		t.Errorf("m.Transpose(m) = %v, wanted [1 3 2 4]", res.Slice())
+	}
+
+	if errorCode(New(3, 2).Transpose(m)) != ShapeError {
+		t.Errorf("Transpose into wrong shape did not give ShapeError.")
+	}
+}
+
+func TestSolve(t *testing.T) {
+	a := FromSlice(3, 3, []float64{2, 1, 1, 1, 3, 2, 1, 0, 0})
+	x := FromSlice(2, 3, []float64{1, -1, 2, 0, 3, 4})
+	b := Mult(a, x)
+
+	if res := Solve(a, b); maxDiff(res, x) > 1e-12 {
+		t.Errorf("Solve(a, b) = %v, wanted %v", res.Slice(), x.Slice())
+	}
+
+	f, _ := NewLU(a)
+	if res := f.Solve(b); maxDiff(res, x) > 1e-12 {
+		t.Errorf("f.Solve(b) = %v, wanted %v", res.Slice(), x.Slice())
+	}
+
+	// Solving in place.
+	bc := Copy(b)
+	if res := bc.SolveLU(f, bc); maxDiff(res, x) > 1e-12 {
+		t.Errorf("b.SolveLU(f, b) = %v, wanted %v", res.Slice(), x.Slice())
+	}
+
+	v, err := SolveVec(a, []float64{5, 8, 1})
+	if err != nil || math.Abs(v[0]-1) > 1e-12 || math.Abs(v[1]-1) > 1e-12 ||
+		math.Abs(v[2]-2) > 1e-12 {
+		t.Errorf("SolveVec(a, b) = (%v, %v), wanted [1 1 2]", v, err)
+	}
+
+	singular := FromSlice(2, 2, []float64{1, 2, 2, 4})
+	if errorCode(Solve(singular, New(1, 2))) != SingularError {
+		t.Errorf("Solve with singular Matrix did not give SingularError.")
+	}
+	if _, err := SolveVec(singular, []float64{1, 2}); err == nil {
+		t.Errorf("SolveVec with singular Matrix returned nil error.")
+	}
+	if errorCode(Solve(a, New(1, 2))) != ShapeError {
+		t.Errorf("Solve with mismatched shapes did not give ShapeError.")
+	}
+}
+
+func TestSolveRefined(t *testing.T) {
+	n := 10
+	a := scaledHilbert(n)
+	x := New(1, n)
+	for i := range x.values {
+		x.values[i] = 1
+	}
+	// Every element of a is an integer, so b is exact and x is the exact
+	// solution.
+	b := Mult(a, x)
+
+	f, _ := NewLU(a)
+	plain := maxDiff(f.Solve(b), x)
+	refined := maxDiff(f.SolveRefined(b), x)
+	if refined > 1e-6 || refined > plain {
+		t.Errorf("Refinement changed error from %g to %g", plain, refined)
+	}
+}
@@ -0,0 +1,268 @@
+/*
+package lp solves linear programs with the revised simplex method.
+
+A linear program is written in the same form as scipy.optimize.linprog:
+
+	minimize    c^T x
+	subject to  AUb x <= bUb
+	            AEq x == bEq
+	            lower <= x <= upper
+
+Any of the constraint sets may be empty, and lower and upper bounds may be
+infinite. Maximization problems can be solved by negating c.
+
+	res, err := lp.Solve(&lp.Problem{
+		C: []float64{-1, -2},
+		AUb: mat.FromSlice(2, 2, []float64{1, 1, 1, -1}),
+		BUb: []float64{4, 1},
+	})
+	if err != nil {
+		// The problem was malformed.
+	} else if res.Status != lp.Optimal {
+		// The problem was infeasible or unbounded.
+	}
+
+The solver is a two-phase dense revised simplex method. Phase one finds a
+feasible point by minimizing the sum of artificial variables, and phase two
+optimizes the objective from that point. Entering variables are chosen by
+Dantzig's most-negative reduced cost rule, falling back to Bland's
+smallest-index rule after a run of degenerate pivots so that the method
+cannot cycle.
+
+Along with the optimal point, Solve returns the dual values (or marginals) of
+every constraint: the rate at which the optimal objective changes as the
+right hand side of the constraint, or the bound, is increased.
+
+Infeasible and unbounded problems are reported through Result.Status.
+Malformed problems, such as those with mismatched shapes, result in errors
+of type *mat.MatrixError.
+*/
+package lp
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+const (
+	defaultTolerance = 1e-9
+	// minMaxIters is the smallest default iteration limit.
+	minMaxIters = 1000
+)
+
+// Problem is a linear program. See the package documentation.
+type Problem struct {
+	// C is the vector of objective coefficients, c. Its length is the number
+	// of variables, n.
+	C []float64
+	// AUb and BUb are the inequality constraints, AUb x <= BUb. AUb must
+	// have width n and height len(BUb), or be nil if BUb is empty.
+	AUb *mat.Matrix
+	BUb []float64
+	// AEq and BEq are the equality constraints, AEq x == BEq. AEq must have
+	// width n and height len(BEq), or be nil if BEq is empty.
+	AEq *mat.Matrix
+	BEq []float64
+	// Bounds gives the bounds of each variable. If Bounds is nil, every
+	// variable is non-negative. Otherwise it must have length n.
+	Bounds []Bound
+}
+
+// Bound is the range [Lower, Upper] of a single variable. Either end may be
+// infinite.
+type Bound struct {
+	Lower, Upper float64
+}
+
+var (
+	// NonNegative is the default Bound, [0, +Inf).
+	NonNegative = Bound{0, math.Inf(+1)}
+	// Free is the Bound of an unconstrained variable, (-Inf, +Inf).
+	Free = Bound{math.Inf(-1), math.Inf(+1)}
+)
+
+// Status describes the outcome of Solve.
+type Status int
+
+const (
+	// Optimal means that an optimal point was found.
+	Optimal Status = iota
+	// Infeasible means that no point satisfies every constraint.
+	Infeasible
+	// Unbounded means that the objective can be made arbitrarily small.
+	Unbounded
+	// IterationLimit means that the iteration limit was reached first.
+	IterationLimit
+)
+
+// String returns the name of s.
+func (s Status) String() string {
+	switch s {
+	case Optimal:
+		return "Optimal"
+	case Infeasible:
+		return "Infeasible"
+	case Unbounded:
+		return "Unbounded"
+	case IterationLimit:
+		return "IterationLimit"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Result is the solution of a linear program. The point and dual values are
+// only set if Status is Optimal.
+type Result struct {
+	Status Status
+	// X is the optimal point and Objective is c^T X.
+	X []float64
+	Objective float64
+	// IneqDuals[i] is the marginal of the ith inequality constraint, the
+	// derivative of Objective with respect to BUb[i]. These are never
+	// positive.
+	IneqDuals []float64
+	// EqDuals[i] is the derivative of Objective with respect to BEq[i].
+	EqDuals []float64
+	// LowerDuals[j] and UpperDuals[j] are the derivatives of Objective with
+	// respect to the lower and upper bounds of X[j]. LowerDuals are never
+	// negative and UpperDuals are never positive.
+	LowerDuals, UpperDuals []float64
+	// Iters is the total number of simplex pivots in both phases.
+	Iters int
+}
+
+type params struct {
+	tol float64
+	maxIters int
+}
+
+type option func(*params)
+
+// Options are passed to Solve as variadic arguments to customize its
+// behavior.
+type Option option
+
+// Tolerance sets the tolerance used for reduced costs, pivot elements, and
+// feasibility. It must be positive and is 1e-9 by default.
+func Tolerance(tol float64) Option {
+	return func(p *params) { p.tol = tol }
+}
+
+// MaxIters sets the maximum number of simplex pivots. By default, the limit
+// is 50 times the number of rows and columns of the problem in standard
+// form, and at least 1000.
+func MaxIters(iters int) Option {
+	return func(p *params) { p.maxIters = iters }
+}
+
+func (p *params) load(opts []Option) *mat.MatrixError {
+	p.tol = defaultTolerance
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if !(p.tol > 0) {
+		desc := fmt.Sprintf("Tolerance %g is not positive.", p.tol)
+		return mat.NewError(mat.ParameterError, "lp.Solve", desc)
+	} else if p.maxIters < 0 {
+		desc := fmt.Sprintf("Iteration limit %d is negative.", p.maxIters)
+		return mat.NewError(mat.ParameterError, "lp.Solve", desc)
+	}
+	return nil
+}
+
+// Solve solves the linear program p.
+//
+// Supported options are:
+//
+//     Tolerance(tol)
+//     MaxIters(iters)
+//
+// If p is nil, if its shapes are inconsistent, if any of its matrices are
+// error Matrices, or if any Bound has Lower > Upper, a non-nil error of type
+// *mat.MatrixError is returned.
+func Solve(p *Problem, opts ...Option) (*Result, error) {
+	par := &params{}
+	if err := par.load(opts); err != nil {
+		return nil, err
+	} else if err := checkProblem(p); err != nil {
+		return nil, err
+	}
+
+	sf := newStandardForm(p)
+	if par.maxIters == 0 {
+		par.maxIters = maxInt(minMaxIters, 50 * (sf.m + sf.n))
+	}
+
+	s := newSimplex(sf, par.tol, par.maxIters)
+	status, err := s.solve()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Status: status, Iters: s.iters}
+	if status == Optimal {
+		s.result(p, res)
+	}
+	return res, nil
+}
+
+// checkProblem returns an error if p is malformed.
+func checkProblem(p *Problem) *mat.MatrixError {
+	if p == nil {
+		return mat.NewError(mat.NilError, "lp.Solve", "Problem is nil.")
+	}
+	n := len(p.C)
+	if n == 0 {
+		return mat.NewError(mat.ParameterError, "lp.Solve", "Problem has no variables.")
+	}
+
+	constraints := []struct {
+		name string
+		a *mat.Matrix
+		b []float64
+	}{
+		{"AUb", p.AUb, p.BUb}, {"AEq", p.AEq, p.BEq},
+	}
+	for _, con := range constraints {
+		if con.a == nil {
+			if len(con.b) != 0 {
+				desc := fmt.Sprintf("%s is nil, but has %d right hand sides.",
+					con.name, len(con.b))
+				return mat.NewError(mat.ShapeError, "lp.Solve", desc)
+			}
+			continue
+		} else if con.a.IsError() {
+			return con.a.MatrixError()
+		} else if con.a.Width() != n || con.a.Height() != len(con.b) {
+			desc := fmt.Sprintf("%s has shape (%d, %d), expected (%d, %d).",
+				con.name, con.a.Width(), con.a.Height(), n, len(con.b))
+			return mat.NewError(mat.ShapeError, "lp.Solve", desc)
+		}
+	}
+
+	if p.Bounds != nil && len(p.Bounds) != n {
+		desc := fmt.Sprintf("Problem has %d variables, but %d Bounds.",
+			n, len(p.Bounds))
+		return mat.NewError(mat.ShapeError, "lp.Solve", desc)
+	}
+	for j, b := range p.Bounds {
+		if !(b.Lower <= b.Upper) || math.IsInf(b.Lower, +1) ||
+			math.IsInf(b.Upper, -1) {
+			desc := fmt.Sprintf("Bound %d, [%g, %g], is empty.",
+				j, b.Lower, b.Upper)
+			return mat.NewError(mat.ParameterError, "lp.Solve", desc)
+		}
+	}
+	return nil
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,344 @@
+package lp
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+func almostEq(x, y, tol float64) bool {
+	return math.Abs(x-y) <= tol*(1+math.Abs(x)+math.Abs(y))
+}
+
+func slicesAlmostEq(x, y []float64, tol float64) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if !almostEq(x[i], y[i], tol) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkOptimal verifies that res is an optimal solution of p by checking
+// primal feasibility, the signs of the duals, and strong duality.
+func checkOptimal(t *testing.T, name string, p *Problem, res *Result) {
+	const tol = 1e-8
+	n := len(p.C)
+	bounds := p.Bounds
+	if bounds == nil {
+		bounds = make([]Bound, n)
+		for j := range bounds {
+			bounds[j] = NonNegative
+		}
+	}
+
+	for i, b := range p.BUb {
+		ax := 0.0
+		for j, x := range res.X {
+			ax += p.AUb.Get(j, i) * x
+		}
+		if ax > b+tol*(1+math.Abs(b)) {
+			t.Errorf("%s: inequality %d violated: %g > %g", name, i, ax, b)
+		}
+		if res.IneqDuals[i] > tol {
+			t.Errorf("%s: inequality dual %d is %g", name, i, res.IneqDuals[i])
+		}
+	}
+	for i, b := range p.BEq {
+		ax := 0.0
+		for j, x := range res.X {
+			ax += p.AEq.Get(j, i) * x
+		}
+		if !almostEq(ax, b, tol) {
+			t.Errorf("%s: equality %d violated: %g != %g", name, i, ax, b)
+		}
+	}
+
+	// The dual objective is b^T lambda plus the contribution of active
+	// bounds.
+	dual := 0.0
+	for i, b := range p.BUb {
+		dual += b * res.IneqDuals[i]
+	}
+	for i, b := range p.BEq {
+		dual += b * res.EqDuals[i]
+	}
+	for j, x := range res.X {
+		if x < bounds[j].Lower-tol || x > bounds[j].Upper+tol {
+			t.Errorf("%s: x[%d] = %g is outside [%g, %g]", name, j, x,
+				bounds[j].Lower, bounds[j].Upper)
+		}
+		if res.LowerDuals[j] < 0 || res.UpperDuals[j] > 0 {
+			t.Errorf("%s: bound duals of x[%d] have the wrong sign", name, j)
+		}
+		if res.LowerDuals[j] != 0 {
+			dual += bounds[j].Lower * res.LowerDuals[j]
+		}
+		if res.UpperDuals[j] != 0 {
+			dual += bounds[j].Upper * res.UpperDuals[j]
+		}
+	}
+	if !almostEq(dual, res.Objective, tol) {
+		t.Errorf("%s: primal objective %g != dual objective %g", name,
+			res.Objective, dual)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     *Problem
+		x     []float64
+		obj   float64
+		ineq  []float64
+		lower []float64
+	}{
+		{
+			"maximization",
+			&Problem{
+				C:   []float64{-1, -2},
+				AUb: mat.FromSlice(2, 2, []float64{1, 1, 1, -1}),
+				BUb: []float64{4, 1},
+			},
+			[]float64{0, 4}, -8, []float64{-2, 0}, []float64{1, 0},
+		},
+		{
+			// The example from the scipy.optimize.linprog documentation.
+			"free and shifted variables",
+			&Problem{
+				C:      []float64{-1, 4},
+				AUb:    mat.FromSlice(2, 2, []float64{-3, 1, 1, 2}),
+				BUb:    []float64{6, 4},
+				Bounds: []Bound{Free, {-3, math.Inf(+1)}},
+			},
+			[]float64{10, -3}, -22, []float64{0, -1}, []float64{0, 6},
+		},
+		{
+			"upper bounds",
+			&Problem{
+				C:      []float64{-1, 1},
+				AEq:    mat.FromSlice(2, 1, []float64{1, 1}),
+				BEq:    []float64{1},
+				Bounds: []Bound{{math.Inf(-1), 3}, {-5, 5}},
+			},
+			[]float64{3, -2}, -5, []float64{}, []float64{0, 0},
+		},
+		{
+			"redundant equalities",
+			&Problem{
+				C:   []float64{1, 2},
+				AEq: mat.FromSlice(2, 2, []float64{1, 1, 2, 2}),
+				BEq: []float64{1, 2},
+			},
+			[]float64{1, 0}, 1, []float64{}, nil,
+		},
+		{
+			// Beale's example, which cycles under the textbook simplex
+			// method.
+			"cycling",
+			&Problem{
+				C: []float64{-0.75, 20, -0.5, 6},
+				AUb: mat.FromSlice(4, 3, []float64{
+					0.25, -8, -1, 9,
+					0.5, -12, -0.5, 3,
+					0, 0, 1, 0,
+				}),
+				BUb: []float64{0, 0, 1},
+			},
+			[]float64{1, 0, 1, 0}, -1.25, nil, nil,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := Solve(test.p)
+		if err != nil {
+			t.Errorf("%s: Solve returned error: %s", test.name, err)
+			continue
+		} else if res.Status != Optimal {
+			t.Errorf("%s: Solve gave status %s", test.name, res.Status)
+			continue
+		}
+
+		if !slicesAlmostEq(res.X, test.x, 1e-10) || !almostEq(res.Objective, test.obj, 1e-10) {
+			t.Errorf("%s: Solve gave x = %v, objective %g, expected %v, %g",
+				test.name, res.X, res.Objective, test.x, test.obj)
+		}
+		if test.ineq != nil && !slicesAlmostEq(res.IneqDuals, test.ineq, 1e-10) {
+			t.Errorf("%s: inequality duals are %v, expected %v", test.name,
+				res.IneqDuals, test.ineq)
+		}
+		if test.lower != nil && !slicesAlmostEq(res.LowerDuals, test.lower, 1e-10) {
+			t.Errorf("%s: lower bound duals are %v, expected %v", test.name,
+				res.LowerDuals, test.lower)
+		}
+		checkOptimal(t, test.name, test.p, res)
+	}
+}
+
+func TestSolveRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 50; trial++ {
+		n, mUb, mEq := 2+rng.Intn(8), rng.Intn(8), rng.Intn(3)
+
+		// A random point inside the bounds is used to make the equality
+		// constraints feasible, and the inequalities hold at that point.
+		x0 := make([]float64, n)
+		bounds := make([]Bound, n)
+		c := make([]float64, n)
+		for j := range c {
+			c[j] = rng.NormFloat64()
+			bounds[j] = Bound{-rng.Float64(), 1 + rng.Float64()}
+			if rng.Intn(4) == 0 {
+				bounds[j].Lower = math.Inf(-1)
+			}
+			x0[j] = rng.Float64()
+		}
+
+		p := &Problem{C: c, Bounds: bounds}
+		if mUb > 0 {
+			p.AUb, p.BUb = mat.New(n, mUb), make([]float64, mUb)
+			for i := range p.BUb {
+				for j := 0; j < n; j++ {
+					a := rng.NormFloat64()
+					p.AUb.Set(j, i, a)
+					p.BUb[i] += a * x0[j]
+				}
+				p.BUb[i] += rng.Float64()
+			}
+		}
+		if mEq > 0 {
+			p.AEq, p.BEq = mat.New(n, mEq), make([]float64, mEq)
+			for i := range p.BEq {
+				for j := 0; j < n; j++ {
+					a := rng.NormFloat64()
+					p.AEq.Set(j, i, a)
+					p.BEq[i] += a * x0[j]
+				}
+			}
+		}
+
+		res, err := Solve(p)
+		if err != nil {
+			t.Errorf("%d) Solve returned error: %s", trial, err)
+		} else if res.Status == Optimal {
+			checkOptimal(t, "random", p, res)
+		} else if res.Status != Unbounded {
+			t.Errorf("%d) Solve of a feasible problem gave status %s",
+				trial, res.Status)
+		}
+	}
+}
+
+func TestSolveStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      *Problem
+		opts   []Option
+		status Status
+	}{
+		{"infeasible", &Problem{
+			C:   []float64{1, 1},
+			AUb: mat.FromSlice(2, 1, []float64{1, 1}),
+			BUb: []float64{-1},
+		}, nil, Infeasible},
+		{"infeasible bounds", &Problem{
+			C:      []float64{1},
+			AEq:    mat.FromSlice(1, 1, []float64{1}),
+			BEq:    []float64{5},
+			Bounds: []Bound{{0, 2}},
+		}, nil, Infeasible},
+		{"unbounded", &Problem{
+			C:   []float64{-1, 0},
+			AUb: mat.FromSlice(2, 1, []float64{1, -1}),
+			BUb: []float64{1},
+		}, nil, Unbounded},
+		{"unconstrained", &Problem{
+			C:      []float64{1},
+			Bounds: []Bound{Free},
+		}, nil, Unbounded},
+		{"iteration limit", &Problem{
+			C:   []float64{-1, -1, -1},
+			AUb: mat.FromSlice(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1}),
+			BUb: []float64{1, 1, 1},
+		}, []Option{MaxIters(1)}, IterationLimit},
+	}
+
+	for _, test := range tests {
+		res, err := Solve(test.p, test.opts...)
+		if err != nil {
+			t.Errorf("%s: Solve returned error: %s", test.name, err)
+		} else if res.Status != test.status {
+			t.Errorf("%s: Solve gave status %s, expected %s", test.name,
+				res.Status, test.status)
+		} else if res.X != nil {
+			t.Errorf("%s: Solve gave a point for a non-optimal problem", test.name)
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	tests := []struct {
+		p    *Problem
+		opts []Option
+		err  error
+	}{
+		{nil, nil, mat.ErrNil},
+		{&Problem{}, nil, mat.ErrParameter},
+		{&Problem{C: []float64{1}, BUb: []float64{1}}, nil, mat.ErrShape},
+		{&Problem{C: []float64{1}, AEq: mat.New(2, 1), BEq: []float64{1}}, nil, mat.ErrShape},
+		{&Problem{C: []float64{1}, AUb: mat.New(0, 1), BUb: []float64{1}}, nil, mat.ErrParameter},
+		{&Problem{C: []float64{1}, Bounds: []Bound{{1, 0}}}, nil, mat.ErrParameter},
+		{&Problem{C: []float64{1}, Bounds: []Bound{Free, Free}}, nil, mat.ErrShape},
+		{&Problem{C: []float64{1}}, []Option{Tolerance(0)}, mat.ErrParameter},
+	}
+
+	for i, test := range tests {
+		if _, err := Solve(test.p, test.opts...); !errors.Is(err, test.err) {
+			t.Errorf("%d) Solve gave error %v, expected %v", i, err, test.err)
+		}
+	}
+}
+
+func TestSolveTransport(t *testing.T) {
+	// Ship goods from 12 sources to 15 sinks at minimum cost. This takes
+	// enough pivots that the basis is refactored several times.
+	rng := rand.New(rand.NewSource(2))
+	ns, nd := 12, 15
+	supply, demand := make([]float64, ns), make([]float64, nd)
+	for i := range demand {
+		demand[i] = 1 + float64(rng.Intn(20))
+	}
+	for i := range supply {
+		supply[i] = 40
+	}
+
+	n := ns * nd
+	p := &Problem{
+		C:   make([]float64, n),
+		AUb: mat.New(n, ns), BUb: supply,
+		AEq: mat.New(n, nd), BEq: demand,
+	}
+	for s := 0; s < ns; s++ {
+		for d := 0; d < nd; d++ {
+			j := s*nd + d
+			p.C[j] = 1 + 10*rng.Float64()
+			p.AUb.Set(j, s, 1)
+			p.AEq.Set(j, d, 1)
+		}
+	}
+
+	res, err := Solve(p)
+	if err != nil || res.Status != Optimal {
+		t.Fatalf("Solve gave %v, %v", res, err)
+	}
+	if res.Iters < refactorInterval {
+		t.Errorf("Solve took only %d pivots", res.Iters)
+	}
+	checkOptimal(t, "transport", p, res)
+}
@@ -0,0 +1,519 @@
+package lp
+
+import (
+	"math"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+const (
+	// refactorInterval is the number of pivots between recomputations of
+	// the basis inverse from scratch, which limits the growth of rounding
+	// error in the product-form updates.
+	refactorInterval = 64
+	// degenerateLimit is the number of consecutive degenerate pivots after
+	// which the solver switches to Bland's rule.
+	degenerateLimit = 10
+	// feasibilityScale multiplies the tolerance when deciding whether phase
+	// one found a feasible point.
+	feasibilityScale = 1e3
+)
+
+// standardForm is a linear program rewritten as
+//
+//	minimize c^T z subject to A z == b, z >= 0, b >= 0
+//
+// along with the information needed to map z back to the original
+// variables, x, and the standard form duals back to the original
+// constraints.
+type standardForm struct {
+	m, n int // number of rows and columns of A
+	a []float64 // A, stored row-major
+	b, c []float64
+
+	// x[j] = shift[j] + sign[j] * z[col[j]] - z[neg[j]], where neg[j] is -1
+	// unless x[j] is free.
+	col, neg []int
+	sign, shift []float64
+
+	// slack[i] is the column of the slack variable of row i, or -1 for
+	// equality constraints. rowSign[i] is -1 if row i was negated to make
+	// b[i] non-negative.
+	slack []int
+	rowSign []float64
+	mUb, mEq int // the first mUb rows are inequalities, the next mEq equalities
+}
+
+// newStandardForm converts p to standard form. Finite lower bounds are
+// removed by shifting variables, variables with only upper bounds are
+// reflected, free variables are split into positive and negative parts, and
+// variables with two finite bounds get an extra inequality row.
+func newStandardForm(p *Problem) *standardForm {
+	nx := len(p.C)
+	mUb, mEq := len(p.BUb), len(p.BEq)
+	sf := &standardForm{
+		col: make([]int, nx), neg: make([]int, nx),
+		sign: make([]float64, nx), shift: make([]float64, nx),
+		mUb: mUb, mEq: mEq,
+	}
+
+	// Assign a column to every part of every variable, and find the
+	// variables which need a bound row.
+	boundRows := []int{}
+	for j := 0; j < nx; j++ {
+		bound := NonNegative
+		if p.Bounds != nil {
+			bound = p.Bounds[j]
+		}
+
+		sf.col[j], sf.neg[j], sf.sign[j] = sf.n, -1, 1
+		sf.n++
+		switch {
+		case !math.IsInf(bound.Lower, 0):
+			sf.shift[j] = bound.Lower
+			if !math.IsInf(bound.Upper, 0) {
+				boundRows = append(boundRows, j)
+			}
+		case !math.IsInf(bound.Upper, 0):
+			sf.shift[j], sf.sign[j] = bound.Upper, -1
+		default:
+			sf.neg[j] = sf.n
+			sf.n++
+		}
+	}
+
+	sf.m = mUb + mEq + len(boundRows)
+	sf.slack = make([]int, sf.m)
+	for i := range sf.slack {
+		sf.slack[i] = -1
+		if i < mUb || i >= mUb + mEq {
+			sf.slack[i] = sf.n
+			sf.n++
+		}
+	}
+
+	sf.a = make([]float64, sf.m * sf.n)
+	sf.b = make([]float64, sf.m)
+	sf.c = make([]float64, sf.n)
+	for j, cj := range p.C {
+		sf.c[sf.col[j]] = sf.sign[j] * cj
+		if sf.neg[j] >= 0 {
+			sf.c[sf.neg[j]] = -cj
+		}
+	}
+
+	// setRow writes the original constraint row, coeffs . x == rhs, to row
+	// i of the standard form.
+	setRow := func(i int, coeffs []float64, rhs float64) {
+		row := sf.a[i * sf.n: (i + 1) * sf.n]
+		for j, aij := range coeffs {
+			row[sf.col[j]] = sf.sign[j] * aij
+			if sf.neg[j] >= 0 {
+				row[sf.neg[j]] = -aij
+			}
+			rhs -= aij * sf.shift[j]
+		}
+		if sf.slack[i] >= 0 {
+			row[sf.slack[i]] = 1
+		}
+		sf.b[i] = rhs
+	}
+	for i := 0; i < mUb; i++ {
+		setRow(i, p.AUb.Row(i).Slice(), p.BUb[i])
+	}
+	for i := 0; i < mEq; i++ {
+		setRow(mUb + i, p.AEq.Row(i).Slice(), p.BEq[i])
+	}
+	for k, j := range boundRows {
+		i := mUb + mEq + k
+		sf.a[i * sf.n + sf.col[j]] = 1
+		sf.a[i * sf.n + sf.slack[i]] = 1
+		sf.b[i] = p.Bounds[j].Upper - p.Bounds[j].Lower
+	}
+
+	sf.rowSign = make([]float64, sf.m)
+	for i := range sf.rowSign {
+		sf.rowSign[i] = 1
+		if sf.b[i] < 0 {
+			sf.rowSign[i] = -1
+			sf.b[i] = -sf.b[i]
+			row := sf.a[i * sf.n: (i + 1) * sf.n]
+			for j := range row {
+				row[j] = -row[j]
+			}
+		}
+	}
+	return sf
+}
+
+// simplex is the state of the revised simplex method applied to a
+// standardForm with one artificial variable per row. Columns 0 through n - 1
+// are the columns of A and column n + i is the artificial variable of row i.
+type simplex struct {
+	sf *standardForm
+	tol float64
+	iters, maxIters int
+
+	basis []int // basis[i] is the column which is basic in position i
+	pos []int // pos[j] is the position of column j in the basis, or -1
+	binv []float64 // inverse of the basis matrix, m x m, row-major
+	xB []float64 // values of the basic variables
+	cost []float64 // costs of the current phase, including artificials
+	phase2 bool // if true, artificial variables may not enter the basis
+
+	sinceRefactor, degenerate int
+	bland bool
+}
+
+// newSimplex returns the initial phase one simplex state for sf. Each row
+// starts with its slack variable in the basis if possible, and its
+// artificial variable otherwise, so that the initial basis matrix is the
+// identity.
+func newSimplex(sf *standardForm, tol float64, maxIters int) *simplex {
+	m, nTot := sf.m, sf.n + sf.m
+	s := &simplex{
+		sf: sf, tol: tol, maxIters: maxIters,
+		basis: make([]int, m), pos: make([]int, nTot),
+		binv: make([]float64, m * m), xB: make([]float64, m),
+		cost: make([]float64, nTot),
+	}
+	for j := range s.pos {
+		s.pos[j] = -1
+	}
+	for i := 0; i < m; i++ {
+		j := sf.slack[i]
+		if j < 0 || sf.rowSign[i] < 0 {
+			j = sf.n + i
+			s.cost[j] = 1
+		}
+		s.basis[i], s.pos[j] = j, i
+		s.binv[i * m + i] = 1
+		s.xB[i] = sf.b[i]
+	}
+	return s
+}
+
+// solve runs both phases of the simplex method. A non-nil error is only
+// returned if the basis becomes numerically singular.
+func (s *simplex) solve() (Status, error) {
+	status, err := s.run()
+	if err != nil || status != Optimal {
+		return status, err
+	}
+
+	// Phase one is finished. If any artificial variable is still positive,
+	// there is no feasible point.
+	infeas, bMax := 0.0, 0.0
+	for i, j := range s.basis {
+		if j >= s.sf.n {
+			infeas += s.xB[i]
+		}
+		bMax = math.Max(bMax, s.sf.b[i])
+	}
+	if infeas > feasibilityScale * s.tol * (1 + bMax) {
+		return Infeasible, nil
+	}
+	s.removeArtificials()
+
+	for j := range s.cost {
+		s.cost[j] = 0
+		if j < s.sf.n {
+			s.cost[j] = s.sf.c[j]
+		}
+	}
+	s.phase2, s.bland, s.degenerate = true, false, 0
+	return s.run()
+}
+
+// removeArtificials pivots every basic artificial variable out of the
+// basis, where possible. Artificial variables which cannot be removed
+// correspond to redundant equality constraints: their rows of B^-1 A are zero
+// in every non-artificial column, so they stay at zero for the rest of the
+// solve.
+func (s *simplex) removeArtificials() {
+	m, n := s.sf.m, s.sf.n
+	for p, j := range s.basis {
+		if j < n {
+			continue
+		}
+		s.xB[p] = 0
+
+		best, bestAlpha := -1, s.tol
+		for q := 0; q < n; q++ {
+			if s.pos[q] >= 0 {
+				continue
+			}
+			alpha := 0.0
+			for k := 0; k < m; k++ {
+				alpha += s.binv[p * m + k] * s.sf.a[k * n + q]
+			}
+			if math.Abs(alpha) > bestAlpha {
+				best, bestAlpha = q, math.Abs(alpha)
+			}
+		}
+		if best >= 0 {
+			s.pivot(p, best, s.ftran(best))
+		}
+	}
+}
+
+// run iterates the simplex method with the current costs until an optimum
+// is found, the problem is found to be unbounded, or the iteration limit is
+// reached.
+func (s *simplex) run() (Status, error) {
+	for {
+		y := s.duals()
+		q := s.entering(y)
+		if q < 0 {
+			return Optimal, nil
+		} else if s.iters >= s.maxIters {
+			return IterationLimit, nil
+		}
+
+		d := s.ftran(q)
+		p := s.leaving(d)
+		if p < 0 {
+			return Unbounded, nil
+		}
+		s.pivot(p, q, d)
+		s.iters++
+
+		if s.sinceRefactor++; s.sinceRefactor >= refactorInterval {
+			if err := s.refactor(); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
+
+// column returns the element of column j in row i, including artificial
+// columns.
+func (s *simplex) column(i, j int) float64 {
+	if j < s.sf.n {
+		return s.sf.a[i * s.sf.n + j]
+	} else if j - s.sf.n == i {
+		return 1
+	}
+	return 0
+}
+
+// duals returns the simplex multipliers, y^T = c_B^T B^-1.
+func (s *simplex) duals() []float64 {
+	m := s.sf.m
+	y := make([]float64, m)
+	for i, j := range s.basis {
+		if cj := s.cost[j]; cj != 0 {
+			row := s.binv[i * m: (i + 1) * m]
+			for k := range y {
+				y[k] += cj * row[k]
+			}
+		}
+	}
+	return y
+}
+
+// reducedCost returns the reduced cost of column j, c_j - y^T a_j.
+func (s *simplex) reducedCost(y []float64, j int) float64 {
+	r := s.cost[j]
+	if j >= s.sf.n {
+		return r - y[j - s.sf.n]
+	}
+	for i, yi := range y {
+		r -= yi * s.sf.a[i * s.sf.n + j]
+	}
+	return r
+}
+
+// entering returns the column which should enter the basis, or -1 if the
+// current basis is optimal. Dantzig's rule chooses the most negative reduced
+// cost and Bland's rule chooses the first negative reduced cost.
+func (s *simplex) entering(y []float64) int {
+	nTot := len(s.cost)
+	if s.phase2 {
+		nTot = s.sf.n
+	}
+
+	best, bestR := -1, -s.tol
+	for j := 0; j < nTot; j++ {
+		if s.pos[j] >= 0 {
+			continue
+		}
+		if r := s.reducedCost(y, j); r < bestR {
+			best, bestR = j, r
+			if s.bland {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// ftran returns the entering column expressed in terms of the basis,
+// d = B^-1 a_q.
+func (s *simplex) ftran(q int) []float64 {
+	m := s.sf.m
+	d := make([]float64, m)
+	for i := range d {
+		row := s.binv[i * m: (i + 1) * m]
+		if q >= s.sf.n {
+			d[i] = row[q - s.sf.n]
+			continue
+		}
+		for k, bik := range row {
+			d[i] += bik * s.sf.a[k * s.sf.n + q]
+		}
+	}
+	return d
+}
+
+// leaving returns the basis position which leaves the basis when a column
+// with basis representation d enters, or -1 if the objective is unbounded
+// along d. Ties in the ratio test are broken by the largest pivot, or under
+// Bland's rule, by the smallest column index.
+func (s *simplex) leaving(d []float64) int {
+	best, bestRatio := -1, math.Inf(+1)
+	for i, di := range d {
+		if di <= s.tol {
+			continue
+		}
+		ratio := s.xB[i] / di
+		switch {
+		case best < 0 || ratio < bestRatio - s.tol:
+			best, bestRatio = i, ratio
+		case ratio <= bestRatio + s.tol:
+			if s.bland && s.basis[i] < s.basis[best] ||
+				!s.bland && di > d[best] {
+				best, bestRatio = i, math.Min(ratio, bestRatio)
+			}
+		}
+	}
+	return best
+}
+
+// pivot replaces the column in basis position p with column q, whose basis
+// representation is d, and updates B^-1 and x_B.
+func (s *simplex) pivot(p, q int, d []float64) {
+	m := s.sf.m
+	t := s.xB[p] / d[p]
+	if t <= s.tol {
+		if s.degenerate++; s.degenerate >= degenerateLimit {
+			s.bland = true
+		}
+	} else {
+		s.degenerate, s.bland = 0, false
+	}
+
+	for i := range s.xB {
+		if i == p {
+			s.xB[i] = t
+		} else if s.xB[i] -= t * d[i]; s.xB[i] < 0 {
+			s.xB[i] = 0
+		}
+	}
+
+	rowP := s.binv[p * m: (p + 1) * m]
+	for k := range rowP {
+		rowP[k] /= d[p]
+	}
+	for i := 0; i < m; i++ {
+		if i == p || d[i] == 0 {
+			continue
+		}
+		row := s.binv[i * m: (i + 1) * m]
+		for k := range row {
+			row[k] -= d[i] * rowP[k]
+		}
+	}
+
+	s.pos[s.basis[p]] = -1
+	s.basis[p], s.pos[q] = q, p
+}
+
+// refactor recomputes B^-1 and x_B from scratch.
+func (s *simplex) refactor() error {
+	m := s.sf.m
+	s.sinceRefactor = 0
+	if m == 0 {
+		return nil
+	}
+
+	b := mat.New(m, m)
+	for i := 0; i < m; i++ {
+		for p, j := range s.basis {
+			b.Set(p, i, s.column(i, j))
+		}
+	}
+	inv := mat.Invert(b)
+	if inv.IsError() {
+		err := inv.MatrixError()
+		return mat.NewError(err.Code, "lp.Solve", "Simplex basis became singular.")
+	}
+	copy(s.binv, inv.Slice())
+
+	for i := range s.xB {
+		s.xB[i] = 0
+		for k, bk := range s.sf.b {
+			s.xB[i] += s.binv[i * m + k] * bk
+		}
+		if s.xB[i] < 0 {
+			s.xB[i] = 0
+		}
+	}
+	return nil
+}
+
+// result fills in the optimal point and dual values of res from the final
+// phase two basis.
+func (s *simplex) result(p *Problem, res *Result) {
+	sf := s.sf
+	z := make([]float64, sf.n)
+	for i, j := range s.basis {
+		if j < sf.n {
+			z[j] = s.xB[i]
+		}
+	}
+
+	nx := len(p.C)
+	res.X = make([]float64, nx)
+	for j := range res.X {
+		res.X[j] = sf.shift[j] + sf.sign[j] * z[sf.col[j]]
+		if sf.neg[j] >= 0 {
+			res.X[j] -= z[sf.neg[j]]
+		}
+		res.Objective += p.C[j] * res.X[j]
+	}
+
+	// The standard form duals are the derivatives of the objective with
+	// respect to the standard form right hand sides.
+	y := s.duals()
+	res.IneqDuals = make([]float64, sf.mUb)
+	res.EqDuals = make([]float64, sf.mEq)
+	for i := range res.IneqDuals {
+		res.IneqDuals[i] = sf.rowSign[i] * y[i]
+	}
+	for i := range res.EqDuals {
+		res.EqDuals[i] = sf.rowSign[sf.mUb + i] * y[sf.mUb + i]
+	}
+
+	// The bound duals are the reduced costs of the original variables with
+	// respect to the original constraints: positive reduced costs belong to
+	// active lower bounds and negative ones to active upper bounds.
+	res.LowerDuals = make([]float64, nx)
+	res.UpperDuals = make([]float64, nx)
+	for j, cj := range p.C {
+		r := cj
+		for i, lambda := range res.IneqDuals {
+			r -= lambda * p.AUb.Get(j, i)
+		}
+		for i, lambda := range res.EqDuals {
+			r -= lambda * p.AEq.Get(j, i)
+		}
+		if math.Abs(r) <= s.tol * (1 + math.Abs(cj)) {
+			continue
+		} else if r > 0 {
+			res.LowerDuals[j] = r
+		} else {
+			res.UpperDuals[j] = r
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// maxRefineIters is the maximum number of steps of iterative refinement
+	// performed by SolveRefined.
+	maxRefineIters = 10
+	machineEpsilon = 1.0 / (1 << 52)
+)
+
+// LU represents the LU decomposition with partial pivoting of a square
+// Matrix, A. The decomposition satisfies P A = L U, where P is a permutation
+// Matrix, L is unit lower triangular, and U is upper triangular.
+//
+// An LU can be used to solve many linear systems with the same left-hand
+// side for the cost of a single decomposition.
+type LU struct {
+	n    int
+	lu   []float64 // L below the diagonal and U on and above it.
+	perm []int     // Row i of P A is row perm[i] of A.
+	sign float64   // Determinant of P.
+	a    []float64 // Copy of A used for iterative refinement.
+
+	singular bool
+}
+
+// NewLU computes the LU decomposition of m. m is not modified.
+//
+// If m is nil, an error Matrix, or not square, a non-nil error is returned.
+// Singular matrices can be decomposed, but cannot be used to solve linear
+// systems or compute inverses.
+func NewLU(m *Matrix) (*LU, error) {
+	f, err := newLU("NewLU", m)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newLU(operationName string, m *Matrix) (*LU, *MatrixError) {
+	m = dense(m)
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	}
+
+	n := m.width
+	f := &LU{
+		n: n, lu: make([]float64, n * n), perm: make([]int, n), sign: 1,
+		a: make([]float64, n * n),
+	}
+	copy(f.lu, m.values)
+	copy(f.a, m.values)
+	for i := range f.perm {
+		f.perm[i] = i
+	}
+
+	lu := f.lu
+	for k := 0; k < n; k++ {
+		p, max := k, math.Abs(lu[k * n + k])
+		for r := k + 1; r < n; r++ {
+			if v := math.Abs(lu[r * n + k]); v > max {
+				p, max = r, v
+			}
+		}
+
+		if p != k {
+			rowK, rowP := lu[k * n: (k + 1) * n], lu[p * n: (p + 1) * n]
+			for c := range rowK {
+				rowK[c], rowP[c] = rowP[c], rowK[c]
+			}
+			f.perm[k], f.perm[p] = f.perm[p], f.perm[k]
+			f.sign = -f.sign
+		}
+
+		pivot := lu[k * n + k]
+		if pivot == 0 {
+			f.singular = true
+			continue
+		}
+
+		rowK := lu[k * n + k + 1: (k + 1) * n]
+		for r := k + 1; r < n; r++ {
+			l := lu[r * n + k] / pivot
+			lu[r * n + k] = l
+			if l == 0 {
+				continue
+			}
+			rowR := lu[r * n + k + 1: (r + 1) * n]
+			for c, u := range rowK {
+				rowR[c] -= l * u
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// IsSingular returns true if the decomposed Matrix is exactly singular.
+func (f *LU) IsSingular() bool {
+	return f.singular
+}
+
+// L returns the unit lower triangular factor of the decomposition.
+func (f *LU) L() *Matrix {
+	n := f.n
+	l := Identity(n)
+	for r := 1; r < n; r++ {
+		copy(l.values[r * n: r * n + r], f.lu[r * n: r * n + r])
+	}
+	return l
+}
+
+// U returns the upper triangular factor of the decomposition.
+func (f *LU) U() *Matrix {
+	n := f.n
+	u := New(n, n)
+	for r := 0; r < n; r++ {
+		copy(u.values[r * n + r: (r + 1) * n], f.lu[r * n + r: (r + 1) * n])
+	}
+	return u
+}
+
+// Pivots returns the row permutation of the decomposition: row i of P A is
+// row Pivots()[i] of A.
+func (f *LU) Pivots() []int {
+	perm := make([]int, f.n)
+	copy(perm, f.perm)
+	return perm
+}
+
+// Determinant returns the determinant of the decomposed Matrix.
+func (f *LU) Determinant() float64 {
+	det := f.sign
+	for i := 0; i < f.n; i++ {
+		det *= f.lu[i * f.n + i]
+	}
+	return det
+}
+
+// LogDeterminant returns the natural logarithm of the absolute value of the
+// determinant of the decomposed Matrix along with its sign, which is one of
+// -1, 0, or +1. This avoids the overflow and underflow which Determinant can
+// suffer from for large matrices.
+func (f *LU) LogDeterminant() (logAbs, sign float64) {
+	if f.singular {
+		return math.Inf(-1), 0
+	}
+
+	sign = f.sign
+	for i := 0; i < f.n; i++ {
+		u := f.lu[i * f.n + i]
+		if u < 0 {
+			sign = -sign
+		}
+		logAbs += math.Log(math.Abs(u))
+	}
+	return logAbs, sign
+}
+
+// ConditionEstimate returns an estimate of the 1-norm condition number of the
+// decomposed Matrix, ||A||_1 ||A^-1||_1. ||A^-1||_1 is estimated with
+// Hager's method and Higham's refinements, which needs only a few solves with
+// A and A^T rather than the full inverse. The estimate never exceeds the true
+// condition number and is almost always within a factor of three of it. If A
+// is singular, +Inf is returned.
+func (f *LU) ConditionEstimate() float64 {
+	if f.singular {
+		return math.Inf(+1)
+	}
+
+	a := &Matrix{f.a, f.n, f.n, f.n, nil}
+	invNorm := invNorm1Estimate(f.n, func(x []float64, trans bool) {
+		if trans {
+			f.solveTransposeInPlace(x)
+		} else {
+			f.solveInPlace(x, 1)
+		}
+	})
+	return norm1(a) * invNorm
+}
+
+// Solve solves the linear system A X = B, where each column of B is a
+// separate right-hand side, and returns X.
+//
+// If b is nil or does not have the same height as A, or if A is singular,
+// an error Matrix is returned.
+func (f *LU) Solve(b *Matrix) *Matrix {
+	if err := inputError("Solve", b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(b.width, b.height).solveLU("Solve", f, b)
+}
+
+// SolveLU solves the linear system A X = B using the decomposition f and
+// stores X in the target Matrix. The target Matrix is also returned. Each
+// column of B is a separate right-hand side.
+//
+// If b is nil or does not have the same height as A, if target is not the
+// same shape as b, or if A is singular, target is set to an error Matrix.
+func (target *Matrix) SolveLU(f *LU, b *Matrix) *Matrix {
+	return target.solveLU("SolveLU", f, b)
+}
+
+func (target *Matrix) solveLU(operationName string, f *LU, b *Matrix) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.solveLU(operationName, f, b) })
+	}
+	b = target.operand(b)
+
+	if err := f.checkSolve(operationName, target, b); err != nil {
+		return target.setError(err)
+	}
+
+	if target != b {
+		copy(target.values, b.values)
+	}
+	f.solveInPlace(target.values, b.width)
+	target.err = nil
+	return target
+}
+
+// SolveVec solves the linear system A x = b for a single right-hand side.
+//
+// If b does not have the same length as the height of A, or if A is
+// singular, a non-nil error is returned.
+func (f *LU) SolveVec(b []float64) ([]float64, error) {
+	if len(b) != f.n {
+		desc := fmt.Sprintf("Length of right-hand side, %d, does not match Matrix height %d.",
+			len(b), f.n)
+		return nil, newError(ShapeError, "SolveVec", desc)
+	} else if f.singular {
+		return nil, newError(SingularError, "SolveVec", "Matrix is singular.")
+	}
+
+	x := make([]float64, f.n)
+	copy(x, b)
+	f.solveInPlace(x, 1)
+	return x, nil
+}
+
+// SolveRefined solves the linear system A X = B like Solve, but then improves
+// the solution with iterative refinement: the residual R = B - A X is
+// computed in extended precision and the correction A dX = R is added
+// to X until it becomes negligible. This recovers most of the accuracy lost
+// when A is ill-conditioned.
+//
+// If b is nil or does not have the same height as A, or if A is singular,
+// an error Matrix is returned.
+func (f *LU) SolveRefined(b *Matrix) *Matrix {
+	if err := inputError("SolveRefined", b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	b = dense(b)
+	x := New(b.width, b.height).solveLU("SolveRefined", f, b)
+	if x.IsError() {
+		return x
+	}
+
+	n, w := f.n, b.width
+	r := make([]float64, n * w)
+	for iter := 0; iter < maxRefineIters; iter++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < w; j++ {
+				// Compensated evaluation of b - A x. The rounding error of
+				// each product is recovered exactly with an FMA.
+				sum, c, prodErr := b.values[i * w + j], 0.0, 0.0
+				for k := 0; k < n; k++ {
+					a, xk := f.a[i * n + k], x.values[k * w + j]
+					p := a * xk
+					prodErr += math.FMA(a, xk, -p)
+
+					y := -p - c
+					t := sum + y
+					c = (t - sum) - y
+					sum = t
+				}
+				r[i * w + j] = (sum - c) - prodErr
+			}
+		}
+
+		f.solveInPlace(r, w)
+
+		dxNorm, xNorm := 0.0, 0.0
+		for i := range r {
+			x.values[i] += r[i]
+			dxNorm = math.Max(dxNorm, math.Abs(r[i]))
+			xNorm = math.Max(xNorm, math.Abs(x.values[i]))
+		}
+		if dxNorm <= machineEpsilon * xNorm {
+			break
+		}
+	}
+
+	return x
+}
+
+// Inverse returns the inverse of the decomposed Matrix.
+//
+// If A is singular, an error Matrix is returned.
+func (f *LU) Inverse() *Matrix {
+	if f.singular {
+		return newErrorMatrix(SingularError, "Inverse", "Matrix is singular.")
+	}
+	inv := Identity(f.n)
+	f.solveInPlace(inv.values, f.n)
+	return inv
+}
+
+// checkSolve returns an error if b cannot be used as the right-hand side of
+// a linear system with f or if target cannot hold the solution.
+func (f *LU) checkSolve(operationName string, target, b *Matrix) *MatrixError {
+	if err := inputError(operationName, b); err != nil {
+		return err
+	} else if target == nil {
+		return newError(NilError, operationName, "Target Matrix is nil.")
+	} else if b.height != f.n {
+		desc := fmt.Sprintf("Right-hand side height %d does not match Matrix height %d.",
+			b.height, f.n)
+		return newError(ShapeError, operationName, desc)
+	} else if !Compatible(target, b) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match right-hand side shape (%d, %d).",
+			target.width, target.height, b.width, b.height)
+		return newError(ShapeError, operationName, desc)
+	} else if f.singular {
+		return newError(SingularError, operationName, "Matrix is singular.")
+	}
+	return nil
+}
+
+// solveInPlace overwrites the row-major (n x w) matrix x with the solution to
+// A X = x.
+func (f *LU) solveInPlace(x []float64, w int) {
+	n, lu := f.n, f.lu
+
+	// Apply the permutation.
+	tmp := make([]float64, n * w)
+	for i := 0; i < n; i++ {
+		copy(tmp[i * w: (i + 1) * w], x[f.perm[i] * w: (f.perm[i] + 1) * w])
+	}
+	copy(x, tmp)
+
+	// Forward substitution with L.
+	for i := 1; i < n; i++ {
+		xi := x[i * w: (i + 1) * w]
+		for k := 0; k < i; k++ {
+			l := lu[i * n + k]
+			if l == 0 {
+				continue
+			}
+			xk := x[k * w: (k + 1) * w]
+			for j := range xi {
+				xi[j] -= l * xk[j]
+			}
+		}
+	}
+
+	// Back substitution with U.
+	for i := n - 1; i >= 0; i-- {
+		xi := x[i * w: (i + 1) * w]
+		for k := i + 1; k < n; k++ {
+			u := lu[i * n + k]
+			if u == 0 {
+				continue
+			}
+			xk := x[k * w: (k + 1) * w]
+			for j := range xi {
+				xi[j] -= u * xk[j]
+			}
+		}
+		d := lu[i * n + i]
+		for j := range xi {
+			xi[j] /= d
+		}
+	}
+}
+
+// solveTransposeInPlace overwrites the vector x with the solution to
+// A^T y = x. Since A^T = U^T L^T P, this is a forward substitution with U^T,
+// a back substitution with L^T, and an inverse permutation.
+func (f *LU) solveTransposeInPlace(x []float64) {
+	n, lu := f.n, f.lu
+
+	for i := 0; i < n; i++ {
+		sum := x[i]
+		for k := 0; k < i; k++ {
+			sum -= lu[k * n + i] * x[k]
+		}
+		x[i] = sum / lu[i * n + i]
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		sum := x[i]
+		for k := i + 1; k < n; k++ {
+			sum -= lu[k * n + i] * x[k]
+		}
+		x[i] = sum
+	}
+
+	tmp := make([]float64, n)
+	for i, p := range f.perm {
+		tmp[p] = x[i]
+	}
+	copy(x, tmp)
+}
+
+// squareError returns an error if m is nil, an error Matrix, or not square.
+func squareError(operationName string, m *Matrix) *MatrixError {
+	if err := inputError(operationName, m); err != nil {
+		return err
+	} else if m.width != m.height {
+		desc := fmt.Sprintf("Matrix shape (%d, %d) is not square.",
+			m.width, m.height)
+		return newError(ShapeError, operationName, desc)
+	}
+	return nil
+}
+
+// Solve solves the linear system A X = B, where each column of B is a
+// separate right-hand side, and returns X.
+//
+// If a or b is nil, if a is not square, if b does not have the same height
+// as a, or if a is singular, an error Matrix is returned.
+func Solve(a, b *Matrix) *Matrix {
+	if err := inputError("Solve", a, b); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(b.width, b.height).Solve(a, b)
+}
+
+// Solve solves the linear system A X = B and stores X in the target Matrix.
+// The target Matrix is also returned.
+//
+// If a or b is nil, if a is not square, if b does not have the same height
+// as a, if target is not the same shape as b, or if a is singular, target is
+// set to an error Matrix.
+func (target *Matrix) Solve(a, b *Matrix) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.Solve(a, b) })
+	}
+
+	f, err := newLU("Solve", a)
+	if err != nil {
+		return target.setError(err)
+	}
+	return target.solveLU("Solve", f, b)
+}
+
+// SolveVec solves the linear system A x = b for a single right-hand side.
+//
+// If a is nil or not square, if b does not have the same length as the
+// height of a, or if a is singular, a non-nil error is returned.
+func SolveVec(a *Matrix, b []float64) ([]float64, error) {
+	f, err := newLU("SolveVec", a)
+	if err != nil {
+		return nil, err
+	}
+	return f.SolveVec(b)
+}
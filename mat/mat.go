@@ -2,6 +2,10 @@ package mat
 
 import (
 	"fmt"
+	"math"
+	"strings"
+
+	"github.com/phil-mansfield/num"
 )
 
 // Matrix represents a two-dimensional rectangluar array of real values.
@@ -9,6 +13,9 @@ import (
 type Matrix struct {
 	values []float64
 	width, height int
+	// stride is the distance in values between the starts of consecutive
+	// rows. It is equal to width unless the Matrix is a view.
+	stride int
 	err *MatrixError
 }
 
@@ -25,7 +32,7 @@ func New(width, height int) *Matrix {
 		return newErrorMatrix(ParameterError, "New", desc)
 	}
 
-	return &Matrix{make([]float64, width * height), width, height, nil}
+	return &Matrix{make([]float64, width * height), width, height, width, nil}
 }
 
 // Identity returns a square matrix with the given width which contains ones
@@ -86,7 +93,8 @@ func FromGrid(values [][]float64) *Matrix {
 	width := len(values[0])
 	for y := 0; y < height; y++ {
 		if width != len(values[y]) {
-			desc := fmt.Sprintf("Input grid has width of %d at row 0, but a width of %d at row %d.")
+			desc := fmt.Sprintf("Input grid has width of %d at row 0, but a width of %d at row %d.",
+				width, len(values[y]), y)
 			return newErrorMatrix(ParameterError, "FromGrid", desc)
 		}
 	}
@@ -112,59 +120,161 @@ func FromGrid(values [][]float64) *Matrix {
 
 // AlmostEqual returns true if every element in the two given arrays is equal to
 // within the library precision fraction, ConvergenceEpsilon, as defined in
-// num/config.go.
+// num/config.go. If the two matrices are not Compatible, AlmostEqual returns
+// false.
 func AlmostEqual(m1, m2 *Matrix) bool {
-	return false
+	if !Compatible(m1, m2) {
+		return false
+	}
+
+	for y := 0; y < m1.height; y++ {
+		r1, r2 := m1.row(y), m2.row(y)
+		for x := range r1 {
+			if !num.AlmostEqual(r1[x], r2[x]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Mismatch describes the element at which two matrices come closest to
+// violating the tolerances given to AlmostEqualTol.
+type Mismatch struct {
+	// X and Y are the coordinates of the element. Both are -1 if the two
+	// matrices were not Compatible.
+	X, Y int
+	// V1 and V2 are the values of the element in the two matrices.
+	V1, V2 float64
+	// Diff is |V1 - V2| and Tol is the largest difference allowed for the
+	// element.
+	Diff, Tol float64
+}
+
+// String returns a description of the mismatch which is suitable for test
+// failure messages.
+func (m Mismatch) String() string {
+	if m.X < 0 {
+		return "matrices have different shapes"
+	}
+	return fmt.Sprintf("element (%d, %d): %g and %g differ by %g, tolerance %g",
+		m.X, m.Y, m.V1, m.V2, m.Diff, m.Tol)
+}
+
+// AlmostEqualTol returns true if every pair of elements, v1 and v2, in the
+// two given matrices satisfies |v1 - v2| <= atol + rtol * max(|v1|, |v2|).
+// It also returns the element with the largest ratio of its difference to
+// its allowed difference, which is the offending element when AlmostEqualTol
+// returns false. NaNs are never equal to anything, while infinities are equal
+// to infinities of the same sign.
+//
+// If the two matrices are not Compatible, AlmostEqualTol returns false and a
+// Mismatch with negative coordinates.
+func AlmostEqualTol(m1, m2 *Matrix, rtol, atol float64) (bool, Mismatch) {
+	if !Compatible(m1, m2) {
+		return false, Mismatch{X: -1, Y: -1}
+	}
+
+	worst, worstRatio := Mismatch{}, -1.0
+	for y := 0; y < m1.height; y++ {
+		r1, r2 := m1.row(y), m2.row(y)
+		for x := range r1 {
+			v1, v2 := r1[x], r2[x]
+			diff := math.Abs(v1 - v2)
+			tol := atol + rtol * math.Max(math.Abs(v1), math.Abs(v2))
+
+			// Differences involving infinities are NaN or Inf, as is their
+			// tolerance, so they are handled separately.
+			ratio := 0.0
+			if (math.IsNaN(diff) || math.IsInf(diff, 0)) && v1 != v2 {
+				ratio = math.Inf(+1)
+			} else if diff > 0 {
+				ratio = diff / tol
+			}
+			if ratio > worstRatio {
+				worst, worstRatio = Mismatch{x, y, v1, v2, diff, tol}, ratio
+			}
+		}
+	}
+	return worstRatio <= 1, worst
 }
 
 // Compatible returns true if the two given matrices have the same shapes and
-// false otherwise. If either Matrix is nil, Compatible returns false.
+// false otherwise. If either Matrix is nil or an error Matrix, Compatible
+// returns false.
 func Compatible(m1, m2 *Matrix) bool {
-	return false
+	if !valid(m1) || !valid(m2) {
+		return false
+	}
+	return m1.width == m2.width && m1.height == m2.height
 }
 
 // MultCompatible returns true if the two given matrices can be multiplied
-// together and false otherwise. If either Matrix is nil, MultCompatible
-// returns false.
+// together and false otherwise. If either Matrix is nil or an error Matrix,
+// MultCompatible returns false.
 func MultCompatible(m1, m2 *Matrix) bool {
-	return false
+	if !valid(m1) || !valid(m2) {
+		return false
+	}
+	return m1.width == m2.height
 }
 
 // TransposeCompatible returns true if m1 is the same shape as the transpose
-// of m2 and false otherwise. If either Matrix is nil, TransposeCompatible
-// returns false.
+// of m2 and false otherwise. If either Matrix is nil or an error Matrix,
+// TransposeCompatible returns false.
 func TransposeCompatible(m1, m2 *Matrix) bool {
-	return false
+	if !valid(m1) || !valid(m2) {
+		return false
+	}
+	return m1.width == m2.height && m1.height == m2.width
+}
+
+// valid returns true if m is neither nil nor an error Matrix.
+func valid(m *Matrix) bool {
+	return m != nil && m.err == nil
 }
 
 // Height returns the height of the matrix.
 func (m *Matrix) Height() int {
-	return -1
+	return m.height
 }
 
 // Width returns the width of the matrix.
 func (m *Matrix) Width() int {
-	return -1
+	return m.width
 }
 
 // Slice returns a slice containing all the values within m. The value at the
 // zero-indexed coordinates (x, y) will be placed at index x + m.Width() * y
 // in the slice.
 func (m *Matrix) Slice() []float64 {
-	return nil
+	values := make([]float64, m.width * m.height)
+	for y := 0; y < m.height; y++ {
+		copy(values[y * m.width: (y + 1) * m.width], m.row(y))
+	}
+	return values
 }
 
 // Grid returns a 2D slice containing all the values within m. The value at
 // the zero-indexed coordinates (x, y) will be placed at index grid[y][x] in
 // the output grid.
 func (m *Matrix) Grid() [][]float64 {
-	return nil
+	grid := make([][]float64, m.height)
+	for y := 0; y < m.height; y++ {
+		grid[y] = make([]float64, m.width)
+		copy(grid[y], m.row(y))
+	}
+	return grid
 }
 
 // InBounds returns true if the (x, y) coordinate pair is within the bounds
 // of m and false otherwise.
 func (m *Matrix) InBounds(x, y int) bool {
-	return false
+	if m == nil {
+		return false
+	}
+	return x >= 0 && y >= 0 && x < m.width && y < m.height
 }
 
 // Get returns the element of the matrix with coordinates (x, y).
@@ -172,7 +282,8 @@ func (m *Matrix) InBounds(x, y int) bool {
 // Get and Set are unique in that they panic upon out of bounds input instead
 // of returning an error.
 func (m *Matrix) Get(x, y int) float64 {
-	return 0.0
+	m.checkBounds(x, y, "Get")
+	return m.values[y * m.stride + x]
 }
 
 // Set changes the element in the matrix with coordinates (x, y) so that it
@@ -181,27 +292,62 @@ func (m *Matrix) Get(x, y int) float64 {
 // Get and Set are unique in that they panic upon out of bounds input instead
 // of returning an error.
 func (m *Matrix) Set(x, y int, val float64) {
-	return
+	m.checkBounds(x, y, "Set")
+	m.values[y * m.stride + x] = val
+}
+
+// checkBounds panics if m is nil or if (x, y) is out of bounds.
+func (m *Matrix) checkBounds(x, y int, operationName string) {
+	if m == nil {
+		panic(fmt.Sprintf("mat.%s called on nil Matrix.", operationName))
+	} else if !m.InBounds(x, y) {
+		panic(fmt.Sprintf("mat.%s given coordinates (%d, %d), which are "+
+			"out of bounds for a %d by %d Matrix.",
+			operationName, x, y, m.width, m.height))
+	}
 }
 
 // Print prints the contents of the matrix as a comma-separated array of
 // arrays. Each row in the matrix is given its own line.
 func (m *Matrix) Print() {
-	return
+	m.Printf("%g")
 }
 
 // Printf prints the contents of of the matrix as a comma-separated array of
 // arrays where each element is formatted according to the given format string.
 // Each row in the matrix is given its own line.
-func (m *Matrix) Printf(fmt string) {
-	return
+func (m *Matrix) Printf(format string) {
+	fmt.Println(m.sprintf(format))
+}
+
+// sprintf returns the string printed by m.Printf(format), without the
+// trailing newline. nil and error matrices are written as "[]".
+func (m *Matrix) sprintf(format string) string {
+	if !valid(m) {
+		return "[]"
+	}
+
+	rows := make([]string, m.height)
+	elems := make([]string, m.width)
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			elems[x] = fmt.Sprintf(format, m.values[y * m.stride + x])
+		}
+		rows[y] = "[" + strings.Join(elems, ", ") + "]"
+	}
+
+	return "[" + strings.Join(rows, ",\n ") + "]"
 }
 
 // Copy returns a copy of m.
 //
 // If m is nil, Copy returns an error Matrix.
 func Copy(m *Matrix) *Matrix {
-	return nil
+	if err := inputError("Copy", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+
+	return New(m.width, m.height).Copy(m)
 }
 
 // Copy copies the values in m to target. The target matrix is also returned.
@@ -209,5 +355,22 @@ func Copy(m *Matrix) *Matrix {
 // If target is not the same shape as m or if m is nil, target is set to an
 // error Matrix.
 func (target *Matrix) Copy(m *Matrix) *Matrix {
-	return nil
+	if err := inputError("Copy", m); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "Copy", "Target Matrix is nil.")
+	} else if target.width != m.width || target.height != m.height {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match input shape (%d, %d).",
+			target.width, target.height, m.width, m.height)
+		return target.setError(newError(ShapeError, "Copy", desc))
+	}
+
+	if overlaps(target, m) {
+		m = compact(m)
+	}
+	for y := 0; y < m.height; y++ {
+		copy(target.row(y), m.row(y))
+	}
+	target.err = nil
+	return target
 }
@@ -1 +1,278 @@
 package mat
+
+import (
+	"testing"
+)
+
+func sliceEq(xs, ys []float64) bool {
+	if len(xs) != len(ys) {
+		return false
+	}
+	for i := range xs {
+		if xs[i] != ys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// panics returns true if f panics.
+func panics(f func()) (didPanic bool) {
+	defer func() {
+		if recover() != nil {
+			didPanic = true
+		}
+	}()
+	f()
+	return false
+}
+
+// errorCode returns the error code of m, or 0 if m is not an error Matrix.
+func errorCode(m *Matrix) int {
+	if !m.IsError() {
+		return 0
+	}
+	return m.MatrixError().Code
+}
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		name          string
+		m             *Matrix
+		width, height int
+		values        []float64
+		code          int
+	}{
+		{"New(2, 3)", New(2, 3), 2, 3, []float64{0, 0, 0, 0, 0, 0}, 0},
+		{"New(0, 3)", New(0, 3), 0, 0, []float64{}, ParameterError},
+		{"New(2, -1)", New(2, -1), 0, 0, []float64{}, ParameterError},
+		{"Identity(2)", Identity(2), 2, 2, []float64{1, 0, 0, 1}, 0},
+		{"Identity(0)", Identity(0), 0, 0, []float64{}, ParameterError},
+		{"FromSlice(3, 2)", FromSlice(3, 2, []float64{1, 2, 3, 4, 5, 6}),
+			3, 2, []float64{1, 2, 3, 4, 5, 6}, 0},
+		{"FromSlice(2, 2)", FromSlice(2, 2, []float64{1, 2, 3}),
+			0, 0, []float64{}, ParameterError},
+		{"FromGrid(3x2)", FromGrid([][]float64{{1, 2, 3}, {4, 5, 6}}),
+			3, 2, []float64{1, 2, 3, 4, 5, 6}, 0},
+		{"FromGrid(ragged)", FromGrid([][]float64{{1, 2}, {3}}),
+			0, 0, []float64{}, ParameterError},
+		{"FromGrid(empty)", FromGrid([][]float64{}),
+			0, 0, []float64{}, ParameterError},
+		{"FromGrid(empty rows)", FromGrid([][]float64{{}, {}}),
+			0, 0, []float64{}, ParameterError},
+	}
+
+	for _, test := range tests {
+		m := test.m
+		if m.Width() != test.width || m.Height() != test.height {
+			t.Errorf("%s has shape (%d, %d), wanted (%d, %d)", test.name,
+				m.Width(), m.Height(), test.width, test.height)
+		} else if !sliceEq(m.Slice(), test.values) {
+			t.Errorf("%s has values %v, wanted %v",
+				test.name, m.Slice(), test.values)
+		} else if errorCode(m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(m), test.code)
+		}
+	}
+}
+
+func TestGetSet(t *testing.T) {
+	m := FromSlice(3, 2, []float64{1, 2, 3, 4, 5, 6})
+
+	tests := []struct {
+		x, y  int
+		val   float64
+		valid bool
+	}{
+		{0, 0, 1, true},
+		{2, 0, 3, true},
+		{0, 1, 4, true},
+		{2, 1, 6, true},
+		{3, 0, 0, false},
+		{0, 2, 0, false},
+		{-1, 0, 0, false},
+		{0, -1, 0, false},
+	}
+
+	for _, test := range tests {
+		if m.InBounds(test.x, test.y) != test.valid {
+			t.Errorf("m.InBounds(%d, %d) = %v, wanted %v",
+				test.x, test.y, !test.valid, test.valid)
+		}
+
+		if !test.valid {
+			if !panics(func() { m.Get(test.x, test.y) }) {
+				t.Errorf("m.Get(%d, %d) did not panic.", test.x, test.y)
+			}
+			if !panics(func() { m.Set(test.x, test.y, 0) }) {
+				t.Errorf("m.Set(%d, %d) did not panic.", test.x, test.y)
+			}
+			continue
+		}
+
+		if val := m.Get(test.x, test.y); val != test.val {
+			t.Errorf("m.Get(%d, %d) = %g, wanted %g",
+				test.x, test.y, val, test.val)
+		}
+		m.Set(test.x, test.y, -test.val)
+		if val := m.Get(test.x, test.y); val != -test.val {
+			t.Errorf("m.Get(%d, %d) = %g after Set, wanted %g",
+				test.x, test.y, val, -test.val)
+		}
+	}
+
+	var nilMatrix *Matrix
+	if !panics(func() { nilMatrix.Get(0, 0) }) {
+		t.Errorf("Get did not panic on nil Matrix.")
+	} else if !panics(func() { nilMatrix.Set(0, 0, 1) }) {
+		t.Errorf("Set did not panic on nil Matrix.")
+	} else if nilMatrix.InBounds(0, 0) {
+		t.Errorf("InBounds returned true for nil Matrix.")
+	}
+}
+
+func TestSliceGrid(t *testing.T) {
+	values := []float64{4, 8, 15, 16, 23, 42}
+	m := FromSlice(3, 2, values)
+
+	slice := m.Slice()
+	grid := m.Grid()
+	if !sliceEq(slice, values) {
+		t.Errorf("m.Slice() = %v, wanted %v", slice, values)
+	}
+	if len(grid) != 2 || !sliceEq(grid[0], values[:3]) ||
+		!sliceEq(grid[1], values[3:]) {
+		t.Errorf("m.Grid() = %v, wanted %v", grid, values)
+	}
+
+	// Neither the input nor the outputs should share memory with m.
+	values[0], slice[1], grid[1][0] = -1, -1, -1
+	if !sliceEq(m.Slice(), []float64{4, 8, 15, 16, 23, 42}) {
+		t.Errorf("m shares memory with its inputs or outputs: %v", m.Slice())
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	a, b, c := New(3, 2), New(3, 2), New(2, 3)
+	var nilMatrix *Matrix
+	errMatrix := New(-1, -1)
+
+	tests := []struct {
+		name               string
+		m1, m2             *Matrix
+		compat, mult, tran bool
+	}{
+		{"(3x2, 3x2)", a, b, true, false, false},
+		{"(3x2, 2x3)", a, c, false, true, true},
+		{"(2x3, 3x2)", c, a, false, true, true},
+		{"(3x3, 3x3)", New(3, 3), Identity(3), true, true, true},
+		{"(nil, 3x2)", nilMatrix, a, false, false, false},
+		{"(3x2, nil)", a, nilMatrix, false, false, false},
+		{"(error, error)", errMatrix, errMatrix, false, false, false},
+	}
+
+	for _, test := range tests {
+		if Compatible(test.m1, test.m2) != test.compat {
+			t.Errorf("Compatible%s = %v, wanted %v",
+				test.name, !test.compat, test.compat)
+		}
+		if MultCompatible(test.m1, test.m2) != test.mult {
+			t.Errorf("MultCompatible%s = %v, wanted %v",
+				test.name, !test.mult, test.mult)
+		}
+		if TransposeCompatible(test.m1, test.m2) != test.tran {
+			t.Errorf("TransposeCompatible%s = %v, wanted %v",
+				test.name, !test.tran, test.tran)
+		}
+	}
+}
+
+func TestAlmostEqual(t *testing.T) {
+	a := FromSlice(2, 2, []float64{1, 2, 3, 4})
+
+	tests := []struct {
+		name   string
+		m1, m2 *Matrix
+		eq     bool
+	}{
+		{"identical", a, FromSlice(2, 2, []float64{1, 2, 3, 4}), true},
+		{"close", a, FromSlice(2, 2, []float64{1, 2, 3, 4 + 1e-9}), true},
+		{"far", a, FromSlice(2, 2, []float64{1, 2, 3, 4.1}), false},
+		{"shape", a, FromSlice(4, 1, []float64{1, 2, 3, 4}), false},
+		{"nil", a, nil, false},
+	}
+
+	for _, test := range tests {
+		if AlmostEqual(test.m1, test.m2) != test.eq {
+			t.Errorf("AlmostEqual(%s) = %v, wanted %v",
+				test.name, !test.eq, test.eq)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	a := FromSlice(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	errMatrix := New(0, 1)
+
+	b := Copy(a)
+	b.Set(0, 0, -1)
+	if a.Get(0, 0) != 1 {
+		t.Errorf("Copy(a) shares memory with a.")
+	}
+
+	target := New(3, 2)
+	if res := target.Copy(a); res != target || !AlmostEqual(target, a) {
+		t.Errorf("target.Copy(a) gave %v, wanted %v",
+			target.Slice(), a.Slice())
+	}
+
+	tests := []struct {
+		name string
+		m    *Matrix
+		code int
+	}{
+		{"Copy(nil)", Copy(nil), NilError},
+		{"Copy(error)", Copy(errMatrix), ParameterError},
+		{"New(2, 2).Copy(a)", New(2, 2).Copy(a), ShapeError},
+		{"New(3, 2).Copy(nil)", New(3, 2).Copy(nil), NilError},
+		{"New(3, 2).Copy(error)", New(3, 2).Copy(errMatrix), ParameterError},
+	}
+
+	for _, test := range tests {
+		if errorCode(test.m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(test.m), test.code)
+		} else if test.m.Width() != 0 || test.m.Height() != 0 {
+			t.Errorf("%s is an error Matrix with shape (%d, %d)",
+				test.name, test.m.Width(), test.m.Height())
+		}
+	}
+
+	// Errors are propagated, so the operation name should be from the
+	// original error.
+	if name := Copy(errMatrix).MatrixError().OperationName; name != "New" {
+		t.Errorf("Copy(error) has operation name %s, wanted New", name)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		m      *Matrix
+		format string
+		out    string
+	}{
+		{FromSlice(3, 2, []float64{4, 8, 15, 16, 23, 42}), "%g",
+			"[[4, 8, 15],\n [16, 23, 42]]"},
+		{FromSlice(1, 1, []float64{0.5}), "%.3f", "[[0.500]]"},
+		{FromSlice(1, 2, []float64{1, 2}), "%g", "[[1],\n [2]]"},
+		{New(-1, 1), "%g", "[]"},
+	}
+
+	for _, test := range tests {
+		if out := test.m.sprintf(test.format); out != test.out {
+			t.Errorf("m.Printf(%q) printed %q, wanted %q",
+				test.format, out, test.out)
+		}
+	}
+}
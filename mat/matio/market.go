@@ -0,0 +1,475 @@
+package matio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/mat/cmat"
+	"github.com/phil-mansfield/num/vec"
+)
+
+const (
+	marketName = "Matrix Market"
+	marketBanner = "%%MatrixMarket"
+	// maxMarketLine is the longest line that ReadMarket will accept.
+	maxMarketLine = 1 << 20
+)
+
+// MarketFormat describes how a Matrix is written in the Matrix Market
+// format. The zero value writes every element in the dense array layout.
+type MarketFormat struct {
+	// Coordinate writes only the non-zero elements, in the sparse
+	// coordinate layout.
+	Coordinate bool
+	// Symmetric writes only the lower triangle of the Matrix. The Matrix
+	// must be symmetric, or for complex Matrices, Hermitian.
+	Symmetric bool
+}
+
+// marketHeader is the parsed banner line of a Matrix Market file.
+type marketHeader struct {
+	coordinate bool
+	field, symmetry string
+}
+
+// ReadMarket reads a real Matrix in the Matrix Market format from r. Both the
+// array and coordinate layouts and all symmetries are supported, as are the
+// real, integer, and pattern fields. The elements of pattern matrices are
+// one. Duplicate coordinate entries are summed.
+//
+// If the data is complex, use ReadMarketComplex instead.
+func ReadMarket(r io.Reader) (*mat.Matrix, error) {
+	a, err := readMarket(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.matrix(marketName)
+}
+
+// ReadMarketComplex reads a complex Matrix in the Matrix Market format from
+// r. Data with real, integer, or pattern fields is also accepted. See
+// ReadMarket.
+func ReadMarketComplex(r io.Reader) (*cmat.Matrix, error) {
+	a, err := readMarket(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.cmatrix(marketName)
+}
+
+// ReadMarketVector reads a real Matrix Market Matrix with a single row or a
+// single column from r and returns it as a Vector. See ReadMarket.
+func ReadMarketVector(r io.Reader) (vec.Vector, error) {
+	a, err := readMarket(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.vector(marketName)
+}
+
+// WriteMarket writes m to w in the Matrix Market format with the real field.
+// Values are written with the minimum number of digits needed to read them
+// back exactly.
+//
+// If m is nil or an error Matrix, or if format.Symmetric is set and m is not
+// symmetric, an error of type *mat.MatrixError is returned.
+func WriteMarket(w io.Writer, m *mat.Matrix, format MarketFormat) error {
+	a, err := matrixArray("WriteMarket", m)
+	if err != nil {
+		return err
+	}
+	return writeMarket("WriteMarket", w, a, format)
+}
+
+// WriteMarketComplex writes m to w in the Matrix Market format with the
+// complex field. If format.Symmetric is set, m is written with Hermitian
+// symmetry. See WriteMarket.
+func WriteMarketComplex(w io.Writer, m *cmat.Matrix, format MarketFormat) error {
+	a, err := cmatrixArray("WriteMarketComplex", m)
+	if err != nil {
+		return err
+	}
+	return writeMarket("WriteMarketComplex", w, a, format)
+}
+
+// WriteMarketVector writes v to w as a Matrix Market array with a single
+// column.
+//
+// If v is empty, an error of type *mat.MatrixError is returned.
+func WriteMarketVector(w io.Writer, v vec.Vector) error {
+	a, err := vectorArray("WriteMarketVector", v)
+	if err != nil {
+		return err
+	}
+	return writeMarket("WriteMarketVector", w, a, MarketFormat{})
+}
+
+// marketScanner returns the fields of the lines of a Matrix Market file,
+// skipping comments and blank lines.
+type marketScanner struct {
+	sc *bufio.Scanner
+	line int
+}
+
+// next returns the fields of the next line which is not a comment or blank.
+// If there are no more lines, nil is returned and the caller should check
+// sc.Err().
+func (s *marketScanner) next() []string {
+	for s.sc.Scan() {
+		s.line++
+		text := strings.TrimSpace(s.sc.Text())
+		if len(text) == 0 || text[0] == '%' {
+			continue
+		}
+		return strings.Fields(text)
+	}
+	return nil
+}
+
+// eof returns the error for a file which ended early.
+func (s *marketScanner) eof(what string) error {
+	if err := s.sc.Err(); err != nil {
+		return err
+	}
+	return formatError(marketName, s.line, "File ended while reading %s.", what)
+}
+
+// readMarket reads a Matrix Market file into an array.
+func readMarket(r io.Reader) (*array, error) {
+	s := &marketScanner{sc: bufio.NewScanner(r)}
+	s.sc.Buffer(nil, maxMarketLine)
+
+	if !s.sc.Scan() {
+		return nil, s.eof("the header")
+	}
+	s.line++
+	h, err := parseMarketHeader(s.sc.Text())
+	if err != nil {
+		return nil, err
+	}
+
+	fields := s.next()
+	if fields == nil {
+		return nil, s.eof("the size line")
+	}
+	sizes := 3
+	if !h.coordinate {
+		sizes = 2
+	}
+	if len(fields) != sizes {
+		return nil, formatError(marketName, s.line,
+			"Size line has %d fields, expected %d.", len(fields), sizes)
+	}
+	dims := make([]int, sizes)
+	for i, field := range fields {
+		if dims[i], err = strconv.Atoi(field); err != nil || dims[i] < 0 {
+			return nil, formatError(marketName, s.line,
+				"Invalid size %q.", field)
+		}
+	}
+
+	a := &array{width: dims[1], height: dims[0]}
+	if a.height > 0 && a.width > math.MaxInt32 / a.height {
+		return nil, formatError(marketName, s.line,
+			"Matrix shape (%d, %d) is too large.", a.height, a.width)
+	}
+	if h.symmetry != "general" && a.width != a.height {
+		return nil, formatError(marketName, s.line,
+			"A %s Matrix must be square, but has shape (%d, %d).",
+			h.symmetry, a.height, a.width)
+	}
+	a.re = make([]float64, a.width * a.height)
+	if h.field == "complex" {
+		a.im = make([]float64, a.width * a.height)
+	}
+
+	if h.coordinate {
+		err = readMarketCoordinate(s, h, a, dims[2])
+	} else {
+		err = readMarketArray(s, h, a)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if fields := s.next(); fields != nil {
+		return nil, formatError(marketName, s.line,
+			"Unexpected data after the final entry.")
+	} else if err := s.sc.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// parseMarketHeader parses the banner line of a Matrix Market file.
+func parseMarketHeader(line string) (*marketHeader, error) {
+	fields := strings.Fields(strings.ToLower(line))
+	if len(fields) != 5 || fields[0] != strings.ToLower(marketBanner) {
+		return nil, formatError(marketName, 1,
+			"File does not begin with a %s header.", marketBanner)
+	} else if fields[1] != "matrix" {
+		return nil, formatError(marketName, 1,
+			"Unsupported object %q.", fields[1])
+	}
+
+	h := &marketHeader{field: fields[3], symmetry: fields[4]}
+	switch fields[2] {
+	case "coordinate":
+		h.coordinate = true
+	case "array":
+	default:
+		return nil, formatError(marketName, 1, "Unknown layout %q.", fields[2])
+	}
+
+	switch h.field {
+	case "double":
+		h.field = "real"
+	case "real", "integer", "complex":
+	case "pattern":
+		if !h.coordinate {
+			return nil, formatError(marketName, 1,
+				"The pattern field requires the coordinate layout.")
+		}
+	default:
+		return nil, formatError(marketName, 1, "Unknown field %q.", h.field)
+	}
+
+	switch h.symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	case "hermitian":
+		if h.field != "complex" {
+			return nil, formatError(marketName, 1,
+				"Hermitian symmetry requires the complex field.")
+		}
+	default:
+		return nil, formatError(marketName, 1,
+			"Unknown symmetry %q.", h.symmetry)
+	}
+	return h, nil
+}
+
+// valueFields returns the number of fields used to store a value.
+func (h *marketHeader) valueFields() int {
+	switch h.field {
+	case "pattern":
+		return 0
+	case "complex":
+		return 2
+	default:
+		return 1
+	}
+}
+
+// parseValue parses the value stored in fields.
+func (h *marketHeader) parseValue(s *marketScanner, fields []string) (re, im float64, err error) {
+	if h.field == "pattern" {
+		return 1, 0, nil
+	}
+	if re, err = strconv.ParseFloat(fields[0], 64); err != nil {
+		return 0, 0, formatError(marketName, s.line, "Invalid value %q.", fields[0])
+	}
+	if h.field == "complex" {
+		if im, err = strconv.ParseFloat(fields[1], 64); err != nil {
+			return 0, 0, formatError(marketName, s.line,
+				"Invalid value %q.", fields[1])
+		}
+	}
+	return re, im, nil
+}
+
+// add adds the value (re, im) to the element (x, y) of a and to its mirror
+// image, as required by the symmetry of h.
+func (h *marketHeader) add(a *array, x, y int, re, im float64) {
+	i := y * a.width + x
+	a.re[i] += re
+	if a.im != nil {
+		a.im[i] += im
+	}
+	if x == y {
+		return
+	}
+
+	switch h.symmetry {
+	case "symmetric":
+		a.re[x * a.width + y] += re
+		if a.im != nil {
+			a.im[x * a.width + y] += im
+		}
+	case "skew-symmetric":
+		a.re[x * a.width + y] -= re
+		if a.im != nil {
+			a.im[x * a.width + y] -= im
+		}
+	case "hermitian":
+		a.re[x * a.width + y] += re
+		a.im[x * a.width + y] -= im
+	}
+}
+
+// readMarketArray reads the entries of an array layout file into a. The
+// entries are stored in column-major order and only the lower triangle of
+// symmetric matrices is stored.
+func readMarketArray(s *marketScanner, h *marketHeader, a *array) error {
+	nFields := h.valueFields()
+	for x := 0; x < a.width; x++ {
+		y0 := 0
+		switch h.symmetry {
+		case "symmetric", "hermitian":
+			y0 = x
+		case "skew-symmetric":
+			y0 = x + 1
+		}
+
+		for y := y0; y < a.height; y++ {
+			fields := s.next()
+			if fields == nil {
+				return s.eof("the entries")
+			} else if len(fields) != nFields {
+				return formatError(marketName, s.line,
+					"Entry has %d fields, expected %d.", len(fields), nFields)
+			}
+			re, im, err := h.parseValue(s, fields)
+			if err != nil {
+				return err
+			}
+			h.add(a, x, y, re, im)
+		}
+	}
+	return nil
+}
+
+// readMarketCoordinate reads the nnz entries of a coordinate layout file
+// into a.
+func readMarketCoordinate(s *marketScanner, h *marketHeader, a *array, nnz int) error {
+	nFields := 2 + h.valueFields()
+	for k := 0; k < nnz; k++ {
+		fields := s.next()
+		if fields == nil {
+			return s.eof("the entries")
+		} else if len(fields) != nFields {
+			return formatError(marketName, s.line,
+				"Entry has %d fields, expected %d.", len(fields), nFields)
+		}
+
+		y, errY := strconv.Atoi(fields[0])
+		x, errX := strconv.Atoi(fields[1])
+		if errY != nil || errX != nil || y < 1 || y > a.height ||
+			x < 1 || x > a.width {
+			return formatError(marketName, s.line,
+				"Invalid index (%s, %s) for a Matrix of shape (%d, %d).",
+				fields[0], fields[1], a.height, a.width)
+		} else if x == y && h.symmetry == "skew-symmetric" {
+			return formatError(marketName, s.line,
+				"Diagonal entry in a skew-symmetric Matrix.")
+		}
+
+		re, im, err := h.parseValue(s, fields[2:])
+		if err != nil {
+			return err
+		}
+		h.add(a, x - 1, y - 1, re, im)
+	}
+	return nil
+}
+
+// writeMarket writes a to w in the Matrix Market format.
+func writeMarket(operationName string, w io.Writer, a *array, format MarketFormat) error {
+	field, symmetry := "real", "general"
+	if a.im != nil {
+		field = "complex"
+	}
+	if format.Symmetric {
+		symmetry = "symmetric"
+		if a.im != nil {
+			symmetry = "hermitian"
+		}
+		if err := checkSymmetric(operationName, a); err != nil {
+			return err
+		}
+	}
+	layout := "array"
+	if format.Coordinate {
+		layout = "coordinate"
+	}
+
+	// inFile reports whether the element (x, y) is written.
+	inFile := func(x, y int) bool {
+		i := y * a.width + x
+		if format.Symmetric && x > y {
+			return false
+		} else if format.Coordinate {
+			return a.re[i] != 0 || (a.im != nil && a.im[i] != 0)
+		}
+		return true
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s matrix %s %s %s\n", marketBanner, layout, field, symmetry)
+	if format.Coordinate {
+		nnz := 0
+		for y := 0; y < a.height; y++ {
+			for x := 0; x < a.width; x++ {
+				if inFile(x, y) {
+					nnz++
+				}
+			}
+		}
+		fmt.Fprintf(bw, "%d %d %d\n", a.height, a.width, nnz)
+	} else {
+		fmt.Fprintf(bw, "%d %d\n", a.height, a.width)
+	}
+
+	// Entries are written in column-major order, as required by the array
+	// layout and expected by most readers of the coordinate layout.
+	buf := []byte{}
+	for x := 0; x < a.width; x++ {
+		for y := 0; y < a.height; y++ {
+			if !inFile(x, y) {
+				continue
+			}
+			buf = buf[:0]
+			if format.Coordinate {
+				buf = strconv.AppendInt(buf, int64(y + 1), 10)
+				buf = append(buf, ' ')
+				buf = strconv.AppendInt(buf, int64(x + 1), 10)
+				buf = append(buf, ' ')
+			}
+			i := y * a.width + x
+			buf = strconv.AppendFloat(buf, a.re[i], 'g', -1, 64)
+			if a.im != nil {
+				buf = append(buf, ' ')
+				buf = strconv.AppendFloat(buf, a.im[i], 'g', -1, 64)
+			}
+			buf = append(buf, '\n')
+			bw.Write(buf)
+		}
+	}
+	return bw.Flush()
+}
+
+// checkSymmetric returns an error if a is not exactly symmetric or, if a is
+// complex, Hermitian.
+func checkSymmetric(operationName string, a *array) error {
+	if a.width != a.height {
+		desc := fmt.Sprintf("Matrix shape (%d, %d) is not square.",
+			a.width, a.height)
+		return mat.NewError(mat.ShapeError, "matio." + operationName, desc)
+	}
+
+	for y := 0; y < a.height; y++ {
+		for x := 0; x < y; x++ {
+			i, j := y * a.width + x, x * a.width + y
+			if a.re[i] != a.re[j] || (a.im != nil && a.im[i] != -a.im[j]) {
+				desc := fmt.Sprintf("Elements (%d, %d) and (%d, %d) break symmetry.",
+					x, y, y, x)
+				return mat.NewError(mat.ParameterError, "matio." + operationName, desc)
+			}
+		}
+	}
+	return nil
+}
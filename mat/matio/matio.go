@@ -0,0 +1,164 @@
+/*
+package matio reads and writes Matrices and Vectors in file formats shared
+with other numerical software, so that data can be exchanged with Python and
+with external solvers without custom converters.
+
+Three formats are supported:
+
+Matrix Market (ReadMarket, WriteMarket, and friends) is the text exchange
+format used by most sparse solvers and by scipy.io.mmread/mmwrite. Both the
+dense "array" and sparse "coordinate" layouts are read and written, as are the
+real, integer, complex, and pattern fields and the general, symmetric,
+skew-symmetric, and Hermitian symmetries. Complex data is read into and
+written from cmat.Matrices.
+
+NumPy's binary .npy and .npz formats (ReadNpy, WriteNpy, ReadNpz, WriteNpz,
+and friends) are the formats used by numpy.save, numpy.load and numpy.savez.
+Arrays of float64, float32, and signed or unsigned integers in either byte
+order and in either C or Fortran order can be read. Arrays are always written
+as little-endian float64 in C order.
+
+Plain whitespace-separated tables (ReadTable and WriteTable) are the format
+used by numpy.loadtxt/savetxt and most plotting tools. Lines which are empty
+or which start with '#' are ignored.
+
+	f, err := os.Open("a.mtx")
+	if err != nil {
+		// Error handling.
+	}
+	defer f.Close()
+	a, err := matio.ReadMarket(f)
+
+In every format a Matrix with width w and height h corresponds to an array
+with h rows and w columns. Vectors are read from any array with a single row
+or a single column, and are written as one-dimensional arrays (or as a single
+column where the format has no one-dimensional arrays).
+
+Malformed input results in errors of type *FormatError. Nil or error Matrices
+passed to writers result in errors of type *mat.MatrixError. Errors from the
+underlying io.Reader or io.Writer are returned unchanged.
+*/
+package matio
+
+import (
+	"fmt"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/mat/cmat"
+	"github.com/phil-mansfield/num/vec"
+)
+
+// FormatError is the error returned when input does not follow the format it
+// is being read as.
+type FormatError struct {
+	Format string // name of the format being read
+	Line int // line on which the error occured, or 0 for binary formats
+	Description string // description of the specifics of the error
+}
+
+// Error returns a string representation of err.
+func (err *FormatError) Error() string {
+	if err.Line > 0 {
+		return fmt.Sprintf("matio: %s line %d: %s", err.Format, err.Line,
+			err.Description)
+	}
+	return fmt.Sprintf("matio: %s: %s", err.Format, err.Description)
+}
+
+// formatError returns a new *FormatError. desc is formatted with args as in
+// fmt.Sprintf.
+func formatError(format string, line int, desc string, args ...interface{}) *FormatError {
+	return &FormatError{format, line, fmt.Sprintf(desc, args...)}
+}
+
+// array is the format-independent representation of data being read or
+// written: a row-major height x width array with real parts re and, for
+// complex data, imaginary parts im. One-dimensional arrays have oneD set and
+// are stored as a single column.
+type array struct {
+	width, height int
+	re, im []float64
+	oneD bool
+}
+
+// matrix converts a to a Matrix. It is an error for a to be empty or
+// complex.
+func (a *array) matrix(format string) (*mat.Matrix, error) {
+	if a.width * a.height == 0 {
+		return nil, formatError(format, 0, "Array is empty.")
+	} else if a.im != nil {
+		return nil, formatError(format, 0,
+			"Data is complex; it must be read into a cmat.Matrix.")
+	}
+	return mat.FromSlice(a.width, a.height, a.re), nil
+}
+
+// cmatrix converts a to a cmat.Matrix. It is an error for a to be empty.
+func (a *array) cmatrix(format string) (*cmat.Matrix, error) {
+	if a.width * a.height == 0 {
+		return nil, formatError(format, 0, "Array is empty.")
+	}
+
+	values := make([]complex128, len(a.re))
+	for i := range values {
+		values[i] = complex(a.re[i], 0)
+		if a.im != nil {
+			values[i] = complex(a.re[i], a.im[i])
+		}
+	}
+	return cmat.FromSlice(a.width, a.height, values), nil
+}
+
+// vector converts a to a Vector. It is an error for a to be empty, to be
+// complex, or to have more than one row and more than one column.
+func (a *array) vector(format string) (vec.Vector, error) {
+	if a.width * a.height == 0 {
+		return nil, formatError(format, 0, "Array is empty.")
+	} else if a.im != nil {
+		return nil, formatError(format, 0,
+			"Data is complex; it cannot be read into a Vector.")
+	} else if a.width != 1 && a.height != 1 {
+		return nil, formatError(format, 0,
+			"Array has shape (%d, %d); a Vector must have a single row or column.",
+			a.height, a.width)
+	}
+	return vec.Vector(a.re), nil
+}
+
+// matrixArray converts m to an array.
+func matrixArray(operationName string, m *mat.Matrix) (*array, error) {
+	if m == nil {
+		return nil, mat.NewError(mat.NilError, "matio." + operationName, "Input Matrix is nil.")
+	} else if m.IsError() {
+		return nil, m.MatrixError()
+	}
+	return &array{width: m.Width(), height: m.Height(), re: m.Slice()}, nil
+}
+
+// cmatrixArray converts m to a complex array.
+func cmatrixArray(operationName string, m *cmat.Matrix) (*array, error) {
+	if m == nil {
+		return nil, mat.NewError(mat.NilError, "matio." + operationName, "Input Matrix is nil.")
+	} else if m.IsError() {
+		return nil, m.MatrixError()
+	}
+
+	values := m.Slice()
+	a := &array{
+		width: m.Width(), height: m.Height(),
+		re: make([]float64, len(values)), im: make([]float64, len(values)),
+	}
+	for i, v := range values {
+		a.re[i], a.im[i] = real(v), imag(v)
+	}
+	return a, nil
+}
+
+// vectorArray converts v to a one-dimensional array.
+func vectorArray(operationName string, v vec.Vector) (*array, error) {
+	if len(v) == 0 {
+		return nil, mat.NewError(mat.ParameterError, "matio." + operationName,
+			"Input Vector is empty.")
+	}
+	return &array{width: 1, height: len(v), re: v, oneD: true}, nil
+}
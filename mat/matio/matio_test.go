@@ -0,0 +1,378 @@
+package matio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/mat/cmat"
+	"github.com/phil-mansfield/num/vec"
+)
+
+func vecEq(v1, v2 vec.Vector) bool {
+	if len(v1) != len(v2) {
+		return false
+	}
+	for i := range v1 {
+		if v1[i] != v2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReadMarket(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		exp  *mat.Matrix
+	}{
+		{"coordinate", `%%MatrixMarket matrix coordinate real general
+% A comment.
+3 2 4
+1 1 1.5
+3 2 -2
+2 1 1e2
+3 2 1
+`, mat.FromSlice(2, 3, []float64{1.5, 0, 100, 0, 0, -1})},
+		{"array", `%%MatrixMarket matrix array integer general
+2 3
+1
+4
+2
+5
+3
+6
+`, mat.FromSlice(3, 2, []float64{1, 2, 3, 4, 5, 6})},
+		{"symmetric array", `%%MatrixMarket matrix array real symmetric
+2 2
+1
+2
+3
+`, mat.FromSlice(2, 2, []float64{1, 2, 2, 3})},
+		{"skew-symmetric", `%%MatrixMarket matrix coordinate real skew-symmetric
+3 3 2
+2 1 4
+3 2 5
+`, mat.FromSlice(3, 3, []float64{0, -4, 0, 4, 0, -5, 0, 5, 0})},
+		{"pattern", `%%MATRIXMARKET Matrix Coordinate Pattern Symmetric
+2 2 2
+1 1
+2 1
+`, mat.FromSlice(2, 2, []float64{1, 1, 1, 0})},
+	}
+
+	for _, test := range tests {
+		m, err := ReadMarket(strings.NewReader(test.text))
+		if err != nil {
+			t.Errorf("%s: ReadMarket returned error: %s", test.name, err)
+		} else if !mat.AlmostEqual(m, test.exp) {
+			t.Errorf("%s: ReadMarket gave %v, expected %v", test.name,
+				m.Grid(), test.exp.Grid())
+		}
+	}
+
+	herm := `%%MatrixMarket matrix coordinate complex hermitian
+2 2 2
+1 1 1 0
+2 1 2 3
+`
+	cm, err := ReadMarketComplex(strings.NewReader(herm))
+	exp := cmat.FromSlice(2, 2, []complex128{1, 2 - 3i, 2 + 3i, 0})
+	if err != nil || !cmat.AlmostEqual(cm, exp) {
+		t.Errorf("ReadMarketComplex gave %v, %v", cm, err)
+	}
+	if _, err := ReadMarket(strings.NewReader(herm)); err == nil {
+		t.Errorf("ReadMarket of complex data did not return an error")
+	}
+
+	v, err := ReadMarketVector(strings.NewReader(
+		"%%MatrixMarket matrix array real general\n1 3\n1\n2\n3\n"))
+	if err != nil || !vecEq(v, vec.Vector{1, 2, 3}) {
+		t.Errorf("ReadMarketVector gave %v, %v", v, err)
+	}
+
+	errTests := []string{
+		"",
+		"%%MatrixMarket matrix array real\n1 1\n1\n",
+		"%%MatrixMarket vector array real general\n1 1\n1\n",
+		"%%MatrixMarket matrix array pattern general\n1 1\n",
+		"%%MatrixMarket matrix array real hermitian\n1 1\n1\n",
+		"%%MatrixMarket matrix array real symmetric\n2 1\n1\n2\n",
+		"%%MatrixMarket matrix array real general\n2 1\n1\n",
+		"%%MatrixMarket matrix array real general\n1 1\n1\n2\n",
+		"%%MatrixMarket matrix array real general\n1 1\nx\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1\n",
+		"%%MatrixMarket matrix coordinate real skew-symmetric\n2 2 1\n1 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n0 0 0\n",
+		"%%MatrixMarket matrix coordinate real general\n4000000000 4000000000 0\n",
+		"%%MatrixMarket matrix array real general\n65536 65536\n",
+	}
+	for i, text := range errTests {
+		_, err := ReadMarket(strings.NewReader(text))
+		var ferr *FormatError
+		if !errors.As(err, &ferr) {
+			t.Errorf("%d) ReadMarket gave error %v, expected a FormatError", i, err)
+		}
+	}
+}
+
+func TestMarketRoundTrip(t *testing.T) {
+	m := mat.FromSlice(3, 2, []float64{1, 0, -1.0 / 3, 0, 1e-300, math.Pi})
+	sym := mat.FromSlice(3, 3, []float64{1, 2, 0, 2, 5, 0.1, 0, 0.1, -7})
+	formats := []MarketFormat{{}, {Coordinate: true}}
+	symFormats := []MarketFormat{{Symmetric: true}, {Coordinate: true, Symmetric: true}}
+
+	for _, format := range append(formats, symFormats...) {
+		in := m
+		if format.Symmetric {
+			in = sym
+		}
+		buf := &bytes.Buffer{}
+		if err := WriteMarket(buf, in, format); err != nil {
+			t.Errorf("%+v: WriteMarket returned error: %s", format, err)
+			continue
+		}
+		out, err := ReadMarket(buf)
+		if err != nil {
+			t.Errorf("%+v: ReadMarket returned error: %s", format, err)
+		} else if !mat.AlmostEqual(in, out) {
+			t.Errorf("%+v: round trip gave %v, expected %v", format,
+				out.Grid(), in.Grid())
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	WriteMarket(buf, sym, MarketFormat{Coordinate: true, Symmetric: true})
+	exp := `%%MatrixMarket matrix coordinate real symmetric
+3 3 5
+1 1 1
+2 1 2
+2 2 5
+3 2 0.1
+3 3 -7
+`
+	if buf.String() != exp {
+		t.Errorf("WriteMarket gave\n%s\nexpected\n%s", buf.String(), exp)
+	}
+
+	c := cmat.FromSlice(2, 2, []complex128{1, 2 - 1i, 2 + 1i, 3})
+	for _, format := range append(formats, symFormats...) {
+		buf := &bytes.Buffer{}
+		if err := WriteMarketComplex(buf, c, format); err != nil {
+			t.Errorf("%+v: WriteMarketComplex returned error: %s", format, err)
+			continue
+		}
+		out, err := ReadMarketComplex(buf)
+		if err != nil || !cmat.AlmostEqual(c, out) {
+			t.Errorf("%+v: complex round trip gave %v, %v", format, out, err)
+		}
+	}
+
+	buf.Reset()
+	v := vec.Vector{1, 2.5, -3}
+	WriteMarketVector(buf, v)
+	if out, err := ReadMarketVector(buf); err != nil || !vecEq(out, v) {
+		t.Errorf("Vector round trip gave %v, %v", out, err)
+	}
+
+	if err := WriteMarket(buf, m, MarketFormat{Symmetric: true}); !errors.Is(err, mat.ErrShape) {
+		t.Errorf("WriteMarket of non-square Matrix gave error %v", err)
+	}
+	notSym := mat.FromSlice(2, 2, []float64{1, 2, 3, 4})
+	if err := WriteMarket(buf, notSym, MarketFormat{Symmetric: true}); !errors.Is(err, mat.ErrParameter) {
+		t.Errorf("WriteMarket of non-symmetric Matrix gave error %v", err)
+	}
+	if err := WriteMarket(buf, nil, MarketFormat{}); !errors.Is(err, mat.ErrNil) {
+		t.Errorf("WriteMarket of nil Matrix gave error %v", err)
+	}
+}
+
+// npyBytes returns a .npy file with the given header fields and data, laid
+// out as numpy writes it.
+func npyBytes(descr, fortran, shape string, data interface{}) []byte {
+	dict := "{'descr': '" + descr + "', 'fortran_order': " + fortran +
+		", 'shape': " + shape + ", }"
+	pad := 64 - (10+len(dict)+1)%64
+	header := dict + strings.Repeat(" ", pad%64) + "\n"
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	order := binary.ByteOrder(binary.LittleEndian)
+	if descr[0] == '>' {
+		order = binary.BigEndian
+	}
+	binary.Write(buf, order, data)
+	return buf.Bytes()
+}
+
+func TestReadNpy(t *testing.T) {
+	exp := mat.FromSlice(3, 2, []float64{1, 2, 3, -4, 5, 6})
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"float64", npyBytes("<f8", "False", "(2, 3)", []float64{1, 2, 3, -4, 5, 6})},
+		{"float32", npyBytes("<f4", "False", "(2, 3)", []float32{1, 2, 3, -4, 5, 6})},
+		{"big-endian", npyBytes(">f8", "False", "(2, 3)", []float64{1, 2, 3, -4, 5, 6})},
+		{"int64", npyBytes("<i8", "False", "(2, 3)", []int64{1, 2, 3, -4, 5, 6})},
+		{"int16", npyBytes(">i2", "False", "(2, 3)", []int16{1, 2, 3, -4, 5, 6})},
+		{"int8", npyBytes("|i1", "False", "(2, 3)", []int8{1, 2, 3, -4, 5, 6})},
+		{"fortran", npyBytes("<f8", "True", "(2, 3)", []float64{1, -4, 2, 5, 3, 6})},
+		{"fortran int32", npyBytes("<i4", "True", "(2, 3)", []int32{1, -4, 2, 5, 3, 6})},
+	}
+	for _, test := range tests {
+		m, err := ReadNpy(bytes.NewReader(test.data))
+		if err != nil {
+			t.Errorf("%s: ReadNpy returned error: %s", test.name, err)
+		} else if !mat.AlmostEqual(m, exp) {
+			t.Errorf("%s: ReadNpy gave %v", test.name, m.Grid())
+		}
+	}
+
+	data := npyBytes("|u1", "False", "(3,)", []uint8{7, 200, 9})
+	v, err := ReadNpyVector(bytes.NewReader(data))
+	if err != nil || !vecEq(v, vec.Vector{7, 200, 9}) {
+		t.Errorf("ReadNpyVector gave %v, %v", v, err)
+	}
+	data = npyBytes("<f8", "True", "(1, 3)", []float64{1, 2, 3})
+	if v, err := ReadNpyVector(bytes.NewReader(data)); err != nil || !vecEq(v, vec.Vector{1, 2, 3}) {
+		t.Errorf("ReadNpyVector of a row gave %v, %v", v, err)
+	}
+
+	errTests := [][]byte{
+		[]byte("NUMPY"),
+		[]byte("\x93NUMPZ\x01\x00\x00\x00"),
+		npyBytes("<c16", "False", "(1,)", []float64{1, 2}),
+		npyBytes("<f8", "False", "(2, 3)", []float64{1, 2}),
+		npyBytes("<f8", "False", "(1, 1, 1)", []float64{1}),
+		npyBytes("<f8", "False", "(0, 3)", []float64{}),
+		npyBytes("<f8", "maybe", "(1,)", []float64{1}),
+		npyBytes("<f8", "False", "(2, 2)", []float64{1, 2, 3, 4})[:20],
+	}
+	for i, data := range errTests {
+		_, err := ReadNpy(bytes.NewReader(data))
+		var ferr *FormatError
+		if !errors.As(err, &ferr) {
+			t.Errorf("%d) ReadNpy gave error %v, expected a FormatError", i, err)
+		}
+	}
+	data = npyBytes("<f8", "False", "(2, 2)", []float64{1, 2, 3, 4})
+	if _, err := ReadNpyVector(bytes.NewReader(data)); err == nil {
+		t.Errorf("ReadNpyVector of a square array did not return an error")
+	}
+}
+
+func TestNpyRoundTrip(t *testing.T) {
+	m := mat.FromSlice(3, 2, []float64{1, math.Inf(-1), -1.0 / 3, 0, 1e-300, math.Pi})
+	buf := &bytes.Buffer{}
+	if err := WriteNpy(buf, m); err != nil {
+		t.Fatalf("WriteNpy returned error: %s", err)
+	}
+	exp := npyBytes("<f8", "False", "(2, 3)", m.Slice())
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("WriteNpy output does not match numpy's layout")
+	}
+	if out, err := ReadNpy(buf); err != nil || !vecEq(out.Slice(), m.Slice()) {
+		t.Errorf("Round trip gave %v, %v", out.Slice(), err)
+	}
+
+	v := vec.Vector{3, 1, 2}
+	buf.Reset()
+	WriteNpyVector(buf, v)
+	if !bytes.Equal(buf.Bytes(), npyBytes("<f8", "False", "(3,)", []float64(v))) {
+		t.Errorf("WriteNpyVector output does not match numpy's layout")
+	}
+	if out, err := ReadNpyVector(buf); err != nil || !vecEq(out, v) {
+		t.Errorf("Vector round trip gave %v, %v", out, err)
+	}
+
+	if err := WriteNpy(buf, mat.New(0, 1)); !errors.Is(err, mat.ErrParameter) {
+		t.Errorf("WriteNpy of error Matrix gave error %v", err)
+	}
+}
+
+func TestNpz(t *testing.T) {
+	ms := map[string]*mat.Matrix{
+		"a": mat.FromSlice(2, 2, []float64{1, 2, 3, 4}),
+		"b": mat.FromSlice(1, 3, []float64{5, 6, 7}),
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteNpz(buf, ms); err != nil {
+		t.Fatalf("WriteNpz returned error: %s", err)
+	}
+	out, err := ReadNpz(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadNpz returned error: %s", err)
+	} else if len(out) != len(ms) {
+		t.Errorf("ReadNpz returned %d arrays, expected %d", len(out), len(ms))
+	}
+	for name, m := range ms {
+		if !mat.AlmostEqual(out[name], m) {
+			t.Errorf("Array %q read as %v", name, out[name])
+		}
+	}
+
+	if _, err := ReadNpzVectors(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err == nil ||
+		!strings.Contains(err.Error(), `"a"`) {
+		t.Errorf("ReadNpzVectors with a square array gave error %v", err)
+	}
+
+	vs := map[string]vec.Vector{"x": {1, 2}, "y": {3}}
+	buf.Reset()
+	WriteNpzVectors(buf, vs)
+	vOut, err := ReadNpzVectors(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil || !vecEq(vOut["x"], vs["x"]) || !vecEq(vOut["y"], vs["y"]) {
+		t.Errorf("Vector round trip gave %v, %v", vOut, err)
+	}
+
+	if _, err := ReadNpz(strings.NewReader("not a zip"), 9); err == nil {
+		t.Errorf("ReadNpz of invalid archive did not return an error")
+	}
+}
+
+func TestTable(t *testing.T) {
+	text := `# x y z
+1 2 3
+
+  4	5e1 -6
+`
+	m, err := ReadTable(strings.NewReader(text))
+	exp := mat.FromSlice(3, 2, []float64{1, 2, 3, 4, 50, -6})
+	if err != nil || !mat.AlmostEqual(m, exp) {
+		t.Errorf("ReadTable gave %v, %v", m, err)
+	}
+
+	buf := &bytes.Buffer{}
+	WriteTable(buf, exp)
+	if buf.String() != "1 2 3\n4 50 -6\n" {
+		t.Errorf("WriteTable gave %q", buf.String())
+	}
+
+	v := vec.Vector{0.1, -1.0 / 3}
+	buf.Reset()
+	WriteTableVector(buf, v)
+	if out, err := ReadTableVector(buf); err != nil || !vecEq(out, v) {
+		t.Errorf("Vector round trip gave %v, %v", out, err)
+	}
+	if out, err := ReadTableVector(strings.NewReader("1 2 3\n")); err != nil ||
+		!vecEq(out, vec.Vector{1, 2, 3}) {
+		t.Errorf("ReadTableVector of a row gave %v, %v", out, err)
+	}
+
+	errTests := []string{"", "# Only comments.\n", "1 2\n3\n", "1 x\n"}
+	for i, text := range errTests {
+		_, err := ReadTable(strings.NewReader(text))
+		var ferr *FormatError
+		if !errors.As(err, &ferr) {
+			t.Errorf("%d) ReadTable gave error %v, expected a FormatError", i, err)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package matio
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/vec"
+)
+
+const (
+	npyName = "npy"
+	npzName = "npz"
+	npyMagic = "\x93NUMPY"
+	// npyAlign is the alignment of the start of the data, matching the
+	// alignment used by numpy.
+	npyAlign = 64
+	// npyChunk is the largest number of elements allocated before any data
+	// has been read, so that corrupt headers cannot cause huge allocations.
+	npyChunk = 1 << 16
+)
+
+// npyType describes the element type of a .npy array.
+type npyType struct {
+	order binary.ByteOrder
+	kind byte // 'f' for floats, 'i' for signed and 'u' for unsigned integers
+	size int // size of an element in bytes
+}
+
+// ReadNpy reads a two-dimensional NumPy array in the .npy format from r. The
+// element type must be a float64, float32, or signed or unsigned integer
+// type, in either byte order, and the array may be in C or Fortran order.
+// Integers are converted to float64, so integers larger than 2^53 lose
+// precision. One-dimensional arrays are read as a Matrix with a single
+// column.
+func ReadNpy(r io.Reader) (*mat.Matrix, error) {
+	a, err := readNpy(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.matrix(npyName)
+}
+
+// ReadNpyVector reads a NumPy array with one dimension, or two dimensions
+// and a single row or column, from r and returns it as a Vector. See
+// ReadNpy.
+func ReadNpyVector(r io.Reader) (vec.Vector, error) {
+	a, err := readNpy(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.vector(npyName)
+}
+
+// WriteNpy writes m to w as a two-dimensional little-endian float64 NumPy
+// array in the .npy format.
+//
+// If m is nil or an error Matrix, an error of type *mat.MatrixError is
+// returned.
+func WriteNpy(w io.Writer, m *mat.Matrix) error {
+	a, err := matrixArray("WriteNpy", m)
+	if err != nil {
+		return err
+	}
+	return writeNpy(w, a)
+}
+
+// WriteNpyVector writes v to w as a one-dimensional little-endian float64
+// NumPy array in the .npy format.
+//
+// If v is empty, an error of type *mat.MatrixError is returned.
+func WriteNpyVector(w io.Writer, v vec.Vector) error {
+	a, err := vectorArray("WriteNpyVector", v)
+	if err != nil {
+		return err
+	}
+	return writeNpy(w, a)
+}
+
+// ReadNpz reads every array of a NumPy .npz archive of the given size from
+// r. The result maps array names, without their ".npy" extension, to
+// Matrices. Both compressed and uncompressed archives are supported. See
+// ReadNpy.
+func ReadNpz(r io.ReaderAt, size int64) (map[string]*mat.Matrix, error) {
+	arrays, err := readNpz(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*mat.Matrix, len(arrays))
+	for name, a := range arrays {
+		if out[name], err = a.matrix(npzName); err != nil {
+			return nil, namedError(name, err)
+		}
+	}
+	return out, nil
+}
+
+// ReadNpzVectors reads every array of a NumPy .npz archive of the given size
+// from r as a Vector. See ReadNpz and ReadNpyVector.
+func ReadNpzVectors(r io.ReaderAt, size int64) (map[string]vec.Vector, error) {
+	arrays, err := readNpz(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]vec.Vector, len(arrays))
+	for name, a := range arrays {
+		if out[name], err = a.vector(npzName); err != nil {
+			return nil, namedError(name, err)
+		}
+	}
+	return out, nil
+}
+
+// WriteNpz writes the given Matrices to w as an uncompressed NumPy .npz
+// archive, as numpy.savez does. Each Matrix is stored under its key in ms.
+//
+// If any Matrix is nil or an error Matrix, an error of type *mat.MatrixError
+// is returned.
+func WriteNpz(w io.Writer, ms map[string]*mat.Matrix) error {
+	arrays := make(map[string]*array, len(ms))
+	for name, m := range ms {
+		a, err := matrixArray("WriteNpz", m)
+		if err != nil {
+			return err
+		}
+		arrays[name] = a
+	}
+	return writeNpz(w, arrays)
+}
+
+// WriteNpzVectors writes the given Vectors to w as one-dimensional arrays in
+// an uncompressed NumPy .npz archive. See WriteNpz.
+//
+// If any Vector is empty, an error of type *mat.MatrixError is returned.
+func WriteNpzVectors(w io.Writer, vs map[string]vec.Vector) error {
+	arrays := make(map[string]*array, len(vs))
+	for name, v := range vs {
+		a, err := vectorArray("WriteNpzVectors", v)
+		if err != nil {
+			return err
+		}
+		arrays[name] = a
+	}
+	return writeNpz(w, arrays)
+}
+
+// namedError adds the name of the array which caused err to its description
+// if err is a *FormatError.
+func namedError(name string, err error) error {
+	if ferr, ok := err.(*FormatError); ok {
+		ferr.Description = fmt.Sprintf("Array %q: %s", name, ferr.Description)
+	}
+	return err
+}
+
+// readNpy reads a .npy file into an array.
+func readNpy(r io.Reader) (*array, error) {
+	prefix := make([]byte, len(npyMagic) + 2)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, npyReadError(err, "the header")
+	} else if string(prefix[:len(npyMagic)]) != npyMagic {
+		return nil, formatError(npyName, 0, "File does not begin with the .npy magic string.")
+	}
+
+	var headerLen int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, npyReadError(err, "the header")
+		}
+		headerLen = int(binary.LittleEndian.Uint16(buf))
+	case 2, 3:
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, npyReadError(err, "the header")
+		}
+		headerLen = int(binary.LittleEndian.Uint32(buf))
+	default:
+		return nil, formatError(npyName, 0, "Unsupported format version %d.", major)
+	}
+
+	header := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, npyReadError(err, "the header")
+	}
+	typ, fortran, shape, err := parseNpyHeader(string(header))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &array{}
+	switch len(shape) {
+	case 1:
+		a.width, a.height, a.oneD = 1, shape[0], true
+	case 2:
+		a.width, a.height = shape[1], shape[0]
+	default:
+		return nil, formatError(npyName, 0,
+			"Arrays with %d dimensions are not supported.", len(shape))
+	}
+	if a.height > 0 && a.width > math.MaxInt32 / a.height {
+		return nil, formatError(npyName, 0, "Array shape %v is too large.", shape)
+	}
+
+	n := a.width * a.height
+	a.re = make([]float64, 0, minInt(n, npyChunk))
+	br := bufio.NewReader(r)
+	buf := make([]byte, typ.size)
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, npyReadError(err, "the data")
+		}
+		a.re = append(a.re, typ.decode(buf))
+	}
+
+	if fortran && !a.oneD {
+		// Fortran order is column-major, so the data is the transpose of a.
+		re := make([]float64, n)
+		for x := 0; x < a.width; x++ {
+			for y := 0; y < a.height; y++ {
+				re[y * a.width + x] = a.re[x * a.height + y]
+			}
+		}
+		a.re = re
+	}
+	return a, nil
+}
+
+// npyReadError converts an error from reading a .npy file into the error
+// returned to the user. Files which end early are format errors.
+func npyReadError(err error, what string) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return formatError(npyName, 0, "File ended while reading %s.", what)
+	}
+	return err
+}
+
+// parseNpyHeader parses the Python dictionary literal at the start of a .npy
+// file, e.g. "{'descr': '<f8', 'fortran_order': False, 'shape': (3, 4), }".
+func parseNpyHeader(header string) (typ *npyType, fortran bool, shape []int, err error) {
+	header = strings.TrimSpace(header)
+	if len(header) < 2 || header[0] != '{' || header[len(header) - 1] != '}' {
+		return nil, false, nil, formatError(npyName, 0,
+			"Header %q is not a dictionary.", header)
+	}
+
+	descr, ok := npyHeaderValue(header, "descr")
+	if !ok {
+		return nil, false, nil, formatError(npyName, 0, "Header has no 'descr' key.")
+	}
+	if typ, err = parseNpyType(descr); err != nil {
+		return nil, false, nil, err
+	}
+
+	switch order, _ := npyHeaderValue(header, "fortran_order"); order {
+	case "True":
+		fortran = true
+	case "False":
+	default:
+		return nil, false, nil, formatError(npyName, 0,
+			"Invalid 'fortran_order' value %q.", order)
+	}
+
+	tuple, ok := npyHeaderValue(header, "shape")
+	if !ok || len(tuple) < 2 || tuple[0] != '(' || tuple[len(tuple) - 1] != ')' {
+		return nil, false, nil, formatError(npyName, 0,
+			"Invalid 'shape' value %q.", tuple)
+	}
+	for _, dim := range strings.Split(tuple[1: len(tuple) - 1], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		n, err := strconv.Atoi(dim)
+		if err != nil || n < 0 {
+			return nil, false, nil, formatError(npyName, 0,
+				"Invalid 'shape' value %q.", tuple)
+		}
+		shape = append(shape, n)
+	}
+	return typ, fortran, shape, nil
+}
+
+// npyHeaderValue returns the literal value associated with key in a .npy
+// header, with the quotes stripped from strings.
+func npyHeaderValue(header, key string) (string, bool) {
+	start := -1
+	for _, quote := range []string{"'", "\""} {
+		if i := strings.Index(header, quote + key + quote); i >= 0 {
+			start = i + len(key) + 2
+			break
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+
+	rest := strings.TrimSpace(header[start:])
+	if len(rest) == 0 || rest[0] != ':' {
+		return "", false
+	}
+	rest = strings.TrimSpace(rest[1:])
+	if len(rest) == 0 {
+		return "", false
+	}
+
+	switch rest[0] {
+	case '\'', '"':
+		end := strings.IndexByte(rest[1:], rest[0])
+		if end < 0 {
+			return "", false
+		}
+		return rest[1: end + 1], true
+	case '(':
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			return "", false
+		}
+		return rest[:end + 1], true
+	default:
+		end := strings.IndexAny(rest, ",}")
+		if end < 0 {
+			return "", false
+		}
+		return strings.TrimSpace(rest[:end]), true
+	}
+}
+
+// parseNpyType parses a NumPy type string such as "<f8" or "|u1".
+func parseNpyType(descr string) (*npyType, error) {
+	invalid := formatError(npyName, 0, "Unsupported element type %q.", descr)
+	if len(descr) < 2 {
+		return nil, invalid
+	}
+
+	typ := &npyType{order: binary.LittleEndian}
+	switch descr[0] {
+	case '>':
+		typ.order = binary.BigEndian
+		descr = descr[1:]
+	case '<', '|', '=':
+		descr = descr[1:]
+	}
+
+	size, err := strconv.Atoi(descr[1:])
+	if err != nil {
+		return nil, invalid
+	}
+	typ.kind, typ.size = descr[0], size
+	switch typ.kind {
+	case 'f':
+		if size != 4 && size != 8 {
+			return nil, invalid
+		}
+	case 'i', 'u':
+		if size != 1 && size != 2 && size != 4 && size != 8 {
+			return nil, invalid
+		}
+	default:
+		return nil, invalid
+	}
+	return typ, nil
+}
+
+// decode converts a single encoded element to a float64.
+func (typ *npyType) decode(buf []byte) float64 {
+	var bits uint64
+	switch typ.size {
+	case 1:
+		bits = uint64(buf[0])
+	case 2:
+		bits = uint64(typ.order.Uint16(buf))
+	case 4:
+		bits = uint64(typ.order.Uint32(buf))
+	case 8:
+		bits = typ.order.Uint64(buf)
+	}
+
+	switch typ.kind {
+	case 'f':
+		if typ.size == 4 {
+			return float64(math.Float32frombits(uint32(bits)))
+		}
+		return math.Float64frombits(bits)
+	case 'i':
+		// Sign extend from the element size.
+		shift := uint(64 - 8 * typ.size)
+		return float64(int64(bits << shift) >> shift)
+	default:
+		return float64(bits)
+	}
+}
+
+// writeNpy writes a to w as a little-endian float64 .npy file in C order.
+func writeNpy(w io.Writer, a *array) error {
+	shape := fmt.Sprintf("(%d, %d)", a.height, a.width)
+	if a.oneD {
+		shape = fmt.Sprintf("(%d,)", a.height)
+	}
+	dict := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': %s, }",
+		shape)
+
+	// The header is padded with spaces and terminated with a newline so
+	// that the data starts on an aligned boundary.
+	prefixLen := len(npyMagic) + 2 + 2
+	pad := npyAlign - (prefixLen + len(dict) + 1) % npyAlign
+	if pad == npyAlign {
+		pad = 0
+	}
+	header := dict + strings.Repeat(" ", pad) + "\n"
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(npyMagic)
+	bw.Write([]byte{1, 0})
+	binary.Write(bw, binary.LittleEndian, uint16(len(header)))
+	bw.WriteString(header)
+
+	buf := make([]byte, 8)
+	for _, val := range a.re {
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(val))
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
+
+// readNpz reads every .npy file in a .npz archive.
+func readNpz(r io.ReaderAt, size int64) (map[string]*array, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, formatError(npzName, 0, "Invalid zip archive: %s", err)
+	}
+
+	arrays := map[string]*array{}
+	for _, f := range zr.File {
+		if !strings.HasSuffix(f.Name, ".npy") {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name, ".npy")
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, formatError(npzName, 0, "Array %q: %s", name, err)
+		}
+		a, err := readNpy(rc)
+		rc.Close()
+		if err != nil {
+			return nil, namedError(name, err)
+		}
+		arrays[name] = a
+	}
+	return arrays, nil
+}
+
+// writeNpz writes arrays to w as an uncompressed .npz archive. Arrays are
+// written in order of their names so that the output is deterministic.
+func writeNpz(w io.Writer, arrays map[string]*array) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name: name + ".npy", Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeNpy(fw, arrays[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func minInt(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,117 @@
+package matio
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/phil-mansfield/num/mat"
+	"github.com/phil-mansfield/num/vec"
+)
+
+const (
+	tableName = "table"
+	// maxTableLine is the longest line that ReadTable will accept.
+	maxTableLine = 1 << 24
+)
+
+// ReadTable reads a Matrix from r, which contains one row of
+// whitespace-separated values per line. Lines which are blank or which start
+// with '#' are ignored. Every row must have the same number of values.
+func ReadTable(r io.Reader) (*mat.Matrix, error) {
+	a, err := readTable(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.matrix(tableName)
+}
+
+// ReadTableVector reads a table with a single row or a single column from r
+// and returns it as a Vector. See ReadTable.
+func ReadTableVector(r io.Reader) (vec.Vector, error) {
+	a, err := readTable(r)
+	if err != nil {
+		return nil, err
+	}
+	return a.vector(tableName)
+}
+
+// WriteTable writes m to w with one row per line and values separated by
+// spaces. Values are written with the minimum number of digits needed to
+// read them back exactly.
+//
+// If m is nil or an error Matrix, an error of type *mat.MatrixError is
+// returned.
+func WriteTable(w io.Writer, m *mat.Matrix) error {
+	a, err := matrixArray("WriteTable", m)
+	if err != nil {
+		return err
+	}
+	return writeTable(w, a)
+}
+
+// WriteTableVector writes v to w as a single column, with one value per line.
+//
+// If v is empty, an error of type *mat.MatrixError is returned.
+func WriteTableVector(w io.Writer, v vec.Vector) error {
+	a, err := vectorArray("WriteTableVector", v)
+	if err != nil {
+		return err
+	}
+	return writeTable(w, a)
+}
+
+// readTable reads a whitespace-separated table into an array.
+func readTable(r io.Reader) (*array, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxTableLine)
+
+	a := &array{}
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if a.height == 0 {
+			a.width = len(fields)
+		} else if len(fields) != a.width {
+			return nil, formatError(tableName, line,
+				"Row has %d values, but previous rows have %d.",
+				len(fields), a.width)
+		}
+		for _, field := range fields {
+			val, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, formatError(tableName, line,
+					"Invalid value %q.", field)
+			}
+			a.re = append(a.re, val)
+		}
+		a.height++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// writeTable writes a to w as a whitespace-separated table.
+func writeTable(w io.Writer, a *array) error {
+	bw := bufio.NewWriter(w)
+	buf := []byte{}
+	for y := 0; y < a.height; y++ {
+		buf = buf[:0]
+		for x, val := range a.re[y * a.width: (y + 1) * a.width] {
+			if x > 0 {
+				buf = append(buf, ' ')
+			}
+			buf = strconv.AppendFloat(buf, val, 'g', -1, 64)
+		}
+		buf = append(buf, '\n')
+		bw.Write(buf)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,200 @@
+package mat
+
+import (
+	"math"
+)
+
+const (
+	// condMaxIters is the maximum number of iterations of the Hager-Higham
+	// 1-norm estimator used by ConditionEstimate.
+	condMaxIters = 5
+)
+
+// Norm1 returns the 1-norm of m, its largest absolute column sum.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) Norm1() (float64, error) {
+	if err := inputError("Norm1", m); err != nil {
+		return 0, err
+	}
+	return norm1(m), nil
+}
+
+// NormInf returns the infinity-norm of m, its largest absolute row sum.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) NormInf() (float64, error) {
+	if err := inputError("NormInf", m); err != nil {
+		return 0, err
+	}
+
+	max := 0.0
+	for y := 0; y < m.height; y++ {
+		sum := 0.0
+		for _, val := range m.row(y) {
+			sum += math.Abs(val)
+		}
+		max = math.Max(max, sum)
+	}
+	return max, nil
+}
+
+// NormFrobenius returns the Frobenius norm of m, the square root of the sum
+// of the squares of its elements. The sum is scaled as it is accumulated, so
+// the result does not overflow or underflow unless the norm itself does.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) NormFrobenius() (float64, error) {
+	if err := inputError("NormFrobenius", m); err != nil {
+		return 0, err
+	}
+
+	// The norm is scale * sqrt(sumSq), following LAPACK's dlassq.
+	scale, sumSq := 0.0, 1.0
+	for y := 0; y < m.height; y++ {
+		for _, val := range m.row(y) {
+			if val == 0 {
+				continue
+			}
+			abs := math.Abs(val)
+			if abs > scale {
+				sumSq = 1 + sumSq * (scale / abs) * (scale / abs)
+				scale = abs
+			} else {
+				sumSq += (abs / scale) * (abs / scale)
+			}
+		}
+	}
+	return scale * math.Sqrt(sumSq), nil
+}
+
+// NormMax returns the largest absolute value of the elements of m. This is
+// not a consistent matrix norm, but is useful for comparisons.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) NormMax() (float64, error) {
+	if err := inputError("NormMax", m); err != nil {
+		return 0, err
+	}
+
+	max := 0.0
+	for y := 0; y < m.height; y++ {
+		for _, val := range m.row(y) {
+			max = math.Max(max, math.Abs(val))
+		}
+	}
+	return max, nil
+}
+
+// ConditionEstimate returns an estimate of the 1-norm condition number of m,
+// ||m||_1 ||m^-1||_1. See LU.ConditionEstimate. If m is singular, +Inf is
+// returned.
+//
+// If m is nil or not square, a non-nil error is returned.
+func (m *Matrix) ConditionEstimate() (float64, error) {
+	f, err := newLU("ConditionEstimate", m)
+	if err != nil {
+		return 0, err
+	}
+	return f.ConditionEstimate(), nil
+}
+
+// norm1 returns the 1-norm of m, its largest absolute column sum.
+func norm1(m *Matrix) float64 {
+	max := 0.0
+	for x := 0; x < m.width; x++ {
+		sum := 0.0
+		for y := 0; y < m.height; y++ {
+			sum += math.Abs(m.values[y * m.stride + x])
+		}
+		max = math.Max(max, sum)
+	}
+	return max
+}
+
+// invNorm1Estimate estimates the 1-norm of the inverse of an n x n matrix,
+// A, using Hager's method with Higham's refinements, as in LAPACK's dlacn2.
+// solve overwrites its argument, x, with A^-1 x if trans is false and with
+// A^-T x if trans is true. The estimate is a lower bound which is almost
+// always within a factor of three of the true norm and requires only a
+// handful of solves.
+func invNorm1Estimate(n int, solve func(x []float64, trans bool)) float64 {
+	// x is the current point on the unit 1-norm ball and y is A^-1 x.
+	x, y := make([]float64, n), make([]float64, n)
+	for i := range x {
+		x[i] = 1 / float64(n)
+	}
+	copy(y, x)
+	solve(y, false)
+	if n == 1 {
+		return math.Abs(y[0])
+	}
+
+	est := sum1(y)
+	sign, z := make([]float64, n), make([]float64, n)
+	for iter := 0; iter < condMaxIters; iter++ {
+		// The gradient of ||A^-1 x||_1 at x is A^-T sign(A^-1 x).
+		changed := iter == 0
+		for i, yi := range y {
+			s := 1.0
+			if yi < 0 {
+				s = -1
+			}
+			if s != sign[i] {
+				changed = true
+			}
+			sign[i] = s
+		}
+		if !changed {
+			break
+		}
+
+		copy(z, sign)
+		solve(z, true)
+		j, zx := 0, 0.0
+		for i, zi := range z {
+			if math.Abs(zi) > math.Abs(z[j]) {
+				j = i
+			}
+			zx += zi * x[i]
+		}
+		if math.Abs(z[j]) <= zx {
+			break
+		}
+
+		// Move to the vertex e_j, where the gradient predicts the largest
+		// increase.
+		for i := range x {
+			x[i] = 0
+		}
+		x[j] = 1
+		copy(y, x)
+		solve(y, false)
+
+		next := sum1(y)
+		if next <= est {
+			break
+		}
+		est = next
+	}
+
+	// Higham's extra test vector guards against the rare matrices for which
+	// the iteration stalls at a poor local maximum.
+	for i := range y {
+		y[i] = 1 + float64(i) / float64(n - 1)
+		if i % 2 == 1 {
+			y[i] = -y[i]
+		}
+	}
+	solve(y, false)
+	return math.Max(est, 2 * sum1(y) / float64(3 * n))
+}
+
+// sum1 returns the 1-norm of the vector x.
+func sum1(x []float64) float64 {
+	sum := 0.0
+	for _, xi := range x {
+		sum += math.Abs(xi)
+	}
+	return sum
+}
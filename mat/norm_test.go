@@ -0,0 +1,109 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNorms(t *testing.T) {
+	m := FromSlice(3, 2, []float64{1, -2, 3, -4, 5, -6})
+
+	norms := []struct {
+		name string
+		f    func() (float64, error)
+		exp  float64
+	}{
+		{"Norm1", m.Norm1, 9},
+		{"NormInf", m.NormInf, 15},
+		{"NormFrobenius", m.NormFrobenius, math.Sqrt(91)},
+		{"NormMax", m.NormMax, 6},
+		{"Norm1 of view", m.Cols(0, 2).Norm1, 7},
+	}
+	for _, test := range norms {
+		val, err := test.f()
+		if err != nil {
+			t.Errorf("%s returned error: %s", test.name, err)
+		} else if math.Abs(val-test.exp) > 1e-14*test.exp {
+			t.Errorf("%s = %g, expected %g", test.name, val, test.exp)
+		}
+	}
+
+	// The Frobenius norm must not overflow or underflow.
+	for _, scale := range []float64{1e300, 1e-300} {
+		big := Scale(m, scale)
+		val, _ := big.NormFrobenius()
+		if exp := math.Sqrt(91) * scale; math.Abs(val-exp) > 1e-14*exp {
+			t.Errorf("NormFrobenius of scaled Matrix = %g, expected %g", val, exp)
+		}
+	}
+
+	if _, err := (*Matrix)(nil).NormInf(); err == nil {
+		t.Errorf("NormInf of nil Matrix did not return an error")
+	}
+}
+
+func TestConditionEstimate(t *testing.T) {
+	tests := []*Matrix{
+		FromSlice(1, 1, []float64{4}),
+		FromSlice(2, 2, []float64{1, 2, 3, 4}),
+		scaledHilbert(6),
+		randomMatrix(10, 10),
+		randomMatrix(40, 40),
+	}
+
+	for i, m := range tests {
+		est, err := m.ConditionEstimate()
+		if err != nil {
+			t.Errorf("%d) ConditionEstimate returned error: %s", i, err)
+			continue
+		}
+		n1, _ := m.Norm1()
+		inv1, _ := Invert(m).Norm1()
+		exact := n1 * inv1
+		if est > exact*(1+1e-8) || est < exact/3 {
+			t.Errorf("%d) ConditionEstimate = %g, exact value is %g", i, est, exact)
+		}
+	}
+
+	est, _ := FromSlice(2, 2, []float64{1, 2, 2, 4}).ConditionEstimate()
+	if !math.IsInf(est, +1) {
+		t.Errorf("ConditionEstimate of singular Matrix = %g", est)
+	}
+}
+
+func TestAlmostEqualTol(t *testing.T) {
+	m1 := FromSlice(2, 2, []float64{1, 100, 0, math.Inf(+1)})
+	m2 := FromSlice(2, 2, []float64{1.02, 101, 1e-9, math.Inf(+1)})
+
+	tests := []struct {
+		rtol, atol float64
+		ok         bool
+		x, y       int
+	}{
+		{0.02, 1e-8, true, 0, 0},
+		{0.005, 1e-8, false, 0, 0},
+		{0.0099, 1e-8, false, 0, 0},
+		{0.1, 0, false, 0, 1},
+	}
+	for i, test := range tests {
+		ok, worst := AlmostEqualTol(m1, m2, test.rtol, test.atol)
+		if ok != test.ok || worst.X != test.x || worst.Y != test.y {
+			t.Errorf("%d) AlmostEqualTol gave %v, %s", i, ok, worst)
+		}
+	}
+
+	ok, worst := AlmostEqualTol(m1, FromSlice(2, 2, []float64{1, 100, 0, math.NaN()}), 1, 1)
+	if ok || worst.X != 1 || worst.Y != 1 {
+		t.Errorf("AlmostEqualTol with NaN gave %v, %s", ok, worst)
+	}
+	for _, v := range []float64{math.Inf(-1), 1e300} {
+		m3 := FromSlice(2, 2, []float64{1, 100, 0, v})
+		ok, worst := AlmostEqualTol(m1, m3, 1e-9, 0)
+		if ok || worst.X != 1 || worst.Y != 1 {
+			t.Errorf("AlmostEqualTol of Inf and %g gave %v, %s", v, ok, worst)
+		}
+	}
+	if ok, worst := AlmostEqualTol(m1, New(2, 3), 1, 1); ok || worst.X != -1 {
+		t.Errorf("AlmostEqualTol of incompatible matrices gave %v, %s", ok, worst)
+	}
+}
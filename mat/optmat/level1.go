@@ -0,0 +1,64 @@
+package optmat
+
+// Axpy computes y = alpha * x + y for vectors of length n.
+func Axpy(n int, alpha float64, x []float64, incX int, y []float64, incY int) {
+	checkVector("Axpy", "x", n, x, incX)
+	checkVector("Axpy", "y", n, y, incY)
+	if n == 0 || alpha == 0 {
+		return
+	}
+
+	if incX == 1 && incY == 1 {
+		axpyUnitary(alpha, x[:n], y[:n])
+		return
+	}
+	for i, ix, iy := 0, 0, 0; i < n; i, ix, iy = i + 1, ix + incX, iy + incY {
+		y[iy] += alpha * x[ix]
+	}
+}
+
+// axpyUnitary computes y = alpha * x + y for contiguous vectors of the same
+// length.
+func axpyUnitary(alpha float64, x, y []float64) {
+	i := 0
+	for ; i + 4 <= len(x); i += 4 {
+		y[i] += alpha * x[i]
+		y[i + 1] += alpha * x[i + 1]
+		y[i + 2] += alpha * x[i + 2]
+		y[i + 3] += alpha * x[i + 3]
+	}
+	for ; i < len(x); i++ {
+		y[i] += alpha * x[i]
+	}
+}
+
+// dotUnitary returns the dot product of contiguous vectors of the same length.
+func dotUnitary(x, y []float64) float64 {
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i + 4 <= len(x); i += 4 {
+		s0 += x[i] * y[i]
+		s1 += x[i + 1] * y[i + 1]
+		s2 += x[i + 2] * y[i + 2]
+		s3 += x[i + 3] * y[i + 3]
+	}
+	for ; i < len(x); i++ {
+		s0 += x[i] * y[i]
+	}
+	return (s0 + s1) + (s2 + s3)
+}
+
+// scale computes y = beta * y for a vector of length n, treating beta == 0
+// as an assignment so that NaNs and infinities in y are not propagated.
+func scale(n int, beta float64, y []float64, incY int) {
+	if beta == 1 {
+		return
+	}
+	for i, iy := 0, 0; i < n; i, iy = i + 1, iy + incY {
+		if beta == 0 {
+			y[iy] = 0
+		} else {
+			y[iy] *= beta
+		}
+	}
+}
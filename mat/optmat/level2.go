@@ -0,0 +1,62 @@
+package optmat
+
+// Gemv computes y = alpha * op(A) * x + beta * y, where A is an m x n
+// matrix. If tA is NoTrans, x has length n and y has length m; if tA is
+// Trans, x has length m and y has length n. x and y must not overlap.
+//
+// If beta is zero, y need not be initialized.
+func Gemv(
+	tA Transpose, m, n int, alpha float64, a []float64, lda int,
+	x []float64, incX int, beta float64, y []float64, incY int,
+) {
+	checkMatrix("Gemv", "A", m, n, a, lda)
+	lenX, lenY := n, m
+	if tA == Trans {
+		lenX, lenY = m, n
+	}
+	checkVector("Gemv", "x", lenX, x, incX)
+	checkVector("Gemv", "y", lenY, y, incY)
+
+	scale(lenY, beta, y, incY)
+	if alpha == 0 || m == 0 || n == 0 {
+		return
+	}
+
+	if tA == NoTrans {
+		xs := x
+		if incX != 1 {
+			xs = gather(n, x, incX)
+		}
+		for i, iy := 0, 0; i < m; i, iy = i + 1, iy + incY {
+			y[iy] += alpha * dotUnitary(a[i * lda: i * lda + n], xs[:n])
+		}
+		return
+	}
+
+	// y += alpha * A^T x is a sum of the rows of A, which keeps memory access
+	// contiguous.
+	ys := y
+	if incY != 1 {
+		ys = gather(n, y, incY)
+	}
+	for i, ix := 0, 0; i < m; i, ix = i + 1, ix + incX {
+		if x[ix] != 0 {
+			axpyUnitary(alpha * x[ix], a[i * lda: i * lda + n], ys[:n])
+		}
+	}
+	if incY != 1 {
+		for i, iy := 0, 0; i < n; i, iy = i + 1, iy + incY {
+			y[iy] = ys[i]
+		}
+	}
+}
+
+// gather copies the n elements of a strided vector into a new contiguous
+// slice.
+func gather(n int, x []float64, inc int) []float64 {
+	out := make([]float64, n)
+	for i, ix := 0, 0; i < n; i, ix = i + 1, ix + inc {
+		out[i] = x[ix]
+	}
+	return out
+}
@@ -0,0 +1,288 @@
+package optmat
+
+import (
+	"sync"
+)
+
+const (
+	// Block sizes used by Gemm. A panel of gemmBlockK x gemmBlockJ elements
+	// of op(B) is stored transposed so that it fits in L2 cache while rows of
+	// op(A) are streamed past it.
+	gemmBlockK = 256
+	gemmBlockJ = 64
+	// gemmParallelWork is the number of multiply-adds below which Gemm runs
+	// on a single goroutine, and gemmMinRows is the smallest number of rows
+	// of C given to a goroutine.
+	gemmParallelWork = 1 << 18
+	gemmMinRows      = 16
+)
+
+// Gemm computes C = alpha * op(A) * op(B) + beta * C, where op(A) is an
+// m x k matrix, op(B) is a k x n matrix, and C is an m x n matrix. C must
+// not overlap with A or B.
+//
+// If beta is zero, C need not be initialized. Large products are split by
+// rows of C across up to Workers() goroutines.
+func Gemm(
+	tA, tB Transpose, m, n, k int, alpha float64, a []float64, lda int,
+	b []float64, ldb int, beta float64, c []float64, ldc int,
+) {
+	ar, ac := dims(tA, m, k)
+	br, bc := dims(tB, k, n)
+	checkMatrix("Gemm", "A", ar, ac, a, lda)
+	checkMatrix("Gemm", "B", br, bc, b, ldb)
+	checkMatrix("Gemm", "C", m, n, c, ldc)
+	if m == 0 || n == 0 {
+		return
+	}
+
+	for i := 0; i < m; i++ {
+		scale(n, beta, c[i * ldc:], 1)
+	}
+	if alpha == 0 || k == 0 {
+		return
+	}
+
+	workers := minInt(Workers(), m / gemmMinRows)
+	if float64(m) * float64(n) * float64(k) < gemmParallelWork || workers <= 1 {
+		gemmRows(tA, tB, 0, m, n, k, alpha, a, lda, b, ldb, c, ldc)
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		lo, hi := w * m / workers, (w + 1) * m / workers
+		go func() {
+			defer wg.Done()
+			gemmRows(tA, tB, lo, hi, n, k, alpha, a, lda, b, ldb, c, ldc)
+		}()
+	}
+	wg.Wait()
+}
+
+// gemmRows adds alpha * op(A) * op(B) to rows [lo, hi) of C.
+func gemmRows(
+	tA, tB Transpose, lo, hi, n, k int, alpha float64, a []float64, lda int,
+	b []float64, ldb int, c []float64, ldc int,
+) {
+	panel := make([]float64, gemmBlockK * gemmBlockJ)
+	var aRow []float64
+	if tA == Trans {
+		aRow = make([]float64, gemmBlockK)
+	}
+
+	for k0 := 0; k0 < k; k0 += gemmBlockK {
+		kb := minInt(gemmBlockK, k - k0)
+		for j0 := 0; j0 < n; j0 += gemmBlockJ {
+			jb := minInt(gemmBlockJ, n - j0)
+			packPanel(tB, b, ldb, k0, kb, j0, jb, panel)
+
+			for i := lo; i < hi; i++ {
+				if tA == NoTrans {
+					aRow = a[i * lda + k0: i * lda + k0 + kb]
+				} else {
+					for kk := 0; kk < kb; kk++ {
+						aRow[kk] = a[(k0 + kk) * lda + i]
+					}
+				}
+				cRow := c[i * ldc + j0: i * ldc + j0 + jb]
+				panelRow(alpha, aRow[:kb], panel[:jb * kb], cRow, kb)
+			}
+		}
+	}
+}
+
+// packPanel copies the kb x jb block of op(B) starting at (k0, j0) into
+// panel so that panel[j * kb + kk] = op(B)[k0 + kk][j0 + j].
+func packPanel(
+	tB Transpose, b []float64, ldb, k0, kb, j0, jb int, panel []float64,
+) {
+	if tB == NoTrans {
+		for kk := 0; kk < kb; kk++ {
+			row := b[(k0 + kk) * ldb + j0: (k0 + kk) * ldb + j0 + jb]
+			for j, v := range row {
+				panel[j * kb + kk] = v
+			}
+		}
+		return
+	}
+	for j := 0; j < jb; j++ {
+		copy(panel[j * kb: (j + 1) * kb], b[(j0 + j) * ldb + k0:])
+	}
+}
+
+// panelRow adds alpha times the dot products of aRow with each row of the
+// transposed panel to the corresponding elements of cRow. Four columns are
+// processed at once so that each element of aRow is loaded only once per four
+// products.
+func panelRow(alpha float64, aRow, panel, cRow []float64, kb int) {
+	j := 0
+	for ; j + 4 <= len(cRow); j += 4 {
+		p0 := panel[j * kb: (j + 1) * kb]
+		p1 := panel[(j + 1) * kb: (j + 2) * kb]
+		p2 := panel[(j + 2) * kb: (j + 3) * kb]
+		p3 := panel[(j + 3) * kb: (j + 4) * kb]
+
+		var s0, s1, s2, s3 float64
+		for kk, av := range aRow {
+			s0 += av * p0[kk]
+			s1 += av * p1[kk]
+			s2 += av * p2[kk]
+			s3 += av * p3[kk]
+		}
+
+		cRow[j] += alpha * s0
+		cRow[j + 1] += alpha * s1
+		cRow[j + 2] += alpha * s2
+		cRow[j + 3] += alpha * s3
+	}
+
+	for ; j < len(cRow); j++ {
+		cRow[j] += alpha * dotUnitary(aRow, panel[j * kb: (j + 1) * kb])
+	}
+}
+
+// Syrk computes the symmetric rank-k update C = alpha * op(A) * op(A)^T +
+// beta * C, where op(A) is an n x k matrix and C is an n x n symmetric
+// matrix. Only the triangle of C given by uplo is read or written. C must
+// not overlap with A.
+//
+// If beta is zero, C need not be initialized.
+func Syrk(
+	uplo Uplo, t Transpose, n, k int, alpha float64, a []float64, lda int,
+	beta float64, c []float64, ldc int,
+) {
+	ar, ac := dims(t, n, k)
+	checkMatrix("Syrk", "A", ar, ac, a, lda)
+	checkMatrix("Syrk", "C", n, n, c, ldc)
+
+	// triangle returns the bounds of the stored columns of row i of C.
+	triangle := func(i int) (int, int) {
+		if uplo == Upper {
+			return i, n
+		}
+		return 0, i + 1
+	}
+
+	for i := 0; i < n; i++ {
+		lo, hi := triangle(i)
+		scale(hi - lo, beta, c[i * ldc + lo:], 1)
+	}
+	if alpha == 0 || k == 0 {
+		return
+	}
+
+	if t == NoTrans {
+		for i := 0; i < n; i++ {
+			ai := a[i * lda: i * lda + k]
+			lo, hi := triangle(i)
+			for j := lo; j < hi; j++ {
+				c[i * ldc + j] += alpha * dotUnitary(ai, a[j * lda: j * lda + k])
+			}
+		}
+		return
+	}
+
+	// op(A) op(A)^T = A^T A is a sum of outer products of the rows of A.
+	for l := 0; l < k; l++ {
+		row := a[l * lda: l * lda + n]
+		for i, v := range row {
+			if v == 0 {
+				continue
+			}
+			lo, hi := triangle(i)
+			axpyUnitary(alpha * v, row[lo: hi], c[i * ldc + lo: i * ldc + hi])
+		}
+	}
+}
+
+// Trsm solves the triangular systems op(A) * X = alpha * B if side is Left
+// or X * op(A) = alpha * B if side is Right, and overwrites the m x n matrix
+// B with X. A is an m x m matrix if side is Left and an n x n matrix if side
+// is Right. Only the triangle of A given by uplo is read, and if diag is Unit
+// the diagonal of A is assumed to be one and is not read.
+//
+// Trsm does not check for singularity: a zero on the diagonal of A produces
+// infinities or NaNs in B.
+func Trsm(
+	side Side, uplo Uplo, tA Transpose, diag Diag, m, n int, alpha float64,
+	a []float64, lda int, b []float64, ldb int,
+) {
+	na := m
+	if side == Right {
+		na = n
+	}
+	checkMatrix("Trsm", "A", na, na, a, lda)
+	checkMatrix("Trsm", "B", m, n, b, ldb)
+	if m == 0 || n == 0 {
+		return
+	}
+
+	for i := 0; i < m; i++ {
+		scale(n, alpha, b[i * ldb:], 1)
+	}
+	if alpha == 0 {
+		return
+	}
+
+	// op(A)[i][j] is stored at a[i * si + j * sj].
+	si, sj := lda, 1
+	if tA == Trans {
+		si, sj = 1, lda
+	}
+	// op(A) is lower triangular exactly when one of the transpose and the
+	// stored triangle is flipped.
+	lower := (uplo == Lower) == (tA == NoTrans)
+
+	if side == Left {
+		// Substitution over rows of B, each step of which is an axpy.
+		for step := 0; step < m; step++ {
+			i := step
+			if !lower {
+				i = m - 1 - step
+			}
+			bi := b[i * ldb: i * ldb + n]
+			lo, hi := 0, i
+			if !lower {
+				lo, hi = i + 1, m
+			}
+			for j := lo; j < hi; j++ {
+				if l := a[i * si + j * sj]; l != 0 {
+					axpyUnitary(-l, b[j * ldb: j * ldb + n], bi)
+				}
+			}
+			if diag == NonUnit {
+				d := 1 / a[i * si + i * sj]
+				for x := range bi {
+					bi[x] *= d
+				}
+			}
+		}
+		return
+	}
+
+	// Each row x of X satisfies x op(A) = b, which is solved by substitution
+	// over the columns of op(A).
+	for r := 0; r < m; r++ {
+		x := b[r * ldb: r * ldb + n]
+		for step := 0; step < n; step++ {
+			j := step
+			if lower {
+				j = n - 1 - step
+			}
+			lo, hi := 0, j
+			if lower {
+				lo, hi = j + 1, n
+			}
+			sum := x[j]
+			for i := lo; i < hi; i++ {
+				sum -= x[i] * a[i * si + j * sj]
+			}
+			if diag == NonUnit {
+				sum /= a[j * si + j * sj]
+			}
+			x[j] = sum
+		}
+	}
+}
@@ -1,4 +1,139 @@
-// package optmat contains optimized real-valued matrix operations with
-// clunkier interfaces than those found in num/mat/. Placeholder until the
-// interface for num/mat/ is finalized.
-package optmat
\ No newline at end of file
+/*
+package optmat contains optimized real-valued matrix operations with
+clunkier interfaces than those found in num/mat/. The routines follow the
+conventions of the Level 1, 2, and 3 BLAS, but operate on row-major data:
+the element in row i and column j of a matrix stored in a with leading
+dimension lda is a[i * lda + j], and lda must be at least the number of
+columns in the matrix. Vectors are stored with a positive increment, so that
+element i of x is x[i * incX].
+
+	// C = A B^T for a 100 x 50 matrix A and a 30 x 50 matrix B.
+	optmat.Gemm(optmat.NoTrans, optmat.Trans, 100, 30, 50,
+		1, a, 50, b, 50, 0, c, 30)
+
+None of the routines allocate their outputs, and none of them report errors
+through return values. Invalid dimensions, leading dimensions, increments,
+or slices which are too short to hold the described matrices cause a panic,
+just as with out-of-bounds indexing.
+
+Gemm splits its work across goroutines when the product is large enough to
+amortize their cost. The number of goroutines is controlled by SetWorkers.
+*/
+package optmat
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// Transpose specifies whether a matrix argument should be used as given or
+// transposed.
+type Transpose int
+
+const (
+	NoTrans Transpose = iota
+	Trans
+)
+
+// Uplo specifies which triangle of a matrix is read or written.
+type Uplo int
+
+const (
+	Upper Uplo = iota
+	Lower
+)
+
+// Side specifies whether a triangular matrix multiplies from the left or from
+// the right.
+type Side int
+
+const (
+	Left Side = iota
+	Right
+)
+
+// Diag specifies whether a triangular matrix has an implicit unit diagonal.
+type Diag int
+
+const (
+	NonUnit Diag = iota
+	Unit
+)
+
+// workers is the number of goroutines used by parallel routines. It is
+// accessed atomically so that SetWorkers may be called concurrently with
+// running kernels.
+var workers int64
+
+// SetWorkers sets the maximum number of goroutines used by parallel routines.
+// If n is non-positive, runtime.GOMAXPROCS(0) goroutines are used, which is
+// the default.
+func SetWorkers(n int) {
+	atomic.StoreInt64(&workers, int64(n))
+}
+
+// Workers returns the maximum number of goroutines which will be used by
+// parallel routines.
+func Workers() int {
+	if n := int(atomic.LoadInt64(&workers)); n > 0 {
+		return n
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// checkMatrix panics if a is not large enough to hold a rows x cols matrix
+// with leading dimension lda.
+func checkMatrix(operationName, name string, rows, cols int, a []float64, lda int) {
+	if rows < 0 || cols < 0 {
+		panic(fmt.Sprintf("optmat.%s given negative dimensions (%d, %d) for %s.",
+			operationName, rows, cols, name))
+	} else if lda < maxInt(1, cols) {
+		panic(fmt.Sprintf("optmat.%s given leading dimension %d for %s, "+
+			"which has %d columns.", operationName, lda, name, cols))
+	} else if rows > 0 && cols > 0 && len(a) < (rows - 1) * lda + cols {
+		panic(fmt.Sprintf("optmat.%s given slice of length %d for %s, "+
+			"which requires %d elements.", operationName, len(a), name,
+			(rows - 1) * lda + cols))
+	}
+}
+
+// checkVector panics if x is not large enough to hold a vector of length n
+// with increment inc.
+func checkVector(operationName, name string, n int, x []float64, inc int) {
+	if n < 0 {
+		panic(fmt.Sprintf("optmat.%s given negative length %d for %s.",
+			operationName, n, name))
+	} else if inc <= 0 {
+		panic(fmt.Sprintf("optmat.%s given non-positive increment %d for %s.",
+			operationName, inc, name))
+	} else if n > 0 && len(x) < (n - 1) * inc + 1 {
+		panic(fmt.Sprintf("optmat.%s given slice of length %d for %s, "+
+			"which requires %d elements.", operationName, len(x), name,
+			(n - 1) * inc + 1))
+	}
+}
+
+// dims returns the stored shape of A when op(A) is a rows x cols matrix.
+// Since transposition is its own inverse, it also returns the shape of op(A)
+// for a stored rows x cols matrix.
+func dims(t Transpose, rows, cols int) (int, int) {
+	if t == Trans {
+		return cols, rows
+	}
+	return rows, cols
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
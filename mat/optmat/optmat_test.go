@@ -0,0 +1,232 @@
+package optmat
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// panics returns true if f panics.
+func panics(f func()) (didPanic bool) {
+	defer func() {
+		if recover() != nil {
+			didPanic = true
+		}
+	}()
+	f()
+	return false
+}
+
+// randomSlice returns a slice of n values uniform in [-0.5, 0.5).
+func randomSlice(n int) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rand.Float64() - 0.5
+	}
+	return x
+}
+
+// get returns op(A)[i][j] for a matrix stored with leading dimension lda.
+func get(t Transpose, a []float64, lda, i, j int) float64 {
+	if t == Trans {
+		return a[j*lda+i]
+	}
+	return a[i*lda+j]
+}
+
+// maxDiff returns the largest absolute difference between x and y.
+func maxDiff(x, y []float64) float64 {
+	diff := 0.0
+	for i := range x {
+		diff = math.Max(diff, math.Abs(x[i]-y[i]))
+	}
+	return diff
+}
+
+func TestAxpyGemv(t *testing.T) {
+	x, y := randomSlice(9), randomSlice(7)
+	exp := append([]float64{}, y...)
+	for i := 0; i < 3; i++ {
+		exp[2*i] += 2 * x[3*i]
+	}
+	Axpy(3, 2, x, 3, y, 2)
+	if maxDiff(y, exp) != 0 {
+		t.Errorf("Axpy gave %v, expected %v", y, exp)
+	}
+
+	m, n, lda := 5, 4, 6
+	a := randomSlice(m * lda)
+	for _, tA := range []Transpose{NoTrans, Trans} {
+		lenX, lenY := dims(tA, n, m)
+		x, y := randomSlice(2*lenX), randomSlice(lenY)
+		exp := make([]float64, lenY)
+		for i := range exp {
+			exp[i] = 0.5 * y[i]
+			for j := 0; j < lenX; j++ {
+				exp[i] += 3 * get(tA, a, lda, i, j) * x[2*j]
+			}
+		}
+		Gemv(tA, m, n, 3, a, lda, x, 2, 0.5, y, 1)
+		if diff := maxDiff(y, exp); diff > 1e-14 {
+			t.Errorf("Gemv(%d) differs from reference by %g", tA, diff)
+		}
+	}
+}
+
+func TestGemm(t *testing.T) {
+	shapes := []struct{ m, n, k int }{
+		{1, 1, 1}, {3, 5, 2}, {7, 70, 300}, {300, 67, 130},
+	}
+	for _, s := range shapes {
+		for _, tA := range []Transpose{NoTrans, Trans} {
+			for _, tB := range []Transpose{NoTrans, Trans} {
+				ar, ac := dims(tA, s.m, s.k)
+				br, bc := dims(tB, s.k, s.n)
+				lda, ldb, ldc := ac+1, bc+2, s.n+3
+				a, b := randomSlice(ar*lda), randomSlice(br*ldb)
+				c := randomSlice(s.m * ldc)
+
+				exp := append([]float64{}, c...)
+				for i := 0; i < s.m; i++ {
+					for j := 0; j < s.n; j++ {
+						sum := 0.0
+						for l := 0; l < s.k; l++ {
+							sum += get(tA, a, lda, i, l) * get(tB, b, ldb, l, j)
+						}
+						exp[i*ldc+j] = 2*sum - c[i*ldc+j]
+					}
+				}
+
+				Gemm(tA, tB, s.m, s.n, s.k, 2, a, lda, b, ldb, -1, c, ldc)
+				if diff := maxDiff(c, exp); diff > 1e-12 {
+					t.Errorf("Gemm(%d, %d) with shape %v differs from reference by %g",
+						tA, tB, s, diff)
+				}
+			}
+		}
+	}
+
+	// beta == 0 must overwrite NaNs in C.
+	c := []float64{math.NaN()}
+	Gemm(NoTrans, NoTrans, 1, 1, 1, 1, []float64{2}, 1, []float64{3}, 1, 0, c, 1)
+	if c[0] != 6 {
+		t.Errorf("Gemm with beta = 0 gave %g", c[0])
+	}
+
+	// Serial and parallel products must agree.
+	m, n, k := 200, 150, 100
+	a, b := randomSlice(m*k), randomSlice(k*n)
+	c1, c2 := make([]float64, m*n), make([]float64, m*n)
+	SetWorkers(1)
+	Gemm(NoTrans, NoTrans, m, n, k, 1, a, k, b, n, 0, c1, n)
+	SetWorkers(4)
+	Gemm(NoTrans, NoTrans, m, n, k, 1, a, k, b, n, 0, c2, n)
+	SetWorkers(0)
+	if diff := maxDiff(c1, c2); diff != 0 {
+		t.Errorf("Serial and parallel Gemm differ by %g", diff)
+	}
+}
+
+func TestSyrk(t *testing.T) {
+	n, k := 6, 4
+	for _, tr := range []Transpose{NoTrans, Trans} {
+		for _, uplo := range []Uplo{Upper, Lower} {
+			ar, ac := dims(tr, n, k)
+			lda, ldc := ac+1, n+2
+			a, c := randomSlice(ar*lda), randomSlice(n*ldc)
+
+			exp := append([]float64{}, c...)
+			for i := 0; i < n; i++ {
+				for j := 0; j < n; j++ {
+					if (uplo == Upper && j < i) || (uplo == Lower && j > i) {
+						continue
+					}
+					sum := 0.0
+					for l := 0; l < k; l++ {
+						sum += get(tr, a, lda, i, l) * get(tr, a, lda, j, l)
+					}
+					exp[i*ldc+j] = 1.5*sum + 2*c[i*ldc+j]
+				}
+			}
+
+			Syrk(uplo, tr, n, k, 1.5, a, lda, 2, c, ldc)
+			if diff := maxDiff(c, exp); diff > 1e-14 {
+				t.Errorf("Syrk(%d, %d) differs from reference by %g", uplo, tr, diff)
+			}
+		}
+	}
+}
+
+func TestTrsm(t *testing.T) {
+	m, n := 5, 4
+	for _, side := range []Side{Left, Right} {
+		for _, uplo := range []Uplo{Upper, Lower} {
+			for _, tA := range []Transpose{NoTrans, Trans} {
+				for _, diag := range []Diag{NonUnit, Unit} {
+					na := m
+					if side == Right {
+						na = n
+					}
+					lda, ldb := na+1, n+2
+					a := randomSlice(na * lda)
+					for i := 0; i < na; i++ {
+						a[i*lda+i] += 2
+					}
+					b := randomSlice(m * ldb)
+					x := append([]float64{}, b...)
+					Trsm(side, uplo, tA, diag, m, n, 3, a, lda, x, ldb)
+
+					// Multiply X back by the triangle of op(A).
+					tri := func(i, j int) float64 {
+						if i == j && diag == Unit {
+							return 1
+						}
+						si, sj := i, j
+						if tA == Trans {
+							si, sj = j, i
+						}
+						if (uplo == Upper && sj < si) || (uplo == Lower && sj > si) {
+							return 0
+						}
+						return a[si*lda+sj]
+					}
+					for r := 0; r < m; r++ {
+						for col := 0; col < n; col++ {
+							sum := 0.0
+							if side == Left {
+								for l := 0; l < m; l++ {
+									sum += tri(r, l) * x[l*ldb+col]
+								}
+							} else {
+								for l := 0; l < n; l++ {
+									sum += x[r*ldb+l] * tri(l, col)
+								}
+							}
+							if diff := math.Abs(sum - 3*b[r*ldb+col]); diff > 1e-12 {
+								t.Errorf("Trsm(%d, %d, %d, %d) residual is %g at (%d, %d)",
+									side, uplo, tA, diag, diff, col, r)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPanics(t *testing.T) {
+	a := make([]float64, 6)
+	tests := []func(){
+		func() { Axpy(3, 1, a, 0, a, 1) },
+		func() { Axpy(4, 1, a, 2, a, 1) },
+		func() { Gemv(NoTrans, 2, 3, 1, a, 2, a, 1, 0, a, 1) },
+		func() { Gemm(NoTrans, NoTrans, 3, 3, 3, 1, a, 3, a, 3, 0, a, 3) },
+		func() { Syrk(Upper, NoTrans, -1, 2, 1, a, 2, 0, a, 2) },
+		func() { Trsm(Left, Upper, NoTrans, Unit, 2, 4, 1, a, 2, a, 4) },
+	}
+	for i, f := range tests {
+		if !panics(f) {
+			t.Errorf("%d) Expected panic", i)
+		}
+	}
+}
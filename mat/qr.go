@@ -0,0 +1,443 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+// QR represents the QR decomposition of a Matrix, A, with at least as many
+// rows as columns. The decomposition satisfies A P = Q R, where P is a
+// permutation Matrix, Q has orthonormal columns, and R is upper triangular.
+// P is the identity unless the decomposition was computed by NewQRPivoted.
+//
+// Q is stored implicitly as a product of Householder reflections, so the
+// decomposition takes no more space than A itself.
+type QR struct {
+	width, height int
+	qr   []float64 // R on and above the diagonal, reflectors below it.
+	tau  []float64 // Scale factors of the Householder reflectors.
+	perm []int     // Column j of A P is column perm[j] of A.
+	rank int
+
+	pivoted bool
+}
+
+// NewQR computes the QR decomposition of m without column pivoting. m is not
+// modified.
+//
+// If m is nil, an error Matrix, or has more columns than rows, a non-nil error
+// is returned. Rank deficient matrices can be decomposed, but cannot be used
+// to solve linear systems.
+func NewQR(m *Matrix) (*QR, error) {
+	f, err := newQR("NewQR", m, false)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// NewQRPivoted computes the QR decomposition of m with column pivoting. At
+// each step the remaining column with the largest norm is moved to the front,
+// so the magnitudes of the diagonal elements of R are non-increasing and
+// reveal the numerical rank of m. m is not modified.
+//
+// Pivoted decompositions can be used to find least-squares solutions of rank
+// deficient systems.
+//
+// If m is nil, an error Matrix, or has more columns than rows, a non-nil error
+// is returned.
+func NewQRPivoted(m *Matrix) (*QR, error) {
+	f, err := newQR("NewQRPivoted", m, true)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newQR(operationName string, m *Matrix, pivot bool) (*QR, *MatrixError) {
+	m = dense(m)
+	if err := inputError(operationName, m); err != nil {
+		return nil, err
+	} else if m.width > m.height {
+		desc := fmt.Sprintf("Matrix shape (%d, %d) has more columns than rows.",
+			m.width, m.height)
+		return nil, newError(ShapeError, operationName, desc)
+	}
+
+	w, h := m.width, m.height
+	f := &QR{
+		width: w, height: h, qr: make([]float64, w * h),
+		tau: make([]float64, w), perm: make([]int, w), pivoted: pivot,
+	}
+	copy(f.qr, m.values)
+	for j := range f.perm {
+		f.perm[j] = j
+	}
+
+	a := f.qr
+	var norms, origNorms []float64
+	if pivot {
+		norms, origNorms = make([]float64, w), make([]float64, w)
+		for j := range norms {
+			norms[j] = colNorm(a, w, j, 0, h)
+			origNorms[j] = norms[j]
+		}
+	}
+
+	work := make([]float64, w)
+	for k := 0; k < w; k++ {
+		if pivot {
+			p := k
+			for j := k + 1; j < w; j++ {
+				if norms[j] > norms[p] {
+					p = j
+				}
+			}
+			if p != k {
+				for i := 0; i < h; i++ {
+					a[i * w + k], a[i * w + p] = a[i * w + p], a[i * w + k]
+				}
+				f.perm[k], f.perm[p] = f.perm[p], f.perm[k]
+				norms[k], norms[p] = norms[p], norms[k]
+				origNorms[k], origNorms[p] = origNorms[p], origNorms[k]
+			}
+		}
+
+		f.tau[k] = householder(a, w, k, h)
+		applyReflector(a, w, k, h, f.tau[k], a, w, k + 1, work)
+
+		if pivot {
+			// Downdate the norms of the remaining columns. When too much
+			// cancellation has occurred, recompute them from scratch.
+			for j := k + 1; j < w; j++ {
+				if norms[j] == 0 {
+					continue
+				}
+				ratio := math.Abs(a[k * w + j]) / norms[j]
+				temp := math.Max(0, 1 - ratio * ratio)
+				scaled := norms[j] / origNorms[j]
+				if temp * scaled * scaled <= math.Sqrt(machineEpsilon) {
+					norms[j] = colNorm(a, w, j, k + 1, h)
+					origNorms[j] = norms[j]
+				} else {
+					norms[j] *= math.Sqrt(temp)
+				}
+			}
+		}
+	}
+
+	maxDiag := 0.0
+	for k := 0; k < w; k++ {
+		maxDiag = math.Max(maxDiag, math.Abs(a[k * w + k]))
+	}
+	tol := float64(h) * machineEpsilon * maxDiag
+	for k := 0; k < w; k++ {
+		if math.Abs(a[k * w + k]) > tol {
+			f.rank++
+		} else if pivot {
+			break
+		}
+	}
+
+	return f, nil
+}
+
+// colNorm returns the 2-norm of rows [start, end) of column j of the
+// row-major matrix a with width w.
+func colNorm(a []float64, w, j, start, end int) float64 {
+	scale, ssq := 0.0, 1.0
+	for i := start; i < end; i++ {
+		v := math.Abs(a[i * w + j])
+		if v == 0 {
+			continue
+		} else if v > scale {
+			ssq = 1 + ssq * (scale / v) * (scale / v)
+			scale = v
+		} else {
+			ssq += (v / scale) * (v / scale)
+		}
+	}
+	return scale * math.Sqrt(ssq)
+}
+
+// householder computes the Householder reflection which zeroes the elements
+// of column k below row k of the row-major matrix a with width w and height h.
+// The reflected diagonal element is stored at a[k][k], the reflector's vector
+// (with an implicit leading one) is stored below it, and its scale factor is
+// returned.
+func householder(a []float64, w, k, h int) float64 {
+	alpha := a[k * w + k]
+	xNorm := colNorm(a, w, k, k + 1, h)
+	if xNorm == 0 {
+		return 0
+	}
+
+	beta := -math.Copysign(math.Hypot(alpha, xNorm), alpha)
+	tau := (beta - alpha) / beta
+	scale := 1 / (alpha - beta)
+	for i := k + 1; i < h; i++ {
+		a[i * w + k] *= scale
+	}
+	a[k * w + k] = beta
+	return tau
+}
+
+// applyReflector applies the reflector H = I - tau v v^T stored in column k
+// of the row-major matrix a with width w to columns [start, bw) of rows
+// [k, h) of the row-major matrix b with width bw. work must have a length of
+// at least bw.
+func applyReflector(
+	a []float64, w, k, h int, tau float64,
+	b []float64, bw, start int, work []float64,
+) {
+	if tau == 0 || start >= bw {
+		return
+	}
+
+	// work = v^T b
+	copy(work[start: bw], b[k * bw + start: (k + 1) * bw])
+	for i := k + 1; i < h; i++ {
+		v := a[i * w + k]
+		if v == 0 {
+			continue
+		}
+		row := b[i * bw + start: (i + 1) * bw]
+		for j, bij := range row {
+			work[start + j] += v * bij
+		}
+	}
+
+	// b -= tau v work
+	row := b[k * bw + start: (k + 1) * bw]
+	for j := range row {
+		row[j] -= tau * work[start + j]
+	}
+	for i := k + 1; i < h; i++ {
+		v := tau * a[i * w + k]
+		if v == 0 {
+			continue
+		}
+		row := b[i * bw + start: (i + 1) * bw]
+		for j := range row {
+			row[j] -= v * work[start + j]
+		}
+	}
+}
+
+// Rank returns the numerical rank of the decomposed Matrix: the number of
+// diagonal elements of R which are larger than height * epsilon times the
+// largest diagonal element. Rank is only reliable for decompositions computed
+// by NewQRPivoted.
+func (f *QR) Rank() int {
+	return f.rank
+}
+
+// IsFullRank returns true if the decomposed Matrix has full column rank.
+func (f *QR) IsFullRank() bool {
+	return f.rank == f.width
+}
+
+// Q returns the orthonormal factor of the decomposition. Q has the same
+// shape as A.
+func (f *QR) Q() *Matrix {
+	w, h := f.width, f.height
+	q := New(w, h)
+	for i := 0; i < w; i++ {
+		q.values[i * w + i] = 1
+	}
+
+	work := make([]float64, w)
+	for k := w - 1; k >= 0; k-- {
+		applyReflector(f.qr, w, k, h, f.tau[k], q.values, w, k, work)
+	}
+	return q
+}
+
+// R returns the upper triangular factor of the decomposition. R is square
+// with the same width as A.
+func (f *QR) R() *Matrix {
+	w := f.width
+	r := New(w, w)
+	for i := 0; i < w; i++ {
+		copy(r.values[i * w + i: (i + 1) * w], f.qr[i * w + i: (i + 1) * w])
+	}
+	return r
+}
+
+// Pivots returns the column permutation of the decomposition: column j of
+// A P is column Pivots()[j] of A.
+func (f *QR) Pivots() []int {
+	perm := make([]int, f.width)
+	copy(perm, f.perm)
+	return perm
+}
+
+// Solve finds the least-squares solution, X, to the linear system A X = B,
+// where each column of B is a separate right-hand side. X minimizes the
+// 2-norm of each column of A X - B. If A is square and non-singular, X is
+// the exact solution.
+//
+// If the decomposition was computed by NewQRPivoted and A is rank deficient,
+// the basic solution is returned: the elements of X which correspond to the
+// trailing Width() - Rank() columns of A P are zero.
+//
+// If b is nil or does not have the same height as A, or if A is rank
+// deficient and the decomposition was computed without pivoting, an error
+// Matrix is returned.
+func (f *QR) Solve(b *Matrix) *Matrix {
+	x, _ := f.solveLS("Solve", b)
+	return x
+}
+
+// SolveLS finds the least-squares solution, X, to the linear system A X = B
+// in the same way as Solve. It also returns the 2-norm of the residual of each
+// column of X, |A x - b|.
+//
+// If b is nil or does not have the same height as A, or if A is rank
+// deficient and the decomposition was computed without pivoting, an error
+// Matrix and a nil slice are returned.
+func (f *QR) SolveLS(b *Matrix) (*Matrix, []float64) {
+	return f.solveLS("SolveLS", b)
+}
+
+// SolveQR finds the least-squares solution, X, to the linear system A X = B
+// using the decomposition f and stores it in the target Matrix. The target
+// Matrix is also returned.
+//
+// If b is nil or does not have the same height as A, if A is rank deficient
+// and the decomposition was computed without pivoting, or if target does not
+// have the same width as b and the same height as A's width, target is set to
+// an error Matrix.
+func (target *Matrix) SolveQR(f *QR, b *Matrix) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.SolveQR(f, b) })
+	}
+
+	if err := inputError("SolveQR", b); err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "SolveQR", "Target Matrix is nil.")
+	}
+	target.solveQR("SolveQR", f, b)
+	return target
+}
+
+func (f *QR) solveLS(operationName string, b *Matrix) (*Matrix, []float64) {
+	if err := inputError(operationName, b); err != nil {
+		return newErrorMatrixFrom(err), nil
+	}
+	x := New(b.width, f.width)
+	resid := x.solveQR(operationName, f, b)
+	return x, resid
+}
+
+// solveQR stores the least-squares solution of A X = B in target and returns
+// the residual norms of each column. b and target must be non-nil and valid.
+func (target *Matrix) solveQR(operationName string, f *QR, b *Matrix) []float64 {
+	b = dense(b)
+	if b.height != f.height {
+		desc := fmt.Sprintf("Right-hand side height %d does not match Matrix height %d.",
+			b.height, f.height)
+		target.setError(newError(ShapeError, operationName, desc))
+		return nil
+	} else if target.width != b.width || target.height != f.width {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match solution shape (%d, %d).",
+			target.width, target.height, b.width, f.width)
+		target.setError(newError(ShapeError, operationName, desc))
+		return nil
+	} else if f.rank < f.width && !f.pivoted {
+		target.setError(newError(SingularError, operationName,
+			"Matrix is rank deficient."))
+		return nil
+	}
+
+	w, h, bw, r := f.width, f.height, b.width, f.rank
+
+	// qtb = Q^T B
+	qtb := make([]float64, len(b.values))
+	copy(qtb, b.values)
+	work := make([]float64, bw)
+	for k := 0; k < w; k++ {
+		applyReflector(f.qr, w, k, h, f.tau[k], qtb, bw, 0, work)
+	}
+
+	resid := make([]float64, bw)
+	for j := range resid {
+		resid[j] = colNorm(qtb, bw, j, r, h)
+	}
+
+	// Back substitution with the leading r x r block of R.
+	for i := r - 1; i >= 0; i-- {
+		zi := qtb[i * bw: (i + 1) * bw]
+		for k := i + 1; k < r; k++ {
+			rik := f.qr[i * w + k]
+			zk := qtb[k * bw: (k + 1) * bw]
+			for j := range zi {
+				zi[j] -= rik * zk[j]
+			}
+		}
+		d := f.qr[i * w + i]
+		for j := range zi {
+			zi[j] /= d
+		}
+	}
+
+	for i := range target.values {
+		target.values[i] = 0
+	}
+	for i := 0; i < r; i++ {
+		p := f.perm[i]
+		copy(target.values[p * bw: (p + 1) * bw], qtb[i * bw: (i + 1) * bw])
+	}
+
+	target.err = nil
+	return resid
+}
+
+// SolveLS finds the least-squares solution, X, to the linear system A X = B,
+// where each column of B is a separate right-hand side, and returns it along
+// with the 2-norm of the residual of each column, |A x - b|. A column pivoted
+// QR decomposition is used, so rank deficient systems are allowed: in this
+// case the basic solution described in QR.Solve is returned.
+//
+// If a or b is nil, if a has more columns than rows, or if b does not have the
+// same height as a, an error Matrix and a nil slice are returned.
+func SolveLS(a, b *Matrix) (*Matrix, []float64) {
+	if err := inputError("SolveLS", a, b); err != nil {
+		return newErrorMatrixFrom(err), nil
+	}
+	return New(b.width, a.width).SolveLS(a, b)
+}
+
+// SolveLS finds the least-squares solution, X, to the linear system A X = B
+// in the same way as the function SolveLS and stores it in the target Matrix.
+// The target Matrix and the residual norms of each column are returned.
+//
+// If a or b is nil, if a has more columns than rows, if b does not have the
+// same height as a, or if target does not have the same width as b and the
+// same height as a's width, target is set to an error Matrix and a nil slice
+// is returned.
+func (target *Matrix) SolveLS(a, b *Matrix) (*Matrix, []float64) {
+	if !target.contiguous() {
+		var resid []float64
+		target.viaDense(func(t *Matrix) *Matrix {
+			_, resid = t.SolveLS(a, b)
+			return t
+		})
+		if target.IsError() {
+			return target, nil
+		}
+		return target, resid
+	}
+
+	f, err := newQR("SolveLS", a, true)
+	if err != nil {
+		return target.setError(err), nil
+	} else if err := inputError("SolveLS", b); err != nil {
+		return target.setError(err), nil
+	} else if target == nil {
+		return newErrorMatrix(NilError, "SolveLS", "Target Matrix is nil."), nil
+	}
+	resid := target.solveQR("SolveLS", f, b)
+	return target, resid
+}
@@ -0,0 +1,168 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQR(t *testing.T) {
+	tests := []*Matrix{
+		FromSlice(2, 2, []float64{0, 1, 1, 0}),
+		FromSlice(2, 3, []float64{1, 2, 3, 4, 5, 6}),
+		FromSlice(3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 10}),
+		randomMatrix(15, 40),
+	}
+
+	for i, m := range tests {
+		for _, pivot := range []bool{false, true} {
+			var f *QR
+			var err error
+			if pivot {
+				f, err = NewQRPivoted(m)
+			} else {
+				f, err = NewQR(m)
+			}
+			if err != nil {
+				t.Errorf("%d) NewQR (pivot = %v) returned error: %s",
+					i, pivot, err)
+				continue
+			}
+
+			q, r := f.Q(), f.R()
+			qtq := Mult(Transpose(q), q)
+			if diff := maxDiff(qtq, Identity(m.width)); diff > 1e-13 {
+				t.Errorf("%d) Q^T Q differs from I by %g (pivot = %v)",
+					i, diff, pivot)
+			}
+
+			// Check that A P = Q R.
+			ap := New(m.width, m.height)
+			for j, p := range f.Pivots() {
+				for y := 0; y < m.height; y++ {
+					ap.Set(j, y, m.Get(p, y))
+				}
+			}
+			if diff := maxDiff(ap, Mult(q, r)); diff > 1e-13 {
+				t.Errorf("%d) A P and Q R differ by %g (pivot = %v)",
+					i, diff, pivot)
+			}
+
+			if pivot {
+				for k := 1; k < m.width; k++ {
+					if math.Abs(r.Get(k, k)) > math.Abs(r.Get(k-1, k-1)) {
+						t.Errorf("%d) Pivoted R has increasing diagonal %v",
+							i, r.Slice())
+						break
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := NewQR(New(3, 2)); err == nil {
+		t.Errorf("NewQR of wide Matrix returned nil error.")
+	}
+	if _, err := NewQRPivoted(nil); err == nil {
+		t.Errorf("NewQRPivoted of nil Matrix returned nil error.")
+	}
+}
+
+func TestSolveLS(t *testing.T) {
+	// Fit a line to four points. The exact answer is y = 3.5 + 1.4 x, with
+	// residuals of (1.1, -1.3, -0.7, 0.9).
+	a := FromSlice(2, 4, []float64{1, 0, 1, 1, 1, 2, 1, 3})
+	b := FromSlice(1, 4, []float64{4.6, 3.6, 5.6, 8.6})
+	bExact := FromSlice(1, 4, []float64{3.5, 4.9, 6.3, 7.7})
+	wantResid := math.Sqrt(1.1*1.1 + 1.3*1.3 + 0.7*0.7 + 0.9*0.9)
+
+	x, resid := SolveLS(a, b)
+	if x.IsError() {
+		t.Fatalf("SolveLS returned error: %s", x.Error())
+	} else if math.Abs(x.Get(0, 0)-3.5) > 1e-12 ||
+		math.Abs(x.Get(0, 1)-1.4) > 1e-12 {
+		t.Errorf("SolveLS(a, b) = %v, wanted [3.5 1.4]", x.Slice())
+	} else if len(resid) != 1 || math.Abs(resid[0]-wantResid) > 1e-12 {
+		t.Errorf("SolveLS(a, b) residuals = %v, wanted [%g]", resid, wantResid)
+	}
+
+	// Both columns at once.
+	b2 := New(2, 4)
+	for y := 0; y < 4; y++ {
+		b2.Set(0, y, b.Get(0, y))
+		b2.Set(1, y, bExact.Get(0, y))
+	}
+	f, _ := NewQR(a)
+	x2, resid2 := f.SolveLS(b2)
+	if math.Abs(x2.Get(1, 0)-3.5) > 1e-12 || math.Abs(x2.Get(1, 1)-1.4) > 1e-12 {
+		t.Errorf("f.SolveLS(b2) = %v, wanted second column [3.5 1.4]",
+			x2.Slice())
+	} else if math.Abs(resid2[0]-wantResid) > 1e-12 || resid2[1] > 1e-12 {
+		t.Errorf("f.SolveLS(b2) residuals = %v, wanted [%g 0]",
+			resid2, wantResid)
+	}
+
+	target := New(1, 2)
+	if res := target.SolveQR(f, b); res != target || maxDiff(res, x) > 1e-12 {
+		t.Errorf("target.SolveQR(f, b) = %v, wanted %v",
+			res.Slice(), x.Slice())
+	}
+}
+
+func TestSolveLSRankDeficient(t *testing.T) {
+	// The third column is the sum of the first two.
+	a := FromSlice(3, 4, []float64{
+		1, 0, 1,
+		0, 1, 1,
+		1, 1, 2,
+		1, -1, 0,
+	})
+	b := FromSlice(1, 4, []float64{1, 2, 3, -1})
+
+	f, _ := NewQRPivoted(a)
+	if f.Rank() != 2 || f.IsFullRank() {
+		t.Errorf("Rank() = %d, wanted 2", f.Rank())
+	}
+
+	x, resid := f.SolveLS(b)
+	if x.IsError() {
+		t.Fatalf("SolveLS returned error: %s", x.Error())
+	}
+	zeros := 0
+	for _, v := range x.Slice() {
+		if v == 0 {
+			zeros++
+		}
+	}
+	if zeros != 1 {
+		t.Errorf("Basic solution %v does not have exactly one zero.",
+			x.Slice())
+	}
+	if diff := maxDiff(Mult(a, x), b); diff > 1e-12 || resid[0] > 1e-12 {
+		t.Errorf("A x - b = %g, residual = %g for consistent system.",
+			diff, resid[0])
+	}
+
+	g, _ := NewQR(a)
+	if errorCode(g.Solve(b)) != SingularError {
+		t.Errorf("Unpivoted Solve of rank deficient system did not give SingularError.")
+	}
+
+	errTests := []struct {
+		name string
+		m    *Matrix
+		code int
+	}{
+		{"f.Solve(wrong height)", f.Solve(New(1, 3)), ShapeError},
+		{"f.Solve(nil)", f.Solve(nil), NilError},
+		{"SolveLS(wide, b)", first(SolveLS(New(3, 2), New(1, 2))), ShapeError},
+		{"target.SolveQR(shape)", New(2, 2).SolveQR(f, b), ShapeError},
+	}
+	for _, test := range errTests {
+		if errorCode(test.m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(test.m), test.code)
+		}
+	}
+}
+
+func first(m *Matrix, _ []float64) *Matrix { return m }
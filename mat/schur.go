@@ -0,0 +1,668 @@
+package mat
+
+import (
+	"math"
+	"math/cmplx"
+	"sync"
+)
+
+const (
+	// schurMaxIters is the maximum number of Francis QR steps performed per
+	// eigenvalue before giving up. Exceptional shifts are used after 10 and
+	// 20 steps.
+	schurMaxIters = 50
+	// balanceRadix is the base of the scale factors used when balancing.
+	// Powers of the floating point radix are used so that balancing introduces
+	// no rounding errors.
+	balanceRadix = 2
+)
+
+// Eigen represents the eigendecomposition of a general real square Matrix, A.
+// Eigenvalues and eigenvectors of non-symmetric matrices are complex in
+// general. Complex eigenvalues come in conjugate pairs, which are always
+// adjacent with the member with a positive imaginary part first.
+//
+// Internally, Eigen stores the complex Schur decomposition A = D Q T Q^H
+// D^-1, where D is a diagonal balancing Matrix, Q is unitary, and T is upper
+// triangular with the eigenvalues on its diagonal. Right and left
+// eigenvectors are computed from this decomposition the first time they are
+// requested. An Eigen is safe for concurrent use.
+type Eigen struct {
+	n      int
+	values []complex128
+	t      []complex128 // Complex Schur form, upper triangular.
+	q      []complex128 // Schur vectors of the balanced Matrix.
+	scale  []float64    // Diagonal of the balancing Matrix, D.
+
+	rightOnce, leftOnce sync.Once
+	right, left         [][]complex128
+}
+
+// NewEigen computes the eigenvalues and the Schur decomposition of m. m is
+// first balanced by a diagonal similarity transformation to reduce the norm
+// of the Matrix, then reduced to upper Hessenberg form with Householder
+// reflections, and then reduced to real Schur form with the shifted Francis
+// double-shift QR algorithm. m is not modified.
+//
+// If m is nil, an error Matrix, or not square, a non-nil error is returned.
+// If the QR algorithm fails to converge, an IterationError is returned.
+func NewEigen(m *Matrix) (*Eigen, error) {
+	f, err := newEigen("NewEigen", m)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newEigen(operationName string, m *Matrix) (*Eigen, *MatrixError) {
+	m = dense(m)
+	if err := squareError(operationName, m); err != nil {
+		return nil, err
+	}
+
+	n := m.width
+	h := make([]float64, n * n)
+	copy(h, m.values)
+
+	scale := balance(h, n)
+	v := hessenberg(h, n)
+	d, e, ok := francisQR(h, v, n)
+	if !ok {
+		return nil, newError(IterationError, operationName,
+			"Francis QR algorithm failed to converge.")
+	}
+
+	f := &Eigen{n: n, scale: scale}
+	f.complexSchur(h, v, d, e)
+	f.values = make([]complex128, n)
+	for i := range f.values {
+		f.values[i] = f.t[i * n + i]
+	}
+	return f, nil
+}
+
+// Values returns the eigenvalues of A. Complex conjugate pairs are adjacent,
+// with the member with a positive imaginary part first. Real eigenvalues have
+// an imaginary part of exactly zero.
+func (f *Eigen) Values() []complex128 {
+	values := make([]complex128, f.n)
+	copy(values, f.values)
+	return values
+}
+
+// Vectors returns the right eigenvectors of A, x, which satisfy A x = lambda
+// x. Vectors()[j] corresponds to Values()[j]. Each eigenvector has a 2-norm of
+// one and is scaled so that its element with the largest magnitude is real
+// and positive. The eigenvectors of real eigenvalues are real.
+func (f *Eigen) Vectors() [][]complex128 {
+	f.rightOnce.Do(func() { f.right = f.eigenvectors(false) })
+	return copyVectors(f.right)
+}
+
+// LeftVectors returns the left eigenvectors of A, y, which satisfy
+// y^H A = lambda y^H. LeftVectors()[j] corresponds to Values()[j] and is
+// normalized in the same way as the vectors returned by Vectors().
+func (f *Eigen) LeftVectors() [][]complex128 {
+	f.leftOnce.Do(func() { f.left = f.eigenvectors(true) })
+	return copyVectors(f.left)
+}
+
+// Conditions returns the condition number of each eigenvalue of A,
+// 1 / |y^H x|, where x and y are the corresponding normalized right and left
+// eigenvectors. An eigenvalue perturbed by a small change to A, E, moves by
+// approximately its condition number times |E|. Condition numbers are at
+// least one, and are exactly one for every eigenvalue of a normal Matrix.
+// Conditions()[j] corresponds to Values()[j].
+func (f *Eigen) Conditions() []float64 {
+	f.rightOnce.Do(func() { f.right = f.eigenvectors(false) })
+	f.leftOnce.Do(func() { f.left = f.eigenvectors(true) })
+
+	cond := make([]float64, f.n)
+	for j := range cond {
+		var dot complex128
+		for i, x := range f.right[j] {
+			dot += cmplx.Conj(f.left[j][i]) * x
+		}
+		cond[j] = 1 / cmplx.Abs(dot)
+	}
+	return cond
+}
+
+func copyVectors(vecs [][]complex128) [][]complex128 {
+	out := make([][]complex128, len(vecs))
+	for i := range vecs {
+		out[i] = make([]complex128, len(vecs[i]))
+		copy(out[i], vecs[i])
+	}
+	return out
+}
+
+// balance scales the rows and columns of the row-major n x n matrix a with a
+// diagonal similarity transformation, a = D^-1 a D, so that the norms of each
+// row and its corresponding column are approximately equal. The diagonal of
+// D is returned.
+//
+// This follows the scaling step of the EISPACK routine balanc.
+func balance(a []float64, n int) []float64 {
+	scale := make([]float64, n)
+	for i := range scale {
+		scale[i] = 1
+	}
+
+	const sqrdx = balanceRadix * balanceRadix
+	for converged := false; !converged; {
+		converged = true
+		for i := 0; i < n; i++ {
+			c, r := 0.0, 0.0
+			for j := 0; j < n; j++ {
+				if j != i {
+					c += math.Abs(a[j * n + i])
+					r += math.Abs(a[i * n + j])
+				}
+			}
+			if c == 0 || r == 0 {
+				continue
+			}
+
+			g, f, s := r / balanceRadix, 1.0, c + r
+			for c < g {
+				f *= balanceRadix
+				c *= sqrdx
+			}
+			g = r * balanceRadix
+			for c > g {
+				f /= balanceRadix
+				c /= sqrdx
+			}
+
+			if (c + r) / f < 0.95 * s {
+				converged = false
+				scale[i] *= f
+				for j := 0; j < n; j++ {
+					a[i * n + j] /= f
+					a[j * n + i] *= f
+				}
+			}
+		}
+	}
+
+	return scale
+}
+
+// hessenberg reduces the row-major n x n matrix h to upper Hessenberg form
+// with Householder reflections and returns the accumulated orthogonal
+// transformation.
+//
+// This follows the EISPACK routines orthes and ortran.
+func hessenberg(h []float64, n int) []float64 {
+	ort := make([]float64, n)
+	for m := 1; m < n - 1; m++ {
+		scale := 0.0
+		for i := m; i < n; i++ {
+			scale += math.Abs(h[i * n + m - 1])
+		}
+		if scale == 0 {
+			continue
+		}
+
+		// Compute the Householder transformation.
+		hh := 0.0
+		for i := n - 1; i >= m; i-- {
+			ort[i] = h[i * n + m - 1] / scale
+			hh += ort[i] * ort[i]
+		}
+		g := math.Sqrt(hh)
+		if ort[m] > 0 {
+			g = -g
+		}
+		hh -= ort[m] * g
+		ort[m] -= g
+
+		// Apply it from the left and the right.
+		for j := m; j < n; j++ {
+			f := 0.0
+			for i := n - 1; i >= m; i-- {
+				f += ort[i] * h[i * n + j]
+			}
+			f /= hh
+			for i := m; i < n; i++ {
+				h[i * n + j] -= f * ort[i]
+			}
+		}
+		for i := 0; i < n; i++ {
+			f := 0.0
+			for j := n - 1; j >= m; j-- {
+				f += ort[j] * h[i * n + j]
+			}
+			f /= hh
+			for j := m; j < n; j++ {
+				h[i * n + j] -= f * ort[j]
+			}
+		}
+		ort[m] *= scale
+		h[m * n + m - 1] = scale * g
+	}
+
+	// Accumulate the transformations.
+	v := make([]float64, n * n)
+	for i := 0; i < n; i++ {
+		v[i * n + i] = 1
+	}
+	for m := n - 2; m >= 1; m-- {
+		if h[m * n + m - 1] == 0 {
+			continue
+		}
+		for i := m + 1; i < n; i++ {
+			ort[i] = h[i * n + m - 1]
+		}
+		for j := m; j < n; j++ {
+			g := 0.0
+			for i := m; i < n; i++ {
+				g += ort[i] * v[i * n + j]
+			}
+			g = (g / ort[m]) / h[m * n + m - 1]
+			for i := m; i < n; i++ {
+				v[i * n + j] += g * ort[i]
+			}
+		}
+	}
+
+	// The reflectors stored below the subdiagonal are no longer needed.
+	for i := 2; i < n; i++ {
+		for j := 0; j < i - 1; j++ {
+			h[i * n + j] = 0
+		}
+	}
+	return v
+}
+
+// francisQR reduces the row-major upper Hessenberg n x n matrix h to real
+// Schur form with the shifted Francis double-shift QR algorithm and applies
+// the transformations to the columns of v. The real and imaginary parts of
+// the eigenvalues are returned in d and e. Complex conjugate pairs occupy a
+// 2 x 2 block on the diagonal of h, and have e[i] > 0 and e[i + 1] < 0. ok is
+// false if the algorithm failed to converge.
+//
+// This follows the iteration in the EISPACK routine hqr2.
+func francisQR(h, v []float64, nn int) (d, e []float64, ok bool) {
+	d, e = make([]float64, nn), make([]float64, nn)
+	at := func(i, j int) *float64 { return &h[i * nn + j] }
+
+	norm := 0.0
+	for i := 0; i < nn; i++ {
+		for j := maxInt(i - 1, 0); j < nn; j++ {
+			norm += math.Abs(h[i * nn + j])
+		}
+	}
+
+	n, iter, exshift := nn - 1, 0, 0.0
+	var p, q, r, s, w, x, y, z float64
+	for n >= 0 {
+		// Look for a single small subdiagonal element.
+		l := n
+		for l > 0 {
+			s = math.Abs(*at(l - 1, l - 1)) + math.Abs(*at(l, l))
+			if s == 0 {
+				s = norm
+			}
+			if math.Abs(*at(l, l - 1)) <= machineEpsilon * s {
+				break
+			}
+			l--
+		}
+
+		if l == n {
+			// One root found.
+			*at(n, n) += exshift
+			d[n], e[n] = *at(n, n), 0
+			if n > 0 {
+				*at(n, n - 1) = 0
+			}
+			n--
+			iter = 0
+			continue
+		} else if l == n - 1 {
+			// Two roots found.
+			w = *at(n, n - 1) * *at(n - 1, n)
+			p = (*at(n - 1, n - 1) - *at(n, n)) / 2
+			q = p * p + w
+			z = math.Sqrt(math.Abs(q))
+			*at(n, n) += exshift
+			*at(n - 1, n - 1) += exshift
+			x = *at(n, n)
+
+			if q >= 0 {
+				// A real pair: rotate the block to upper triangular form.
+				z = p + math.Copysign(z, p)
+				d[n - 1] = x + z
+				d[n] = d[n - 1]
+				if z != 0 {
+					d[n] = x - w / z
+				}
+				e[n - 1], e[n] = 0, 0
+				x = *at(n, n - 1)
+				s = math.Abs(x) + math.Abs(z)
+				p, q = x / s, z / s
+				r = math.Hypot(p, q)
+				p, q = p / r, q / r
+
+				for j := n - 1; j < nn; j++ {
+					z = *at(n - 1, j)
+					*at(n - 1, j) = q * z + p * *at(n, j)
+					*at(n, j) = q * *at(n, j) - p * z
+				}
+				for i := 0; i <= n; i++ {
+					z = *at(i, n - 1)
+					*at(i, n - 1) = q * z + p * *at(i, n)
+					*at(i, n) = q * *at(i, n) - p * z
+				}
+				for i := 0; i < nn; i++ {
+					z = v[i * nn + n - 1]
+					v[i * nn + n - 1] = q * z + p * v[i * nn + n]
+					v[i * nn + n] = q * v[i * nn + n] - p * z
+				}
+				*at(n, n - 1) = 0
+			} else {
+				// A complex pair.
+				d[n - 1], d[n] = x + p, x + p
+				e[n - 1], e[n] = z, -z
+			}
+			n -= 2
+			iter = 0
+			continue
+		}
+
+		if iter >= schurMaxIters {
+			return nil, nil, false
+		}
+
+		// Form the shift.
+		x = *at(n, n)
+		y, w = 0, 0
+		if l < n {
+			y = *at(n - 1, n - 1)
+			w = *at(n, n - 1) * *at(n - 1, n)
+		}
+
+		// Exceptional shifts break cycles which the standard shift can get
+		// stuck in.
+		if iter == 10 {
+			exshift += x
+			for i := 0; i <= n; i++ {
+				*at(i, i) -= x
+			}
+			s = math.Abs(*at(n, n - 1)) + math.Abs(*at(n - 1, n - 2))
+			x, y = 0.75 * s, 0.75 * s
+			w = -0.4375 * s * s
+		} else if iter == 20 {
+			s = (y - x) / 2
+			s = s * s + w
+			if s > 0 {
+				s = math.Sqrt(s)
+				if y < x {
+					s = -s
+				}
+				s = x - w / ((y - x) / 2 + s)
+				for i := 0; i <= n; i++ {
+					*at(i, i) -= s
+				}
+				exshift += s
+				x, y, w = 0.964, 0.964, 0.964
+			}
+		}
+		iter++
+
+		// Look for two consecutive small subdiagonal elements.
+		m := n - 2
+		for ; m >= l; m-- {
+			z = *at(m, m)
+			r = x - z
+			s = y - z
+			p = (r * s - w) / *at(m + 1, m) + *at(m, m + 1)
+			q = *at(m + 1, m + 1) - z - r - s
+			r = *at(m + 2, m + 1)
+			s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+			p, q, r = p / s, q / s, r / s
+			if m == l {
+				break
+			}
+			lhs := math.Abs(*at(m, m - 1)) * (math.Abs(q) + math.Abs(r))
+			rhs := machineEpsilon * (math.Abs(p) * (math.Abs(*at(m - 1, m - 1)) +
+				math.Abs(z) + math.Abs(*at(m + 1, m + 1))))
+			if lhs < rhs {
+				break
+			}
+		}
+
+		for i := m + 2; i <= n; i++ {
+			*at(i, i - 2) = 0
+			if i > m + 2 {
+				*at(i, i - 3) = 0
+			}
+		}
+
+		// Double QR step on rows l to n and columns m to n.
+		for k := m; k <= n - 1; k++ {
+			notLast := k != n - 1
+			if k != m {
+				p, q, r = *at(k, k - 1), *at(k + 1, k - 1), 0
+				if notLast {
+					r = *at(k + 2, k - 1)
+				}
+				x = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				if x == 0 {
+					continue
+				}
+				p, q, r = p / x, q / x, r / x
+			}
+
+			s = math.Sqrt(p * p + q * q + r * r)
+			if p < 0 {
+				s = -s
+			}
+			if s == 0 {
+				continue
+			}
+
+			if k != m {
+				*at(k, k - 1) = -s * x
+			} else if l != m {
+				*at(k, k - 1) = -*at(k, k - 1)
+			}
+			p += s
+			x, y, z = p / s, q / s, r / s
+			q, r = q / p, r / p
+
+			for j := k; j < nn; j++ {
+				p = *at(k, j) + q * *at(k + 1, j)
+				if notLast {
+					p += r * *at(k + 2, j)
+					*at(k + 2, j) -= p * z
+				}
+				*at(k, j) -= p * x
+				*at(k + 1, j) -= p * y
+			}
+			for i := 0; i <= minInt(n, k + 3); i++ {
+				p = x * *at(i, k) + y * *at(i, k + 1)
+				if notLast {
+					p += z * *at(i, k + 2)
+					*at(i, k + 2) -= p * r
+				}
+				*at(i, k) -= p
+				*at(i, k + 1) -= p * q
+			}
+			for i := 0; i < nn; i++ {
+				p = x * v[i * nn + k] + y * v[i * nn + k + 1]
+				if notLast {
+					p += z * v[i * nn + k + 2]
+					v[i * nn + k + 2] -= p * r
+				}
+				v[i * nn + k] -= p
+				v[i * nn + k + 1] -= p * q
+			}
+		}
+	}
+
+	return d, e, true
+}
+
+// complexSchur converts the real Schur decomposition with quasi-triangular
+// factor h and orthogonal factor v into a complex Schur decomposition by
+// triangularizing each 2 x 2 block with a unitary rotation. e gives the
+// imaginary parts of the eigenvalues, as returned by francisQR.
+func (f *Eigen) complexSchur(h, v, d, e []float64) {
+	n := f.n
+	t, q := make([]complex128, n * n), make([]complex128, n * n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if j >= i - 1 {
+				t[i * n + j] = complex(h[i * n + j], 0)
+			}
+			q[i * n + j] = complex(v[i * n + j], 0)
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		if k > 0 {
+			t[k * n + k - 1] = 0
+		}
+		if e[k] <= 0 {
+			continue
+		}
+
+		// Triangularize the block with the eigenvector of its eigenvalue
+		// with a positive imaginary part.
+		lambda := complex(d[k], e[k])
+		a, b := t[k * n + k], t[k * n + k + 1]
+		v1, v2 := b, lambda - a
+		norm := math.Hypot(cmplx.Abs(v1), cmplx.Abs(v2))
+		v1, v2 = v1 / complex(norm, 0), v2 / complex(norm, 0)
+		g11, g12, g21, g22 := v1, -cmplx.Conj(v2), v2, cmplx.Conj(v1)
+
+		for j := 0; j < n; j++ {
+			x, y := t[k * n + j], t[(k + 1) * n + j]
+			t[k * n + j] = cmplx.Conj(g11) * x + cmplx.Conj(g21) * y
+			t[(k + 1) * n + j] = cmplx.Conj(g12) * x + cmplx.Conj(g22) * y
+		}
+		for i := 0; i < n; i++ {
+			x, y := t[i * n + k], t[i * n + k + 1]
+			t[i * n + k] = x * g11 + y * g21
+			t[i * n + k + 1] = x * g12 + y * g22
+
+			x, y = q[i * n + k], q[i * n + k + 1]
+			q[i * n + k] = x * g11 + y * g21
+			q[i * n + k + 1] = x * g12 + y * g22
+		}
+
+		t[k * n + k], t[(k + 1) * n + k + 1] = lambda, cmplx.Conj(lambda)
+		t[(k + 1) * n + k] = 0
+		k++
+	}
+
+	f.t, f.q = t, q
+}
+
+// eigenvectors computes the normalized right eigenvectors of A from its
+// Schur decomposition, or the left eigenvectors if left is true.
+func (f *Eigen) eigenvectors(left bool) [][]complex128 {
+	n, t := f.n, f.t
+
+	norm := 0.0
+	for _, x := range t {
+		norm = math.Max(norm, cmplx.Abs(x))
+	}
+	small := math.Max(machineEpsilon * norm, math.SmallestNonzeroFloat64)
+
+	// safeDiv divides by a diagonal difference, perturbing it if it is too
+	// small. This occurs for repeated eigenvalues.
+	safeDiv := func(num, den complex128) complex128 {
+		if cmplx.Abs(den) < small {
+			den = complex(small, 0)
+		}
+		return num / den
+	}
+
+	vecs := make([][]complex128, n)
+	w := make([]complex128, n)
+	for k := 0; k < n; k++ {
+		lambda := t[k * n + k]
+		for i := range w {
+			w[i] = 0
+		}
+		w[k] = 1
+
+		if !left {
+			// Solve (T - lambda I) w = 0 with back substitution.
+			for i := k - 1; i >= 0; i-- {
+				var sum complex128
+				for j := i + 1; j <= k; j++ {
+					sum += t[i * n + j] * w[j]
+				}
+				w[i] = safeDiv(-sum, t[i * n + i] - lambda)
+			}
+		} else {
+			// Solve w^T (T - lambda I) = 0 with forward substitution. The
+			// left eigenvector of T is conj(w).
+			for j := k + 1; j < n; j++ {
+				var sum complex128
+				for i := k; i < j; i++ {
+					sum += w[i] * t[i * n + j]
+				}
+				w[j] = safeDiv(-sum, t[j * n + j] - lambda)
+			}
+			for i := range w {
+				w[i] = cmplx.Conj(w[i])
+			}
+		}
+
+		// Transform back to A: x = D Q w, or y = D^-1 Q w.
+		vec := make([]complex128, n)
+		for i := 0; i < n; i++ {
+			var sum complex128
+			for j := 0; j < n; j++ {
+				sum += f.q[i * n + j] * w[j]
+			}
+			if left {
+				vec[i] = sum / complex(f.scale[i], 0)
+			} else {
+				vec[i] = sum * complex(f.scale[i], 0)
+			}
+		}
+
+		normalizeVector(vec, imag(lambda) == 0)
+		vecs[k] = vec
+	}
+
+	return vecs
+}
+
+// normalizeVector scales vec to have a 2-norm of one and its element with the
+// largest magnitude real and positive. If isReal is true, the imaginary parts
+// of vec, which should only contain rounding errors, are set to zero.
+func normalizeVector(vec []complex128, isReal bool) {
+	norm, max := 0.0, complex128(0)
+	for _, x := range vec {
+		norm = math.Hypot(norm, cmplx.Abs(x))
+		if cmplx.Abs(x) > cmplx.Abs(max) {
+			max = x
+		}
+	}
+	if norm == 0 {
+		return
+	}
+
+	phase := cmplx.Conj(max) / complex(cmplx.Abs(max) * norm, 0)
+	for i := range vec {
+		vec[i] *= phase
+		if isReal {
+			vec[i] = complex(real(vec[i]), 0)
+		}
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
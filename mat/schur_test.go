@@ -0,0 +1,189 @@
+package mat
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+	"testing"
+)
+
+// sortedValues returns the eigenvalues sorted by real and then imaginary
+// part.
+func sortedValues(values []complex128) []complex128 {
+	out := make([]complex128, len(values))
+	copy(out, values)
+	sort.Slice(out, func(i, j int) bool {
+		if real(out[i]) != real(out[j]) {
+			return real(out[i]) < real(out[j])
+		}
+		return imag(out[i]) < imag(out[j])
+	})
+	return out
+}
+
+// eigenResidual returns max |A x - lambda x| over all eigenpairs, or
+// max |y^H A - lambda y^H| if left is true.
+func eigenResidual(m *Matrix, values []complex128, vecs [][]complex128, left bool) float64 {
+	n, max := m.width, 0.0
+	for k, lambda := range values {
+		for i := 0; i < n; i++ {
+			var sum complex128
+			for j := 0; j < n; j++ {
+				if left {
+					sum += cmplx.Conj(vecs[k][j]) * complex(m.Get(i, j), 0)
+				} else {
+					sum += complex(m.Get(j, i), 0) * vecs[k][j]
+				}
+			}
+			if left {
+				sum -= lambda * cmplx.Conj(vecs[k][i])
+			} else {
+				sum -= lambda * vecs[k][i]
+			}
+			max = math.Max(max, cmplx.Abs(sum))
+		}
+	}
+	return max
+}
+
+func TestEigen(t *testing.T) {
+	tests := []struct {
+		m      *Matrix
+		values []complex128
+	}{
+		{FromSlice(1, 1, []float64{-2}), []complex128{-2}},
+		// Rotation generator.
+		{FromSlice(2, 2, []float64{0, -1, 1, 0}), []complex128{-1i, 1i}},
+		{FromSlice(2, 2, []float64{1, 2, 0, 3}), []complex128{1, 3}},
+		{FromSlice(3, 3, []float64{2, 0, 0, 0, 3, 4, 0, -4, 3}),
+			[]complex128{2, 3 - 4i, 3 + 4i}},
+		// Companion Matrix of (x - 1)(x - 2)(x - 3).
+		{FromSlice(3, 3, []float64{6, -11, 6, 1, 0, 0, 0, 1, 0}),
+			[]complex128{1, 2, 3}},
+		{New(3, 3), []complex128{0, 0, 0}},
+		// Cyclic permutation, which stalls the QR algorithm without
+		// exceptional shifts.
+		{FromSlice(4, 4, []float64{0, 0, 0, 1, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0}),
+			[]complex128{-1, -1i, 1i, 1}},
+		// Badly scaled Matrix which needs balancing.
+		{FromSlice(3, 3, []float64{1, 1e8, 0, 1e-8, 1, 1e8, 0, 1e-8, 1}), nil},
+		{randomMatrix(40, 40), nil},
+	}
+
+	for i, test := range tests {
+		f, err := NewEigen(test.m)
+		if err != nil {
+			t.Errorf("%d) NewEigen returned error: %s", i, err)
+			continue
+		}
+
+		values := f.Values()
+		if test.values != nil {
+			got := sortedValues(values)
+			for j := range got {
+				if cmplx.Abs(got[j]-test.values[j]) > 1e-12 {
+					t.Errorf("%d) Eigenvalues = %v, wanted %v",
+						i, got, test.values)
+					break
+				}
+			}
+		}
+
+		// Conjugate pairs are adjacent with the positive imaginary part
+		// first.
+		for j := 0; j < len(values); j++ {
+			if imag(values[j]) == 0 {
+				continue
+			} else if j+1 == len(values) || imag(values[j]) < 0 ||
+				values[j+1] != cmplx.Conj(values[j]) {
+				t.Errorf("%d) Eigenvalues %v are not in conjugate pairs",
+					i, values)
+				break
+			}
+			j++
+		}
+
+		scale := math.Max(1, maxAbs(test.m))
+		if r := eigenResidual(test.m, values, f.Vectors(), false); r > 1e-11*scale {
+			t.Errorf("%d) Right eigenvector residual = %g", i, r)
+		}
+		if r := eigenResidual(test.m, values, f.LeftVectors(), true); r > 1e-11*scale {
+			t.Errorf("%d) Left eigenvector residual = %g", i, r)
+		}
+
+		for j, vec := range f.Vectors() {
+			norm := 0.0
+			for _, x := range vec {
+				norm += real(x)*real(x) + imag(x)*imag(x)
+			}
+			if math.Abs(norm-1) > 1e-12 {
+				t.Errorf("%d) Eigenvector %d has norm %g", i, j, math.Sqrt(norm))
+			}
+			if imag(values[j]) == 0 {
+				for _, x := range vec {
+					if imag(x) != 0 {
+						t.Errorf("%d) Eigenvector %d of real eigenvalue is complex",
+							i, j)
+						break
+					}
+				}
+			}
+		}
+
+		for j, c := range f.Conditions() {
+			if c < 1-1e-12 || math.IsNaN(c) {
+				t.Errorf("%d) Condition number %d = %g", i, j, c)
+			}
+		}
+	}
+}
+
+func maxAbs(m *Matrix) float64 {
+	max := 0.0
+	for _, x := range m.values {
+		max = math.Max(max, math.Abs(x))
+	}
+	return max
+}
+
+func TestEigenConditions(t *testing.T) {
+	// Eigenvalues of normal matrices are perfectly conditioned.
+	f, _ := NewEigen(FromSlice(2, 2, []float64{0, -1, 1, 0}))
+	for _, c := range f.Conditions() {
+		if math.Abs(c-1) > 1e-12 {
+			t.Errorf("Condition number of normal Matrix = %g, wanted 1", c)
+		}
+	}
+
+	// For [[1, a], [0, 2]], both condition numbers are sqrt(1 + a^2).
+	a := 100.0
+	f, _ = NewEigen(FromSlice(2, 2, []float64{1, a, 0, 2}))
+	want := math.Sqrt(1 + a*a)
+	for _, c := range f.Conditions() {
+		if math.Abs(c-want) > 1e-9*want {
+			t.Errorf("Condition number = %g, wanted %g", c, want)
+		}
+	}
+}
+
+func TestEigenNonSymmetric(t *testing.T) {
+	m := FromSlice(2, 2, []float64{0, -2, 1, 0})
+	values, err := m.Eigenvalues()
+	r := math.Sqrt2
+	if err != nil || len(values) != 2 || cmplx.Abs(values[0]-complex(0, r)) > 1e-14 ||
+		cmplx.Abs(values[1]-complex(0, -r)) > 1e-14 {
+		t.Errorf("m.Eigenvalues() = (%v, %v), wanted [%gi -%gi]",
+			values, err, r, r)
+	}
+
+	vectors, err := m.Eigenvectors()
+	if err != nil || len(vectors) != 2 {
+		t.Fatalf("m.Eigenvectors() = (%v, %v)", vectors, err)
+	} else if res := eigenResidual(m, values, vectors, false); res > 1e-14 {
+		t.Errorf("m.Eigenvectors() has residual %g", res)
+	}
+
+	if _, err := NewEigen(New(2, 3)); err == nil {
+		t.Errorf("NewEigen of non-square Matrix returned nil error.")
+	}
+}
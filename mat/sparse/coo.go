@@ -0,0 +1,107 @@
+package sparse
+
+// COO is a sparse matrix in coordinate format: an unordered list of
+// (x, y, value) triplets. It is intended for assembling matrices, which
+// should then be converted to CSR or CSC format for computation.
+//
+// A COO may contain several entries with the same coordinates. These are
+// summed when the COO is converted to another format.
+type COO struct {
+	width, height int
+	xs, ys        []int
+	values        []float64
+}
+
+// NewCOO returns an empty COO with the given dimensions.
+//
+// If width or height is non-positive, a non-nil error is returned.
+func NewCOO(width, height int) (*COO, error) {
+	if err := checkShape("NewCOO", width, height); err != nil {
+		return nil, err
+	}
+	return &COO{width: width, height: height}, nil
+}
+
+// Width returns the width of c.
+func (c *COO) Width() int {
+	return c.width
+}
+
+// Height returns the height of c.
+func (c *COO) Height() int {
+	return c.height
+}
+
+// NNZ returns the number of entries that have been added to c, including
+// duplicates and explicit zeros.
+func (c *COO) NNZ() int {
+	return len(c.values)
+}
+
+// Add adds val to the element of c with coordinates (x, y).
+//
+// Like m.Set in package mat, Add panics if (x, y) is out of bounds.
+func (c *COO) Add(x, y int, val float64) {
+	checkBounds("Add", x, y, c.width, c.height)
+	c.xs = append(c.xs, x)
+	c.ys = append(c.ys, y)
+	c.values = append(c.values, val)
+}
+
+// CSR converts c to a CSR matrix, summing duplicate entries. c is not
+// modified.
+func (c *COO) CSR() *CSR {
+	return &CSR{c.compress(c.ys, c.xs, c.height, c.width)}
+}
+
+// CSC converts c to a CSC matrix, summing duplicate entries. c is not
+// modified.
+func (c *COO) CSC() *CSC {
+	return &CSC{c.compress(c.xs, c.ys, c.width, c.height)}
+}
+
+// compress converts the triplets to compressed storage with the given major
+// and minor indices. The triplets are bucketed by minor index and then by
+// major index with two stable counting sorts, which leaves minor indices
+// sorted within each major index, after which duplicates are merged.
+func (c *COO) compress(major, minor []int, nMajor, nMinor int) *compressed {
+	byMinor := newCompressed(nMinor, nMajor, len(c.values))
+	for _, j := range minor {
+		byMinor.ptr[j + 1]++
+	}
+	for j := 0; j < nMinor; j++ {
+		byMinor.ptr[j + 1] += byMinor.ptr[j]
+	}
+	next := make([]int, nMinor)
+	copy(next, byMinor.ptr[:nMinor])
+	for k, j := range minor {
+		byMinor.idx[next[j]] = major[k]
+		byMinor.values[next[j]] = c.values[k]
+		next[j]++
+	}
+
+	out := byMinor.transpose()
+
+	// Merge duplicates in place. Duplicates are adjacent because minor
+	// indices are sorted within each major index.
+	n := 0
+	start := 0
+	for i := 0; i < nMajor; i++ {
+		end := out.ptr[i + 1]
+		rowStart := n
+		for k := start; k < end; k++ {
+			if n > rowStart && out.idx[n - 1] == out.idx[k] {
+				out.values[n - 1] += out.values[k]
+			} else {
+				out.idx[n] = out.idx[k]
+				out.values[n] = out.values[k]
+				n++
+			}
+		}
+		start = end
+		out.ptr[i + 1] = n
+	}
+	out.idx = out.idx[:n]
+	out.values = out.values[:n]
+	return out
+}
@@ -0,0 +1,402 @@
+package sparse
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// CSR is a sparse matrix in compressed sparse row format. The entries of row
+// y are stored contiguously and sorted by column.
+//
+// CSR matrices are immutable. Matrix-vector products with a CSR take time
+// proportional to the number of stored entries.
+type CSR struct {
+	c *compressed
+}
+
+// CSC is a sparse matrix in compressed sparse column format. The entries of
+// column x are stored contiguously and sorted by row.
+//
+// CSC matrices are immutable. Matrix-vector products with a CSC take time
+// proportional to the number of stored entries.
+type CSC struct {
+	c *compressed
+}
+
+// NewCSR creates a CSR from raw compressed arrays: the column indices and
+// values of row y are colIdx[rowPtr[y]: rowPtr[y + 1]] and
+// values[rowPtr[y]: rowPtr[y + 1]]. The input slices are copied.
+//
+// If width or height is non-positive, if len(rowPtr) != height + 1, or if
+// the column indices of any row are out of bounds or not strictly
+// increasing, a non-nil error is returned.
+func NewCSR(
+	width, height int, rowPtr, colIdx []int, values []float64,
+) (*CSR, error) {
+	c, err := newCompressedFrom("NewCSR", "row", "column",
+		height, width, rowPtr, colIdx, values)
+	if err != nil {
+		return nil, err
+	}
+	return &CSR{c}, nil
+}
+
+// NewCSC creates a CSC from raw compressed arrays: the row indices and
+// values of column x are rowIdx[colPtr[x]: colPtr[x + 1]] and
+// values[colPtr[x]: colPtr[x + 1]]. The input slices are copied.
+//
+// If width or height is non-positive, if len(colPtr) != width + 1, or if
+// the row indices of any column are out of bounds or not strictly
+// increasing, a non-nil error is returned.
+func NewCSC(
+	width, height int, colPtr, rowIdx []int, values []float64,
+) (*CSC, error) {
+	c, err := newCompressedFrom("NewCSC", "column", "row",
+		width, height, colPtr, rowIdx, values)
+	if err != nil {
+		return nil, err
+	}
+	return &CSC{c}, nil
+}
+
+// newCompressedFrom copies and validates raw compressed arrays.
+func newCompressedFrom(
+	operationName, majorName, minorName string,
+	major, minor int, ptr, idx []int, values []float64,
+) (*compressed, *mat.MatrixError) {
+	if err := checkShape(operationName, minor, major); err != nil {
+		return nil, err
+	}
+
+	c := &compressed{
+		major: major, minor: minor, ptr: append([]int{}, ptr...),
+		idx: append([]int{}, idx...), values: append([]float64{}, values...),
+	}
+	if err := c.validate(operationName, majorName, minorName); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CSRFromDense converts a dense Matrix to a CSR, skipping zero elements.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func CSRFromDense(m *mat.Matrix) (*CSR, error) {
+	if err := denseError("CSRFromDense", m); err != nil {
+		return nil, err
+	}
+	return &CSR{fromDense(m, false)}, nil
+}
+
+// CSCFromDense converts a dense Matrix to a CSC, skipping zero elements.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func CSCFromDense(m *mat.Matrix) (*CSC, error) {
+	if err := denseError("CSCFromDense", m); err != nil {
+		return nil, err
+	}
+	return &CSC{fromDense(m, true)}, nil
+}
+
+// fromDense compresses the non-zero elements of m by row or, if byColumn is
+// true, by column.
+func fromDense(m *mat.Matrix, byColumn bool) *compressed {
+	major, minor := m.Height(), m.Width()
+	get := func(i, j int) float64 { return m.Get(j, i) }
+	if byColumn {
+		major, minor = minor, major
+		get = m.Get
+	}
+
+	c := newCompressed(major, minor, 0)
+	for i := 0; i < major; i++ {
+		for j := 0; j < minor; j++ {
+			if val := get(i, j); val != 0 {
+				c.idx = append(c.idx, j)
+				c.values = append(c.values, val)
+			}
+		}
+		c.ptr[i + 1] = len(c.idx)
+	}
+	return c
+}
+
+// Width returns the width of a.
+func (a *CSR) Width() int {
+	return a.c.minor
+}
+
+// Height returns the height of a.
+func (a *CSR) Height() int {
+	return a.c.major
+}
+
+// NNZ returns the number of stored entries in a.
+func (a *CSR) NNZ() int {
+	return a.c.nnz()
+}
+
+// At returns the element of a with coordinates (x, y). Elements which are
+// not stored are zero.
+//
+// Like m.Get in package mat, At panics if (x, y) is out of bounds.
+func (a *CSR) At(x, y int) float64 {
+	checkBounds("At", x, y, a.Width(), a.Height())
+	return a.c.at(y, x)
+}
+
+// Row returns the column indices and values of the stored entries in row y.
+// The returned slices are copies.
+//
+// Row panics if y is out of bounds.
+func (a *CSR) Row(y int) (cols []int, values []float64) {
+	checkBounds("Row", 0, y, a.Width(), a.Height())
+	start, end := a.c.ptr[y], a.c.ptr[y + 1]
+	cols = append([]int{}, a.c.idx[start: end]...)
+	values = append([]float64{}, a.c.values[start: end]...)
+	return cols, values
+}
+
+// Dense converts a to a dense Matrix.
+func (a *CSR) Dense() *mat.Matrix {
+	m := mat.New(a.Width(), a.Height())
+	for y := 0; y < a.Height(); y++ {
+		for k := a.c.ptr[y]; k < a.c.ptr[y + 1]; k++ {
+			m.Set(a.c.idx[k], y, a.c.values[k])
+		}
+	}
+	return m
+}
+
+// CSC converts a to CSC format.
+func (a *CSR) CSC() *CSC {
+	return &CSC{a.c.transpose()}
+}
+
+// Transpose returns the transpose of a.
+func (a *CSR) Transpose() *CSR {
+	return &CSR{a.c.transpose()}
+}
+
+// MulVec computes the product A x and returns the result.
+//
+// If len(x) is not equal to the width of A, a non-nil error is returned.
+func (a *CSR) MulVec(x []float64) ([]float64, error) {
+	target := make([]float64, a.Height())
+	if err := a.mulVecAt("MulVec", x, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// MulVecAt computes the product A x and stores it in target. target may
+// overlap x.
+//
+// If len(x) is not equal to the width of A or len(target) is not equal to
+// the height of A, a non-nil error is returned.
+func (a *CSR) MulVecAt(x, target []float64) error {
+	if err := a.mulVecAt("MulVecAt", x, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *CSR) mulVecAt(operationName string, x, target []float64) *mat.MatrixError {
+	if err := checkVec(operationName, x, target, a.Width(), a.Height()); err != nil {
+		return err
+	}
+
+	out := target
+	if overlaps(x, target) {
+		out = make([]float64, len(target))
+	}
+	c := a.c
+	for y := 0; y < c.major; y++ {
+		sum := 0.0
+		for k := c.ptr[y]; k < c.ptr[y + 1]; k++ {
+			sum += c.values[k] * x[c.idx[k]]
+		}
+		out[y] = sum
+	}
+	if len(target) > 0 && &out[0] != &target[0] {
+		copy(target, out)
+	}
+	return nil
+}
+
+// Mult computes the sparse product A B and returns the result.
+//
+// If a or b is nil or the width of A does not equal the height of B, a
+// non-nil error is returned.
+func Mult(a, b *CSR) (*CSR, error) {
+	if a == nil || b == nil {
+		return nil, mat.NewError(mat.NilError, "sparse.Mult", "Input Matrix is nil.")
+	} else if a.Width() != b.Height() {
+		desc := fmt.Sprintf("Width of first Matrix, %d, does not match height of second Matrix, %d.",
+			a.Width(), b.Height())
+		return nil, mat.NewError(mat.ShapeError, "sparse.Mult", desc)
+	}
+
+	// Gustavson's algorithm: row y of A B is accumulated in a dense work
+	// vector, and marker records which of its columns are in use.
+	ac, bc := a.c, b.c
+	out := newCompressed(ac.major, bc.minor, 0)
+	work := make([]float64, bc.minor)
+	marker := make([]int, bc.minor)
+	for j := range marker {
+		marker[j] = -1
+	}
+
+	var cols []int
+	for y := 0; y < ac.major; y++ {
+		cols = cols[:0]
+		for ka := ac.ptr[y]; ka < ac.ptr[y + 1]; ka++ {
+			row, val := ac.idx[ka], ac.values[ka]
+			for kb := bc.ptr[row]; kb < bc.ptr[row + 1]; kb++ {
+				x := bc.idx[kb]
+				if marker[x] != y {
+					marker[x] = y
+					work[x] = 0
+					cols = append(cols, x)
+				}
+				work[x] += val * bc.values[kb]
+			}
+		}
+
+		sort.Ints(cols)
+		for _, x := range cols {
+			out.idx = append(out.idx, x)
+			out.values = append(out.values, work[x])
+		}
+		out.ptr[y + 1] = len(out.idx)
+	}
+
+	return &CSR{out}, nil
+}
+
+// Width returns the width of a.
+func (a *CSC) Width() int {
+	return a.c.major
+}
+
+// Height returns the height of a.
+func (a *CSC) Height() int {
+	return a.c.minor
+}
+
+// NNZ returns the number of stored entries in a.
+func (a *CSC) NNZ() int {
+	return a.c.nnz()
+}
+
+// At returns the element of a with coordinates (x, y). Elements which are
+// not stored are zero.
+//
+// Like m.Get in package mat, At panics if (x, y) is out of bounds.
+func (a *CSC) At(x, y int) float64 {
+	checkBounds("At", x, y, a.Width(), a.Height())
+	return a.c.at(x, y)
+}
+
+// Col returns the row indices and values of the stored entries in column x.
+// The returned slices are copies.
+//
+// Col panics if x is out of bounds.
+func (a *CSC) Col(x int) (rows []int, values []float64) {
+	checkBounds("Col", x, 0, a.Width(), a.Height())
+	start, end := a.c.ptr[x], a.c.ptr[x + 1]
+	rows = append([]int{}, a.c.idx[start: end]...)
+	values = append([]float64{}, a.c.values[start: end]...)
+	return rows, values
+}
+
+// Dense converts a to a dense Matrix.
+func (a *CSC) Dense() *mat.Matrix {
+	m := mat.New(a.Width(), a.Height())
+	for x := 0; x < a.Width(); x++ {
+		for k := a.c.ptr[x]; k < a.c.ptr[x + 1]; k++ {
+			m.Set(x, a.c.idx[k], a.c.values[k])
+		}
+	}
+	return m
+}
+
+// CSR converts a to CSR format.
+func (a *CSC) CSR() *CSR {
+	return &CSR{a.c.transpose()}
+}
+
+// Transpose returns the transpose of a.
+func (a *CSC) Transpose() *CSC {
+	return &CSC{a.c.transpose()}
+}
+
+// MulVec computes the product A x and returns the result.
+//
+// If len(x) is not equal to the width of A, a non-nil error is returned.
+func (a *CSC) MulVec(x []float64) ([]float64, error) {
+	target := make([]float64, a.Height())
+	if err := a.mulVecAt("MulVec", x, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// MulVecAt computes the product A x and stores it in target. target may
+// overlap x.
+//
+// If len(x) is not equal to the width of A or len(target) is not equal to
+// the height of A, a non-nil error is returned.
+func (a *CSC) MulVecAt(x, target []float64) error {
+	if err := a.mulVecAt("MulVecAt", x, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *CSC) mulVecAt(operationName string, x, target []float64) *mat.MatrixError {
+	if err := checkVec(operationName, x, target, a.Width(), a.Height()); err != nil {
+		return err
+	}
+
+	out := target
+	if overlaps(x, target) {
+		out = make([]float64, len(target))
+	} else {
+		for i := range out {
+			out[i] = 0
+		}
+	}
+	c := a.c
+	for x0 := 0; x0 < c.major; x0++ {
+		val := x[x0]
+		for k := c.ptr[x0]; k < c.ptr[x0 + 1]; k++ {
+			out[c.idx[k]] += c.values[k] * val
+		}
+	}
+	if len(target) > 0 && &out[0] != &target[0] {
+		copy(target, out)
+	}
+	return nil
+}
+
+// overlaps returns true if x and y share the same underlying array.
+func overlaps(x, y []float64) bool {
+	cx, cy := cap(x), cap(y)
+	if cx == 0 || cy == 0 {
+		return false
+	}
+	// Slices into the same array always end at the same element.
+	return &x[:cx][cx - 1] == &y[:cy][cy - 1]
+}
+
+// checkBounds panics if (x, y) is out of bounds.
+func checkBounds(operationName string, x, y, width, height int) {
+	if x < 0 || y < 0 || x >= width || y >= height {
+		panic(fmt.Sprintf("sparse.%s given coordinates (%d, %d), which are "+
+			"out of bounds for a %d by %d Matrix.",
+			operationName, x, y, width, height))
+	}
+}
@@ -0,0 +1,199 @@
+/*
+package sparse implements real-valued sparse matrices for problems where
+dense Matrices from package mat would be too large to store, such as
+discretized PDEs and graph adjacency matrices.
+
+Three storage formats are provided. COO (coordinate) matrices are a list of
+(x, y, value) triplets and are used for assembly: entries can be added in any
+order and duplicate entries are summed. Once assembled, a COO is converted to
+one of the two compressed formats, CSR (compressed sparse row) and CSC
+(compressed sparse column), which support fast products. CSR is the better
+choice for matrix-vector products and row access, and CSC for column access.
+
+	c, err := sparse.NewCOO(n, n)
+	if err != nil {
+		// Error handling.
+	}
+	for i := 0; i < n; i++ {
+		c.Add(i, i, 2)
+		if i > 0 {
+			c.Add(i - 1, i, -1)
+			c.Add(i, i - 1, -1)
+		}
+	}
+	a := c.CSR()
+
+	y, err := a.MulVec(x)
+
+As in package mat, element coordinates are given as (x, y) pairs, where x is
+the column and y is the row, and shapes are given as (width, height).
+Compressed matrices are immutable once constructed.
+
+Like m.Get and m.Set in package mat, c.Add and a.At panic when given
+coordinates which are out of bounds. All other operations return errors of
+type *mat.MatrixError.
+*/
+package sparse
+
+import (
+	"fmt"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// compressed is the shared storage of CSR and CSC matrices. The "major"
+// dimension is the one which is compressed (rows for CSR, columns for CSC)
+// and the "minor" dimension is the one indexed by idx. The entries of
+// major index i are stored in idx[ptr[i]: ptr[i + 1]] and
+// values[ptr[i]: ptr[i + 1]], sorted by minor index and without duplicates.
+type compressed struct {
+	major, minor int
+	ptr, idx     []int
+	values       []float64
+}
+
+// newCompressed allocates a compressed matrix with room for nnz entries.
+func newCompressed(major, minor, nnz int) *compressed {
+	return &compressed{
+		major: major, minor: minor, ptr: make([]int, major + 1),
+		idx: make([]int, nnz), values: make([]float64, nnz),
+	}
+}
+
+// nnz returns the number of stored entries.
+func (c *compressed) nnz() int {
+	return c.ptr[c.major]
+}
+
+// at returns the element with the given major and minor indices.
+func (c *compressed) at(i, j int) float64 {
+	lo, hi := c.ptr[i], c.ptr[i + 1]
+	for lo < hi {
+		mid := int(uint(lo + hi) >> 1)
+		switch {
+		case c.idx[mid] < j:
+			lo = mid + 1
+		case c.idx[mid] > j:
+			hi = mid
+		default:
+			return c.values[mid]
+		}
+	}
+	return 0
+}
+
+// transpose returns the compressed representation of the matrix with the
+// major and minor dimensions exchanged. The entries are scattered with a
+// counting sort, so minor indices within each major index stay sorted.
+func (c *compressed) transpose() *compressed {
+	t := newCompressed(c.minor, c.major, c.nnz())
+	for _, j := range c.idx {
+		t.ptr[j + 1]++
+	}
+	for j := 0; j < t.major; j++ {
+		t.ptr[j + 1] += t.ptr[j]
+	}
+
+	next := make([]int, t.major)
+	copy(next, t.ptr[:t.major])
+	for i := 0; i < c.major; i++ {
+		for k := c.ptr[i]; k < c.ptr[i + 1]; k++ {
+			j := c.idx[k]
+			t.idx[next[j]] = i
+			t.values[next[j]] = c.values[k]
+			next[j]++
+		}
+	}
+	return t
+}
+
+// copy returns a deep copy of c.
+func (c *compressed) copy() *compressed {
+	out := newCompressed(c.major, c.minor, c.nnz())
+	copy(out.ptr, c.ptr)
+	copy(out.idx, c.idx)
+	copy(out.values, c.values)
+	return out
+}
+
+// validate checks that raw compressed arrays describe a valid matrix.
+// majorName and minorName are used in error descriptions.
+func (c *compressed) validate(
+	operationName, majorName, minorName string,
+) *mat.MatrixError {
+	if len(c.ptr) != c.major + 1 {
+		desc := fmt.Sprintf("Length of %s pointer slice, %d, does not match %d %ss.",
+			majorName, len(c.ptr), c.major, majorName)
+		return mat.NewError(mat.ShapeError, "sparse." + operationName, desc)
+	} else if len(c.idx) != len(c.values) {
+		desc := fmt.Sprintf("Length of index slice, %d, does not match length of value slice, %d.",
+			len(c.idx), len(c.values))
+		return mat.NewError(mat.ShapeError, "sparse." + operationName, desc)
+	} else if c.ptr[0] != 0 || c.ptr[c.major] != len(c.idx) {
+		desc := fmt.Sprintf("%s pointers must start at 0 and end at %d.",
+			majorName, len(c.idx))
+		return mat.NewError(mat.ParameterError, "sparse." + operationName, desc)
+	}
+
+	// The pointers must be checked before any are used to index idx.
+	for i := 0; i < c.major; i++ {
+		if c.ptr[i + 1] < c.ptr[i] {
+			desc := fmt.Sprintf("%s pointers decrease at %s %d.",
+				majorName, majorName, i)
+			return mat.NewError(mat.ParameterError, "sparse." + operationName, desc)
+		}
+	}
+
+	for i := 0; i < c.major; i++ {
+		for k := c.ptr[i]; k < c.ptr[i + 1]; k++ {
+			j := c.idx[k]
+			if j < 0 || j >= c.minor {
+				desc := fmt.Sprintf("%s index %d in %s %d is out of bounds for %d %ss.",
+					minorName, j, majorName, i, c.minor, minorName)
+				return mat.NewError(mat.ParameterError, "sparse." + operationName, desc)
+			} else if k > c.ptr[i] && j <= c.idx[k - 1] {
+				desc := fmt.Sprintf("%s indices in %s %d are not strictly increasing.",
+					minorName, majorName, i)
+				return mat.NewError(mat.ParameterError, "sparse." + operationName, desc)
+			}
+		}
+	}
+	return nil
+}
+
+// checkShape returns an error if width or height is non-positive.
+func checkShape(operationName string, width, height int) *mat.MatrixError {
+	if width <= 0 {
+		desc := fmt.Sprintf("Input width %d is non-positive.", width)
+		return mat.NewError(mat.ParameterError, "sparse." + operationName, desc)
+	} else if height <= 0 {
+		desc := fmt.Sprintf("Input height %d is non-positive.", height)
+		return mat.NewError(mat.ParameterError, "sparse." + operationName, desc)
+	}
+	return nil
+}
+
+// checkVec returns an error if x and target do not have the given lengths.
+// target is not checked if it is nil.
+func checkVec(operationName string, x, target []float64, width, height int) *mat.MatrixError {
+	if len(x) != width {
+		desc := fmt.Sprintf("Length of input vector, %d, does not match Matrix width %d.",
+			len(x), width)
+		return mat.NewError(mat.ShapeError, "sparse." + operationName, desc)
+	} else if target != nil && len(target) != height {
+		desc := fmt.Sprintf("Length of target vector, %d, does not match Matrix height %d.",
+			len(target), height)
+		return mat.NewError(mat.ShapeError, "sparse." + operationName, desc)
+	}
+	return nil
+}
+
+// denseError returns an error if m is nil or an error Matrix.
+func denseError(operationName string, m *mat.Matrix) *mat.MatrixError {
+	if m == nil {
+		return mat.NewError(mat.NilError, "sparse." + operationName, "Input Matrix is nil.")
+	} else if m.IsError() {
+		return m.MatrixError()
+	}
+	return nil
+}
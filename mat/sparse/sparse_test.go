@@ -0,0 +1,297 @@
+package sparse
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/phil-mansfield/num/mat"
+)
+
+// randomCOO returns a COO with n random entries, many of which share
+// coordinates, along with the equivalent dense Matrix.
+func randomCOO(width, height, n int) (*COO, *mat.Matrix) {
+	c, _ := NewCOO(width, height)
+	m := mat.New(width, height)
+	for i := 0; i < n; i++ {
+		x, y := rand.Intn(width), rand.Intn(height)
+		val := rand.Float64() - 0.5
+		c.Add(x, y, val)
+		m.Set(x, y, m.Get(x, y) + val)
+	}
+	return c, m
+}
+
+// maxDiff returns the largest absolute difference between the elements of
+// two matrices of the same shape.
+func maxDiff(m1, m2 *mat.Matrix) float64 {
+	if !mat.Compatible(m1, m2) {
+		return math.Inf(1)
+	}
+	s1, s2 := m1.Slice(), m2.Slice()
+	diff := 0.0
+	for i := range s1 {
+		diff = math.Max(diff, math.Abs(s1[i] - s2[i]))
+	}
+	return diff
+}
+
+func vecDiff(x, y []float64) float64 {
+	if len(x) != len(y) {
+		return math.Inf(1)
+	}
+	diff := 0.0
+	for i := range x {
+		diff = math.Max(diff, math.Abs(x[i] - y[i]))
+	}
+	return diff
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		width, height, n int
+	}{
+		{1, 1, 3}, {5, 3, 0}, {4, 7, 10}, {30, 30, 200}, {50, 20, 2000},
+	}
+
+	for i, test := range tests {
+		c, m := randomCOO(test.width, test.height, test.n)
+		csr, csc := c.CSR(), c.CSC()
+		if diff := maxDiff(csr.Dense(), m); diff > 1e-14 {
+			t.Errorf("%d) COO -> CSR differs from dense by %g", i, diff)
+		}
+		if diff := maxDiff(csc.Dense(), m); diff > 1e-14 {
+			t.Errorf("%d) COO -> CSC differs from dense by %g", i, diff)
+		}
+		if diff := maxDiff(csr.CSC().Dense(), m); diff > 1e-14 {
+			t.Errorf("%d) CSR -> CSC differs from dense by %g", i, diff)
+		}
+		if diff := maxDiff(csc.CSR().Dense(), m); diff > 1e-14 {
+			t.Errorf("%d) CSC -> CSR differs from dense by %g", i, diff)
+		}
+		if csr.NNZ() != csc.NNZ() || csr.NNZ() > test.n {
+			t.Errorf("%d) CSR has %d entries and CSC has %d for %d triplets",
+				i, csr.NNZ(), csc.NNZ(), test.n)
+		}
+
+		for y := 0; y < test.height; y++ {
+			for x := 0; x < test.width; x++ {
+				if csr.At(x, y) != csc.At(x, y) ||
+					math.Abs(csr.At(x, y) - m.Get(x, y)) > 1e-14 {
+					t.Errorf("%d) At(%d, %d) = %g (CSR), %g (CSC), wanted %g",
+						i, x, y, csr.At(x, y), csc.At(x, y), m.Get(x, y))
+				}
+			}
+		}
+
+		fromDense, err := CSRFromDense(m)
+		if err != nil {
+			t.Errorf("%d) CSRFromDense returned error: %s", i, err)
+		} else if diff := maxDiff(fromDense.Dense(), m); diff != 0 {
+			t.Errorf("%d) CSRFromDense round trip differs by %g", i, diff)
+		}
+		fromDenseC, err := CSCFromDense(m)
+		if err != nil {
+			t.Errorf("%d) CSCFromDense returned error: %s", i, err)
+		} else if diff := maxDiff(fromDenseC.Dense(), m); diff != 0 {
+			t.Errorf("%d) CSCFromDense round trip differs by %g", i, diff)
+		}
+
+		mt := mat.Transpose(m)
+		if diff := maxDiff(csr.Transpose().Dense(), mt); diff > 1e-14 {
+			t.Errorf("%d) CSR transpose differs by %g", i, diff)
+		}
+		if diff := maxDiff(csc.Transpose().Dense(), mt); diff > 1e-14 {
+			t.Errorf("%d) CSC transpose differs by %g", i, diff)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	c, _ := NewCOO(3, 2)
+	c.Add(2, 1, 1)
+	c.Add(0, 0, 4)
+	c.Add(2, 1, 2)
+	c.Add(1, 1, -1)
+	c.Add(2, 1, 3)
+
+	a := c.CSR()
+	if a.NNZ() != 3 {
+		t.Errorf("CSR has %d entries, wanted 3", a.NNZ())
+	}
+	cols, values := a.Row(1)
+	if len(cols) != 2 || cols[0] != 1 || cols[1] != 2 ||
+		values[0] != -1 || values[1] != 6 {
+		t.Errorf("Row(1) = %v, %v, wanted [1 2], [-1 6]", cols, values)
+	}
+	rows, values := c.CSC().Col(2)
+	if len(rows) != 1 || rows[0] != 1 || values[0] != 6 {
+		t.Errorf("Col(2) = %v, %v, wanted [1], [6]", rows, values)
+	}
+}
+
+func TestMulVec(t *testing.T) {
+	for i, n := range []int{1, 4, 40} {
+		c, m := randomCOO(n, n + 3, 4 * n)
+		x := make([]float64, n)
+		for j := range x {
+			x[j] = rand.Float64() - 0.5
+		}
+		want := mat.Mult(m, mat.FromSlice(1, n, x)).Slice()
+
+		for _, a := range []interface {
+			MulVec([]float64) ([]float64, error)
+		}{c.CSR(), c.CSC()} {
+			y, err := a.MulVec(x)
+			if err != nil {
+				t.Errorf("%d) MulVec returned error: %s", i, err)
+			} else if diff := vecDiff(y, want); diff > 1e-14 {
+				t.Errorf("%d) MulVec differs from dense product by %g",
+					i, diff)
+			}
+		}
+
+		if _, err := c.CSR().MulVec(make([]float64, n + 1)); err == nil {
+			t.Errorf("%d) MulVec with wrong length gave no error.", i)
+		}
+	}
+
+	// Aliased products with square matrices.
+	c, m := randomCOO(10, 10, 40)
+	x := make([]float64, 10)
+	for j := range x {
+		x[j] = float64(j)
+	}
+	want := mat.Mult(m, mat.FromSlice(1, 10, x)).Slice()
+	for _, a := range []interface {
+		MulVecAt(x, target []float64) error
+	}{c.CSR(), c.CSC()} {
+		y := append([]float64{}, x...)
+		if err := a.MulVecAt(y, y); err != nil {
+			t.Errorf("MulVecAt returned error: %s", err)
+		} else if diff := vecDiff(y, want); diff > 1e-14 {
+			t.Errorf("Aliased MulVecAt differs from dense product by %g", diff)
+		}
+
+		// Partially overlapping slices of the same array.
+		buf := make([]float64, 15)
+		copy(buf, x)
+		if err := a.MulVecAt(buf[:10], buf[5:]); err != nil {
+			t.Errorf("MulVecAt returned error: %s", err)
+		} else if diff := vecDiff(buf[5:], want); diff > 1e-14 {
+			t.Errorf("Overlapping MulVecAt differs from dense product by %g", diff)
+		}
+	}
+}
+
+func TestMulVecNonFinite(t *testing.T) {
+	// 0 * Inf is NaN, so a zero in x must not cause a column to be skipped.
+	a, err := NewCSR(2, 2, []int{0, 2, 3}, []int{0, 1, 0},
+		[]float64{math.Inf(+1), 1, 2})
+	if err != nil {
+		t.Fatalf("NewCSR returned error: %s", err)
+	}
+	x := []float64{0, 3}
+
+	yr, _ := a.MulVec(x)
+	yc, _ := a.CSC().MulVec(x)
+	for i := range yr {
+		if !(math.IsNaN(yr[i]) && math.IsNaN(yc[i])) && yr[i] != yc[i] {
+			t.Errorf("CSR product %v and CSC product %v differ", yr, yc)
+			break
+		}
+	}
+	if !math.IsNaN(yr[0]) || yr[1] != 0 {
+		t.Errorf("MulVec gave %v, wanted [NaN 0]", yr)
+	}
+}
+
+func TestMult(t *testing.T) {
+	tests := []struct {
+		w1, h1, w2, n int
+	}{
+		{1, 1, 1, 1}, {3, 4, 5, 6}, {20, 30, 10, 60}, {40, 40, 40, 400},
+	}
+
+	for i, test := range tests {
+		c1, m1 := randomCOO(test.w1, test.h1, test.n)
+		c2, m2 := randomCOO(test.w2, test.w1, test.n)
+		prod, err := Mult(c1.CSR(), c2.CSR())
+		if err != nil {
+			t.Errorf("%d) Mult returned error: %s", i, err)
+			continue
+		}
+		if diff := maxDiff(prod.Dense(), mat.Mult(m1, m2)); diff > 1e-13 {
+			t.Errorf("%d) Mult differs from dense product by %g", i, diff)
+		}
+
+		// Column indices must be sorted for the result to be valid.
+		for y := 0; y < prod.Height(); y++ {
+			cols, _ := prod.Row(y)
+			for k := 1; k < len(cols); k++ {
+				if cols[k] <= cols[k - 1] {
+					t.Errorf("%d) Row %d of product is unsorted: %v",
+						i, y, cols)
+					break
+				}
+			}
+		}
+	}
+
+	a, _ := NewCOO(3, 2)
+	if _, err := Mult(a.CSR(), a.CSR()); err == nil {
+		t.Errorf("Mult with incompatible shapes gave no error.")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"NewCOO(0, 1)", second(NewCOO(0, 1)), mat.ParameterError},
+		{"NewCSR(short ptr)",
+			second(NewCSR(2, 2, []int{0, 1}, []int{0}, []float64{1})),
+			mat.ShapeError},
+		{"NewCSR(out of bounds)",
+			second(NewCSR(2, 2, []int{0, 1, 1}, []int{2}, []float64{1})),
+			mat.ParameterError},
+		{"NewCSR(unsorted)",
+			second(NewCSR(2, 1, []int{0, 2}, []int{1, 0}, []float64{1, 2})),
+			mat.ParameterError},
+		{"NewCSR(ptr past end)",
+			second(NewCSR(2, 2, []int{0, 5, 2}, []int{0, 1}, []float64{1, 2})),
+			mat.ParameterError},
+		{"NewCSC(ptr past end)",
+			second(NewCSC(2, 2, []int{0, 5, 2}, []int{0, 1}, []float64{1, 2})),
+			mat.ParameterError},
+		{"NewCSC(bad end)",
+			second(NewCSC(2, 2, []int{0, 1, 3}, []int{0, 1}, []float64{1, 2})),
+			mat.ParameterError},
+		{"CSRFromDense(nil)", second(CSRFromDense(nil)), mat.NilError},
+		{"CSCFromDense(error)", second(CSCFromDense(mat.New(0, 1))),
+			mat.ParameterError},
+	}
+
+	for _, test := range tests {
+		err, ok := test.err.(*mat.MatrixError)
+		if !ok || err == nil {
+			t.Errorf("%s returned %v, wanted *mat.MatrixError", test.name, test.err)
+		} else if err.Code != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, err.Code, test.code)
+		}
+	}
+
+	a, err := NewCSR(3, 2, []int{0, 2, 3}, []int{0, 2, 1}, []float64{1, 2, 3})
+	if err != nil {
+		t.Errorf("NewCSR returned error: %s", err)
+	} else if d := a.Dense().Slice(); vecDiff(d, []float64{1, 0, 2, 0, 3, 0}) != 0 {
+		t.Errorf("NewCSR gave %v, wanted [1 0 2 0 3 0]", d)
+	}
+}
+
+func second(_ interface{}, err error) error {
+	return err
+}
@@ -0,0 +1,710 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+// SVDKind specifies which parts of the singular vectors are computed by
+// NewSVD.
+type SVDKind int
+
+const (
+	// ThinSVD computes only the first min(width, height) left and right
+	// singular vectors. This is all that is needed to reconstruct A.
+	ThinSVD SVDKind = iota
+	// FullSVD computes complete orthonormal bases of left and right singular
+	// vectors.
+	FullSVD
+)
+
+const (
+	// svdMaxIters is the maximum number of implicit QR steps performed per
+	// singular value before giving up.
+	svdMaxIters = 75
+)
+
+// SVD represents the singular value decomposition of a Matrix, A. The
+// decomposition satisfies A = U S V^T, where U and V have orthonormal columns
+// and S is diagonal with the non-negative singular values of A in descending
+// order.
+//
+// For a decomposition computed with ThinSVD, U has shape (k, height) and V
+// has shape (k, width), where k = min(width, height). For FullSVD, U has shape
+// (height, height) and V has shape (width, width).
+type SVD struct {
+	width, height int
+	kind          SVDKind
+	s             []float64
+	u, v          *Matrix
+}
+
+// NewSVD computes the singular value decomposition of m by bidiagonalizing
+// it with Householder reflections and then diagonalizing the bidiagonal
+// Matrix with the Golub-Kahan implicit QR algorithm. m is not modified.
+//
+// If m is nil or an error Matrix, a non-nil error is returned. If the QR
+// algorithm fails to converge, an IterationError is returned.
+func NewSVD(m *Matrix, kind SVDKind) (*SVD, error) {
+	f, err := newSVD("NewSVD", m, kind)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func newSVD(operationName string, m *Matrix, kind SVDKind) (*SVD, *MatrixError) {
+	m = dense(m)
+	if err := inputError(operationName, m); err != nil {
+		return nil, err
+	} else if kind != ThinSVD && kind != FullSVD {
+		return nil, newError(ParameterError, operationName,
+			"Unrecognized SVDKind.")
+	}
+
+	f := &SVD{width: m.width, height: m.height, kind: kind}
+	var ok bool
+	if m.height >= m.width {
+		f.s, f.u, f.v, ok = golubKahan(m.values, m.height, m.width, kind == FullSVD)
+	} else {
+		// Decompose A^T = V S U^T instead.
+		at := Transpose(m)
+		f.s, f.v, f.u, ok = golubKahan(at.values, at.height, at.width, kind == FullSVD)
+	}
+	if !ok {
+		return nil, newError(IterationError, operationName,
+			"Implicit QR algorithm failed to converge.")
+	}
+	return f, nil
+}
+
+// golubKahan computes the singular value decomposition of the row-major
+// (m x n) matrix a, where m >= n. a is not modified. The singular values are
+// returned in descending order along with U, which is (m x n) or (m x m) if
+// full is true, and V, which is (n x n).
+//
+// This follows the LINPACK routine dsvdc.
+func golubKahan(values []float64, m, n int, full bool) (
+	sv []float64, um, vm *Matrix, ok bool,
+) {
+	a := make([]float64, m * n)
+	copy(a, values)
+
+	nu := n
+	if full {
+		nu = m
+	}
+	s := make([]float64, minInt(m + 1, n))
+	u := make([]float64, m * nu)
+	v := make([]float64, n * n)
+	e := make([]float64, n)
+	work := make([]float64, m)
+
+	// Reduce a to bidiagonal form, storing the diagonal in s and the
+	// superdiagonal in e.
+	nct, nrt := minInt(m - 1, n), maxInt(0, minInt(n - 2, m))
+	for k := 0; k < maxInt(nct, nrt); k++ {
+		if k < nct {
+			// Compute the transformation for the k-th column.
+			s[k] = 0
+			for i := k; i < m; i++ {
+				s[k] = math.Hypot(s[k], a[i * n + k])
+			}
+			if s[k] != 0 {
+				if a[k * n + k] < 0 {
+					s[k] = -s[k]
+				}
+				for i := k; i < m; i++ {
+					a[i * n + k] /= s[k]
+				}
+				a[k * n + k]++
+			}
+			s[k] = -s[k]
+		}
+
+		for j := k + 1; j < n; j++ {
+			if k < nct && s[k] != 0 {
+				t := 0.0
+				for i := k; i < m; i++ {
+					t += a[i * n + k] * a[i * n + j]
+				}
+				t = -t / a[k * n + k]
+				for i := k; i < m; i++ {
+					a[i * n + j] += t * a[i * n + k]
+				}
+			}
+			e[j] = a[k * n + j]
+		}
+
+		if k < nct {
+			for i := k; i < m; i++ {
+				u[i * nu + k] = a[i * n + k]
+			}
+		}
+
+		if k < nrt {
+			// Compute the transformation for the k-th row.
+			e[k] = 0
+			for i := k + 1; i < n; i++ {
+				e[k] = math.Hypot(e[k], e[i])
+			}
+			if e[k] != 0 {
+				if e[k + 1] < 0 {
+					e[k] = -e[k]
+				}
+				for i := k + 1; i < n; i++ {
+					e[i] /= e[k]
+				}
+				e[k + 1]++
+			}
+			e[k] = -e[k]
+
+			if k + 1 < m && e[k] != 0 {
+				for i := k + 1; i < m; i++ {
+					work[i] = 0
+				}
+				for i := k + 1; i < m; i++ {
+					for j := k + 1; j < n; j++ {
+						work[i] += e[j] * a[i * n + j]
+					}
+				}
+				for j := k + 1; j < n; j++ {
+					t := -e[j] / e[k + 1]
+					for i := k + 1; i < m; i++ {
+						a[i * n + j] += t * work[i]
+					}
+				}
+			}
+
+			for i := k + 1; i < n; i++ {
+				v[i * n + k] = e[i]
+			}
+		}
+	}
+
+	// Set up the final bidiagonal matrix of order p.
+	p := minInt(n, m + 1)
+	if nct < n {
+		s[nct] = a[nct * n + nct]
+	}
+	if m < p {
+		s[p - 1] = 0
+	}
+	if nrt + 1 < p {
+		e[nrt] = a[nrt * n + p - 1]
+	}
+	e[p - 1] = 0
+
+	// Generate U.
+	for j := nct; j < nu; j++ {
+		for i := 0; i < m; i++ {
+			u[i * nu + j] = 0
+		}
+		u[j * nu + j] = 1
+	}
+	for k := nct - 1; k >= 0; k-- {
+		if s[k] != 0 {
+			for j := k + 1; j < nu; j++ {
+				t := 0.0
+				for i := k; i < m; i++ {
+					t += u[i * nu + k] * u[i * nu + j]
+				}
+				t = -t / u[k * nu + k]
+				for i := k; i < m; i++ {
+					u[i * nu + j] += t * u[i * nu + k]
+				}
+			}
+			for i := k; i < m; i++ {
+				u[i * nu + k] = -u[i * nu + k]
+			}
+			u[k * nu + k]++
+			for i := 0; i < k; i++ {
+				u[i * nu + k] = 0
+			}
+		} else {
+			for i := 0; i < m; i++ {
+				u[i * nu + k] = 0
+			}
+			u[k * nu + k] = 1
+		}
+	}
+
+	// Generate V.
+	for k := n - 1; k >= 0; k-- {
+		if k < nrt && e[k] != 0 {
+			for j := k + 1; j < n; j++ {
+				t := 0.0
+				for i := k + 1; i < n; i++ {
+					t += v[i * n + k] * v[i * n + j]
+				}
+				t = -t / v[(k + 1) * n + k]
+				for i := k + 1; i < n; i++ {
+					v[i * n + j] += t * v[i * n + k]
+				}
+			}
+		}
+		for i := 0; i < n; i++ {
+			v[i * n + k] = 0
+		}
+		v[k * n + k] = 1
+	}
+
+	// rotate applies a Givens rotation to columns j and k of the row-major
+	// matrix x with width w.
+	rotate := func(x []float64, w, j, k int, cs, sn float64) {
+		for i := j; i < len(x); i += w {
+			xj, xk := x[i], x[i - j + k]
+			x[i] = cs * xj + sn * xk
+			x[i - j + k] = -sn * xj + cs * xk
+		}
+	}
+
+	// Main iteration loop for the singular values.
+	pp, iter := p - 1, 0
+	const tiny = 0x1p-966
+	for p > 0 {
+		if iter > svdMaxIters {
+			return nil, nil, nil, false
+		}
+
+		// Inspect the bidiagonal matrix for negligible elements and decide
+		// what to do:
+		//   1: s[p-1] and e[k-1] are negligible and k < p.
+		//   2: s[k] is negligible and k < p.
+		//   3: e[k-1] is negligible, k < p, and s[k], ..., s[p-1] are not
+		//      negligible, so a QR step is needed.
+		//   4: e[p-2] is negligible, so s[p-1] has converged.
+		var k, kase int
+		for k = p - 2; k >= 0; k-- {
+			if math.Abs(e[k]) <= tiny + machineEpsilon * (math.Abs(s[k]) + math.Abs(s[k + 1])) {
+				e[k] = 0
+				break
+			}
+		}
+		if k == p - 2 {
+			kase = 4
+		} else {
+			var ks int
+			for ks = p - 1; ks > k; ks-- {
+				t := 0.0
+				if ks != p {
+					t += math.Abs(e[ks])
+				}
+				if ks != k + 1 {
+					t += math.Abs(e[ks - 1])
+				}
+				if math.Abs(s[ks]) <= tiny + machineEpsilon * t {
+					s[ks] = 0
+					break
+				}
+			}
+			if ks == k {
+				kase = 3
+			} else if ks == p - 1 {
+				kase = 1
+			} else {
+				kase = 2
+				k = ks
+			}
+		}
+		k++
+
+		switch kase {
+		case 1:
+			// Deflate the negligible s[p-1].
+			f := e[p - 2]
+			e[p - 2] = 0
+			for j := p - 2; j >= k; j-- {
+				t := math.Hypot(s[j], f)
+				cs, sn := s[j] / t, f / t
+				s[j] = t
+				if j != k {
+					f = -sn * e[j - 1]
+					e[j - 1] *= cs
+				}
+				rotate(v, n, j, p - 1, cs, sn)
+			}
+
+		case 2:
+			// Split at the negligible s[k].
+			f := e[k - 1]
+			e[k - 1] = 0
+			for j := k; j < p; j++ {
+				t := math.Hypot(s[j], f)
+				cs, sn := s[j] / t, f / t
+				s[j] = t
+				f = -sn * e[j]
+				e[j] *= cs
+				rotate(u, nu, j, k - 1, cs, sn)
+			}
+
+		case 3:
+			// Perform one implicit QR step, starting with a shift computed
+			// from the trailing 2 x 2 block.
+			scale := math.Max(math.Max(math.Max(math.Max(
+				math.Abs(s[p - 1]), math.Abs(s[p - 2])), math.Abs(e[p - 2])),
+				math.Abs(s[k])), math.Abs(e[k]))
+			sp, spm1 := s[p - 1] / scale, s[p - 2] / scale
+			epm1, sk, ek := e[p - 2] / scale, s[k] / scale, e[k] / scale
+			b := ((spm1 + sp) * (spm1 - sp) + epm1 * epm1) / 2
+			c := (sp * epm1) * (sp * epm1)
+			shift := 0.0
+			if b != 0 || c != 0 {
+				shift = math.Sqrt(b * b + c)
+				if b < 0 {
+					shift = -shift
+				}
+				shift = c / (b + shift)
+			}
+			f := (sk + sp) * (sk - sp) + shift
+			g := sk * ek
+
+			// Chase the bulge.
+			for j := k; j < p - 1; j++ {
+				t := math.Hypot(f, g)
+				cs, sn := f / t, g / t
+				if j != k {
+					e[j - 1] = t
+				}
+				f = cs * s[j] + sn * e[j]
+				e[j] = cs * e[j] - sn * s[j]
+				g = sn * s[j + 1]
+				s[j + 1] *= cs
+				rotate(v, n, j, j + 1, cs, sn)
+
+				t = math.Hypot(f, g)
+				cs, sn = f / t, g / t
+				s[j] = t
+				f = cs * e[j] + sn * s[j + 1]
+				s[j + 1] = -sn * e[j] + cs * s[j + 1]
+				g = sn * e[j + 1]
+				e[j + 1] *= cs
+				if j < m - 1 {
+					rotate(u, nu, j, j + 1, cs, sn)
+				}
+			}
+			e[p - 2] = f
+			iter++
+
+		case 4:
+			// Make the converged singular value positive.
+			if s[k] <= 0 {
+				s[k] = math.Abs(s[k])
+				for i := 0; i <= pp; i++ {
+					v[i * n + k] = -v[i * n + k]
+				}
+			}
+
+			// Order the singular values.
+			for ; k < pp && s[k] < s[k + 1]; k++ {
+				s[k], s[k + 1] = s[k + 1], s[k]
+				if k < n - 1 {
+					swapColumns(v, n, k, k + 1)
+				}
+				if k < m - 1 {
+					swapColumns(u, nu, k, k + 1)
+				}
+			}
+			iter = 0
+			p--
+		}
+	}
+
+	return s[:n], FromSlice(nu, m, u), FromSlice(n, n, v), true
+}
+
+// swapColumns swaps columns i and j of the row-major matrix x with width w.
+func swapColumns(x []float64, w, i, j int) {
+	for r := 0; r < len(x); r += w {
+		x[r + i], x[r + j] = x[r + j], x[r + i]
+	}
+}
+
+// Values returns the singular values of A in descending order. There are
+// min(width, height) singular values.
+func (f *SVD) Values() []float64 {
+	s := make([]float64, len(f.s))
+	copy(s, f.s)
+	return s
+}
+
+// U returns the left singular vectors of A as the columns of a Matrix.
+func (f *SVD) U() *Matrix {
+	return Copy(f.u)
+}
+
+// V returns the right singular vectors of A as the columns of a Matrix.
+func (f *SVD) V() *Matrix {
+	return Copy(f.v)
+}
+
+// Norm2 returns the 2-norm of A, which is its largest singular value.
+func (f *SVD) Norm2() float64 {
+	return f.s[0]
+}
+
+// ConditionNumber returns the 2-norm condition number of A, the ratio of its
+// largest and smallest singular values. If A is rank deficient, +Inf is
+// returned.
+func (f *SVD) ConditionNumber() float64 {
+	min := f.s[len(f.s) - 1]
+	if min == 0 {
+		return math.Inf(1)
+	}
+	return f.s[0] / min
+}
+
+// Rank returns the numerical rank of A: the number of singular values larger
+// than cutoff times the largest singular value. If cutoff is negative, a
+// default of max(width, height) * epsilon is used.
+func (f *SVD) Rank(cutoff float64) int {
+	tol := f.tolerance(cutoff)
+	r := 0
+	for r < len(f.s) && f.s[r] > tol {
+		r++
+	}
+	return r
+}
+
+// tolerance returns the absolute tolerance corresponding to the relative
+// cutoff used by Rank.
+func (f *SVD) tolerance(cutoff float64) float64 {
+	if cutoff < 0 {
+		cutoff = float64(maxInt(f.width, f.height)) * machineEpsilon
+	}
+	return cutoff * f.s[0]
+}
+
+// PseudoInverse returns the Moore-Penrose pseudoinverse of A, V S^+ U^T,
+// where S^+ is found by inverting every singular value larger than cutoff
+// times the largest singular value and setting the rest to zero. If cutoff is
+// negative, the default cutoff described in Rank is used.
+func (f *SVD) PseudoInverse(cutoff float64) *Matrix {
+	r := f.Rank(cutoff)
+	pinv := New(f.height, f.width)
+	uw, vw := f.u.width, f.v.width
+	for y := 0; y < f.width; y++ {
+		row := pinv.values[y * f.height: (y + 1) * f.height]
+		for k := 0; k < r; k++ {
+			c := f.v.values[y * vw + k] / f.s[k]
+			if c == 0 {
+				continue
+			}
+			for x := range row {
+				row[x] += c * f.u.values[x * uw + k]
+			}
+		}
+	}
+	return pinv
+}
+
+// Range returns a Matrix whose columns are an orthonormal basis of the range
+// (column space) of A, using the cutoff described in Rank. If A is
+// numerically zero, nil is returned.
+func (f *SVD) Range(cutoff float64) *Matrix {
+	return columns(f.u, 0, f.Rank(cutoff))
+}
+
+// NullSpace returns a Matrix whose columns are an orthonormal basis of the
+// null space of A, using the cutoff described in Rank. If the null space is
+// trivial, nil is returned.
+//
+// If A has more columns than rows, a FullSVD is needed to find the complete
+// null space. If the decomposition is a ThinSVD of such a Matrix, an error
+// Matrix is returned.
+func (f *SVD) NullSpace(cutoff float64) *Matrix {
+	if f.width > f.height && f.kind != FullSVD {
+		return newErrorMatrix(ParameterError, "NullSpace",
+			"Null space of a wide Matrix requires a FullSVD.")
+	}
+	return columns(f.v, f.Rank(cutoff), f.width)
+}
+
+// LowRank returns the best rank-k approximation to A in both the 2-norm and
+// the Frobenius norm, U_k S_k V_k^T, where only the first k singular values
+// and vectors are kept.
+//
+// If k is negative or larger than min(width, height), an error Matrix is
+// returned.
+func (f *SVD) LowRank(k int) *Matrix {
+	if k < 0 || k > len(f.s) {
+		desc := fmt.Sprintf("Rank %d is outside the range [0, %d].", k, len(f.s))
+		return newErrorMatrix(ParameterError, "LowRank", desc)
+	}
+
+	out := New(f.width, f.height)
+	uw, vw := f.u.width, f.v.width
+	for y := 0; y < f.height; y++ {
+		row := out.values[y * f.width: (y + 1) * f.width]
+		for i := 0; i < k; i++ {
+			c := f.s[i] * f.u.values[y * uw + i]
+			if c == 0 {
+				continue
+			}
+			for x := range row {
+				row[x] += c * f.v.values[x * vw + i]
+			}
+		}
+	}
+	return out
+}
+
+// columns returns a copy of columns [start, end) of m, or nil if the range is
+// empty.
+func columns(m *Matrix, start, end int) *Matrix {
+	if start >= end {
+		return nil
+	}
+	w := end - start
+	out := New(w, m.height)
+	for y := 0; y < m.height; y++ {
+		copy(out.values[y * w: (y + 1) * w],
+			m.values[y * m.width + start: y * m.width + end])
+	}
+	return out
+}
+
+// Norm2 returns the 2-norm of m, its largest singular value.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) Norm2() (float64, error) {
+	f, err := newSVD("Norm2", m, ThinSVD)
+	if err != nil {
+		return 0, err
+	}
+	return f.Norm2(), nil
+}
+
+// ConditionNumber returns the 2-norm condition number of m, the ratio of its
+// largest and smallest singular values. If m is rank deficient, +Inf is
+// returned.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) ConditionNumber() (float64, error) {
+	f, err := newSVD("ConditionNumber", m, ThinSVD)
+	if err != nil {
+		return 0, err
+	}
+	return f.ConditionNumber(), nil
+}
+
+// Rank returns the numerical rank of m, using the default cutoff described
+// in SVD.Rank.
+//
+// If m is nil or an error Matrix, a non-nil error is returned.
+func (m *Matrix) Rank() (int, error) {
+	f, err := newSVD("Rank", m, ThinSVD)
+	if err != nil {
+		return 0, err
+	}
+	return f.Rank(-1), nil
+}
+
+// PseudoInverse returns the Moore-Penrose pseudoinverse of m. Singular values
+// smaller than cutoff times the largest singular value are treated as zero.
+// If cutoff is negative, the default cutoff described in SVD.Rank is used.
+//
+// If m is nil, an error Matrix is returned.
+func PseudoInverse(m *Matrix, cutoff float64) *Matrix {
+	if err := inputError("PseudoInverse", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.height, m.width).PseudoInverse(m, cutoff)
+}
+
+// PseudoInverse computes the Moore-Penrose pseudoinverse of m and stores it
+// in the target Matrix. The target Matrix is also returned.
+//
+// If m is nil or if target is not the same shape as the transpose of m,
+// target is set to an error Matrix.
+func (target *Matrix) PseudoInverse(m *Matrix, cutoff float64) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.PseudoInverse(m, cutoff) })
+	}
+
+	f, err := newSVD("PseudoInverse", m, ThinSVD)
+	if err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "PseudoInverse", "Target Matrix is nil.")
+	} else if !TransposeCompatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match pseudoinverse shape (%d, %d).",
+			target.width, target.height, m.height, m.width)
+		return target.setError(newError(ShapeError, "PseudoInverse", desc))
+	}
+
+	copy(target.values, f.PseudoInverse(cutoff).values)
+	target.err = nil
+	return target
+}
+
+// LowRank returns the best rank-k approximation to m.
+//
+// If m is nil or if k is negative or larger than the smaller of m's width and
+// height, an error Matrix is returned.
+func LowRank(m *Matrix, k int) *Matrix {
+	if err := inputError("LowRank", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return New(m.width, m.height).LowRank(m, k)
+}
+
+// LowRank computes the best rank-k approximation to m and stores it in the
+// target Matrix. The target Matrix is also returned.
+//
+// If m is nil, if k is negative or larger than the smaller of m's width and
+// height, or if target is not the same shape as m, target is set to an error
+// Matrix.
+func (target *Matrix) LowRank(m *Matrix, k int) *Matrix {
+	if !target.contiguous() {
+		return target.viaDense(func(t *Matrix) *Matrix { return t.LowRank(m, k) })
+	}
+
+	f, err := newSVD("LowRank", m, ThinSVD)
+	if err != nil {
+		return target.setError(err)
+	} else if target == nil {
+		return newErrorMatrix(NilError, "LowRank", "Target Matrix is nil.")
+	} else if !Compatible(target, m) {
+		desc := fmt.Sprintf("Target shape (%d, %d) does not match input shape (%d, %d).",
+			target.width, target.height, m.width, m.height)
+		return target.setError(newError(ShapeError, "LowRank", desc))
+	}
+
+	approx := f.LowRank(k)
+	if approx.IsError() {
+		return target.setError(approx.err)
+	}
+	copy(target.values, approx.values)
+	target.err = nil
+	return target
+}
+
+// NullSpace returns a Matrix whose columns are an orthonormal basis of the
+// null space of m, treating singular values smaller than cutoff times the
+// largest singular value as zero. If cutoff is negative, the default cutoff
+// described in SVD.Rank is used. If the null space is trivial, nil is
+// returned.
+//
+// If m is nil, an error Matrix is returned.
+func NullSpace(m *Matrix, cutoff float64) *Matrix {
+	f, err := newSVD("NullSpace", m, FullSVD)
+	if err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return f.NullSpace(cutoff)
+}
+
+// Range returns a Matrix whose columns are an orthonormal basis of the range
+// (column space) of m, treating singular values smaller than cutoff times the
+// largest singular value as zero. If cutoff is negative, the default cutoff
+// described in SVD.Rank is used. If m is numerically zero, nil is returned.
+//
+// If m is nil, an error Matrix is returned.
+func Range(m *Matrix, cutoff float64) *Matrix {
+	f, err := newSVD("Range", m, ThinSVD)
+	if err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return f.Range(cutoff)
+}
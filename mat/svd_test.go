@@ -0,0 +1,185 @@
+package mat
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestSVD(t *testing.T) {
+	tests := []struct {
+		m      *Matrix
+		values []float64
+	}{
+		{FromSlice(1, 1, []float64{-3}), []float64{3}},
+		{FromSlice(2, 2, []float64{3, 0, 0, -4}), []float64{4, 3}},
+		{FromSlice(2, 3, []float64{1, 0, 0, 1, 1, 1}), []float64{math.Sqrt(3), 1}},
+		{FromSlice(3, 2, []float64{1, 0, 1, 0, 1, 1}), []float64{math.Sqrt(3), 1}},
+		{New(3, 2), []float64{0, 0}},
+		{randomMatrix(12, 30), nil},
+		{randomMatrix(30, 12), nil},
+		{randomMatrix(20, 20), nil},
+	}
+
+	for i, test := range tests {
+		for _, kind := range []SVDKind{ThinSVD, FullSVD} {
+			f, err := NewSVD(test.m, kind)
+			if err != nil {
+				t.Errorf("%d) NewSVD(%d) returned error: %s", i, kind, err)
+				continue
+			}
+
+			w, h := test.m.width, test.m.height
+			k := minInt(w, h)
+			s, u, v := f.Values(), f.U(), f.V()
+
+			if len(s) != k {
+				t.Errorf("%d) len(Values()) = %d, wanted %d", i, len(s), k)
+				continue
+			} else if !sort.IsSorted(sort.Reverse(sort.Float64Slice(s))) {
+				t.Errorf("%d) Singular values %v are not descending", i, s)
+			}
+			for j := range test.values {
+				if math.Abs(s[j]-test.values[j]) > 1e-13 {
+					t.Errorf("%d) Singular values = %v, wanted %v",
+						i, s, test.values)
+					break
+				}
+			}
+
+			uw, vw := k, k
+			if kind == FullSVD {
+				uw, vw = h, w
+			}
+			if u.Width() != uw || u.Height() != h ||
+				v.Width() != vw || v.Height() != w {
+				t.Errorf("%d) U and V have shapes (%d, %d) and (%d, %d) (kind %d)",
+					i, u.Width(), u.Height(), v.Width(), v.Height(), kind)
+				continue
+			}
+
+			if diff := maxDiff(Mult(Transpose(u), u), Identity(uw)); diff > 1e-13 {
+				t.Errorf("%d) U^T U differs from I by %g (kind %d)", i, diff, kind)
+			}
+			if diff := maxDiff(Mult(Transpose(v), v), Identity(vw)); diff > 1e-13 {
+				t.Errorf("%d) V^T V differs from I by %g (kind %d)", i, diff, kind)
+			}
+
+			// A = U_k S V_k^T
+			if diff := maxDiff(f.LowRank(k), test.m); diff > 1e-13 {
+				t.Errorf("%d) U S V^T differs from A by %g (kind %d)",
+					i, diff, kind)
+			}
+		}
+	}
+
+	if _, err := NewSVD(nil, ThinSVD); err == nil {
+		t.Errorf("NewSVD(nil) returned nil error.")
+	}
+	if _, err := NewSVD(Identity(2), SVDKind(5)); err == nil {
+		t.Errorf("NewSVD with invalid kind returned nil error.")
+	}
+}
+
+func TestSVDDerived(t *testing.T) {
+	// A rank 2 Matrix: the third column is the sum of the first two.
+	a := FromSlice(3, 4, []float64{
+		1, 0, 1,
+		0, 1, 1,
+		1, 1, 2,
+		1, -1, 0,
+	})
+
+	if r, err := a.Rank(); err != nil || r != 2 {
+		t.Errorf("a.Rank() = (%d, %v), wanted 2", r, err)
+	}
+	if c, _ := a.ConditionNumber(); !math.IsInf(c, 1) && c < 1e14 {
+		t.Errorf("a.ConditionNumber() = %g, wanted a huge value", c)
+	}
+
+	// Moore-Penrose conditions.
+	p := PseudoInverse(a, -1)
+	if diff := maxDiff(Mult(Mult(a, p), a), a); diff > 1e-13 {
+		t.Errorf("A A^+ A differs from A by %g", diff)
+	}
+	if diff := maxDiff(Mult(Mult(p, a), p), p); diff > 1e-13 {
+		t.Errorf("A^+ A A^+ differs from A^+ by %g", diff)
+	}
+	ap := Mult(a, p)
+	if diff := maxDiff(ap, Transpose(ap)); diff > 1e-13 {
+		t.Errorf("A A^+ is not symmetric: %g", diff)
+	}
+
+	// For full rank square matrices the pseudoinverse is the inverse.
+	b := FromSlice(2, 2, []float64{4, 7, 2, 6})
+	if diff := maxDiff(PseudoInverse(b, -1), Invert(b)); diff > 1e-13 {
+		t.Errorf("PseudoInverse(b) differs from Invert(b) by %g", diff)
+	}
+
+	null := NullSpace(a, -1)
+	if null == nil || null.Width() != 1 {
+		t.Fatalf("NullSpace(a) = %v, wanted one column", null)
+	} else if diff := maxDiff(Mult(a, null), New(1, 4)); diff > 1e-13 {
+		t.Errorf("A N differs from zero by %g", diff)
+	}
+
+	// Null space of a wide Matrix.
+	wide := Transpose(a)
+	wnull := NullSpace(wide, -1)
+	if wnull == nil || wnull.Width() != 2 {
+		t.Errorf("NullSpace(wide) has %d columns, wanted 2", wnull.Width())
+	} else if diff := maxDiff(Mult(wide, wnull), New(2, 3)); diff > 1e-13 {
+		t.Errorf("wide N differs from zero by %g", diff)
+	}
+	f, _ := NewSVD(wide, ThinSVD)
+	if errorCode(f.NullSpace(-1)) != ParameterError {
+		t.Errorf("Thin NullSpace of wide Matrix did not give ParameterError.")
+	}
+	if NullSpace(Identity(3), -1) != nil {
+		t.Errorf("NullSpace(I) is not nil.")
+	}
+
+	rng := Range(a, -1)
+	if rng == nil || rng.Width() != 2 {
+		t.Fatalf("Range(a) = %v, wanted two columns", rng)
+	}
+	// Projecting the columns of a onto the range leaves them unchanged.
+	proj := Mult(rng, Mult(Transpose(rng), a))
+	if diff := maxDiff(proj, a); diff > 1e-13 {
+		t.Errorf("Projection onto Range(a) changes a by %g", diff)
+	}
+
+	// The error of the best rank-k approximation in the 2-norm is the next
+	// singular value.
+	m := randomMatrix(8, 10)
+	g, _ := NewSVD(m, ThinSVD)
+	s := g.Values()
+	for k := 0; k < len(s); k++ {
+		diff, _ := Sub(m, LowRank(m, k)).Norm2()
+		if math.Abs(diff-s[k]) > 1e-12 {
+			t.Errorf("|A - A_%d| = %g, wanted %g", k, diff, s[k])
+		}
+	}
+
+	if n, _ := FromSlice(2, 2, []float64{3, 0, 0, -4}).Norm2(); n != 4 {
+		t.Errorf("Norm2 = %g, wanted 4", n)
+	}
+
+	errTests := []struct {
+		name string
+		m    *Matrix
+		code int
+	}{
+		{"LowRank(m, -1)", LowRank(m, -1), ParameterError},
+		{"LowRank(m, 9)", LowRank(m, 9), ParameterError},
+		{"LowRank(nil, 1)", LowRank(nil, 1), NilError},
+		{"target.PseudoInverse(shape)", New(8, 10).PseudoInverse(m, -1), ShapeError},
+		{"PseudoInverse(nil)", PseudoInverse(nil, -1), NilError},
+	}
+	for _, test := range errTests {
+		if errorCode(test.m) != test.code {
+			t.Errorf("%s has error code %d, wanted %d",
+				test.name, errorCode(test.m), test.code)
+		}
+	}
+}
@@ -0,0 +1,497 @@
+package mat
+
+import (
+	"fmt"
+	"math"
+)
+
+// TridiagonalMatrix represents a square Matrix whose only non-zero elements
+// are on the diagonal and the first sub- and superdiagonals. Tridiagonal
+// matrices are factored when they are created, so that linear systems can be
+// solved in O(n) time. (The name Tridiagonal is used by the SymEigenMethod.)
+//
+// TridiagonalMatrices are immutable.
+type TridiagonalMatrix struct {
+	n                  int
+	lower, diag, upper []float64
+
+	// LU factorization with partial pivoting. U has two superdiagonals,
+	// du and du2, and the multipliers of L are stored in dl.
+	dl, d, du, du2 []float64
+	swap           []bool // Rows i and i + 1 were exchanged at step i.
+	singular       bool
+}
+
+// NewTridiagonalMatrix creates a TridiagonalMatrix with the given diagonal
+// and sub- and superdiagonals: element (i, i) is diag[i], element (i, i + 1) is upper[i],
+// and element (i + 1, i) is lower[i]. The input slices are copied.
+//
+// If diag is empty or if lower or upper does not have a length of
+// len(diag) - 1, a non-nil error is returned. Singular matrices can be
+// created, but cannot be used to solve linear systems.
+func NewTridiagonalMatrix(lower, diag, upper []float64) (*TridiagonalMatrix, error) {
+	t, err := newTridiagonalMatrix("NewTridiagonalMatrix", lower, diag, upper)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func newTridiagonalMatrix(
+	operationName string, lower, diag, upper []float64,
+) (*TridiagonalMatrix, *MatrixError) {
+	n := len(diag)
+	if n == 0 {
+		return nil, newError(ParameterError, operationName,
+			"Diagonal has a length of 0.")
+	} else if len(lower) != n - 1 || len(upper) != n - 1 {
+		desc := fmt.Sprintf("Off-diagonal lengths %d and %d do not match diagonal length %d minus one.",
+			len(lower), len(upper), n)
+		return nil, newError(ShapeError, operationName, desc)
+	}
+
+	t := &TridiagonalMatrix{
+		n: n, lower: append([]float64{}, lower...),
+		diag: append([]float64{}, diag...), upper: append([]float64{}, upper...),
+	}
+	t.factor()
+	return t, nil
+}
+
+// factor computes the LU factorization of t with partial pivoting. This is
+// the Thomas algorithm with row exchanges, which are needed when t is not
+// diagonally dominant.
+func (t *TridiagonalMatrix) factor() {
+	n := t.n
+	t.dl = append([]float64{}, t.lower...)
+	t.d = append([]float64{}, t.diag...)
+	t.du = append([]float64{}, t.upper...)
+	t.du2 = make([]float64, maxInt(n - 2, 0))
+	t.swap = make([]bool, maxInt(n - 1, 0))
+	dl, d, du := t.dl, t.d, t.du
+
+	for i := 0; i < n - 1; i++ {
+		if math.Abs(d[i]) >= math.Abs(dl[i]) {
+			// No row exchange.
+			if d[i] != 0 {
+				fact := dl[i] / d[i]
+				dl[i] = fact
+				d[i + 1] -= fact * du[i]
+			}
+			continue
+		}
+
+		// Exchange rows i and i + 1 and eliminate.
+		fact := d[i] / dl[i]
+		d[i], dl[i] = dl[i], fact
+		tmp := du[i]
+		du[i] = d[i + 1]
+		d[i + 1] = tmp - fact * d[i + 1]
+		if i < n - 2 {
+			t.du2[i] = du[i + 1]
+			du[i + 1] = -fact * du[i + 1]
+		}
+		t.swap[i] = true
+	}
+
+	for _, x := range d {
+		if x == 0 {
+			t.singular = true
+		}
+	}
+}
+
+// Width returns the width of t.
+func (t *TridiagonalMatrix) Width() int {
+	return t.n
+}
+
+// Height returns the height of t.
+func (t *TridiagonalMatrix) Height() int {
+	return t.n
+}
+
+// At returns the element of t with coordinates (x, y).
+//
+// Like m.Get, At panics if (x, y) is out of bounds.
+func (t *TridiagonalMatrix) At(x, y int) float64 {
+	checkSquareBounds("At", x, y, t.n)
+	switch x - y {
+	case 0:
+		return t.diag[y]
+	case 1:
+		return t.upper[y]
+	case -1:
+		return t.lower[x]
+	default:
+		return 0
+	}
+}
+
+// Dense converts t to a Matrix.
+func (t *TridiagonalMatrix) Dense() *Matrix {
+	m := New(t.n, t.n)
+	for i := 0; i < t.n; i++ {
+		m.values[i * t.n + i] = t.diag[i]
+		if i < t.n - 1 {
+			m.values[i * t.n + i + 1] = t.upper[i]
+			m.values[(i + 1) * t.n + i] = t.lower[i]
+		}
+	}
+	return m
+}
+
+// IsSingular returns true if t is singular.
+func (t *TridiagonalMatrix) IsSingular() bool {
+	return t.singular
+}
+
+// Determinant returns the determinant of t.
+func (t *TridiagonalMatrix) Determinant() float64 {
+	det := 1.0
+	for i, x := range t.d {
+		det *= x
+		if i < t.n - 1 && t.swap[i] {
+			det = -det
+		}
+	}
+	return det
+}
+
+// MulVec computes the product t * x and returns the result.
+//
+// If len(x) is not equal to the width of t, a non-nil error is returned.
+func (t *TridiagonalMatrix) MulVec(x []float64) ([]float64, error) {
+	target := make([]float64, t.n)
+	if err := t.mulVecAt("MulVec", x, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// MulVecAt computes the product t * x and stores the result in target.
+// target may be the same slice as x.
+//
+// If x or target does not have a length equal to the width of t, a non-nil
+// error is returned.
+func (t *TridiagonalMatrix) MulVecAt(x, target []float64) error {
+	if err := t.mulVecAt("MulVecAt", x, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *TridiagonalMatrix) mulVecAt(operationName string, x, target []float64) *MatrixError {
+	if err := checkVecs(operationName, t.n, x, target); err != nil {
+		return err
+	}
+
+	out := target
+	if sameVec(x, target) {
+		out = make([]float64, t.n)
+	}
+	n := t.n
+	for i := 0; i < n; i++ {
+		sum := t.diag[i] * x[i]
+		if i > 0 {
+			sum += t.lower[i - 1] * x[i - 1]
+		}
+		if i < n - 1 {
+			sum += t.upper[i] * x[i + 1]
+		}
+		out[i] = sum
+	}
+	if !sameVec(out, target) {
+		copy(target, out)
+	}
+	return nil
+}
+
+// SolveVec solves the linear system t * x = b in O(n) time and returns x.
+//
+// If b does not have a length equal to the height of t, or if t is singular,
+// a non-nil error is returned.
+func (t *TridiagonalMatrix) SolveVec(b []float64) ([]float64, error) {
+	x := make([]float64, t.n)
+	if err := t.solveVecAt("SolveVec", b, x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// SolveVecAt solves the linear system t * x = b in O(n) time and stores x
+// in target. target may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of t, or if t is
+// singular, a non-nil error is returned.
+func (t *TridiagonalMatrix) SolveVecAt(b, target []float64) error {
+	if err := t.solveVecAt("SolveVecAt", b, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *TridiagonalMatrix) solveVecAt(operationName string, b, target []float64) *MatrixError {
+	if err := checkVecs(operationName, t.n, b, target); err != nil {
+		return err
+	} else if t.singular {
+		return newError(SingularError, operationName, "Matrix is singular.")
+	}
+
+	copy(target, b)
+	t.solveInPlace(target)
+	return nil
+}
+
+// solveInPlace overwrites x with the solution of t * y = x.
+func (t *TridiagonalMatrix) solveInPlace(x []float64) {
+	n, dl, d, du, du2 := t.n, t.dl, t.d, t.du, t.du2
+
+	// L y = P b.
+	for i := 0; i < n - 1; i++ {
+		if t.swap[i] {
+			x[i], x[i + 1] = x[i + 1], x[i] - dl[i] * x[i + 1]
+		} else {
+			x[i + 1] -= dl[i] * x[i]
+		}
+	}
+
+	// U x = y.
+	x[n - 1] /= d[n - 1]
+	if n > 1 {
+		x[n - 2] = (x[n - 2] - du[n - 2] * x[n - 1]) / d[n - 2]
+	}
+	for i := n - 3; i >= 0; i-- {
+		x[i] = (x[i] - du[i] * x[i + 1] - du2[i] * x[i + 2]) / d[i]
+	}
+}
+
+// CyclicTridiagonal represents a square Matrix which is tridiagonal except
+// for two non-zero corner elements, (n - 1, 0) and (0, n - 1). Such matrices
+// arise from periodic boundary conditions. Linear systems are solved in
+// O(n) time with the Sherman-Morrison formula.
+//
+// CyclicTridiagonal matrices are immutable.
+type CyclicTridiagonal struct {
+	n                  int
+	lower, diag, upper []float64
+
+	t        *TridiagonalMatrix // Tridiagonal part with a modified diagonal.
+	z        []float64          // Solution of t * z = u.
+	gamma    float64
+	singular bool
+}
+
+// NewCyclicTridiagonal creates a CyclicTridiagonal. All three input slices
+// must have the same length, n, and are interpreted periodically: element
+// (i, i) is diag[i], element (i, (i + 1) mod n) is upper[i], and element
+// (i, (i - 1) mod n) is lower[i]. In particular, the corner element (n - 1,
+// 0) is lower[0] and the corner element (0, n - 1) is upper[n - 1]. The
+// input slices are copied.
+//
+// If n is less than 3 or if the input slices have different lengths, a
+// non-nil error is returned. Singular matrices can be created, but cannot be
+// used to solve linear systems.
+func NewCyclicTridiagonal(lower, diag, upper []float64) (*CyclicTridiagonal, error) {
+	n := len(diag)
+	if n < 3 {
+		desc := fmt.Sprintf("Diagonal length %d is less than 3.", n)
+		return nil, newError(ParameterError, "NewCyclicTridiagonal", desc)
+	} else if len(lower) != n || len(upper) != n {
+		desc := fmt.Sprintf("Off-diagonal lengths %d and %d do not match diagonal length %d.",
+			len(lower), len(upper), n)
+		return nil, newError(ShapeError, "NewCyclicTridiagonal", desc)
+	}
+
+	c := &CyclicTridiagonal{
+		n: n, lower: append([]float64{}, lower...),
+		diag: append([]float64{}, diag...), upper: append([]float64{}, upper...),
+	}
+
+	// A = T + u v^T, where u = (gamma, 0, ..., 0, alpha) and
+	// v = (1, 0, ..., 0, beta / gamma).
+	alpha, beta := upper[n - 1], lower[0]
+	c.gamma = -diag[0]
+	if c.gamma == 0 {
+		c.gamma = 1
+	}
+	d := append([]float64{}, diag...)
+	d[0] -= c.gamma
+	d[n - 1] -= alpha * beta / c.gamma
+
+	t, err := newTridiagonalMatrix("NewCyclicTridiagonal", lower[1:], d, upper[:n - 1])
+	if err != nil {
+		return nil, err
+	}
+	c.t = t
+	if t.singular {
+		c.singular = true
+		return c, nil
+	}
+
+	c.z = make([]float64, n)
+	c.z[0], c.z[n - 1] = c.gamma, alpha
+	t.solveInPlace(c.z)
+	if 1 + c.z[0] + beta * c.z[n - 1] / c.gamma == 0 {
+		c.singular = true
+	}
+	return c, nil
+}
+
+// Width returns the width of c.
+func (c *CyclicTridiagonal) Width() int {
+	return c.n
+}
+
+// Height returns the height of c.
+func (c *CyclicTridiagonal) Height() int {
+	return c.n
+}
+
+// At returns the element of c with coordinates (x, y).
+//
+// Like m.Get, At panics if (x, y) is out of bounds.
+func (c *CyclicTridiagonal) At(x, y int) float64 {
+	checkSquareBounds("At", x, y, c.n)
+	switch (x - y + c.n) % c.n {
+	case 0:
+		return c.diag[y]
+	case 1:
+		return c.upper[y]
+	case c.n - 1:
+		return c.lower[y]
+	default:
+		return 0
+	}
+}
+
+// Dense converts c to a Matrix.
+func (c *CyclicTridiagonal) Dense() *Matrix {
+	n := c.n
+	m := New(n, n)
+	for i := 0; i < n; i++ {
+		m.values[i * n + i] = c.diag[i]
+		m.values[i * n + (i + 1) % n] = c.upper[i]
+		m.values[i * n + (i + n - 1) % n] = c.lower[i]
+	}
+	return m
+}
+
+// IsSingular returns true if c is singular.
+func (c *CyclicTridiagonal) IsSingular() bool {
+	return c.singular
+}
+
+// MulVec computes the product c * x and returns the result.
+//
+// If len(x) is not equal to the width of c, a non-nil error is returned.
+func (c *CyclicTridiagonal) MulVec(x []float64) ([]float64, error) {
+	target := make([]float64, c.n)
+	if err := c.mulVecAt("MulVec", x, target); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// MulVecAt computes the product c * x and stores the result in target.
+// target may be the same slice as x.
+//
+// If x or target does not have a length equal to the width of c, a non-nil
+// error is returned.
+func (c *CyclicTridiagonal) MulVecAt(x, target []float64) error {
+	if err := c.mulVecAt("MulVecAt", x, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CyclicTridiagonal) mulVecAt(operationName string, x, target []float64) *MatrixError {
+	if err := checkVecs(operationName, c.n, x, target); err != nil {
+		return err
+	}
+
+	out := target
+	if sameVec(x, target) {
+		out = make([]float64, c.n)
+	}
+	n := c.n
+	for i := 0; i < n; i++ {
+		out[i] = c.lower[i] * x[(i + n - 1) % n] + c.diag[i] * x[i] +
+			c.upper[i] * x[(i + 1) % n]
+	}
+	if !sameVec(out, target) {
+		copy(target, out)
+	}
+	return nil
+}
+
+// SolveVec solves the linear system c * x = b in O(n) time and returns x.
+//
+// If b does not have a length equal to the height of c, or if c is singular,
+// a non-nil error is returned.
+func (c *CyclicTridiagonal) SolveVec(b []float64) ([]float64, error) {
+	x := make([]float64, c.n)
+	if err := c.solveVecAt("SolveVec", b, x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// SolveVecAt solves the linear system c * x = b in O(n) time and stores x
+// in target. target may be the same slice as b.
+//
+// If b or target does not have a length equal to the height of c, or if c is
+// singular, a non-nil error is returned.
+func (c *CyclicTridiagonal) SolveVecAt(b, target []float64) error {
+	if err := c.solveVecAt("SolveVecAt", b, target); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CyclicTridiagonal) solveVecAt(operationName string, b, target []float64) *MatrixError {
+	if err := checkVecs(operationName, c.n, b, target); err != nil {
+		return err
+	} else if c.singular {
+		return newError(SingularError, operationName, "Matrix is singular.")
+	}
+
+	// x = y - (v^T y / (1 + v^T z)) z, where T y = b.
+	n, beta := c.n, c.lower[0]
+	copy(target, b)
+	c.t.solveInPlace(target)
+	fact := (target[0] + beta * target[n - 1] / c.gamma) /
+		(1 + c.z[0] + beta * c.z[n - 1] / c.gamma)
+	for i := range target {
+		target[i] -= fact * c.z[i]
+	}
+	return nil
+}
+
+// checkVecs returns an error if x does not have length n or if target is
+// non-nil and does not have length n.
+func checkVecs(operationName string, n int, x, target []float64) *MatrixError {
+	if len(x) != n {
+		desc := fmt.Sprintf("Length of input vector, %d, does not match Matrix size %d.",
+			len(x), n)
+		return newError(ShapeError, operationName, desc)
+	} else if target != nil && len(target) != n {
+		desc := fmt.Sprintf("Length of target vector, %d, does not match Matrix size %d.",
+			len(target), n)
+		return newError(ShapeError, operationName, desc)
+	}
+	return nil
+}
+
+// sameVec returns true if x and y start at the same element.
+func sameVec(x, y []float64) bool {
+	return len(x) > 0 && len(y) > 0 && &x[0] == &y[0]
+}
+
+// checkSquareBounds panics if (x, y) is out of bounds for an n by n Matrix.
+func checkSquareBounds(operationName string, x, y, n int) {
+	if x < 0 || y < 0 || x >= n || y >= n {
+		panic(fmt.Sprintf("mat.%s given coordinates (%d, %d), which are "+
+			"out of bounds for a %d by %d Matrix.", operationName, x, y, n, n))
+	}
+}
@@ -0,0 +1,150 @@
+package mat
+
+import (
+	"math"
+	"testing"
+)
+
+// vecMaxDiff returns the largest absolute difference between two slices of
+// the same length.
+func vecMaxDiff(x, y []float64) float64 {
+	diff := 0.0
+	for i := range x {
+		diff = math.Max(diff, math.Abs(x[i] - y[i]))
+	}
+	return diff
+}
+
+func TestTridiagonal(t *testing.T) {
+	tests := []struct {
+		lower, diag, upper []float64
+	}{
+		{[]float64{}, []float64{3}, []float64{}},
+		{[]float64{1}, []float64{0, 2}, []float64{3}},
+		{[]float64{-1, -1, -1}, []float64{2, 2, 2, 2}, []float64{-1, -1, -1}},
+		// Not diagonally dominant, so rows must be exchanged.
+		{[]float64{5, 1, 7, 2}, []float64{1e-3, 0, 2, 1, 3},
+			[]float64{1, 4, 1, 6}},
+		{randomMatrix(29, 1).Slice(), randomMatrix(30, 1).Slice(),
+			randomMatrix(29, 1).Slice()},
+	}
+
+	for i, test := range tests {
+		tri, err := NewTridiagonalMatrix(test.lower, test.diag, test.upper)
+		if err != nil {
+			t.Errorf("%d) NewTridiagonalMatrix returned error: %s", i, err)
+			continue
+		}
+		m := tri.Dense()
+		n := len(test.diag)
+
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				if tri.At(x, y) != m.Get(x, y) {
+					t.Errorf("%d) At(%d, %d) = %g, but Dense has %g",
+						i, x, y, tri.At(x, y), m.Get(x, y))
+				}
+			}
+		}
+
+		b := randomMatrix(1, n).Slice()
+		want, _ := SolveVec(m, b)
+		x, err := tri.SolveVec(b)
+		if err != nil {
+			t.Errorf("%d) SolveVec returned error: %s", i, err)
+		} else if diff := vecMaxDiff(x, want); diff > 1e-10 {
+			t.Errorf("%d) SolveVec differs from dense solve by %g", i, diff)
+		}
+
+		if err := tri.SolveVecAt(b, b); err != nil {
+			t.Errorf("%d) SolveVecAt returned error: %s", i, err)
+		} else if diff := vecMaxDiff(b, want); diff > 1e-10 {
+			t.Errorf("%d) Aliased SolveVecAt differs by %g", i, diff)
+		}
+
+		prod, _ := tri.MulVec(x)
+		denseProd, _ := m.MulVec(x)
+		if diff := vecMaxDiff(prod, denseProd); diff > 1e-13 {
+			t.Errorf("%d) MulVec differs from dense product by %g", i, diff)
+		}
+
+		det, _ := m.Determinant()
+		if diff := math.Abs(tri.Determinant() - det); diff > 1e-10 * math.Max(1, math.Abs(det)) {
+			t.Errorf("%d) Determinant = %g, wanted %g", i, tri.Determinant(), det)
+		}
+	}
+
+	singular, _ := NewTridiagonalMatrix([]float64{1}, []float64{1, 1}, []float64{1})
+	if !singular.IsSingular() {
+		t.Errorf("Singular TridiagonalMatrix was not detected.")
+	} else if _, err := singular.SolveVec([]float64{1, 1}); err == nil {
+		t.Errorf("SolveVec on singular TridiagonalMatrix gave no error.")
+	}
+	if _, err := NewTridiagonalMatrix([]float64{1}, []float64{1, 1}, nil); err == nil {
+		t.Errorf("NewTridiagonalMatrix with short upper diagonal gave no error.")
+	}
+	tri, _ := NewTridiagonalMatrix(nil, []float64{1}, nil)
+	if _, err := tri.SolveVec([]float64{1, 2}); err == nil {
+		t.Errorf("SolveVec with wrong length gave no error.")
+	}
+}
+
+func TestCyclicTridiagonal(t *testing.T) {
+	tests := []struct {
+		lower, diag, upper []float64
+	}{
+		{[]float64{1, 1, 1}, []float64{4, 4, 4}, []float64{1, 1, 1}},
+		// Periodic 1D Laplacian plus a small shift.
+		{[]float64{-1, -1, -1, -1, -1}, []float64{2.1, 2.1, 2.1, 2.1, 2.1},
+			[]float64{-1, -1, -1, -1, -1}},
+		// Zero leading diagonal element.
+		{[]float64{2, 3, 1, 1}, []float64{0, 5, 1, 2}, []float64{1, 2, 3, 4}},
+		{randomMatrix(25, 1).Slice(), randomMatrix(25, 1).Slice(),
+			randomMatrix(25, 1).Slice()},
+	}
+
+	for i, test := range tests {
+		c, err := NewCyclicTridiagonal(test.lower, test.diag, test.upper)
+		if err != nil {
+			t.Errorf("%d) NewCyclicTridiagonal returned error: %s", i, err)
+			continue
+		}
+		m := c.Dense()
+		n := len(test.diag)
+		if m.Get(n - 1, 0) != test.lower[0] || m.Get(0, n - 1) != test.upper[n - 1] {
+			t.Errorf("%d) Corner elements of Dense are wrong.", i)
+		}
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				if c.At(x, y) != m.Get(x, y) {
+					t.Errorf("%d) At(%d, %d) = %g, but Dense has %g",
+						i, x, y, c.At(x, y), m.Get(x, y))
+				}
+			}
+		}
+
+		b := randomMatrix(1, n).Slice()
+		want, _ := SolveVec(m, b)
+		x, err := c.SolveVec(b)
+		if err != nil {
+			t.Errorf("%d) SolveVec returned error: %s", i, err)
+		} else if diff := vecMaxDiff(x, want); diff > 1e-10 {
+			t.Errorf("%d) SolveVec differs from dense solve by %g", i, diff)
+		}
+
+		prod, _ := c.MulVec(x)
+		if diff := vecMaxDiff(prod, b); diff > 1e-10 {
+			t.Errorf("%d) MulVec(SolveVec(b)) differs from b by %g", i, diff)
+		}
+	}
+
+	c, _ := NewCyclicTridiagonal([]float64{1, 0, 0}, []float64{0, 0, 0},
+		[]float64{0, 0, 1})
+	if !c.IsSingular() {
+		t.Errorf("Singular CyclicTridiagonal was not detected.")
+	}
+	if _, err := NewCyclicTridiagonal([]float64{1, 1}, []float64{1, 1},
+		[]float64{1, 1}); err == nil {
+		t.Errorf("NewCyclicTridiagonal with n = 2 gave no error.")
+	}
+}
@@ -0,0 +1,156 @@
+package mat
+
+import (
+	"fmt"
+)
+
+// View returns a Matrix which shares storage with the width x height block
+// of m whose upper left corner has the coordinates (x, y). Changes to the
+// elements of the view are changes to the elements of m, and vice versa.
+// Views may be used as the inputs and targets of every operation.
+//
+// If the view would not fit within m, or if width or height is non-positive,
+// an error Matrix is returned.
+func (m *Matrix) View(x, y, width, height int) *Matrix {
+	return m.view("View", x, y, width, height)
+}
+
+// Rows returns a view of the rows of m with indices in [start, end). See
+// View.
+//
+// If the range is empty or not within m, an error Matrix is returned.
+func (m *Matrix) Rows(start, end int) *Matrix {
+	if err := inputError("Rows", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return m.view("Rows", 0, start, m.width, end - start)
+}
+
+// Cols returns a view of the columns of m with indices in [start, end). See
+// View.
+//
+// If the range is empty or not within m, an error Matrix is returned.
+func (m *Matrix) Cols(start, end int) *Matrix {
+	if err := inputError("Cols", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return m.view("Cols", start, 0, end - start, m.height)
+}
+
+// Row returns a view of row y of m as a Matrix with a height of one. See
+// View.
+//
+// If y is out of bounds, an error Matrix is returned.
+func (m *Matrix) Row(y int) *Matrix {
+	if err := inputError("Row", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return m.view("Row", 0, y, m.width, 1)
+}
+
+// Col returns a view of column x of m as a Matrix with a width of one. See
+// View.
+//
+// If x is out of bounds, an error Matrix is returned.
+func (m *Matrix) Col(x int) *Matrix {
+	if err := inputError("Col", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+	return m.view("Col", x, 0, 1, m.height)
+}
+
+// Diag returns a view of the main diagonal of m as a Matrix with a width of
+// one. See View.
+//
+// If m is nil or an error Matrix, an error Matrix is returned.
+func (m *Matrix) Diag() *Matrix {
+	if err := inputError("Diag", m); err != nil {
+		return newErrorMatrixFrom(err)
+	}
+
+	n := minInt(m.width, m.height)
+	return &Matrix{
+		m.values[: (n - 1) * (m.stride + 1) + 1], 1, n, m.stride + 1, nil,
+	}
+}
+
+func (m *Matrix) view(operationName string, x, y, width, height int) *Matrix {
+	if err := inputError(operationName, m); err != nil {
+		return newErrorMatrixFrom(err)
+	} else if width <= 0 || height <= 0 {
+		desc := fmt.Sprintf("View shape (%d, %d) is not positive.", width, height)
+		return newErrorMatrix(ParameterError, operationName, desc)
+	} else if x < 0 || y < 0 || x + width > m.width || y + height > m.height {
+		desc := fmt.Sprintf("View of shape (%d, %d) at (%d, %d) does not fit "+
+			"within Matrix shape (%d, %d).", width, height, x, y,
+			m.width, m.height)
+		return newErrorMatrix(ParameterError, operationName, desc)
+	}
+
+	start := y * m.stride + x
+	end := start + (height - 1) * m.stride + width
+	return &Matrix{m.values[start: end], width, height, m.stride, nil}
+}
+
+// row returns the elements of row y of m.
+func (m *Matrix) row(y int) []float64 {
+	return m.values[y * m.stride: y * m.stride + m.width]
+}
+
+// contiguous returns true if the elements of m are stored contiguously in
+// row-major order, as is assumed by most of the algorithms in this package.
+// Only views can be non-contiguous. nil is considered contiguous.
+func (m *Matrix) contiguous() bool {
+	return m == nil || m.height <= 1 || m.stride == m.width
+}
+
+// compact returns a contiguous copy of the valid Matrix m.
+func compact(m *Matrix) *Matrix {
+	out := New(m.width, m.height)
+	for y := 0; y < m.height; y++ {
+		copy(out.values[y * m.width: (y + 1) * m.width], m.row(y))
+	}
+	return out
+}
+
+// dense returns m if it is contiguous and a contiguous copy of it otherwise.
+// nil and error Matrices are returned unchanged. Operations pass their inputs
+// through dense so that they only need to handle contiguous Matrices.
+func dense(m *Matrix) *Matrix {
+	if !valid(m) || m.contiguous() {
+		return m
+	}
+	return compact(m)
+}
+
+// operand returns the input m of an operation which writes to target, or a
+// contiguous copy of m if m is not contiguous or if it partially overlaps
+// target. Operations which pass their inputs through operand only need to
+// handle contiguous inputs which are either identical to target or do not
+// overlap with it at all.
+func (target *Matrix) operand(m *Matrix) *Matrix {
+	if !valid(m) {
+		return m
+	} else if !m.contiguous() {
+		return compact(m)
+	} else if valid(target) && overlaps(target, m) &&
+		(&target.values[0] != &m.values[0] || target.stride != m.stride) {
+		return compact(m)
+	}
+	return m
+}
+
+// viaDense performs the operation op, which writes its result to a target
+// Matrix, on a contiguous copy of the non-contiguous view target and copies
+// the result back into target. target is returned.
+func (target *Matrix) viaDense(op func(*Matrix) *Matrix) *Matrix {
+	tmp := compact(target)
+	if out := op(tmp); out.IsError() {
+		return target.setError(out.err)
+	}
+	for y := 0; y < target.height; y++ {
+		copy(target.row(y), tmp.values[y * tmp.width: (y + 1) * tmp.width])
+	}
+	target.err = nil
+	return target
+}
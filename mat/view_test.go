@@ -0,0 +1,146 @@
+package mat
+
+import (
+	"testing"
+)
+
+func TestViews(t *testing.T) {
+	m := FromSlice(4, 3, []float64{
+		0, 1, 2, 3,
+		4, 5, 6, 7,
+		8, 9, 10, 11,
+	})
+
+	tests := []struct {
+		name string
+		view *Matrix
+		exp  *Matrix
+	}{
+		{"View", m.View(1, 1, 2, 2), FromSlice(2, 2, []float64{5, 6, 9, 10})},
+		{"Rows", m.Rows(1, 3), FromSlice(4, 2, []float64{4, 5, 6, 7, 8, 9, 10, 11})},
+		{"Cols", m.Cols(2, 4), FromSlice(2, 3, []float64{2, 3, 6, 7, 10, 11})},
+		{"Row", m.Row(2), FromSlice(4, 1, []float64{8, 9, 10, 11})},
+		{"Col", m.Col(1), FromSlice(1, 3, []float64{1, 5, 9})},
+		{"Diag", m.Diag(), FromSlice(1, 3, []float64{0, 5, 10})},
+		{"View of view", m.Cols(1, 4).View(1, 1, 2, 2), FromSlice(2, 2, []float64{6, 7, 10, 11})},
+		{"Diag of view", m.Cols(1, 4).Diag(), FromSlice(1, 3, []float64{1, 6, 11})},
+	}
+	for _, test := range tests {
+		if !AlmostEqual(test.view, test.exp) {
+			t.Errorf("%s gave %v, expected %v", test.name,
+				test.view.Grid(), test.exp.Grid())
+		}
+		if !sliceEq(test.view.Slice(), test.exp.Slice()) {
+			t.Errorf("%s.Slice() gave %v", test.name, test.view.Slice())
+		}
+	}
+
+	// Writes through a view are visible in the original Matrix.
+	m.Col(3).Set(0, 1, -1)
+	m.Diag().Scale(m.Diag(), 10)
+	exp := FromSlice(4, 3, []float64{
+		0, 1, 2, 3,
+		4, 50, 6, -1,
+		8, 9, 100, 11,
+	})
+	if !AlmostEqual(m, exp) {
+		t.Errorf("Writes through views gave %v", m.Grid())
+	}
+
+	errTests := []*Matrix{
+		m.View(3, 0, 2, 1), m.View(0, 0, 0, 1), m.Rows(2, 2), m.Cols(-1, 1),
+		m.Row(3), m.Col(4), (*Matrix)(nil).Diag(),
+	}
+	for i, v := range errTests {
+		if !v.IsError() {
+			t.Errorf("%d) Expected error Matrix", i)
+		}
+	}
+}
+
+func TestViewOperations(t *testing.T) {
+	a := randomMatrix(6, 6)
+	orig := Copy(a)
+
+	// Views as inputs.
+	left, right := a.Cols(0, 3), a.Cols(3, 6)
+	if !AlmostEqual(Mult(Transpose(left), right),
+		naiveMult(Transpose(Copy(left)), Copy(right))) {
+		t.Errorf("Mult of column views is incorrect")
+	}
+	det1, _ := a.View(1, 1, 4, 4).Determinant()
+	det2, _ := Copy(a.View(1, 1, 4, 4)).Determinant()
+	if det1 != det2 {
+		t.Errorf("Determinant of view is %g, expected %g", det1, det2)
+	}
+	tr, _ := a.View(1, 0, 3, 3).Trace()
+	if exp := a.Get(1, 0) + a.Get(2, 1) + a.Get(3, 2); tr != exp {
+		t.Errorf("Trace of view is %g, expected %g", tr, exp)
+	}
+	x := []float64{1, 2, 3}
+	y, _ := right.MulVec(x)
+	yExp, _ := Copy(right).MulVec(x)
+	if !sliceEq(y, yExp) {
+		t.Errorf("MulVec of view gave %v, expected %v", y, yExp)
+	}
+
+	// Views as targets. Only the viewed elements may change.
+	b := randomMatrix(3, 3)
+	block := a.View(2, 1, 3, 3)
+	tests := []struct {
+		name string
+		op   func() *Matrix
+		exp  *Matrix
+	}{
+		{"Add", func() *Matrix { return block.Add(b, b) }, Scale(b, 2)},
+		{"Mult", func() *Matrix { return block.Mult(b, b) }, Mult(b, b)},
+		{"Transpose", func() *Matrix { return block.Transpose(b) }, Transpose(b)},
+		{"Invert", func() *Matrix { return block.Invert(b) }, Invert(b)},
+		{"Exp", func() *Matrix { return block.Exp(b) }, Exp(b)},
+		{"Solve", func() *Matrix { return block.Solve(b, Identity(3)) }, Invert(b)},
+		{"Copy", func() *Matrix { return block.Copy(b) }, b},
+	}
+	for _, test := range tests {
+		a.Copy(orig)
+		block = a.View(2, 1, 3, 3)
+		if out := test.op(); out != block {
+			t.Errorf("%s did not return its target", test.name)
+		}
+		if diff := maxDiff(Copy(block), test.exp); diff > 1e-12 {
+			t.Errorf("%s into view differs from expected result by %g",
+				test.name, diff)
+		}
+		outside := Copy(a)
+		outside.View(2, 1, 3, 3).Copy(orig.View(2, 1, 3, 3))
+		if !sliceEq(outside.Slice(), orig.Slice()) {
+			t.Errorf("%s modified elements outside the view", test.name)
+		}
+	}
+
+	// Partially overlapping views.
+	a.Copy(orig)
+	a.Cols(1, 6).Add(a.Cols(0, 5), a.Cols(0, 5))
+	exp := Copy(orig)
+	exp.Cols(1, 6).Copy(Scale(orig.Cols(0, 5), 2))
+	if !AlmostEqual(a, exp) {
+		t.Errorf("Add with overlapping views gave %v", a.Grid())
+	}
+	a.Copy(orig)
+	a.Rows(1, 6).Copy(a.Rows(0, 5))
+	exp = Copy(orig)
+	exp.Rows(1, 6).Copy(Copy(orig.Rows(0, 5)))
+	if !AlmostEqual(a, exp) {
+		t.Errorf("Copy with overlapping views gave %v", a.Grid())
+	}
+
+	// A failed operation turns the view into an error Matrix, but leaves the
+	// original untouched.
+	a.Copy(orig)
+	block = a.View(0, 0, 2, 2)
+	if code := errorCode(block.Mult(b, b)); code != ShapeError {
+		t.Errorf("Mult into mismatched view gave error code %d", code)
+	}
+	if !sliceEq(a.Slice(), orig.Slice()) {
+		t.Errorf("Failed operation modified the viewed Matrix")
+	}
+}
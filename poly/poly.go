@@ -0,0 +1,416 @@
+/*
+package poly implements real-valued polynomials of a single variable.
+
+A Poly is a slice of coefficients in order of increasing degree, so the
+polynomial 3 - 2x + x^3 is written as
+
+	p := poly.Poly{3, -2, 0, 1}
+
+As in package vec, unary operations are implemented as methods while binary
+operations are implemented as functions:
+
+	dp := p.Deriv()
+	q := poly.Mult(p, dp)
+	quo, rem := poly.Div(q, p)
+
+Polynomials returned by functions in this package never have trailing zero
+coefficients, and the zero polynomial is represented by an empty Poly.
+
+Polynomials can be fit to data with Fit and their roots can be found with
+Roots. The classical orthogonal polynomials can be constructed with Legendre,
+Chebyshev, Hermite, and Laguerre.
+*/
+package poly
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+const (
+	rootIters      = 500
+	rootPolishIter = 3
+	realSnapTol    = 1e-12
+)
+
+// Poly represents a polynomial by its coefficients, so that p[i] is the
+// coefficient of x^i.
+type Poly []float64
+
+// Degree returns the degree of p. The zero polynomial has degree -1.
+func (p Poly) Degree() int { return len(p.trim()) - 1 }
+
+// trim returns p with all trailing zero coefficients removed. The underlying
+// array is shared.
+func (p Poly) trim() Poly {
+	n := len(p)
+	for n > 0 && p[n-1] == 0 { n-- }
+	return p[:n]
+}
+
+// Copy returns a copy of p with trailing zero coefficients removed.
+func (p Poly) Copy() Poly {
+	t := p.trim()
+	out := make(Poly, len(t))
+	copy(out, t)
+	return out
+}
+
+// Eval evaluates p at x using Horner's method.
+func (p Poly) Eval(x float64) float64 {
+	y := 0.0
+	for i := len(p) - 1; i >= 0; i-- { y = y*x + p[i] }
+	return y
+}
+
+// EvalDeriv evaluates p and its first derivative at x in a single pass.
+func (p Poly) EvalDeriv(x float64) (y, dy float64) {
+	for i := len(p) - 1; i >= 0; i-- {
+		dy = dy*x + y
+		y = y*x + p[i]
+	}
+	return y, dy
+}
+
+// EvalComplex evaluates p at the complex point z.
+func (p Poly) EvalComplex(z complex128) complex128 {
+	y := complex(0, 0)
+	for i := len(p) - 1; i >= 0; i-- { y = y*z + complex(p[i], 0) }
+	return y
+}
+
+// Deriv returns the derivative of p.
+func (p Poly) Deriv() Poly {
+	t := p.trim()
+	if len(t) <= 1 { return Poly{} }
+	out := make(Poly, len(t)-1)
+	for i := range out { out[i] = float64(i+1) * t[i+1] }
+	return out
+}
+
+// Antideriv returns the antiderivative of p whose constant term is zero.
+func (p Poly) Antideriv() Poly {
+	t := p.trim()
+	if len(t) == 0 { return Poly{} }
+	out := make(Poly, len(t)+1)
+	for i := range t { out[i+1] = t[i] / float64(i+1) }
+	return out
+}
+
+// Int returns the integral of p over the interval [low, high].
+func (p Poly) Int(low, high float64) float64 {
+	ap := p.Antideriv()
+	return ap.Eval(high) - ap.Eval(low)
+}
+
+// Scale returns the polynomial c p.
+func (p Poly) Scale(c float64) Poly {
+	out := make(Poly, len(p))
+	for i := range p { out[i] = c * p[i] }
+	return out.trim()
+}
+
+// Add returns the sum of two polynomials.
+func Add(p, q Poly) Poly {
+	if len(p) < len(q) { p, q = q, p }
+	out := make(Poly, len(p))
+	copy(out, p)
+	for i := range q { out[i] += q[i] }
+	return out.trim()
+}
+
+// Sub returns the difference of two polynomials.
+func Sub(p, q Poly) Poly {
+	n := len(p)
+	if len(q) > n { n = len(q) }
+	out := make(Poly, n)
+	copy(out, p)
+	for i := range q { out[i] -= q[i] }
+	return out.trim()
+}
+
+// Mult returns the product of two polynomials.
+func Mult(p, q Poly) Poly {
+	p, q = p.trim(), q.trim()
+	if len(p) == 0 || len(q) == 0 { return Poly{} }
+	out := make(Poly, len(p)+len(q)-1)
+	for i := range p {
+		for j := range q { out[i+j] += p[i] * q[j] }
+	}
+	return out.trim()
+}
+
+// Div divides p by q and returns the quotient and remainder, so that
+// p = quo * q + rem and the degree of rem is less than the degree of q.
+//
+// Div panics if q is the zero polynomial.
+func Div(p, q Poly) (quo, rem Poly) {
+	q = q.trim()
+	if len(q) == 0 { panic("poly.Div given a zero divisor.") }
+
+	rem = p.Copy()
+	if len(rem) < len(q) { return Poly{}, rem }
+
+	quo = make(Poly, len(rem)-len(q)+1)
+	lead := q[len(q)-1]
+	for i := len(quo) - 1; i >= 0; i-- {
+		c := rem[i+len(q)-1] / lead
+		quo[i] = c
+		for j := range q { rem[i+j] -= c * q[j] }
+		rem[i+len(q)-1] = 0
+	}
+
+	return quo.trim(), rem[:len(q)-1].trim()
+}
+
+// Compose returns the polynomial p(q(x)).
+func Compose(p, q Poly) Poly {
+	p = p.trim()
+	out := Poly{}
+	for i := len(p) - 1; i >= 0; i-- {
+		out = Add(Mult(out, q), Poly{p[i]})
+	}
+	return out
+}
+
+// Fit returns the polynomial of the given degree which minimizes the
+// weighted squared error
+//
+//     sum_i ws[i] (p(xs[i]) - ys[i])^2.
+//
+// If ws is nil, all points are weighted equally. The fit is computed by
+// Householder QR on the Vandermonde matrix of the points after they have been
+// mapped onto [-1, 1], which avoids most of the ill-conditioning associated
+// with fitting directly in the monomial basis.
+//
+// Fit panics if the input slices have different lengths, if there are fewer
+// points than coefficients, or if any weight is negative.
+func Fit(xs, ys, ws []float64, degree int) Poly {
+	n, m := len(xs), degree+1
+	if len(ys) != n {
+		panic(fmt.Sprintf("len(xs) = %d, but len(ys) = %d", n, len(ys)))
+	} else if ws != nil && len(ws) != n {
+		panic(fmt.Sprintf("len(xs) = %d, but len(ws) = %d", n, len(ws)))
+	} else if degree < 0 {
+		panic(fmt.Sprintf("Fit given negative degree %d.", degree))
+	} else if n < m {
+		panic(fmt.Sprintf("Cannot fit degree %d polynomial to %d points.",
+			degree, n))
+	}
+
+	lo, hi := xs[0], xs[0]
+	for _, x := range xs {
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+	}
+	mid, half := (hi+lo)/2, (hi-lo)/2
+	if half == 0 { half = 1 }
+
+	// a is the column-major, weighted Vandermonde matrix.
+	a, b := make([]float64, n*m), make([]float64, n)
+	for i := range xs {
+		w := 1.0
+		if ws != nil {
+			if ws[i] < 0 {
+				panic(fmt.Sprintf("Fit given negative weight %g at index %d.",
+					ws[i], i))
+			}
+			w = math.Sqrt(ws[i])
+		}
+		t, tk := (xs[i]-mid)/half, w
+		for k := 0; k < m; k++ {
+			a[k*n+i] = tk
+			tk *= t
+		}
+		b[i] = w * ys[i]
+	}
+
+	coeffs := leastSquares(a, b, n, m)
+	return Compose(coeffs, Poly{-mid / half, 1 / half})
+}
+
+// leastSquares solves the n x m column-major least squares problem a x = b
+// with Householder QR. a and b are overwritten.
+func leastSquares(a, b []float64, n, m int) Poly {
+	for k := 0; k < m; k++ {
+		col := a[k*n : (k+1)*n]
+		norm := 0.0
+		for i := k; i < n; i++ { norm += col[i] * col[i] }
+		norm = math.Sqrt(norm)
+		if norm == 0 { continue }
+		if col[k] > 0 { norm = -norm }
+
+		// v = col[k:] - norm e_k, stored in place.
+		col[k] -= norm
+		vNorm2 := 0.0
+		for i := k; i < n; i++ { vNorm2 += col[i] * col[i] }
+
+		reflect := func(y []float64) {
+			s := 0.0
+			for i := k; i < n; i++ { s += col[i] * y[i] }
+			s = 2 * s / vNorm2
+			for i := k; i < n; i++ { y[i] -= s * col[i] }
+		}
+		for j := k + 1; j < m; j++ { reflect(a[j*n : (j+1)*n]) }
+		reflect(b)
+		col[k] = norm
+	}
+
+	x := make(Poly, m)
+	for k := m - 1; k >= 0; k-- {
+		s := b[k]
+		for j := k + 1; j < m; j++ { s -= a[j*n+k] * x[j] }
+		if a[k*n+k] != 0 { x[k] = s / a[k*n+k] }
+	}
+	return x.trim()
+}
+
+// Roots returns all the complex roots of p, repeated according to their
+// multiplicity. The roots are found simultaneously with the Aberth-Ehrlich
+// method and then polished with Newton iteration. Roots whose imaginary
+// parts are negligible are returned as exactly real, and the roots are
+// sorted by real part and then by imaginary part.
+//
+// Roots panics if p is a zero or constant polynomial.
+func (p Poly) Roots() []complex128 {
+	t := p.trim()
+	if len(t) <= 1 {
+		panic("poly.Roots called on a constant polynomial.")
+	}
+
+	// Factor out roots at zero.
+	zeros := 0
+	for t[zeros] == 0 { zeros++ }
+	q := t[zeros:]
+	n := len(q) - 1
+
+	roots := make([]complex128, zeros, zeros+n)
+	if n > 0 {
+		z := aberth(q)
+		for i := range z { z[i] = polish(t, z[i]) }
+		roots = append(roots, z...)
+	}
+
+	for i, z := range roots {
+		if math.Abs(imag(z)) <= realSnapTol*cmplx.Abs(z) {
+			roots[i] = complex(real(z), 0)
+		}
+	}
+
+	sort.Slice(roots, func(i, j int) bool {
+		if real(roots[i]) != real(roots[j]) {
+			return real(roots[i]) < real(roots[j])
+		}
+		return imag(roots[i]) < imag(roots[j])
+	})
+	return roots
+}
+
+// aberth finds the roots of q, which must have a nonzero constant term, with
+// the Aberth-Ehrlich method.
+func aberth(q Poly) []complex128 {
+	n := len(q) - 1
+	dq := q.Deriv()
+
+	// Initial guesses lie on a circle whose radius is the geometric mean of
+	// the root magnitudes, offset from the real axis to break symmetry.
+	r := math.Pow(math.Abs(q[0]/q[n]), 1/float64(n))
+	z := make([]complex128, n)
+	for k := range z {
+		theta := 2*math.Pi*float64(k)/float64(n) + 0.4
+		z[k] = complex(r*math.Cos(theta), r*math.Sin(theta))
+	}
+
+	for iter := 0; iter < rootIters; iter++ {
+		done := true
+		for k := range z {
+			pz, dpz := q.EvalComplex(z[k]), dq.EvalComplex(z[k])
+			if pz == 0 { continue }
+			ratio := pz / dpz
+
+			sum := complex(0, 0)
+			for j := range z {
+				if j != k { sum += 1 / (z[k] - z[j]) }
+			}
+			w := ratio / (1 - ratio*sum)
+			z[k] -= w
+
+			if cmplx.Abs(w) > 1e-15*cmplx.Abs(z[k]) { done = false }
+		}
+		if done { break }
+	}
+
+	return z
+}
+
+// polish improves the root estimate z of p with a few Newton steps, stopping
+// early if a step does not decrease |p(z)|.
+func polish(p Poly, z complex128) complex128 {
+	dp := p.Deriv()
+	pz := p.EvalComplex(z)
+	for i := 0; i < rootPolishIter; i++ {
+		dpz := dp.EvalComplex(z)
+		if dpz == 0 || pz == 0 { break }
+		next := z - pz/dpz
+		pNext := p.EvalComplex(next)
+		if cmplx.Abs(pNext) >= cmplx.Abs(pz) { break }
+		z, pz = next, pNext
+	}
+	return z
+}
+
+// Legendre returns the Legendre polynomial of degree n, P_n(x), which are
+// orthogonal on [-1, 1] with unit weight.
+func Legendre(n int) Poly {
+	return recurrence(n, Poly{1}, Poly{0, 1}, func(k int, pk, pkm1 Poly) Poly {
+		// (k + 1) P_{k+1} = (2k + 1) x P_k - k P_{k-1}
+		a := Mult(Poly{0, float64(2*k + 1)}, pk)
+		return Sub(a, pkm1.Scale(float64(k))).Scale(1 / float64(k+1))
+	})
+}
+
+// Chebyshev returns the Chebyshev polynomial of the first kind of degree n,
+// T_n(x), which are orthogonal on [-1, 1] with weight 1 / sqrt(1 - x^2).
+func Chebyshev(n int) Poly {
+	return recurrence(n, Poly{1}, Poly{0, 1}, func(k int, pk, pkm1 Poly) Poly {
+		// T_{k+1} = 2x T_k - T_{k-1}
+		return Sub(Mult(Poly{0, 2}, pk), pkm1)
+	})
+}
+
+// Hermite returns the physicists' Hermite polynomial of degree n, H_n(x),
+// which are orthogonal on (-inf, inf) with weight exp(-x^2).
+func Hermite(n int) Poly {
+	return recurrence(n, Poly{1}, Poly{0, 2}, func(k int, pk, pkm1 Poly) Poly {
+		// H_{k+1} = 2x H_k - 2k H_{k-1}
+		return Sub(Mult(Poly{0, 2}, pk), pkm1.Scale(float64(2*k)))
+	})
+}
+
+// Laguerre returns the Laguerre polynomial of degree n, L_n(x), which are
+// orthogonal on [0, inf) with weight exp(-x).
+func Laguerre(n int) Poly {
+	return recurrence(n, Poly{1}, Poly{1, -1}, func(k int, pk, pkm1 Poly) Poly {
+		// (k + 1) L_{k+1} = (2k + 1 - x) L_k - k L_{k-1}
+		a := Mult(Poly{float64(2*k + 1), -1}, pk)
+		return Sub(a, pkm1.Scale(float64(k))).Scale(1 / float64(k+1))
+	})
+}
+
+// recurrence computes the nth member of a family of polynomials defined by a
+// three-term recurrence relation, where step computes p_{k+1} from p_k and
+// p_{k-1}.
+func recurrence(n int, p0, p1 Poly, step func(k int, pk, pkm1 Poly) Poly) Poly {
+	if n < 0 {
+		panic(fmt.Sprintf("Polynomial degree %d is negative.", n))
+	} else if n == 0 {
+		return p0
+	}
+
+	prev, cur := p0, p1
+	for k := 1; k < n; k++ {
+		prev, cur = cur, step(k, cur, prev)
+	}
+	return cur
+}
@@ -0,0 +1,164 @@
+package poly
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func polyAlmostEq(p, q Poly, tol float64) bool {
+	if len(p) < len(q) { p, q = q, p }
+	for i := range p {
+		qi := 0.0
+		if i < len(q) { qi = q[i] }
+		if math.Abs(p[i]-qi) > tol { return false }
+	}
+	return true
+}
+
+func TestEval(t *testing.T) {
+	p := Poly{3, -2, 0, 1}
+
+	tests := []struct {
+		x, y, dy float64
+	}{
+		{0, 3, -2},
+		{1, 2, 1},
+		{2, 7, 10},
+		{-1, 4, 1},
+	}
+
+	for _, test := range tests {
+		if y := p.Eval(test.x); y != test.y {
+			t.Errorf("%v.Eval(%g) -> %g, wanted %g", p, test.x, y, test.y)
+		}
+		y, dy := p.EvalDeriv(test.x)
+		if y != test.y || dy != test.dy {
+			t.Errorf("%v.EvalDeriv(%g) -> (%g, %g), wanted (%g, %g)",
+				p, test.x, y, dy, test.y, test.dy)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	p, q := Poly{1, 1}, Poly{-1, 1}
+
+	tests := []struct {
+		name     string
+		res, exp Poly
+	}{
+		{"Add", Add(p, q), Poly{0, 2}},
+		{"Sub", Sub(p, p), Poly{}},
+		{"Mult", Mult(p, q), Poly{-1, 0, 1}},
+		{"Compose", Compose(Poly{0, 0, 1}, p), Poly{1, 2, 1}},
+		{"Deriv", Poly{3, -2, 0, 1}.Deriv(), Poly{-2, 0, 3}},
+		{"Antideriv", Poly{-2, 0, 3}.Antideriv(), Poly{0, -2, 0, 1}},
+		{"Scale", p.Scale(0), Poly{}},
+	}
+
+	for _, test := range tests {
+		if !polyAlmostEq(test.res, test.exp, 0) {
+			t.Errorf("%s gave %v, wanted %v", test.name, test.res, test.exp)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		p, q, quo, rem Poly
+	}{
+		{Poly{-1, 0, 1}, Poly{-1, 1}, Poly{1, 1}, Poly{}},
+		{Poly{1, 2, 3, 4}, Poly{1, 1}, Poly{3, -1, 4}, Poly{-2}},
+		{Poly{1, 2}, Poly{1, 2, 3}, Poly{}, Poly{1, 2}},
+	}
+
+	for _, test := range tests {
+		quo, rem := Div(test.p, test.q)
+		if !polyAlmostEq(quo, test.quo, 1e-12) ||
+			!polyAlmostEq(rem, test.rem, 1e-12) {
+			t.Errorf("Div(%v, %v) -> (%v, %v), wanted (%v, %v)",
+				test.p, test.q, quo, rem, test.quo, test.rem)
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	tests := []struct {
+		p   Poly
+		exp []complex128
+	}{
+		{Poly{-6, 11, -6, 1}, []complex128{1, 2, 3}},
+		{Poly{1, 0, 1}, []complex128{-1i, 1i}},
+		{Poly{0, 0, -4, 0, 1}, []complex128{-2, 0, 0, 2}},
+		{Poly{1, 0, 0, 0, 0, 1}, nil},
+	}
+
+	// The fifth roots of -1.
+	for k := 0; k < 5; k++ {
+		tests[3].exp = append(tests[3].exp,
+			cmplx.Rect(1, math.Pi*float64(2*k+1)/5))
+	}
+
+	for _, test := range tests {
+		roots := test.p.Roots()
+		if len(roots) != len(test.exp) {
+			t.Errorf("%v.Roots() -> %v, wanted %v", test.p, roots, test.exp)
+			continue
+		}
+		for _, exp := range test.exp {
+			found := false
+			for _, r := range roots {
+				if cmplx.Abs(r-exp) < 1e-10 { found = true }
+			}
+			if !found {
+				t.Errorf("%v.Roots() -> %v, wanted %v",
+					test.p, roots, test.exp)
+				break
+			}
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	p := Poly{1, -3, 0.5, 2}
+	xs := make([]float64, 20)
+	ys := make([]float64, 20)
+	ws := make([]float64, 20)
+	for i := range xs {
+		xs[i] = 10 + float64(i)/4
+		ys[i] = p.Eval(xs[i])
+		ws[i] = float64(i + 1)
+	}
+
+	if fit := Fit(xs, ys, nil, 3); !polyAlmostEq(fit, p, 1e-6) {
+		t.Errorf("Fit(degree = 3) -> %v, wanted %v", fit, p)
+	}
+	if fit := Fit(xs, ys, ws, 3); !polyAlmostEq(fit, p, 1e-6) {
+		t.Errorf("Fit(degree = 3, weighted) -> %v, wanted %v", fit, p)
+	}
+
+	// Fitting a line to points symmetric about a parabola's vertex.
+	xs, ys = []float64{-1, 0, 1}, []float64{1, 0, 1}
+	if fit := Fit(xs, ys, nil, 1); !polyAlmostEq(fit, Poly{2.0 / 3}, 1e-12) {
+		t.Errorf("Fit(degree = 1) -> %v, wanted %v", fit, Poly{2.0 / 3})
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	tests := []struct {
+		name     string
+		res, exp Poly
+	}{
+		{"Legendre(3)", Legendre(3), Poly{0, -1.5, 0, 2.5}},
+		{"Chebyshev(4)", Chebyshev(4), Poly{1, 0, -8, 0, 8}},
+		{"Hermite(3)", Hermite(3), Poly{0, -12, 0, 8}},
+		{"Laguerre(2)", Laguerre(2), Poly{1, -2, 0.5}},
+		{"Legendre(0)", Legendre(0), Poly{1}},
+	}
+
+	for _, test := range tests {
+		if !polyAlmostEq(test.res, test.exp, 1e-12) {
+			t.Errorf("%s -> %v, wanted %v", test.name, test.res, test.exp)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package num
+
+import (
+	"math"
+)
+
+const (
+	seriesTermLimit    = 1 << 20
+	seriesTailLen      = 3
+	richardsonMaxOrder = 12
+)
+
+// Series represents an infinite series, a(0) + a(1) + a(2) + ..., by a
+// function which returns its nth term.
+type Series func(n int) float64
+
+// KahanSum is an accumulator which uses Kahan-compensated summation to add
+// together a large number of values without accumulating the usual O(n)
+// roundoff error. The zero value is an empty sum.
+type KahanSum struct {
+	sum, c float64
+}
+
+// Add adds x to the sum.
+func (k *KahanSum) Add(x float64) {
+	y := x - k.c
+	t := k.sum + y
+	k.c = (t - k.sum) - y
+	k.sum = t
+}
+
+// Sum returns the current value of the sum.
+func (k *KahanSum) Sum() float64 { return k.sum }
+
+// SumSeries sums the terms of a by brute force using compensated summation.
+// Terms are added until seriesTailLen consecutive terms are negligible
+// compared to the sum, as judged by CloseEnough. The magnitude of the last
+// term added is returned as an error estimate.
+//
+// SumSeries is only appropriate for rapidly converging series. Slowly
+// converging or alternating series should use one of SumAitken, SumWynn,
+// SumLevin, or SumRichardson instead.
+func SumSeries(a Series) (sum, errEst float64) {
+	k := &KahanSum{}
+	small := 0
+	for n := 0; n < seriesTermLimit; n++ {
+		term := a(n)
+		k.Add(term)
+		errEst = math.Abs(term)
+
+		if CloseEnough(k.Sum(), term) {
+			small++
+			if small >= seriesTailLen { break }
+		} else {
+			small = 0
+		}
+	}
+
+	return k.Sum(), errEst
+}
+
+// AitkenLimit estimates the limit of the sequence s using iterated
+// applications of Aitken's delta-squared process. An estimate of the error
+// in the limit is also returned.
+//
+// AitkenLimit panics if given a sequence with fewer than three elements.
+func AitkenLimit(s []float64) (limit, errEst float64) {
+	if len(s) < 3 {
+		panic("AitkenLimit requires a sequence of at least three elements.")
+	}
+
+	prev := s[len(s)-2]
+	cur := append([]float64{}, s...)
+	for len(cur) >= 3 {
+		next := cur[:len(cur)-2]
+		for i := range next {
+			d1, d2 := cur[i+1]-cur[i], cur[i+2]-cur[i+1]
+			denom := d2 - d1
+			if denom == 0 {
+				next[i] = cur[i+2]
+			} else {
+				next[i] = cur[i+2] - d2*d2/denom
+			}
+		}
+		prev = cur[len(cur)-1]
+		cur = next
+	}
+
+	limit = cur[len(cur)-1]
+	return limit, math.Abs(limit - prev)
+}
+
+// WynnLimit estimates the limit of the sequence s using Wynn's epsilon
+// algorithm, which is equivalent to computing the Shanks transformation to
+// the highest order allowed by the length of s. An estimate of the error in
+// the limit is also returned.
+//
+// WynnLimit panics if given a sequence with fewer than three elements.
+func WynnLimit(s []float64) (limit, errEst float64) {
+	if len(s) < 3 {
+		panic("WynnLimit requires a sequence of at least three elements.")
+	}
+
+	n := len(s)
+	limit = s[n-1]
+	errEst = math.Abs(s[n-1] - s[n-2])
+
+	// prev and cur are columns k - 1 and k of the epsilon table. Only even
+	// columns contain estimates of the limit.
+	prev := make([]float64, n+1)
+	cur := append([]float64{}, s...)
+	for k := 1; len(cur) > 1; k++ {
+		next := make([]float64, len(cur)-1)
+		for i := range next {
+			d := cur[i+1] - cur[i]
+			if d == 0 { return limit, errEst }
+			next[i] = prev[i+1] + 1/d
+		}
+		prev, cur = cur, next
+
+		if k%2 == 0 {
+			est := cur[len(cur)-1]
+			errEst = math.Abs(est - limit)
+			limit = est
+		}
+	}
+
+	return limit, errEst
+}
+
+// LevinLimit estimates the limit of the sequence s using the Levin
+// u-transformation. The terms of the underlying series are taken to be the
+// differences between consecutive elements of s. An estimate of the error in
+// the limit is also returned.
+//
+// LevinLimit panics if given a sequence with fewer than three elements.
+func LevinLimit(s []float64) (limit, errEst float64) {
+	if len(s) < 3 {
+		panic("LevinLimit requires a sequence of at least three elements.")
+	}
+
+	n := len(s)
+	limit = levinU(s, n-1)
+	return limit, math.Abs(limit - levinU(s, n-2))
+}
+
+// levinU computes the order-k Levin u-transformation of the partial sums
+// s[0], ..., s[k] with beta = 1.
+func levinU(s []float64, k int) float64 {
+	const beta = 1.0
+
+	num, den := 0.0, 0.0
+	binom := 1.0
+	for j := 0; j <= k; j++ {
+		a := s[j]
+		if j > 0 { a = s[j] - s[j-1] }
+		if a == 0 { return s[j] }
+
+		omega := (beta + float64(j)) * a
+		c := binom * math.Pow((beta+float64(j))/(beta+float64(k)), float64(k-1))
+		if j%2 == 1 { c = -c }
+
+		num += c * s[j] / omega
+		den += c / omega
+		binom = binom * float64(k-j) / float64(j+1)
+	}
+
+	return num / den
+}
+
+// RichardsonLimit estimates the limit of the sequence s using Richardson
+// extrapolation under the assumption that s[n] approaches its limit as a
+// power series in 1 / (n + 1). This is the appropriate assumption for the
+// partial sums of series whose terms decay as a power law. Extrapolation is
+// done to the highest order allowed by the length of s, up to a maximum of
+// richardsonMaxOrder. An estimate of the error in the limit is also returned.
+//
+// RichardsonLimit panics if given a sequence with fewer than three elements.
+func RichardsonLimit(s []float64) (limit, errEst float64) {
+	if len(s) < 3 {
+		panic("RichardsonLimit requires a sequence of at least three elements.")
+	}
+
+	order := len(s) - 1
+	if order > richardsonMaxOrder { order = richardsonMaxOrder }
+
+	limit = richardson(s, order)
+	return limit, math.Abs(limit - richardson(s, order-1))
+}
+
+// richardson computes the order-N Richardson extrapolation of the last N + 1
+// elements of s.
+func richardson(s []float64, order int) float64 {
+	start := len(s) - 1 - order
+	sum := 0.0
+	for k := 0; k <= order; k++ {
+		n := float64(start + k + 1)
+		term := s[start+k] * math.Pow(n, float64(order)) /
+			(factorial(k) * factorial(order-k))
+		if (k+order)%2 == 1 { term = -term }
+		sum += term
+	}
+	return sum
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ { f *= float64(i) }
+	return f
+}
+
+// SumAitken sums the terms of a by accelerating its partial sums with
+// AitkenLimit. The estimate of the sum and its error are returned.
+//
+// Terms are added until the error estimate satisfies CloseEnough or until
+// ConvergenceIters terms have been summed. In the latter case the estimate
+// with the smallest error is returned.
+func SumAitken(a Series) (sum, errEst float64) {
+	return sumAccelerated(a, AitkenLimit)
+}
+
+// SumWynn sums the terms of a by accelerating its partial sums with
+// WynnLimit. The estimate of the sum and its error are returned.
+//
+// Terms are added until the error estimate satisfies CloseEnough or until
+// ConvergenceIters terms have been summed. In the latter case the estimate
+// with the smallest error is returned.
+func SumWynn(a Series) (sum, errEst float64) {
+	return sumAccelerated(a, WynnLimit)
+}
+
+// SumLevin sums the terms of a by accelerating its partial sums with
+// LevinLimit. The estimate of the sum and its error are returned.
+//
+// Terms are added until the error estimate satisfies CloseEnough or until
+// ConvergenceIters terms have been summed. In the latter case the estimate
+// with the smallest error is returned.
+func SumLevin(a Series) (sum, errEst float64) {
+	return sumAccelerated(a, LevinLimit)
+}
+
+// SumRichardson sums the terms of a by accelerating its partial sums with
+// RichardsonLimit. The estimate of the sum and its error are returned.
+//
+// Terms are added until the error estimate satisfies CloseEnough or until
+// ConvergenceIters terms have been summed. In the latter case the estimate
+// with the smallest error is returned.
+func SumRichardson(a Series) (sum, errEst float64) {
+	return sumAccelerated(a, RichardsonLimit)
+}
+
+func sumAccelerated(
+	a Series, limit func([]float64) (float64, float64),
+) (sum, errEst float64) {
+	k := &KahanSum{}
+	s := make([]float64, 0, ConvergenceIters)
+	errEst = math.Inf(+1)
+
+	for n := 0; n < ConvergenceIters; n++ {
+		k.Add(a(n))
+		s = append(s, k.Sum())
+		if len(s) < 3 { continue }
+
+		est, err := limit(s)
+		if math.IsNaN(est) || math.IsNaN(err) { continue }
+		if err < errEst { sum, errEst = est, err }
+		if CloseEnough(est, err) { return est, err }
+	}
+
+	return sum, errEst
+}
@@ -0,0 +1,160 @@
+package num
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKahanSum(t *testing.T) {
+	k := &KahanSum{}
+	k.Add(1.0)
+	for i := 0; i < 1000000; i++ {
+		k.Add(1e-16)
+	}
+
+	if exp := 1.0 + 1e-10; math.Abs(k.Sum()-exp) > 1e-16 {
+		t.Errorf("KahanSum gave %.17g, wanted %.17g", k.Sum(), exp)
+	}
+}
+
+func TestSumSeries(t *testing.T) {
+	geom := func(n int) float64 { return math.Pow(0.5, float64(n)) }
+	exp := func(n int) float64 {
+		f := 1.0
+		for i := 2; i <= n; i++ { f *= float64(i) }
+		return 1 / f
+	}
+
+	tests := []struct {
+		name string
+		a    Series
+		sum  float64
+	}{
+		{"geometric", geom, 2},
+		{"exp", exp, math.E},
+	}
+
+	for _, test := range tests {
+		sum, errEst := SumSeries(test.a)
+		if !AlmostEqual(sum, test.sum) {
+			t.Errorf("SumSeries(%s) -> %.10g, wanted %.10g",
+				test.name, sum, test.sum)
+		} else if errEst > math.Abs(sum)*ConvergenceEpsilon {
+			t.Errorf("SumSeries(%s) error estimate %g is too large",
+				test.name, errEst)
+		}
+	}
+}
+
+func TestSumAccelerated(t *testing.T) {
+	// ln(2) = 1 - 1/2 + 1/3 - ...
+	ln2 := func(n int) float64 {
+		if n%2 == 0 { return 1 / float64(n+1) }
+		return -1 / float64(n+1)
+	}
+	// pi^2 / 6 = 1 + 1/4 + 1/9 + ...
+	zeta2 := func(n int) float64 { return 1 / float64((n+1)*(n+1)) }
+
+	tests := []struct {
+		name string
+		f    func(Series) (float64, float64)
+		a    Series
+		sum  float64
+	}{
+		{"SumAitken(ln2)", SumAitken, ln2, math.Ln2},
+		{"SumWynn(ln2)", SumWynn, ln2, math.Ln2},
+		{"SumLevin(ln2)", SumLevin, ln2, math.Ln2},
+		{"SumLevin(zeta2)", SumLevin, zeta2, math.Pi * math.Pi / 6},
+		{"SumRichardson(zeta2)", SumRichardson, zeta2, math.Pi * math.Pi / 6},
+	}
+
+	for _, test := range tests {
+		sum, errEst := test.f(test.a)
+		if !AlmostEqual(sum, test.sum) {
+			t.Errorf("%s -> %.10g (err = %g), wanted %.10g",
+				test.name, sum, errEst, test.sum)
+		}
+	}
+}
+
+func TestSequenceLimits(t *testing.T) {
+	// Partial sums of the alternating series for ln(2).
+	s := make([]float64, 20)
+	sum := 0.0
+	for n := range s {
+		sum += math.Pow(-1, float64(n)) / float64(n+1)
+		s[n] = sum
+	}
+
+	tests := []struct {
+		name string
+		f    func([]float64) (float64, float64)
+	}{
+		{"AitkenLimit", AitkenLimit},
+		{"WynnLimit", WynnLimit},
+		{"LevinLimit", LevinLimit},
+	}
+
+	for _, test := range tests {
+		limit, errEst := test.f(s)
+		if !AlmostEqual(limit, math.Ln2) {
+			t.Errorf("%s -> %.10g (err = %g), wanted %.10g",
+				test.name, limit, errEst, math.Ln2)
+		}
+	}
+}
+
+func TestRichardsonLimit(t *testing.T) {
+	// The trapezoid rule for the integral of x^2 over [0, 1] with n panels
+	// is 1/3 + 1/(6 n^2), so extrapolation in 1/n is exact.
+	trap := make([]float64, 6)
+	for i := range trap {
+		n := i + 1
+		h := 1 / float64(n)
+		sum := 0.0
+		for j := 0; j <= n; j++ {
+			x := float64(j) * h
+			w := 1.0
+			if j == 0 || j == n {
+				w = 0.5
+			}
+			sum += w * x * x
+		}
+		trap[i] = sum * h
+	}
+	if limit, errEst := RichardsonLimit(trap); math.Abs(limit-1.0/3) > 1e-12 {
+		t.Errorf("RichardsonLimit(trapezoid) -> %.15g (err = %g), wanted 1/3",
+			limit, errEst)
+	}
+
+	// Partial sums of pi^2 / 6 = 1 + 1/4 + 1/9 + ... converge as 1/n, and
+	// the extrapolated limit should converge much faster as terms are added.
+	zeta2 := math.Pi * math.Pi / 6
+	s := make([]float64, 16)
+	sum := 0.0
+	for n := range s {
+		sum += 1 / float64((n+1)*(n+1))
+		s[n] = sum
+	}
+	prevErr := math.Inf(+1)
+	for _, n := range []int{4, 8, 16} {
+		limit, errEst := RichardsonLimit(s[:n])
+		err := math.Abs(limit - zeta2)
+		if err >= prevErr || err > 1e-2*(zeta2-s[n-1]) {
+			t.Errorf("RichardsonLimit of %d partial sums of zeta(2) -> "+
+				"%.15g (err = %g), wanted %.15g", n, limit, errEst, zeta2)
+		}
+		if errEst == 0 || errEst > 1e3*err+1e-12 {
+			t.Errorf("RichardsonLimit of %d partial sums gave error "+
+				"estimate %g for actual error %g", n, errEst, err)
+		}
+		prevErr = err
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RichardsonLimit of two elements did not panic")
+		}
+	}()
+	RichardsonLimit(s[:2])
+}
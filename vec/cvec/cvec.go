@@ -1,8 +1,374 @@
 /*
-package cvec implements operation for complex vectors. These operations are
-the same as in num/vec/. 
+package cvec implements operations for complex vectors. These operations
+follow the same conventions as num/vec/: unary operations are methods, binary
+operations are functions, and every operation which returns a vector has an
+in-place version whose name ends in "At" and whose last argument is the
+target.
 
-This is a holdover to make num/mat/ compile.*/
+  spec := cvec.FromInterleaved(raw)
+  power := spec.Abs()          // vec.Vector
+  vec.MultAt(power, power, power)
+  phase := spec.Phase()        // vec.Vector
+
+Two dot products are provided: Dot conjugates its first argument, so that
+Dot(v, v) is the squared norm of v, while DotU does not conjugate either
+argument.
+
+Complex vectors can be converted to and from real vectors with Real, Imag,
+Abs, Phase, FromParts, and FromPolar, and to and from the interleaved
+[re0, im0, re1, im1, ...] layout used by FFT libraries and binary files with
+Interleaved and FromInterleaved.
+
+Unless otherwise stated, all operations support vectors of all sizes and will
+panic if given vectors of different sizes as arguments.
+*/
 package cvec
 
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/phil-mansfield/num"
+	"github.com/phil-mansfield/num/vec"
+)
+
+// Vector is a type representing an ordered collection of complex numbers.
+//
+// Vector is implemented as a simple slice to allow for ease of conversion.
 type Vector []complex128
+
+// checkLen panics if the given lengths are not all the same.
+func checkLen(operationName string, lens ...int) {
+	for _, n := range lens[1:] {
+		if n != lens[0] {
+			panic(fmt.Sprintf("cvec.%s given vectors of lengths %d and %d.",
+				operationName, lens[0], n))
+		}
+	}
+}
+
+// CopyAt copies v into target.
+func (v Vector) CopyAt(target Vector) {
+	checkLen("CopyAt", len(v), len(target))
+	copy(target, v)
+}
+
+// Copy returns a copy of v.
+func (v Vector) Copy() Vector {
+	target := make([]complex128, len(v))
+	v.CopyAt(target)
+	return target
+}
+
+// AddAt computes the sum of two vectors and places the result in a
+// target vector.
+func AddAt(v1, v2, target Vector) {
+	checkLen("AddAt", len(v1), len(v2), len(target))
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] + v2[i] }
+}
+
+// SubAt computes the difference of two vectors and places the result in
+// a target vector.
+func SubAt(v1, v2, target Vector) {
+	checkLen("SubAt", len(v1), len(v2), len(target))
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] - v2[i] }
+}
+
+// MultAt computes the element-wise product of two vectors and places the
+// result in a target vector.
+func MultAt(v1, v2, target Vector) {
+	checkLen("MultAt", len(v1), len(v2), len(target))
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] * v2[i] }
+}
+
+// DivAt computes the element-wise quotient of two vectors, v1[i] / v2[i],
+// and places the result in a target vector.
+func DivAt(v1, v2, target Vector) {
+	checkLen("DivAt", len(v1), len(v2), len(target))
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] / v2[i] }
+}
+
+// AxpyAt computes alpha * x + y and places the result in a target vector.
+func AxpyAt(alpha complex128, x, y, target Vector) {
+	checkLen("AxpyAt", len(x), len(y), len(target))
+	for i := 0; i < len(x); i++ { target[i] = alpha * x[i] + y[i] }
+}
+
+// Add returns the sum of two vectors.
+func Add(v1, v2 Vector) Vector {
+	target := make([]complex128, len(v1))
+	AddAt(v1, v2, target)
+	return target
+}
+
+// Sub returns the difference of two vectors.
+func Sub(v1, v2 Vector) Vector {
+	target := make([]complex128, len(v1))
+	SubAt(v1, v2, target)
+	return target
+}
+
+// Mult returns the element-wise product of two vectors.
+func Mult(v1, v2 Vector) Vector {
+	target := make([]complex128, len(v1))
+	MultAt(v1, v2, target)
+	return target
+}
+
+// Div returns the element-wise quotient of two vectors, v1[i] / v2[i].
+func Div(v1, v2 Vector) Vector {
+	target := make([]complex128, len(v1))
+	DivAt(v1, v2, target)
+	return target
+}
+
+// Axpy returns alpha * x + y.
+func Axpy(alpha complex128, x, y Vector) Vector {
+	target := make([]complex128, len(x))
+	AxpyAt(alpha, x, y, target)
+	return target
+}
+
+// ScaleAt multiplies every element of v by a complex scaler and places the
+// result in a target vector.
+func (v Vector) ScaleAt(scaler complex128, target Vector) {
+	checkLen("ScaleAt", len(v), len(target))
+	for i := 0; i < len(v); i++ { target[i] = v[i] * scaler }
+}
+
+// Scale multiplies every element of v by a complex scaler.
+func (v Vector) Scale(scaler complex128) Vector {
+	target := make([]complex128, len(v))
+	v.ScaleAt(scaler, target)
+	return target
+}
+
+// ConjAt computes the complex conjugate of every element of v and places the
+// result in a target vector.
+func (v Vector) ConjAt(target Vector) {
+	checkLen("ConjAt", len(v), len(target))
+	for i := 0; i < len(v); i++ { target[i] = cmplx.Conj(v[i]) }
+}
+
+// Conj returns the complex conjugates of the elements of v.
+func (v Vector) Conj() Vector {
+	target := make([]complex128, len(v))
+	v.ConjAt(target)
+	return target
+}
+
+// Dot computes the conjugated dot product of two vectors, the sum of
+// conj(v1[i]) * v2[i]. Dot(v, v) is the squared norm of v.
+func Dot(v1, v2 Vector) complex128 {
+	checkLen("Dot", len(v1), len(v2))
+	sum := complex128(0)
+	for i := 0; i < len(v1); i++ {
+		sum += cmplx.Conj(v1[i]) * v2[i]
+	}
+	return sum
+}
+
+// DotU computes the unconjugated dot product of two vectors, the sum of
+// v1[i] * v2[i].
+func DotU(v1, v2 Vector) complex128 {
+	checkLen("DotU", len(v1), len(v2))
+	sum := complex128(0)
+	for i := 0; i < len(v1); i++ {
+		sum += v1[i] * v2[i]
+	}
+	return sum
+}
+
+// Norm returns the Euclidean norm (2-norm) of v, the square root of the sum
+// of |v[i]|^2. The sum is scaled as it is accumulated, so the result does not
+// overflow or underflow unless the norm itself does. If v contains a NaN, NaN
+// is returned, and otherwise if v contains an infinity, +Inf is returned.
+func (v Vector) Norm() float64 {
+	// The real and imaginary parts are treated as separate elements of a
+	// real vector, following LAPACK's dlassq.
+	scale, sumSq := 0.0, 1.0
+	inf := false
+	for _, z := range v {
+		for _, x := range [2]float64{real(z), imag(z)} {
+			if x == 0 {
+				continue
+			} else if math.IsNaN(x) {
+				return math.NaN()
+			} else if math.IsInf(x, 0) {
+				inf = true
+				continue
+			}
+			abs := math.Abs(x)
+			if abs > scale {
+				sumSq = 1 + sumSq * (scale / abs) * (scale / abs)
+				scale = abs
+			} else {
+				sumSq += (abs / scale) * (abs / scale)
+			}
+		}
+	}
+	if inf {
+		return math.Inf(+1)
+	}
+	return scale * math.Sqrt(sumSq)
+}
+
+// Norm1 returns the 1-norm of v, the sum of the absolute values of its
+// elements.
+func (v Vector) Norm1() float64 {
+	sum := 0.0
+	for _, z := range v {
+		sum += cmplx.Abs(z)
+	}
+	return sum
+}
+
+// NormInf returns the infinity-norm of v, the largest absolute value of its
+// elements. The infinity-norm of an empty vector is zero.
+func (v Vector) NormInf() float64 {
+	max := 0.0
+	for _, z := range v {
+		max = math.Max(max, cmplx.Abs(z))
+	}
+	return max
+}
+
+// RealAt places the real parts of the elements of v in a target vector.
+func (v Vector) RealAt(target vec.Vector) {
+	checkLen("RealAt", len(v), len(target))
+	for i, z := range v { target[i] = real(z) }
+}
+
+// Real returns the real parts of the elements of v.
+func (v Vector) Real() vec.Vector {
+	target := make([]float64, len(v))
+	v.RealAt(target)
+	return target
+}
+
+// ImagAt places the imaginary parts of the elements of v in a target vector.
+func (v Vector) ImagAt(target vec.Vector) {
+	checkLen("ImagAt", len(v), len(target))
+	for i, z := range v { target[i] = imag(z) }
+}
+
+// Imag returns the imaginary parts of the elements of v.
+func (v Vector) Imag() vec.Vector {
+	target := make([]float64, len(v))
+	v.ImagAt(target)
+	return target
+}
+
+// AbsAt places the absolute values (magnitudes) of the elements of v in a
+// target vector.
+func (v Vector) AbsAt(target vec.Vector) {
+	checkLen("AbsAt", len(v), len(target))
+	for i, z := range v { target[i] = cmplx.Abs(z) }
+}
+
+// Abs returns the absolute values (magnitudes) of the elements of v.
+func (v Vector) Abs() vec.Vector {
+	target := make([]float64, len(v))
+	v.AbsAt(target)
+	return target
+}
+
+// PhaseAt places the phases (arguments) of the elements of v in a target
+// vector. Phases are in the range [-Pi, Pi].
+func (v Vector) PhaseAt(target vec.Vector) {
+	checkLen("PhaseAt", len(v), len(target))
+	for i, z := range v { target[i] = cmplx.Phase(z) }
+}
+
+// Phase returns the phases (arguments) of the elements of v, in the range
+// [-Pi, Pi].
+func (v Vector) Phase() vec.Vector {
+	target := make([]float64, len(v))
+	v.PhaseAt(target)
+	return target
+}
+
+// FromPartsAt places the complex numbers re[i] + i im[i] in a target vector.
+func FromPartsAt(re, im vec.Vector, target Vector) {
+	checkLen("FromPartsAt", len(re), len(im), len(target))
+	for i := range target { target[i] = complex(re[i], im[i]) }
+}
+
+// FromParts returns the vector whose elements are re[i] + i im[i].
+func FromParts(re, im vec.Vector) Vector {
+	target := make([]complex128, len(re))
+	FromPartsAt(re, im, target)
+	return target
+}
+
+// FromPolarAt places the complex numbers with magnitudes r[i] and phases
+// theta[i] in a target vector.
+func FromPolarAt(r, theta vec.Vector, target Vector) {
+	checkLen("FromPolarAt", len(r), len(theta), len(target))
+	for i := range target { target[i] = cmplx.Rect(r[i], theta[i]) }
+}
+
+// FromPolar returns the vector whose elements have magnitudes r[i] and phases
+// theta[i].
+func FromPolar(r, theta vec.Vector) Vector {
+	target := make([]complex128, len(r))
+	FromPolarAt(r, theta, target)
+	return target
+}
+
+// InterleavedAt writes v to target in the interleaved layout
+// [re0, im0, re1, im1, ...]. target must have length 2 * len(v).
+func (v Vector) InterleavedAt(target []float64) {
+	checkLen("InterleavedAt", 2 * len(v), len(target))
+	for i, z := range v {
+		target[2 * i], target[2 * i + 1] = real(z), imag(z)
+	}
+}
+
+// Interleaved returns v in the interleaved layout [re0, im0, re1, im1, ...].
+func (v Vector) Interleaved() []float64 {
+	target := make([]float64, 2 * len(v))
+	v.InterleavedAt(target)
+	return target
+}
+
+// FromInterleavedAt reads the interleaved layout [re0, im0, re1, im1, ...]
+// from data into target. data must have length 2 * len(target).
+func FromInterleavedAt(data []float64, target Vector) {
+	checkLen("FromInterleavedAt", len(data), 2 * len(target))
+	for i := range target {
+		target[i] = complex(data[2 * i], data[2 * i + 1])
+	}
+}
+
+// FromInterleaved returns the vector stored in the interleaved layout
+// [re0, im0, re1, im1, ...].
+//
+// FromInterleaved panics if data has odd length.
+func FromInterleaved(data []float64) Vector {
+	if len(data) % 2 != 0 {
+		panic(fmt.Sprintf("cvec.FromInterleaved given data of odd length %d.",
+			len(data)))
+	}
+	target := make([]complex128, len(data) / 2)
+	FromInterleavedAt(data, target)
+	return target
+}
+
+// AlmostEqual returns true if every element in the two given vectors is equal
+// to within the library precision fraction, ConvergenceEpsilon, as defined
+// in num/config.go, relative to the larger of their magnitudes. If the
+// vectors have different lengths, AlmostEqual returns false.
+func AlmostEqual(v1, v2 Vector) bool {
+	if len(v1) != len(v2) {
+		return false
+	}
+	for i := range v1 {
+		scale := math.Max(cmplx.Abs(v1[i]), cmplx.Abs(v2[i]))
+		if !num.CloseEnough(scale, cmplx.Abs(v1[i] - v2[i])) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package cvec
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/phil-mansfield/num/vec"
+)
+
+func TestArithmetic(t *testing.T) {
+	v1, v2 := Vector{1 + 1i, 2, -3i}, Vector{2 - 1i, 1i, 4}
+
+	tests := []struct {
+		name     string
+		out, exp Vector
+	}{
+		{"Add", Add(v1, v2), Vector{3, 2 + 1i, 4 - 3i}},
+		{"Sub", Sub(v1, v2), Vector{-1 + 2i, 2 - 1i, -4 - 3i}},
+		{"Mult", Mult(v1, v2), Vector{3 + 1i, 2i, -12i}},
+		{"Div", Div(v1, v2), Vector{0.2 + 0.6i, -2i, -0.75i}},
+		{"Axpy", Axpy(1i, v1, v2), Vector{1, 3i, 7}},
+		{"Scale", v1.Scale(2i), Vector{-2 + 2i, 4i, 6}},
+		{"Conj", v1.Conj(), Vector{1 - 1i, 2, 3i}},
+		{"Copy", v1.Copy(), v1},
+	}
+	for _, test := range tests {
+		if !AlmostEqual(test.out, test.exp) {
+			t.Errorf("%s gave %v, expected %v", test.name, test.out, test.exp)
+		}
+	}
+
+	// Targets may alias the inputs.
+	v := v1.Copy()
+	MultAt(v, v.Conj(), v)
+	if !AlmostEqual(v, Vector{2, 4, 9}) {
+		t.Errorf("Aliased MultAt gave %v", v)
+	}
+
+	if d := Dot(v1, v2); d != (1-1i)*(2-1i)+2*1i+3i*4 {
+		t.Errorf("Dot gave %v", d)
+	}
+	if d := DotU(v1, v2); d != (1+1i)*(2-1i)+2*1i-3i*4 {
+		t.Errorf("DotU gave %v", d)
+	}
+	if d := Dot(v1, v1); imag(d) != 0 || math.Abs(real(d)-15) > 1e-14 {
+		t.Errorf("Dot(v, v) gave %v, expected 15", d)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Add of mismatched lengths did not panic")
+		}
+	}()
+	Add(v1, Vector{1})
+}
+
+func TestNorms(t *testing.T) {
+	v := Vector{3 + 4i, -12i, 0}
+
+	tests := []struct {
+		name     string
+		val, exp float64
+	}{
+		{"Norm", v.Norm(), 13},
+		{"Norm1", v.Norm1(), 17},
+		{"NormInf", v.NormInf(), 12},
+		{"Norm of large vector", v.Scale(1e300).Norm(), 13e300},
+		{"Norm of small vector", v.Scale(1e-300).Norm(), 13e-300},
+		{"Norm of infinite vector",
+			Vector{complex(math.Inf(+1), 1), complex(0, math.Inf(-1))}.Norm(),
+			math.Inf(+1)},
+	}
+	for _, test := range tests {
+		if test.val != test.exp && math.Abs(test.val-test.exp) > 1e-14*test.exp {
+			t.Errorf("%s = %g, expected %g", test.name, test.val, test.exp)
+		}
+	}
+	if n := (Vector{complex(math.Inf(+1), math.NaN())}).Norm(); !math.IsNaN(n) {
+		t.Errorf("Norm of vector containing NaN = %g, expected NaN", n)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	v := Vector{1 + 1i, -2, -3i}
+
+	if re, im := v.Real(), v.Imag(); !vec.AlmostEqual(re, vec.Vector{1, -2, 0}) ||
+		!vec.AlmostEqual(im, vec.Vector{1, 0, -3}) {
+		t.Errorf("Real and Imag gave %v and %v", re, im)
+	}
+	if !AlmostEqual(FromParts(v.Real(), v.Imag()), v) {
+		t.Errorf("FromParts did not invert Real and Imag")
+	}
+
+	r, theta := v.Abs(), v.Phase()
+	if !vec.AlmostEqual(r, vec.Vector{math.Sqrt2, 2, 3}) ||
+		!vec.AlmostEqual(theta, vec.Vector{math.Pi / 4, math.Pi, -math.Pi / 2}) {
+		t.Errorf("Abs and Phase gave %v and %v", r, theta)
+	}
+	if w := FromPolar(r, theta); cmplx.Abs(w[0]-v[0]) > 1e-15 ||
+		cmplx.Abs(w[1]-v[1]) > 1e-15 || cmplx.Abs(w[2]-v[2]) > 1e-15 {
+		t.Errorf("FromPolar did not invert Abs and Phase: %v", w)
+	}
+
+	data := v.Interleaved()
+	if !vec.AlmostEqual(data, vec.Vector{1, 1, -2, 0, 0, -3}) {
+		t.Errorf("Interleaved gave %v", data)
+	}
+	if w := FromInterleaved(data); !AlmostEqual(w, v) {
+		t.Errorf("FromInterleaved gave %v", w)
+	}
+
+	for _, f := range []func(){
+		func() { FromInterleaved([]float64{1, 2, 3}) },
+		func() { v.InterleavedAt(make([]float64, 3)) },
+		func() { v.AbsAt(make(vec.Vector, 2)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic from mismatched lengths")
+				}
+			}()
+			f()
+		}()
+	}
+}
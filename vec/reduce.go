@@ -0,0 +1,203 @@
+package vec
+
+import (
+	"fmt"
+	"math"
+)
+
+// Dot computes the dot product of two vectors.
+func Dot(v1, v2 Vector) float64 {
+	checkLen("Dot", v1, v2)
+
+	sum := 0.0
+	for i := 0; i < len(v1); i++ {
+		sum += v1[i] * v2[i]
+	}
+
+	return sum
+}
+
+// Norm returns the Euclidean norm (2-norm) of a given vector. The sum of
+// squares is scaled as it is accumulated, so the result does not overflow or
+// underflow unless the norm itself does. If v contains a NaN, NaN is
+// returned, and otherwise if v contains an infinity, +Inf is returned.
+func (v Vector) Norm() float64 {
+	// The norm is scale * sqrt(sumSq), following LAPACK's dlassq.
+	scale, sumSq := 0.0, 1.0
+	inf := false
+	for _, x := range v {
+		if x == 0 {
+			continue
+		} else if math.IsNaN(x) {
+			return math.NaN()
+		} else if math.IsInf(x, 0) {
+			inf = true
+			continue
+		}
+		abs := math.Abs(x)
+		if abs > scale {
+			sumSq = 1 + sumSq * (scale / abs) * (scale / abs)
+			scale = abs
+		} else {
+			sumSq += (abs / scale) * (abs / scale)
+		}
+	}
+	if inf {
+		return math.Inf(+1)
+	}
+	return scale * math.Sqrt(sumSq)
+}
+
+// Norm1 returns the 1-norm of v, the sum of the absolute values of its
+// elements.
+func (v Vector) Norm1() float64 {
+	var s kahanSum
+	for _, x := range v {
+		s.add(math.Abs(x))
+	}
+	return s.value()
+}
+
+// NormInf returns the infinity-norm of v, the largest absolute value of its
+// elements. The infinity-norm of an empty vector is zero.
+func (v Vector) NormInf() float64 {
+	max := 0.0
+	for _, x := range v {
+		max = math.Max(max, math.Abs(x))
+	}
+	return max
+}
+
+// NormP returns the p-norm of v, (sum |v[i]|^p)^(1/p). p may be +Inf, in
+// which case the infinity-norm is returned. The elements are scaled by the
+// infinity-norm before being raised to the pth power, so the result does not
+// overflow unless the norm itself does.
+//
+// NormP panics if p < 1.
+func (v Vector) NormP(p float64) float64 {
+	switch {
+	case !(p >= 1):
+		panic(fmt.Sprintf("vec.NormP given p = %g, which is less than 1.", p))
+	case p == 1:
+		return v.Norm1()
+	case p == 2:
+		return v.Norm()
+	case math.IsInf(p, +1):
+		return v.NormInf()
+	}
+
+	scale := v.NormInf()
+	if scale == 0 || math.IsInf(scale, 0) {
+		return scale
+	}
+	var s kahanSum
+	for _, x := range v {
+		s.add(math.Pow(math.Abs(x) / scale, p))
+	}
+	return scale * math.Pow(s.value(), 1 / p)
+}
+
+// kahanSum accumulates a compensated sum with the Kahan-Babuska (Neumaier)
+// algorithm. Its zero value is an empty sum.
+type kahanSum struct {
+	sum, c float64
+}
+
+// add adds x to the sum.
+func (s *kahanSum) add(x float64) {
+	t := s.sum + x
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.c += (s.sum - t) + x
+	} else {
+		s.c += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+// value returns the current value of the sum.
+func (s *kahanSum) value() float64 {
+	if math.IsInf(s.sum, 0) || math.IsNaN(s.sum) {
+		return s.sum
+	}
+	return s.sum + s.c
+}
+
+// Sum returns the sum of the elements of v. The sum is compensated, so its
+// error is of order machine epsilon times the sum of the absolute values of
+// the elements, independent of the length of v.
+func (v Vector) Sum() float64 {
+	var s kahanSum
+	for _, x := range v {
+		s.add(x)
+	}
+	return s.value()
+}
+
+// CumSumAt computes the cumulative sums of v, where element i is the sum of
+// v[0] through v[i], and places the result in a target vector. The sums are
+// compensated as in Sum.
+func (v Vector) CumSumAt(target Vector) {
+	checkLen("CumSumAt", v, target)
+	var s kahanSum
+	for i, x := range v {
+		s.add(x)
+		target[i] = s.value()
+	}
+}
+
+// CumSum returns the cumulative sums of v, where element i is the sum of v[0]
+// through v[i].
+func (v Vector) CumSum() Vector {
+	target := make([]float64, len(v))
+	v.CumSumAt(target)
+	return target
+}
+
+// Min returns the smallest element of v. If v contains a NaN, NaN is
+// returned.
+//
+// Min panics if v is empty.
+func (v Vector) Min() float64 {
+	return v[v.argExtremum("Min", -1)]
+}
+
+// Max returns the largest element of v. If v contains a NaN, NaN is
+// returned.
+//
+// Max panics if v is empty.
+func (v Vector) Max() float64 {
+	return v[v.argExtremum("Max", +1)]
+}
+
+// ArgMin returns the index of the smallest element of v. If there are ties,
+// the first index is returned. If v contains a NaN, the index of the first
+// NaN is returned.
+//
+// ArgMin panics if v is empty.
+func (v Vector) ArgMin() int {
+	return v.argExtremum("ArgMin", -1)
+}
+
+// ArgMax returns the index of the largest element of v. If there are ties,
+// the first index is returned. If v contains a NaN, the index of the first
+// NaN is returned.
+//
+// ArgMax panics if v is empty.
+func (v Vector) ArgMax() int {
+	return v.argExtremum("ArgMax", +1)
+}
+
+// argExtremum returns the index of the first maximum of sign * v, or of the
+// first NaN.
+func (v Vector) argExtremum(operationName string, sign float64) int {
+	checkNonEmpty(operationName, v)
+	best := 0
+	for i, x := range v {
+		if math.IsNaN(x) {
+			return i
+		} else if sign * x > sign * v[best] {
+			best = i
+		}
+	}
+	return best
+}
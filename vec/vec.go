@@ -2,8 +2,413 @@
 package vec implements operations for real-valued vectors. These same
 operations are implemented for complex vectors in the subpackage cvec/.
 
-Placeholder package to make sure that the mat/ pacakge 
+All unary operations are implemented as methods while all binary operations are
+implemented as straight functions. Any operation which returns a vector is also
+implemented as an in-place operation whose last arguement is the target and
+whose name now ends with the word "At".
+
+  // returned result
+  result = vec.Cross(v1, v2)
+  // in-place modification of result
+  vec.CrossAt(v1, v2, result)
+
+This latter interface is slightly less conveinent but skips an allocation call,
+allowing for more efficient execution.
+
+All in-place operations are garuanteed to give correct results if the result
+vector is one of the arguments, but may not work correctly if the result
+overlaps with the arguments in other ways.
+
+  base := []float64{ .. }
+  // correct usage
+  vec.AddAt(base[10: 20], base[20: 30], base[10: 20])
+  // incorrect usage
+  vec.AddAt(base[10: 20], base[20: 30], base[15: 25])
+
+In the case where a large number of vectors are being stored in an array and
+performance is of prime importance, the user is adviced to make a single
+vector and use IdxSlice to access vectors instead of using an array of Vectors.
+
+  // This code will fill an array of vectors with copies of v.
+  elemNum := ...
+  v := ...
+  // This code will consume more memory and result in more cache misses
+  vecsSlow := make([]vec.Vector, elemNum)
+  for i := 0; i < len(vecsSlow); i++ {
+      vecsSlow[i] = v.Copy()
+  }
+  // This code will result in better performance.
+  var vecsFast vec.Vector = make([]float64, elemNum * len(v))
+  for i := 0; i < elemNum; i++ {
+      v.CopyAt(vecsFast.IdxSlice(len(v), i))
+  }
+
+The package provides element-wise arithmetic (Add, Sub, Mult, Div, Scale,
+and Axpy), products and norms (Dot, Cross, Norm, Norm1, NormInf, and NormP),
+reductions (Sum, CumSum, Min, Max, ArgMin, and ArgMax), the constructors
+Linspace and Logspace, and comparisons (AlmostEqual and AlmostEqualTol). Sums
+are computed with compensated (Kahan-Babuska) summation, so their error does
+not grow with the length of the vector.
+
+Unless otherwise stated, all operations support vectors of all sizes and will
+panic if given vectors of different sizes as arguments.
 */
 package vec
 
+import (
+	"fmt"
+	"math"
+
+	"github.com/phil-mansfield/num"
+)
+
+// Vector is a type representing an ordered collection of real numbers. It
+// is the type upon which this entire package is built.
+//
+// Vector is implemented as a simple slice to allow for ease of conversion.
 type Vector []float64
+
+// checkLen panics if the lengths of the given vectors are not all the same.
+func checkLen(operationName string, vs ...Vector) {
+	for _, v := range vs[1:] {
+		if len(v) != len(vs[0]) {
+			panic(fmt.Sprintf("vec.%s given vectors of lengths %d and %d.",
+				operationName, len(vs[0]), len(v)))
+		}
+	}
+}
+
+// checkNonEmpty panics if v is empty.
+func checkNonEmpty(operationName string, v Vector) {
+	if len(v) == 0 {
+		panic(fmt.Sprintf("vec.%s given an empty vector.", operationName))
+	}
+}
+
+func (v Vector) Print(fmtStr string) {
+	fmt.Print("[")
+	for i := 0; i < len(v); i++ {
+		fmt.Printf(fmtStr, v[i])
+		if i != len(v) - 1 { fmt.Printf(", ") }
+	}
+	fmt.Print("]")
+}
+
+func (v Vector) Println(fmtStr string) {
+	v.Print(fmtStr)
+	fmt.Println()
+}
+
+// FromAnglesAt converts a radius and a set of angles into a 2-dimensional or
+// 3-dimensional vector and places the result in a target vector.
+//
+// If one angle is given, it is taken to be the vector's azimuthal angle, phi,
+// from the x-axis. If two angles are given, the first is taken to be the vector's
+// polar angle, theta, from the z-axis and the second is taken to be the vector's
+// azimuthal angle, phi, from the x-axis.
+func FromAnglesAt(r float64, angles []float64, target Vector) {
+	if len(target) != len(angles) + 1 {
+		panic(fmt.Sprintf("vec.FromAnglesAt given %d angles and a target "+
+			"of length %d.", len(angles), len(target)))
+	}
+
+	if len(angles) == 1 {
+		// Cylindircal
+		target[0] = r * math.Cos(angles[0])
+		target[1] = r * math.Sin(angles[0])
+	} else if len(angles) == 2 {
+		// Spherical
+		target[0] = r * math.Sin(angles[0]) * math.Cos(angles[1])
+		target[1] =	r * math.Sin(angles[0]) * math.Sin(angles[1])
+		target[2] = r * math.Cos(angles[0])
+	} else {
+		panic("FromAngles currently only supports 2 and 3-vectors")
+	}
+}
+
+// FromAngles converts a radius and a set of angles into a 2-dimensional or
+// 3-dimensional vector.
+//
+// If one angle is given, it is taken to be the vector's azimuthal angle, phi,
+// from the x-axis. If two angles are given, the first is taken to be the vector's
+// polar angle, theta, from the z-axis and the second is taken to be the vector's
+// azimuthal angle, phi, from the x-axis.
+func FromAngles(r float64, angles []float64) Vector {
+	target := make([]float64, len(angles) + 1)
+	FromAnglesAt(r, angles, target)
+	return target
+}
+
+// LinspaceAt fills target with len(target) evenly spaced values from start to
+// end, inclusive. If target has length one, it is set to start.
+func LinspaceAt(start, end float64, target Vector) {
+	n := len(target)
+	if n == 1 {
+		target[0] = start
+		return
+	}
+
+	dx := (end - start) / float64(n - 1)
+	for i := range target {
+		target[i] = start + float64(i) * dx
+	}
+	if n > 1 {
+		target[n - 1] = end
+	}
+}
+
+// Linspace returns n evenly spaced values from start to end, inclusive.
+//
+// Linspace panics if n is non-positive.
+func Linspace(start, end float64, n int) Vector {
+	if n <= 0 {
+		panic(fmt.Sprintf("vec.Linspace given non-positive length %d.", n))
+	}
+	target := make([]float64, n)
+	LinspaceAt(start, end, target)
+	return target
+}
+
+// LogspaceAt fills target with len(target) values from 10^start to 10^end,
+// inclusive, which are evenly spaced in log space.
+func LogspaceAt(start, end float64, target Vector) {
+	LinspaceAt(start, end, target)
+	for i, x := range target {
+		target[i] = math.Pow(10, x)
+	}
+}
+
+// Logspace returns n values from 10^start to 10^end, inclusive, which are
+// evenly spaced in log space.
+//
+// Logspace panics if n is non-positive.
+func Logspace(start, end float64, n int) Vector {
+	if n <= 0 {
+		panic(fmt.Sprintf("vec.Logspace given non-positive length %d.", n))
+	}
+	target := make([]float64, n)
+	LogspaceAt(start, end, target)
+	return target
+}
+
+// IdxSlice returns the vector corresponding to the idxth sub-vector
+// of v which is of length width.
+//
+// The primary use of this method is to emulate accessing an array of
+// vectors while still maintaining favorable cache and memory properties.
+func (v Vector) IdxSlice(width, idx int) Vector {
+	start := width * idx
+	end := width * (idx + 1)
+
+	if start < 0 || end > len(v) {
+		panic(fmt.Sprintf("vec.IdxSlice index %d with width %d is out of "+
+			"range for a vector of length %d.", idx, width, len(v)))
+	}
+
+	return v[start: end]
+}
+
+// CopyAt copies v into target.
+func (v Vector) CopyAt(target Vector) {
+	checkLen("CopyAt", v, target)
+	copy(target, v)
+}
+
+// Copy returns a copy of v.
+func (v Vector) Copy() Vector {
+	target := make([]float64, len(v))
+	v.CopyAt(target)
+	return target
+}
+
+// AddAt computes the sum of two vectors and places the result in a
+// target vector.
+func AddAt(v1, v2, target Vector) {
+	checkLen("AddAt", v1, v2, target)
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] + v2[i] }
+}
+
+// SubAt computes the difference of two vectors and places the result in
+// a target vector.
+func SubAt(v1, v2, target Vector) {
+	checkLen("SubAt", v1, v2, target)
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] - v2[i] }
+}
+
+// MultAt computes the element-wise product of two vectors and places the
+// result in a target vector.
+func MultAt(v1, v2, target Vector) {
+	checkLen("MultAt", v1, v2, target)
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] * v2[i] }
+}
+
+// DivAt computes the element-wise quotient of two vectors, v1[i] / v2[i],
+// and places the result in a target vector.
+func DivAt(v1, v2, target Vector) {
+	checkLen("DivAt", v1, v2, target)
+	for i := 0; i < len(v1); i++ { target[i] = v1[i] / v2[i] }
+}
+
+// AxpyAt computes alpha * x + y and places the result in a target vector.
+func AxpyAt(alpha float64, x, y, target Vector) {
+	checkLen("AxpyAt", x, y, target)
+	for i := 0; i < len(x); i++ { target[i] = alpha * x[i] + y[i] }
+}
+
+// CrossAt computes the cross product of two vectors and places the result
+// in a target vector.
+//
+// This function panics if given vectors with lengths other than 3.
+func CrossAt(v1, v2, target Vector) {
+	if len(target) != 3 ||  len(v1) != 3 || len(v2) != 3 {
+		panic(fmt.Sprintf("vec.CrossAt given vectors of lengths %d, %d, "+
+			"and %d instead of 3.", len(v1), len(v2), len(target)))
+	}
+
+	// Doing this with a for loop is either slow or will break invariants
+	x := v1[1] * v2[2] - v1[2] * v2[1]
+	y := v1[2] * v2[0] - v1[0] * v2[2]
+	z := v1[0] * v2[1] - v1[1] * v2[0]
+	target[0] = x
+	target[1] = y
+	target[2] = z
+}
+
+// Add returns the sum of two vectors.
+func Add(v1, v2 Vector) Vector {
+	target := make([]float64, len(v1))
+	AddAt(v1, v2, target)
+	return target
+}
+
+// Sub returns the difference of two vectors.
+func Sub(v1, v2 Vector) Vector {
+	target := make([]float64, len(v1))
+	SubAt(v1, v2, target)
+	return target
+}
+
+// Mult returns the element-wise product of two vectors.
+func Mult(v1, v2 Vector) Vector {
+	target := make([]float64, len(v1))
+	MultAt(v1, v2, target)
+	return target
+}
+
+// Div returns the element-wise quotient of two vectors, v1[i] / v2[i].
+func Div(v1, v2 Vector) Vector {
+	target := make([]float64, len(v1))
+	DivAt(v1, v2, target)
+	return target
+}
+
+// Axpy returns alpha * x + y.
+func Axpy(alpha float64, x, y Vector) Vector {
+	target := make([]float64, len(x))
+	AxpyAt(alpha, x, y, target)
+	return target
+}
+
+// Cross returns the cross product of two vectors.
+func Cross(v1, v2 Vector) Vector {
+	target := make([]float64, 3)
+	CrossAt(v1, v2, target)
+	return target
+}
+
+// Scale at multiplies every element of a given vector by a given scaler
+// and places the result in a target vector.
+func (v Vector) ScaleAt(scaler float64, target Vector) {
+	checkLen("ScaleAt", v, target)
+	for i := 0; i < len(v); i++ {
+		target[i] = v[i] * scaler
+	}
+}
+
+// Scale multiplies every element of a given vector by a given scaler.
+func (v Vector) Scale(scaler float64) Vector {
+	target := make([]float64, len(v))
+	v.ScaleAt(scaler, target)
+	return target
+}
+
+// AbsAt computes the absolute value of every element of v and places the
+// result in a target vector.
+func (v Vector) AbsAt(target Vector) {
+	checkLen("AbsAt", v, target)
+	for i := 0; i < len(v); i++ {
+		target[i] = math.Abs(v[i])
+	}
+}
+
+// Abs returns the absolute values of the elements of v.
+func (v Vector) Abs() Vector {
+	target := make([]float64, len(v))
+	v.AbsAt(target)
+	return target
+}
+
+// NormalizeAt computes a norm-1 vector which points in the same direction as
+// a given vector and places the result in a target vector.
+func (v Vector) NormalizeAt(target Vector) {
+	checkLen("NormalizeAt", v, target)
+	v.ScaleAt(1 / v.Norm(), target)
+}
+
+// Normalize returns a norm-1 vector which points in the same direction as
+// a given vector.
+func (v Vector) Normalize() Vector {
+	target := make([]float64, len(v))
+	v.NormalizeAt(target)
+	return target
+}
+
+// AlmostEqual returns true if every element in the two given vectors is equal
+// to within the library precision fraction, ConvergenceEpsilon, as defined
+// in num/config.go. If the vectors have different lengths, AlmostEqual returns
+// false.
+func AlmostEqual(v1, v2 Vector) bool {
+	if len(v1) != len(v2) {
+		return false
+	}
+	for i := range v1 {
+		if !num.AlmostEqual(v1[i], v2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AlmostEqualTol returns true if every pair of elements in the two given
+// vectors satisfies |v1[i] - v2[i]| <= atol + rtol * max(|v1[i]|, |v2[i]|).
+// It also returns the index of the element with the largest ratio of its
+// difference to its allowed difference, which is the offending element when
+// AlmostEqualTol returns false. NaNs are never equal to anything, while
+// infinities are equal to infinities of the same sign.
+//
+// If the two vectors have different lengths, AlmostEqualTol returns false
+// and -1.
+func AlmostEqualTol(v1, v2 Vector, rtol, atol float64) (bool, int) {
+	if len(v1) != len(v2) {
+		return false, -1
+	}
+
+	worst, worstRatio := -1, -1.0
+	for i := range v1 {
+		diff := math.Abs(v1[i] - v2[i])
+		tol := atol + rtol * math.Max(math.Abs(v1[i]), math.Abs(v2[i]))
+
+		// Differences involving infinities are NaN or Inf, as is their
+		// tolerance, so they are handled separately.
+		ratio := 0.0
+		if (math.IsNaN(diff) || math.IsInf(diff, 0)) && v1[i] != v2[i] {
+			ratio = math.Inf(+1)
+		} else if diff > 0 {
+			ratio = diff / tol
+		}
+		if ratio > worstRatio {
+			worst, worstRatio = i, ratio
+		}
+	}
+	return worstRatio <= 1, worst
+}
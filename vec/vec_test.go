@@ -0,0 +1,204 @@
+package vec
+
+import (
+	"math"
+	"testing"
+)
+
+func eq(v1, v2 Vector) bool {
+	if len(v1) != len(v2) {
+		return false
+	}
+	for i := range v1 {
+		if v1[i] != v2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestArithmetic(t *testing.T) {
+	v1, v2 := Vector{1, 2, 3}, Vector{4, -5, 0.5}
+
+	tests := []struct {
+		name     string
+		out, exp Vector
+	}{
+		{"Add", Add(v1, v2), Vector{5, -3, 3.5}},
+		{"Sub", Sub(v1, v2), Vector{-3, 7, 2.5}},
+		{"Mult", Mult(v1, v2), Vector{4, -10, 1.5}},
+		{"Div", Div(v1, v2), Vector{0.25, -0.4, 6}},
+		{"Axpy", Axpy(2, v1, v2), Vector{6, -1, 6.5}},
+		{"Cross", Cross(v1, v2), Vector{16, 11.5, -13}},
+		{"Scale", v1.Scale(-2), Vector{-2, -4, -6}},
+		{"Abs", v2.Abs(), Vector{4, 5, 0.5}},
+		{"Normalize", Vector{3, 0, 4}.Normalize(), Vector{0.6, 0, 0.8}},
+		{"Copy", v1.Copy(), v1},
+		{"FromAngles", FromAngles(2, []float64{0}), Vector{2, 0}},
+	}
+	for _, test := range tests {
+		if !AlmostEqual(test.out, test.exp) {
+			t.Errorf("%s gave %v, expected %v", test.name, test.out, test.exp)
+		}
+	}
+
+	// Targets may alias the inputs.
+	v := v1.Copy()
+	AxpyAt(-1, v, v, v)
+	if !eq(v, Vector{0, 0, 0}) {
+		t.Errorf("Aliased AxpyAt gave %v", v)
+	}
+	v = v1.Copy()
+	CrossAt(v, v2, v)
+	if !eq(v, Cross(v1, v2)) {
+		t.Errorf("Aliased CrossAt gave %v", v)
+	}
+
+	if Dot(v1, v2) != -4.5 {
+		t.Errorf("Dot gave %g", Dot(v1, v2))
+	}
+
+	packed := Vector{1, 2, 3, 4, 5, 6}
+	if s := packed.IdxSlice(2, 2); !eq(s, Vector{5, 6}) {
+		t.Errorf("IdxSlice gave %v", s)
+	}
+
+	for _, f := range []func(){
+		func() { Add(v1, Vector{1}) },
+		func() { v1.ScaleAt(2, Vector{1, 2}) },
+		func() { Cross(Vector{1, 2}, Vector{3, 4}) },
+		func() { packed.IdxSlice(4, 1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic from mismatched lengths")
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestNorms(t *testing.T) {
+	v := Vector{3, -4, 0, 12}
+
+	tests := []struct {
+		name     string
+		val, exp float64
+	}{
+		{"Norm", v.Norm(), 13},
+		{"Norm1", v.Norm1(), 19},
+		{"NormInf", v.NormInf(), 12},
+		{"NormP(1)", v.NormP(1), 19},
+		{"NormP(3)", v.NormP(3), math.Cbrt(27 + 64 + 1728)},
+		{"NormP(Inf)", v.NormP(math.Inf(+1)), 12},
+		{"Norm of large vector", v.Scale(1e300).Norm(), 13e300},
+		{"NormP of large vector", v.Scale(1e300).NormP(3), math.Cbrt(27+64+1728) * 1e300},
+		{"Norm of small vector", v.Scale(1e-300).Norm(), 13e-300},
+		{"Norm of empty vector", Vector{}.Norm(), 0},
+		{"Norm of infinite vector", Vector{math.Inf(+1), 1, math.Inf(-1)}.Norm(),
+			math.Inf(+1)},
+	}
+	for _, test := range tests {
+		if test.val != test.exp && math.Abs(test.val-test.exp) > 1e-14*test.exp {
+			t.Errorf("%s = %g, expected %g", test.name, test.val, test.exp)
+		}
+	}
+	if n := (Vector{math.Inf(+1), math.NaN()}).Norm(); !math.IsNaN(n) {
+		t.Errorf("Norm of vector containing NaN = %g, expected NaN", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NormP(0.5) did not panic")
+		}
+	}()
+	v.NormP(0.5)
+}
+
+func TestReductions(t *testing.T) {
+	// A naive sum of these elements is 0.
+	v := Vector{1, 1e100, 1, -1e100}
+	if sum := v.Sum(); sum != 2 {
+		t.Errorf("Sum gave %g, expected 2", sum)
+	}
+	if cs := v.CumSum(); !eq(cs, Vector{1, 1e100, 1e100, 2}) {
+		t.Errorf("CumSum gave %v", cs)
+	}
+
+	// The error of a naive sum of n copies of 0.1 grows with n.
+	n := 1000000
+	tenths := make(Vector, n)
+	for i := range tenths {
+		tenths[i] = 0.1
+	}
+	if sum := tenths.Sum(); math.Abs(sum-float64(n)/10) > 1e-16*float64(n) {
+		t.Errorf("Sum of %d tenths gave %.17g", n, sum)
+	}
+
+	w := Vector{3, -1, 4, -1, 5}
+	if w.Min() != -1 || w.ArgMin() != 1 || w.Max() != 5 || w.ArgMax() != 4 {
+		t.Errorf("Min/ArgMin/Max/ArgMax gave %g, %d, %g, %d",
+			w.Min(), w.ArgMin(), w.Max(), w.ArgMax())
+	}
+	nan := Vector{1, math.NaN(), 0}
+	if !math.IsNaN(nan.Min()) || nan.ArgMax() != 1 {
+		t.Errorf("Min and ArgMax ignored a NaN")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Max of empty vector did not panic")
+		}
+	}()
+	Vector{}.Max()
+}
+
+func TestSpaces(t *testing.T) {
+	if v := Linspace(0, 1, 5); !eq(v, Vector{0, 0.25, 0.5, 0.75, 1}) {
+		t.Errorf("Linspace gave %v", v)
+	}
+	if v := Linspace(0.1, 0.7, 7); v[6] != 0.7 {
+		t.Errorf("Linspace did not end exactly at its endpoint: %v", v)
+	}
+	if v := Linspace(3, 4, 1); !eq(v, Vector{3}) {
+		t.Errorf("Linspace of length 1 gave %v", v)
+	}
+	if v := Logspace(0, 3, 4); !AlmostEqual(v, Vector{1, 10, 100, 1000}) {
+		t.Errorf("Logspace gave %v", v)
+	}
+}
+
+func TestAlmostEqualTol(t *testing.T) {
+	v1 := Vector{1, 100, math.Inf(+1)}
+	v2 := Vector{1.02, 101, math.Inf(+1)}
+
+	tests := []struct {
+		rtol, atol float64
+		ok         bool
+		idx        int
+	}{
+		{0.02, 0, true, 0},
+		{0.01, 0, false, 0},
+		{0, 0.5, false, 1},
+	}
+	for i, test := range tests {
+		ok, idx := AlmostEqualTol(v1, v2, test.rtol, test.atol)
+		if ok != test.ok || idx != test.idx {
+			t.Errorf("%d) AlmostEqualTol gave %v, %d", i, ok, idx)
+		}
+	}
+
+	if ok, idx := AlmostEqualTol(Vector{math.NaN()}, Vector{math.NaN()}, 1, 1); ok || idx != 0 {
+		t.Errorf("AlmostEqualTol of NaNs gave %v, %d", ok, idx)
+	}
+	for _, v := range []float64{math.Inf(-1), 1} {
+		if ok, idx := AlmostEqualTol(Vector{math.Inf(+1)}, Vector{v}, 1e-9, 0); ok || idx != 0 {
+			t.Errorf("AlmostEqualTol of Inf and %g gave %v, %d", v, ok, idx)
+		}
+	}
+	if ok, idx := AlmostEqualTol(v1, Vector{1}, 1, 1); ok || idx != -1 {
+		t.Errorf("AlmostEqualTol of different lengths gave %v, %d", ok, idx)
+	}
+}